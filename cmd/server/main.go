@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,22 +15,33 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/themobileprof/momlaunchpad-be/internal/api"
 	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/billing"
 	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
+	"github.com/themobileprof/momlaunchpad-be/internal/careplan"
 	"github.com/themobileprof/momlaunchpad-be/internal/chat"
 	"github.com/themobileprof/momlaunchpad-be/internal/classifier"
+	"github.com/themobileprof/momlaunchpad-be/internal/codes"
 	"github.com/themobileprof/momlaunchpad-be/internal/community"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
 	"github.com/themobileprof/momlaunchpad-be/internal/language"
 	"github.com/themobileprof/momlaunchpad-be/internal/memory"
+	"github.com/themobileprof/momlaunchpad-be/internal/outbound"
+	"github.com/themobileprof/momlaunchpad-be/internal/phone"
 	"github.com/themobileprof/momlaunchpad-be/internal/prompt"
+	"github.com/themobileprof/momlaunchpad-be/internal/reminders"
 	"github.com/themobileprof/momlaunchpad-be/internal/storage"
 	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 	"github.com/themobileprof/momlaunchpad-be/internal/symptoms"
+	"github.com/themobileprof/momlaunchpad-be/internal/usage"
 	"github.com/themobileprof/momlaunchpad-be/internal/welcome"
 	"github.com/themobileprof/momlaunchpad-be/internal/ws"
 	"github.com/themobileprof/momlaunchpad-be/pkg/deepseek"
+	"github.com/themobileprof/momlaunchpad-be/pkg/embedding"
 	"github.com/themobileprof/momlaunchpad-be/pkg/gemini"
 	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+	"github.com/themobileprof/momlaunchpad-be/pkg/ollama"
+	"github.com/themobileprof/momlaunchpad-be/pkg/openai"
 	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
 )
 
@@ -41,7 +54,8 @@ func main() {
 	// Get configuration from environment
 	port := getEnv("PORT", "8080")
 	databaseURL := getEnv("DATABASE_URL", "")
-	llmProvider := getEnv("LLM_PROVIDER", "deepseek") // Default to deepseek
+	llmProvider := getEnv("LLM_PROVIDER", "deepseek") // Primary provider when LLM_PROVIDERS is unset
+	llmRouteSpec := getEnv("LLM_PROVIDERS", llmProvider+",gemini,deepseek")
 	deepseekAPIKey := getEnv("DEEPSEEK_API_KEY", "")
 	geminiAPIKey := getEnv("GEMINI_API_KEY", "")
 	jwtSecret := getEnv("JWT_SECRET", "")
@@ -52,10 +66,10 @@ func main() {
 	if databaseURL == "" {
 		log.Fatal("DATABASE_URL is required")
 	}
-	if llmProvider == "deepseek" && deepseekAPIKey == "" {
+	if os.Getenv("LLM_PROVIDERS") == "" && llmProvider == "deepseek" && deepseekAPIKey == "" {
 		log.Fatal("DEEPSEEK_API_KEY is required for deepseek provider")
 	}
-	if llmProvider == "gemini" && geminiAPIKey == "" {
+	if os.Getenv("LLM_PROVIDERS") == "" && llmProvider == "gemini" && geminiAPIKey == "" {
 		log.Fatal("GEMINI_API_KEY is required for gemini provider")
 	}
 	if jwtSecret == "" {
@@ -76,49 +90,86 @@ func main() {
 	memAdapter := db.NewMemoryAdapter(database)
 	memMgr := memory.NewMemoryManager(10, memAdapter) // Keep last 10 messages, load from DB
 
-	// Initialize LLM client
-	var llmClient llm.Client
-	switch llmProvider {
-	case "gemini":
-		llmClient = gemini.NewHTTPClient(gemini.Config{
+	// Initialize LLM router (shared by chat, titles, summaries, welcome and moderation).
+	// LLM_PROVIDERS sets failover order with optional A/B weights, e.g. "gemini:70,deepseek:30";
+	// <PROVIDER>_TASK_MODELS picks a model per task, e.g. "chat=gemini-2.5-flash,title=gemini-2.0-flash-lite".
+	llmClients := make(map[string]llm.Client)
+	if geminiAPIKey != "" {
+		llmClients["gemini"] = gemini.NewHTTPClient(gemini.Config{
 			APIKey: geminiAPIKey,
+			Model:  getEnv("GEMINI_MODEL", ""),
 		})
-		log.Println("✅ Initialize Gemini LLM client")
-	case "deepseek":
-		fallthrough
-	default:
-		llmClient = deepseek.NewHTTPClient(deepseek.Config{
+	}
+	if deepseekAPIKey != "" {
+		llmClients["deepseek"] = deepseek.NewHTTPClient(deepseek.Config{
 			APIKey: deepseekAPIKey,
+			Model:  getEnv("DEEPSEEK_MODEL", ""),
+		})
+	}
+	if openaiBaseURL := getEnv("OPENAI_BASE_URL", ""); openaiBaseURL != "" || getEnv("OPENAI_API_KEY", "") != "" {
+		llmClients["openai"] = openai.NewHTTPClient(openai.Config{
+			APIKey:     getEnv("OPENAI_API_KEY", ""),
+			BaseURL:    openaiBaseURL,
+			Model:      getEnv("OPENAI_MODEL", ""),
+			AuthHeader: getEnv("OPENAI_AUTH_HEADER", ""),
+		})
+	}
+	if ollamaBaseURL := getEnv("OLLAMA_BASE_URL", ""); ollamaBaseURL != "" {
+		llmClients["ollama"] = ollama.NewHTTPClient(ollama.Config{
+			BaseURL:   ollamaBaseURL,
+			Model:     getEnv("OLLAMA_MODEL", ""),
+			KeepAlive: getEnv("OLLAMA_KEEP_ALIVE", ""),
 		})
-		log.Println("✅ Initialize DeepSeek LLM client")
 	}
 
-	var welcomeGemini llm.Client
-	if geminiAPIKey != "" {
-		welcomeGemini = gemini.NewHTTPClient(gemini.Config{APIKey: geminiAPIKey})
+	llmRoute, err := llm.ParseRoute(llmRouteSpec)
+	if err != nil {
+		log.Fatalf("Invalid LLM_PROVIDERS: %v", err)
 	}
+	var llmProviders []llm.Provider
+	seenProviders := make(map[string]bool)
+	for _, entry := range llmRoute {
+		if seenProviders[entry.Name] {
+			continue
+		}
+		seenProviders[entry.Name] = true
 
-	var welcomeDeepseek llm.Client
-	if deepseekAPIKey != "" {
-		welcomeDeepseek = deepseek.NewHTTPClient(deepseek.Config{
-			APIKey: deepseekAPIKey,
+		client, ok := llmClients[entry.Name]
+		if !ok {
+			log.Printf("⚠️  LLM provider %q is not configured — skipping", entry.Name)
+			continue
+		}
+		models, err := llm.ParseTaskModels(getEnv(strings.ToUpper(entry.Name)+"_TASK_MODELS", ""))
+		if err != nil {
+			log.Fatalf("Invalid %s_TASK_MODELS: %v", strings.ToUpper(entry.Name), err)
+		}
+		llmProviders = append(llmProviders, llm.Provider{
+			Name:   entry.Name,
+			Client: client,
+			Weight: entry.Weight,
+			Models: models,
 		})
 	}
-
-	symptomSummarizer := symptoms.NewSummarizer(welcomeGemini, welcomeDeepseek)
-	switch {
-	case welcomeGemini != nil && welcomeDeepseek != nil:
-		log.Println("✅ Symptom summaries: Gemini primary, DeepSeek fallback")
-	case welcomeGemini != nil || welcomeDeepseek != nil:
-		log.Println("✅ Symptom summaries enabled")
-	default:
-		log.Println("⚠️  No LLM keys for symptom summaries — using template fallback")
+	llmPrices, err := llm.ParsePriceTable(getEnv("LLM_PRICES", defaultLLMPrices))
+	if err != nil {
+		log.Fatalf("Invalid LLM_PRICES: %v", err)
+	}
+	subMgr := subscription.NewManager(database.DB)
+	usageLedger := usage.NewLedger(database, llmPrices).WithQuota(subMgr)
+	llmRouter := llm.NewRouter(llm.RouterConfig{Usage: usageLedger}, llmProviders...)
+	if len(llmRouter.Providers()) == 0 {
+		log.Fatal("At least one LLM provider must be configured")
 	}
+	log.Printf("✅ LLM router initialized: %s", strings.Join(llmRouter.Providers(), " → "))
+
+	symptomSummarizer := symptoms.NewSummarizer(llmRouter)
 
 	promptBuilder := prompt.NewBuilder()
 	calSuggester := calendar.NewSuggester()
+	if getEnv("CALENDAR_LLM_ASSIST", "off") == "on" {
+		calSuggester.WithLLM(llmRouter)
+	}
 	langMgr := language.NewManager()
-	subMgr := subscription.NewManager(database.DB)
 
 	// Initialize Twilio client (optional - only if credentials provided)
 	var twilioClient *twilio.VoiceClient
@@ -132,16 +183,41 @@ func main() {
 	}
 
 	// Initialize chat engine (shared between WebSocket and Voice)
+	// Long-term semantic memory over chat history and visit notes.
+	// MEMORY_EMBEDDER: off (default), openai (OpenAI-compatible /embeddings),
+	// hash (local lexical embeddings, only good enough for development)
+	var semanticMemory *memory.SemanticMemory
+	switch embedderName := getEnv("MEMORY_EMBEDDER", "off"); embedderName {
+	case "off":
+	case "hash":
+		if !isDevelopment() {
+			log.Println("⚠️  MEMORY_EMBEDDER=hash matches words, not meaning - use openai outside development")
+		}
+		semanticMemory = memory.NewSemanticMemory(memAdapter, embedding.NewHashEmbedder(0), memory.SemanticConfig{})
+	case "openai":
+		semanticMemory = memory.NewSemanticMemory(memAdapter, embedding.NewHTTPEmbedder(embedding.Config{
+			APIKey:  getEnv("EMBEDDING_API_KEY", getEnv("OPENAI_API_KEY", "")),
+			BaseURL: getEnv("EMBEDDING_BASE_URL", getEnv("OPENAI_BASE_URL", "")),
+			Model:   getEnv("EMBEDDING_MODEL", ""),
+		}), memory.SemanticConfig{})
+	default:
+		log.Fatalf("Invalid MEMORY_EMBEDDER %q (want hash, openai or off)", embedderName)
+	}
+
 	chatEngine := chat.NewEngine(
 		cls,
 		memMgr,
 		promptBuilder,
-		llmClient,
+		llmRouter,
 		calSuggester,
 		langMgr,
 		database,
 		symptomSummarizer,
 	)
+	if semanticMemory != nil {
+		chatEngine.WithRecall(semanticMemory)
+		log.Println("✅ Long-term memory enabled")
+	}
 
 	// Load enabled languages from database
 	ctx := context.Background()
@@ -165,6 +241,7 @@ func main() {
 	authHandler := api.NewAuthHandler(database, jwtSecret)
 	oauthHandler := api.NewOAuthHandler(database)
 	calendarHandler := api.NewCalendarHandler(database)
+	calendarFeedHandler := api.NewCalendarFeedHandler(database, subMgr)
 	savingsHandler := api.NewSavingsHandler(database)
 	subscriptionHandler := api.NewSubscriptionHandler(subMgr)
 	conversationHandler := api.NewConversationHandler(database)
@@ -173,27 +250,34 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize uploads: %v", err)
 	}
-	profileHandler := api.NewProfileHandler(database, photoStore)
+	carePlanTemplates := careplan.DefaultTemplates()
+	if path := getEnv("CARE_PLAN_TEMPLATES", ""); path != "" {
+		custom, err := careplan.LoadTemplates(path)
+		if err != nil {
+			log.Fatalf("Failed to load care plan templates: %v", err)
+		}
+		carePlanTemplates = careplan.Merge(carePlanTemplates, custom)
+		log.Printf("✅ Loaded %d care plan templates from %s", len(custom), path)
+	}
+	profileHandler := api.NewProfileHandler(database, photoStore).
+		WithCarePlan(careplan.NewGenerator(database, carePlanTemplates))
 	doctorVisitHandler := api.NewDoctorVisitHandler(database)
-	vitalsHandler := api.NewVitalsHandler(database)
-
-	switch {
-	case welcomeGemini != nil && welcomeDeepseek != nil:
-		log.Println("✅ Weekly welcome messages: Gemini primary, DeepSeek fallback")
-	case welcomeGemini != nil:
-		log.Println("✅ Weekly welcome messages will use Gemini")
-	case welcomeDeepseek != nil:
-		log.Println("✅ Weekly welcome messages will use DeepSeek")
-	default:
-		log.Println("⚠️  No LLM keys for welcome — using static fallback templates")
+	if semanticMemory != nil {
+		doctorVisitHandler.WithMemory(semanticMemory)
 	}
+	vitalsHandler := api.NewVitalsHandler(database)
+	memoryHandler := api.NewMemoryHandler(database, memMgr)
+	notificationHandler := api.NewNotificationHandler(database)
 
-	welcomeSvc := welcome.NewService(database, welcomeGemini, welcomeDeepseek)
+	welcomeSvc := welcome.NewService(database, llmRouter)
 	welcomeHandler := api.NewWelcomeHandler(welcomeSvc)
-	communityProcessor := community.NewProcessor(welcomeGemini, welcomeDeepseek)
+	communityProcessor := community.NewProcessor(llmRouter)
 	communityHandler := api.NewCommunityHandler(database, communityProcessor)
 	adminCommunityHandler := api.NewAdminCommunityHandler(database)
 	symptomHandler := api.NewSymptomHandler(database, symptomSummarizer)
+	billingHandler := api.NewBillingHandler(database, billing.NewService(database, subMgr, buildBillingProviders()...))
+	codesHandler := api.NewCodesHandler(database, codes.NewService(database, subMgr, buildCodesConfig()))
+	phoneHandler := api.NewPhoneHandler(database, phone.NewService(database, buildPhoneSender(twilioAccountSID, twilioAuthToken, twilioPhoneNumber), phone.Config{}))
 
 	chatHandler := ws.NewChatHandler(
 		chatEngine,
@@ -204,11 +288,40 @@ func main() {
 
 	// Initialize voice handler (if Twilio configured)
 	var voiceHandler *api.VoiceHandler
+	var voiceSessions api.VoiceSessionStore = database
 	if twilioClient != nil {
-		voiceHandler = api.NewVoiceHandler(twilioClient, chatEngine, database)
+		// Call state lives in Postgres so any replica can take a call's next callback
+		if getEnv("VOICE_SESSION_STORE", "postgres") == "memory" {
+			voiceSessions = api.NewMemoryVoiceSessionStore()
+		}
+		voiceHandler = api.NewVoiceHandler(twilioClient, chatEngine, database, subMgr, voiceSessions)
 		log.Println("✅ Voice handler initialized")
 	}
 
+	// SMS and WhatsApp chat, for users without the app (same Twilio account as voice)
+	var messagingHandler *api.MessagingHandler
+	if twilioClient != nil {
+		messagingHandler = api.NewMessagingHandler(chatEngine, database, subMgr, twilio.NewMessagingClient(twilio.MessagingConfig{
+			AccountSID:   twilioAccountSID,
+			AuthToken:    twilioAuthToken,
+			From:         getEnv("TWILIO_SMS_FROM", twilioPhoneNumber),
+			WhatsAppFrom: getEnv("TWILIO_WHATSAPP_FROM", ""),
+		}))
+		log.Println("✅ Messaging handler initialized")
+	}
+
+	// Outbound check-in and reminder calls (OUTBOUND_CALLS=on); Twilio fetches the call's TwiML from our public URL
+	var caller *outbound.Caller
+	if voiceHandler != nil && getEnv("OUTBOUND_CALLS", "off") == "on" {
+		webhookBaseURL := getEnv("TWILIO_WEBHOOK_BASE_URL", "")
+		if webhookBaseURL == "" || twilioPhoneNumber == "" {
+			log.Println("⚠️  OUTBOUND_CALLS needs TWILIO_WEBHOOK_BASE_URL and TWILIO_PHONE_NUMBER - outbound calls disabled")
+		} else {
+			caller = outbound.NewCaller(database, twilioClient, subMgr, outbound.Config{BaseURL: webhookBaseURL})
+			voiceHandler.EnableOutbound(caller)
+		}
+	}
+
 	// Setup Gin router
 	router := gin.Default()
 
@@ -260,9 +373,28 @@ func main() {
 		calendarGroup.GET("", calendarHandler.GetReminders)
 		calendarGroup.POST("", calendarHandler.CreateReminder)
 		calendarGroup.PUT("/:id", calendarHandler.UpdateReminder)
+		calendarGroup.PUT("/:id/occurrences", calendarHandler.CompleteOccurrence)
 		calendarGroup.DELETE("/:id", calendarHandler.DeleteReminder)
+		calendarGroup.GET("/suggestions", calendarHandler.ListSuggestions)
+		calendarGroup.POST("/suggestions/:id/accept", calendarHandler.AcceptSuggestion)
+		calendarGroup.POST("/suggestions/:id/dismiss", calendarHandler.DismissSuggestion)
 	}
 
+	// Calendar feed and import (protected + feature gate + per-user rate limiting)
+	calendarFeedGroup := router.Group("/api/calendar")
+	calendarFeedGroup.Use(middleware.JWTAuth(jwtSecret))
+	calendarFeedGroup.Use(middleware.RequireFeature(subMgr, "calendar"))
+	calendarFeedGroup.Use(middleware.PerUser(100.0/3600.0, 20)) // 100/hour per user
+	{
+		calendarFeedGroup.GET("/feed", calendarFeedHandler.GetFeed)
+		calendarFeedGroup.POST("/feed", calendarFeedHandler.CreateFeed)
+		calendarFeedGroup.DELETE("/feed", calendarFeedHandler.DeleteFeed)
+		calendarFeedGroup.POST("/import", calendarFeedHandler.Import)
+	}
+
+	// iCalendar subscription feed (public: the secret token in the URL authenticates)
+	router.GET("/calendar/:file", calendarFeedHandler.ServeFeed)
+
 	// Savings routes (protected + feature gate + per-user rate limiting)
 	savingsGroup := router.Group("/api/savings")
 	savingsGroup.Use(middleware.JWTAuth(jwtSecret))
@@ -284,6 +416,27 @@ func main() {
 		subscriptionGroup.GET("/me", subscriptionHandler.GetMySubscription)
 		subscriptionGroup.GET("/features", subscriptionHandler.GetMyFeatures)
 		subscriptionGroup.GET("/quota/:feature", subscriptionHandler.GetMyQuota)
+		subscriptionGroup.POST("/trial", subscriptionHandler.StartTrial)
+	}
+
+	// Billing routes (protected)
+	billingGroup := router.Group("/api/billing")
+	billingGroup.Use(middleware.JWTAuth(jwtSecret))
+	{
+		billingGroup.GET("/plans", billingHandler.ListPrices)
+		billingGroup.POST("/checkout", billingHandler.Checkout)
+		billingGroup.GET("/history", billingHandler.GetHistory)
+	}
+
+	// Payment provider webhooks (public: the provider's signature authenticates)
+	router.POST("/api/billing/webhooks/:provider", billingHandler.Webhook)
+
+	// Promo, sponsor and referral code routes (protected)
+	codesGroup := router.Group("/api/codes")
+	codesGroup.Use(middleware.JWTAuth(jwtSecret))
+	{
+		codesGroup.POST("/redeem", codesHandler.Redeem)
+		codesGroup.GET("/referral", codesHandler.GetReferral)
 	}
 
 	// Symptom tracking routes (protected)
@@ -351,6 +504,7 @@ func main() {
 		adminGroup.GET("/plans/:planId/features", adminHandler.GetPlanFeatures)
 		adminGroup.POST("/plans/:planId/features/:featureId", adminHandler.AssignFeatureToPlan)
 		adminGroup.DELETE("/plans/:planId/features/:featureId", adminHandler.RemoveFeatureFromPlan)
+		adminGroup.PUT("/plans/:planId/prices/:provider", billingHandler.SetPlanPrice)
 
 		// Feature management (CRUD)
 		adminGroup.GET("/features", adminHandler.ListFeatures)
@@ -380,6 +534,14 @@ func main() {
 		adminGroup.GET("/analytics/topics", adminHandler.GetChatAnalytics)
 		adminGroup.GET("/analytics/users", adminHandler.GetUserStats)
 		adminGroup.GET("/analytics/calls", adminHandler.GetCallHistory)
+		adminGroup.GET("/analytics/suggestions", adminHandler.GetSuggestionStats)
+		adminGroup.GET("/analytics/llm-costs/users", adminHandler.GetLLMCostByUser)
+		adminGroup.GET("/analytics/llm-costs/plans", adminHandler.GetLLMCostByPlan)
+		adminGroup.GET("/analytics/llm-costs/daily", adminHandler.GetLLMCostByDay)
+
+		// Red-flag escalation audit
+		adminGroup.GET("/escalations", adminHandler.ListEscalations)
+		adminGroup.PUT("/escalations/:id", adminHandler.UpdateEscalation)
 
 		// System settings management
 		adminGroup.GET("/settings", adminHandler.GetSystemSettings)
@@ -387,6 +549,19 @@ func main() {
 		adminGroup.PUT("/settings/:key", adminHandler.UpdateSystemSetting)
 
 		adminCommunityHandler.RegisterRoutes(adminGroup)
+
+		// Promo codes, sponsors and redemption analytics
+		adminGroup.GET("/promo-codes", codesHandler.ListPromoCodes)
+		adminGroup.POST("/promo-codes", codesHandler.CreatePromoCode)
+		adminGroup.PUT("/promo-codes/:id", codesHandler.UpdatePromoCode)
+		adminGroup.GET("/promo-codes/:id/redemptions", codesHandler.ListRedemptions)
+		adminGroup.GET("/sponsors", codesHandler.ListSponsors)
+		adminGroup.POST("/sponsors", codesHandler.CreateSponsor)
+		adminGroup.PUT("/sponsors/:id", codesHandler.UpdateSponsor)
+		adminGroup.GET("/sponsors/:id/codes", codesHandler.ListSponsorCodes)
+		adminGroup.POST("/sponsors/:id/codes", codesHandler.IssueSponsorCodes)
+		adminGroup.GET("/sponsors/:id/report", codesHandler.GetSponsorReport)
+		adminGroup.GET("/analytics/redemptions", codesHandler.GetRedemptionAnalytics)
 	}
 
 	// User profile & onboarding (authenticated)
@@ -399,22 +574,83 @@ func main() {
 		profileGroup.DELETE("/profile-photo", profileHandler.DeleteProfilePhoto)
 		profileGroup.PUT("/onboarding", profileHandler.CompleteOnboarding)
 		profileGroup.GET("/welcome", welcomeHandler.GetWelcome)
+		profileGroup.GET("/memory", memoryHandler.ListFacts)
+		profileGroup.PUT("/memory/facts/:key", memoryHandler.CorrectFact)
+		profileGroup.DELETE("/memory/facts/:key", memoryHandler.DeleteFact)
+		profileGroup.DELETE("/memory", memoryHandler.WipeMemory)
+		profileGroup.GET("/facts", memoryHandler.RedirectFacts)      // Moved to /memory
+		profileGroup.PUT("/facts/:key", memoryHandler.RedirectFacts) // Moved to /memory/facts/:key
+		profileGroup.GET("/notifications", notificationHandler.GetPreferences)
+		profileGroup.PUT("/notifications", notificationHandler.UpdatePreferences)
+		profileGroup.POST("/devices", notificationHandler.RegisterDevice)
+		profileGroup.DELETE("/devices/:token", notificationHandler.UnregisterDevice)
+		profileGroup.GET("/voice-calls", notificationHandler.GetVoiceCallPreferences)
+		profileGroup.PUT("/voice-calls", notificationHandler.UpdateVoiceCallPreferences)
+		profileGroup.GET("/phone", phoneHandler.GetPhone)
+		profileGroup.POST("/phone", phoneHandler.StartVerification)
+		profileGroup.POST("/phone/verify", phoneHandler.Verify)
+		profileGroup.DELETE("/phone", phoneHandler.DeletePhone)
+		profileGroup.PUT("/phone/pin", phoneHandler.SetVoicePin)
 	}
 
 	// WebSocket chat route (protected via query param/header)
 	router.GET("/ws/chat", chatHandler.HandleChat)
 
-	// Twilio Voice routes (public webhooks, but user lookup enforces subscription)
+	// Twilio Voice routes (public webhooks: Twilio's signature authenticates, user lookup enforces subscription)
 	if voiceHandler != nil {
 		voice := router.Group("/api/voice")
+		voice.Use(middleware.TwilioSignature(twilioClient, getEnv("TWILIO_WEBHOOK_BASE_URL", "")))
 		{
 			voice.POST("/incoming", voiceHandler.HandleIncoming) // Initial call webhook
 			voice.POST("/gather", voiceHandler.HandleGather)     // Speech recognition callback
 			voice.POST("/status", voiceHandler.HandleStatus)     // Call status updates
+			voice.POST("/outbound", voiceHandler.HandleOutbound) // Answered outbound call
+			voice.POST("/pin", voiceHandler.HandlePin)           // Voice PIN callback
 		}
 		log.Println("✅ Voice routes registered")
 	}
 
+	// SMS and WhatsApp routes (public webhooks, signed by Twilio like voice)
+	if messagingHandler != nil {
+		messaging := router.Group("/api/messaging")
+		messaging.Use(middleware.TwilioSignature(twilioClient, getEnv("TWILIO_WEBHOOK_BASE_URL", "")))
+		{
+			messaging.POST("/incoming", messagingHandler.HandleIncoming) // Inbound SMS and WhatsApp
+		}
+		log.Println("✅ Messaging routes registered")
+	}
+
+	// Reminder delivery (REMINDER_SCHEDULER=off to run it on other replicas only)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	notifiers := buildNotifiers(schedulerCtx, database, twilioAccountSID, twilioAuthToken, twilioPhoneNumber)
+	if voiceHandler != nil {
+		go api.SweepVoiceSessions(schedulerCtx, voiceSessions, 10*time.Minute)
+	}
+	if caller != nil {
+		notifiers = append(notifiers, caller) // Reminder calls
+		go caller.Run(schedulerCtx)
+		log.Println("✅ Outbound caller started")
+	}
+	if getEnv("REMINDER_SCHEDULER", "on") != "off" {
+		scheduler := reminders.NewScheduler(database, notifiers, reminders.Config{})
+		go scheduler.Run(schedulerCtx)
+		log.Println("✅ Reminder scheduler started")
+	}
+
+	// Subscription expiry, trials and grace periods (SUBSCRIPTION_LIFECYCLE=off on other replicas)
+	if getEnv("SUBSCRIPTION_LIFECYCLE", "on") != "off" {
+		gracePeriod, err := time.ParseDuration(getEnv("SUBSCRIPTION_GRACE_PERIOD", "72h"))
+		if err != nil {
+			log.Fatalf("Invalid SUBSCRIPTION_GRACE_PERIOD: %v", err)
+		}
+		lifecycle := subscription.NewLifecycle(subMgr, database, notifiers, subscription.LifecycleConfig{
+			GracePeriod: gracePeriod,
+		})
+		go lifecycle.Run(schedulerCtx)
+		log.Printf("✅ Subscription lifecycle started (grace period %s)", gracePeriod)
+	}
+
 	// Create HTTP server
 	// Bind to 0.0.0.0 to accept connections from all network interfaces
 	srv := &http.Server{
@@ -439,7 +675,16 @@ func main() {
 		log.Printf("   GET    /api/reminders")
 		log.Printf("   POST   /api/reminders")
 		log.Printf("   PUT    /api/reminders/:id")
+		log.Printf("   PUT    /api/reminders/:id/occurrences")
 		log.Printf("   DELETE /api/reminders/:id")
+		log.Printf("   GET    /api/reminders/suggestions")
+		log.Printf("   POST   /api/reminders/suggestions/:id/accept")
+		log.Printf("   POST   /api/reminders/suggestions/:id/dismiss")
+		log.Printf("   GET    /api/calendar/feed")
+		log.Printf("   POST   /api/calendar/feed")
+		log.Printf("   DELETE /api/calendar/feed")
+		log.Printf("   POST   /api/calendar/import")
+		log.Printf("   GET    /calendar/:token.ics (subscription feed)")
 		log.Printf("   GET    /api/savings/summary")
 		log.Printf("   GET    /api/savings/entries")
 		log.Printf("   POST   /api/savings/entries")
@@ -448,18 +693,46 @@ func main() {
 		log.Printf("   GET    /api/subscription/me")
 		log.Printf("   GET    /api/subscription/features")
 		log.Printf("   GET    /api/subscription/quota/:feature")
+		log.Printf("   POST   /api/subscription/trial")
+		log.Printf("   GET    /api/billing/plans")
+		log.Printf("   POST   /api/billing/checkout")
+		log.Printf("   GET    /api/billing/history")
+		log.Printf("   POST   /api/billing/webhooks/:provider (payment webhooks)")
+		log.Printf("   POST   /api/codes/redeem")
+		log.Printf("   GET    /api/codes/referral")
 		log.Printf("   GET    /api/admin/plans")
+		log.Printf("   PUT    /api/admin/plans/:planId/prices/:provider")
 		log.Printf("   GET    /api/admin/users/:userId/subscription")
 		log.Printf("   PUT    /api/admin/users/:userId/plan")
 		log.Printf("   GET    /api/admin/users/:userId/quota/:feature")
 		log.Printf("   POST   /api/admin/users/:userId/quota/:feature/reset")
 		log.Printf("   GET    /api/admin/quota/stats")
 		log.Printf("   POST   /api/admin/users/:userId/features")
+		log.Printf("   GET    /api/admin/promo-codes")
+		log.Printf("   GET    /api/admin/sponsors")
+		log.Printf("   GET    /api/admin/sponsors/:id/report")
+		log.Printf("   GET    /api/admin/analytics/redemptions")
+		log.Printf("   GET    /api/users/me/notifications")
+		log.Printf("   PUT    /api/users/me/notifications")
+		log.Printf("   GET    /api/users/me/voice-calls")
+		log.Printf("   PUT    /api/users/me/voice-calls")
+		log.Printf("   GET    /api/users/me/phone")
+		log.Printf("   POST   /api/users/me/phone")
+		log.Printf("   POST   /api/users/me/phone/verify")
+		log.Printf("   DELETE /api/users/me/phone")
+		log.Printf("   PUT    /api/users/me/phone/pin")
+		log.Printf("   POST   /api/users/me/devices")
+		log.Printf("   DELETE /api/users/me/devices/:token")
 		log.Printf("   WS     /ws/chat")
 		if voiceHandler != nil {
 			log.Printf("   POST   /api/voice/incoming (Twilio webhook)")
 			log.Printf("   POST   /api/voice/gather (Twilio webhook)")
 			log.Printf("   POST   /api/voice/status (Twilio webhook)")
+			log.Printf("   POST   /api/voice/outbound (Twilio webhook)")
+			log.Printf("   POST   /api/voice/pin (Twilio webhook)")
+		}
+		if messagingHandler != nil {
+			log.Printf("   POST   /api/messaging/incoming (Twilio webhook)")
 		}
 		log.Printf("")
 		log.Printf("Press Ctrl+C to stop")
@@ -475,6 +748,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopScheduler()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -482,10 +756,163 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	usageLedger.Wait()
 
 	log.Println("Server exited")
 }
 
+// defaultLLMPrices are list prices in USD per million input:output tokens (override with LLM_PRICES)
+const defaultLLMPrices = "deepseek/deepseek-chat=0.27:1.10," +
+	"gemini/gemini-2.0-flash=0.10:0.40,gemini/*=0.10:0.40," +
+	"openai/gpt-4o-mini=0.15:0.60," +
+	"ollama/*=0:0"
+
+// buildNotifiers returns a notifier per configured reminder channel; the
+// scheduler never queues deliveries for the others. In development push and
+// SMS fall back to logging. Email is only enabled with SMTP_HOST.
+func buildNotifiers(ctx context.Context, database *db.DB, twilioAccountSID, twilioAuthToken, twilioFrom string) []notify.Notifier {
+	pushSenders := make(map[string]notify.PushSender)
+	if path := getEnv("FCM_CREDENTIALS_FILE", ""); path != "" {
+		creds, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read FCM_CREDENTIALS_FILE: %v", err)
+		}
+		sender, err := notify.NewFCMSender(ctx, creds)
+		if err != nil {
+			log.Fatalf("Failed to initialize FCM: %v", err)
+		}
+		pushSenders[notify.ProviderFCM] = sender
+		log.Println("✅ FCM push initialized")
+	}
+	if path := getEnv("APNS_KEY_FILE", ""); path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read APNS_KEY_FILE: %v", err)
+		}
+		sender, err := notify.NewAPNsSender(notify.APNsConfig{
+			KeyID:      getEnv("APNS_KEY_ID", ""),
+			TeamID:     getEnv("APNS_TEAM_ID", ""),
+			BundleID:   getEnv("APNS_BUNDLE_ID", ""),
+			PrivateKey: key,
+			Sandbox:    getEnv("APNS_SANDBOX", "false") == "true",
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize APNs: %v", err)
+		}
+		pushSenders[notify.ProviderAPNs] = sender
+		log.Println("✅ APNs push initialized")
+	}
+
+	var notifiers []notify.Notifier
+	if len(pushSenders) > 0 {
+		notifiers = append(notifiers, notify.NewPushNotifier(pushSenders, func(d notify.Device) {
+			if err := database.DeletePushToken(context.Background(), d.Token); err != nil {
+				log.Printf("Failed to remove invalid push token: %v", err)
+			}
+		}))
+	} else if isDevelopment() {
+		notifiers = append(notifiers, notify.NewLogNotifier(notify.ChannelPush))
+	} else {
+		log.Println("⚠️  No push provider configured - push reminders disabled")
+	}
+
+	smsFrom := getEnv("TWILIO_SMS_FROM", twilioFrom)
+	if twilioAccountSID != "" && twilioAuthToken != "" && smsFrom != "" {
+		notifiers = append(notifiers, notify.NewSMSNotifier(twilio.NewMessagingClient(twilio.MessagingConfig{
+			AccountSID: twilioAccountSID,
+			AuthToken:  twilioAuthToken,
+			From:       smsFrom,
+		})))
+		log.Println("✅ Twilio SMS initialized")
+	} else if isDevelopment() {
+		notifiers = append(notifiers, notify.NewLogNotifier(notify.ChannelSMS))
+	} else {
+		log.Println("⚠️  Twilio SMS not configured - SMS reminders disabled")
+	}
+
+	if host := getEnv("SMTP_HOST", ""); host != "" {
+		port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+		if err != nil {
+			log.Fatalf("Invalid SMTP_PORT: %v", err)
+		}
+		notifiers = append(notifiers, notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "MomLaunchpad <no-reply@momlaunchpad.com>"),
+		}))
+		log.Println("✅ SMTP email initialized")
+	}
+
+	return notifiers
+}
+
+// buildBillingProviders returns the payment providers that have credentials.
+// Without any, checkout is unavailable but the rest of billing still works.
+func buildBillingProviders() []billing.Provider {
+	var providers []billing.Provider
+	if key := getEnv("STRIPE_SECRET_KEY", ""); key != "" {
+		providers = append(providers, billing.NewStripe(billing.StripeConfig{
+			SecretKey:     key,
+			WebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			SuccessURL:    getEnv("BILLING_SUCCESS_URL", ""),
+			CancelURL:     getEnv("BILLING_CANCEL_URL", ""),
+		}))
+		log.Println("✅ Stripe billing initialized")
+	}
+	if key := getEnv("PAYSTACK_SECRET_KEY", ""); key != "" {
+		providers = append(providers, billing.NewPaystack(billing.PaystackConfig{
+			SecretKey:   key,
+			CallbackURL: getEnv("PAYSTACK_CALLBACK_URL", getEnv("BILLING_SUCCESS_URL", "")),
+		}))
+		log.Println("✅ Paystack billing initialized")
+	}
+	return providers
+}
+
+// buildCodesConfig reads the referral credit settings
+func buildCodesConfig() codes.Config {
+	cfg := codes.Config{
+		ReferralPlan:    getEnv("REFERRAL_PLAN", "premium"),
+		ReferralBaseURL: getEnv("REFERRAL_BASE_URL", ""),
+	}
+	if v := getEnv("REFERRAL_DAYS", ""); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatalf("Invalid REFERRAL_DAYS: %v", err)
+		}
+		cfg.ReferralDays = days
+	}
+	return cfg
+}
+
+// buildPhoneSender texts verification codes through Twilio. Without Twilio it
+// returns a stand-in that drops them in development, or nil otherwise, which
+// makes the phone verification endpoints answer 503.
+func buildPhoneSender(twilioAccountSID, twilioAuthToken, twilioFrom string) phone.Sender {
+	smsFrom := getEnv("TWILIO_SMS_FROM", twilioFrom)
+	if twilioAccountSID == "" || twilioAuthToken == "" || smsFrom == "" {
+		if isDevelopment() {
+			log.Println("⚠️  Twilio SMS not configured - phone verification codes will not be sent (development)")
+			return phone.LogSender{}
+		}
+		log.Println("⚠️  Twilio SMS not configured - phone verification disabled")
+		return nil
+	}
+	return twilio.NewMessagingClient(twilio.MessagingConfig{
+		AccountSID: twilioAccountSID,
+		AuthToken:  twilioAuthToken,
+		From:       smsFrom,
+	})
+}
+
+// isDevelopment reports whether ENV=development, which allows stand-ins that
+// log instead of delivering (they would silently drop messages in production)
+func isDevelopment() bool {
+	return getEnv("ENV", "production") == "development"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
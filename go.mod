@@ -9,6 +9,7 @@ require (
 	github.com/gorilla/websocket v1.5.3
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
+	github.com/teambition/rrule-go v1.8.2
 	golang.org/x/crypto v0.40.0
 	golang.org/x/oauth2 v0.24.0
 	golang.org/x/term v0.43.0
@@ -40,6 +40,7 @@ type UpdatePlanRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Active      *bool  `json:"active"`
+	TrialDays   *int   `json:"trial_days" binding:"omitempty,min=0,max=365"` // 0 turns the trial off
 }
 
 // CreatePlan creates a new subscription plan
@@ -79,7 +80,7 @@ func (h *AdminHandler) UpdatePlan(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.UpdatePlan(c.Request.Context(), planID, req.Name, req.Description, req.Active); err != nil {
+	if err := h.db.UpdatePlan(c.Request.Context(), planID, req.Name, req.Description, req.Active, req.TrialDays); err != nil {
 		if err == db.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "plan not found"})
 			return
@@ -483,7 +484,7 @@ func (h *AdminHandler) GetUserStats(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
 
-// GetCallHistory returns voice call history
+// GetCallHistory returns outbound calls and a count of their outcomes
 // GET /api/admin/analytics/calls
 func (h *AdminHandler) GetCallHistory(c *gin.Context) {
 	// Parse query parameters
@@ -507,13 +508,36 @@ func (h *AdminHandler) GetCallHistory(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get call history"})
 		return
 	}
+	outcomes, err := h.db.GetVoiceCallOutcomes(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get call history"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"period_days": days,
+		"outcomes":    outcomes,
 		"calls":       calls,
 	})
 }
 
+// GetSuggestionStats returns calendar suggestion acceptance rates by type
+// GET /api/admin/analytics/suggestions?days=30
+func (h *AdminHandler) GetSuggestionStats(c *gin.Context) {
+	days := costPeriodDays(c)
+
+	stats, err := h.db.GetCalendarSuggestionStats(c.Request.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get suggestion stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period_days": days,
+		"types":       stats,
+	})
+}
+
 // ============================================================================
 // SYSTEM SETTINGS
 // ============================================================================
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// ============================================================================
+// RED-FLAG ESCALATIONS
+// ============================================================================
+
+// UpdateEscalationRequest represents an admin review of an escalation
+type UpdateEscalationRequest struct {
+	Status string  `json:"status" binding:"required"`
+	Notes  *string `json:"notes"`
+}
+
+// ListEscalations returns red-flag escalations for clinical audit
+// GET /api/admin/escalations?status=open&category=heavy_bleeding&limit=50
+func (h *AdminHandler) ListEscalations(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	escalations, err := h.db.ListEscalations(c.Request.Context(), c.Query("status"), c.Query("category"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list escalations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"escalations": escalations})
+}
+
+// UpdateEscalation acknowledges or resolves an escalation
+// PUT /api/admin/escalations/:id
+func (h *AdminHandler) UpdateEscalation(c *gin.Context) {
+	var req UpdateEscalationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	switch req.Status {
+	case "open", "acknowledged", "resolved":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	err := h.db.UpdateEscalationStatus(c.Request.Context(), c.Param("id"), req.Status, middleware.GetUserID(c), req.Notes)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "escalation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update escalation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": req.Status})
+}
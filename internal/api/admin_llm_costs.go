@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// LLM COST ANALYTICS
+// ============================================================================
+
+// GetLLMCostByUser returns the highest LLM spenders
+// GET /api/admin/analytics/llm-costs/users?days=30&limit=50
+func (h *AdminHandler) GetLLMCostByUser(c *gin.Context) {
+	days := costPeriodDays(c)
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	costs, err := h.db.GetLLMCostByUser(c.Request.Context(), time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get llm costs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period_days": days,
+		"users":       costs,
+	})
+}
+
+// GetLLMCostByPlan returns LLM spend per subscription plan
+// GET /api/admin/analytics/llm-costs/plans?days=30
+func (h *AdminHandler) GetLLMCostByPlan(c *gin.Context) {
+	days := costPeriodDays(c)
+
+	costs, err := h.db.GetLLMCostByPlan(c.Request.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get llm costs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period_days": days,
+		"plans":       costs,
+	})
+}
+
+// GetLLMCostByDay returns daily LLM spend
+// GET /api/admin/analytics/llm-costs/daily?days=30
+func (h *AdminHandler) GetLLMCostByDay(c *gin.Context) {
+	days := costPeriodDays(c)
+
+	costs, err := h.db.GetLLMCostByDay(c.Request.Context(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get llm costs"})
+		return
+	}
+
+	var total float64
+	for _, day := range costs {
+		total += day.CostUSD
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period_days":    days,
+		"total_cost_usd": total,
+		"days":           costs,
+	})
+}
+
+// costPeriodDays parses ?days= (default 30, max 365)
+func costPeriodDays(c *gin.Context) int {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+	return days
+}
@@ -260,7 +260,7 @@ func TestAdminUpdatePlan_NotFound(t *testing.T) {
 	database, mock := newMockDB(t)
 
 	mock.ExpectExec(`UPDATE plans`).
-		WithArgs(99, "Name", "", nil).
+		WithArgs(99, "Name", "", nil, nil).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
 	r := ginAdmin()
@@ -305,3 +305,196 @@ func TestAdminGetUserStats_Success(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAdminListEscalations_FiltersByStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM red_flag_escalations`).
+		WithArgs("open", "", 50).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "conversation_id", "message_id", "category", "matched_phrase", "language",
+			"country_code", "response", "status", "reviewed_by", "reviewed_at", "review_notes", "created_at",
+		}).AddRow("esc-1", "user-1", "conv-1", "msg-1", "heavy_bleeding", "heavy bleeding", "en",
+			"NG", "Please go to hospital", "open", nil, nil, nil, now))
+
+	r := ginAdmin()
+	h := NewAdminHandler(database, language.NewManager())
+	r.GET("/escalations", h.ListEscalations)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/escalations?status=open", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Escalations []struct {
+			ID       string `json:"id"`
+			Category string `json:"category"`
+		} `json:"escalations"`
+	}
+	decodeJSONBody(t, w, &body)
+	if len(body.Escalations) != 1 || body.Escalations[0].Category != "heavy_bleeding" {
+		t.Errorf("unexpected escalations: %+v", body.Escalations)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminUpdateEscalation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("invalid status", func(t *testing.T) {
+		database, _ := newMockDB(t)
+		r := ginAdmin()
+		r.PUT("/escalations/:id", NewAdminHandler(database, language.NewManager()).UpdateEscalation)
+
+		req, _ := jsonRequest(http.MethodPut, "/escalations/esc-1", map[string]string{"status": "closed"})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectExec(`UPDATE red_flag_escalations`).
+			WithArgs("resolved", "admin-1", nil, "missing").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		r := ginAdmin()
+		r.PUT("/escalations/:id", NewAdminHandler(database, language.NewManager()).UpdateEscalation)
+
+		req, _ := jsonRequest(http.MethodPut, "/escalations/missing", map[string]string{"status": "resolved"})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", w.Code)
+		}
+	})
+}
+
+func TestAdminGetLLMCostByPlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectQuery(`FROM llm_usage`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"plan", "users", "calls", "prompt_tokens", "completion_tokens", "cost", "avg_chat",
+		}).
+			AddRow("premium", 4, 120, 60000, 24000, 2.0, 0.015).
+			AddRow("none", 10, 50, 10000, 5000, 0.5, 0.01))
+
+	r := ginAdmin()
+	r.GET("/analytics/llm-costs/plans", NewAdminHandler(database, language.NewManager()).GetLLMCostByPlan)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/llm-costs/plans?days=7", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		PeriodDays int `json:"period_days"`
+		Plans      []struct {
+			PlanCode       string  `json:"plan_code"`
+			Calls          int     `json:"calls"`
+			CostUSD        float64 `json:"cost_usd"`
+			CostPerUserUSD float64 `json:"cost_per_user_usd"`
+		} `json:"plans"`
+	}
+	decodeJSONBody(t, w, &body)
+	if body.PeriodDays != 7 || len(body.Plans) != 2 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if body.Plans[0].PlanCode != "premium" || body.Plans[0].Calls != 120 || body.Plans[0].CostPerUserUSD != 0.5 {
+		t.Errorf("unexpected premium row: %+v", body.Plans[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminGetSuggestionStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectQuery(`FROM calendar_suggestions`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "total", "accepted", "edited", "dismissed", "pending"}).
+			AddRow("appointment", 40, 24, 6, 8, 8).
+			AddRow("symptom_followup", 10, 0, 0, 0, 10))
+
+	r := ginAdmin()
+	r.GET("/analytics/suggestions", NewAdminHandler(database, language.NewManager()).GetSuggestionStats)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/suggestions", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		PeriodDays int `json:"period_days"`
+		Types      []struct {
+			Type           string  `json:"type"`
+			Accepted       int     `json:"accepted"`
+			AcceptanceRate float64 `json:"acceptance_rate"`
+		} `json:"types"`
+	}
+	decodeJSONBody(t, w, &body)
+	if body.PeriodDays != 30 || len(body.Types) != 2 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if body.Types[0].AcceptanceRate != 0.75 || body.Types[1].AcceptanceRate != 0 {
+		t.Errorf("acceptance rates = %+v", body.Types)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminGetCallHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM outbound_calls c`).
+		WithArgs(sqlmock.AnyArg(), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "call_sid", "user_id", "email", "phone", "kind", "scheduled_for",
+			"attempts", "duration", "status", "last_error", "created_at"}).
+			AddRow("c1", "CA1", "u1", "ada@example.com", "+2348012345678", "checkin", now, 2, 95, "completed", nil, now))
+	mock.ExpectQuery(`GROUP BY status`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).
+			AddRow("completed", 4).
+			AddRow("no_answer", 1))
+
+	r := ginAdmin()
+	r.GET("/analytics/calls", NewAdminHandler(database, language.NewManager()).GetCallHistory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/calls", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Outcomes map[string]int `json:"outcomes"`
+		Calls    []struct {
+			Kind     string `json:"kind"`
+			Attempts int    `json:"attempts"`
+		} `json:"calls"`
+	}
+	decodeJSONBody(t, w, &body)
+	if body.Outcomes["no_answer"] != 1 || len(body.Calls) != 1 || body.Calls[0].Attempts != 2 {
+		t.Errorf("body = %+v", body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
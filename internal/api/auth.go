@@ -50,6 +50,7 @@ type UserInfo struct {
 	Email    string `json:"email"`
 	Name     string `json:"name,omitempty"`
 	Language string `json:"language"`
+	Timezone string `json:"timezone,omitempty"`
 	IsAdmin  bool   `json:"is_admin"`
 }
 
@@ -215,6 +216,7 @@ func userToUserInfo(user *db.User) *UserInfo {
 		Email:    user.Email,
 		Name:     name,
 		Language: user.Language,
+		Timezone: user.Timezone,
 		IsAdmin:  user.IsAdmin,
 	}
 }
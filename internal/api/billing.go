@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/billing"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// maxWebhookBytes caps payment provider webhook bodies
+const maxWebhookBytes = 1 << 20
+
+// BillingHandler handles plan purchases and payment provider webhooks
+type BillingHandler struct {
+	db      *db.DB
+	billing *billing.Service
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(database *db.DB, service *billing.Service) *BillingHandler {
+	return &BillingHandler{
+		db:      database,
+		billing: service,
+	}
+}
+
+// CheckoutRequest is a request to buy a plan
+type CheckoutRequest struct {
+	PlanCode string `json:"plan_code" binding:"required"`
+	Provider string `json:"provider" binding:"required"` // stripe or paystack
+}
+
+// CheckoutResponse tells the client where to pay
+type CheckoutResponse struct {
+	Reference   string `json:"reference"`
+	Provider    string `json:"provider"`
+	PlanCode    string `json:"plan_code"`
+	Currency    string `json:"currency"`
+	Amount      int64  `json:"amount"`
+	CheckoutURL string `json:"checkout_url"`
+}
+
+// ListPrices returns what each plan costs with each configured provider
+// GET /api/billing/plans
+func (h *BillingHandler) ListPrices(c *gin.Context) {
+	prices, err := h.db.ListPlanPrices(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list plan prices"})
+		return
+	}
+
+	configured := make(map[string]bool)
+	for _, name := range h.billing.Providers() {
+		configured[name] = true
+	}
+	available := make([]db.PlanPrice, 0, len(prices))
+	for _, p := range prices {
+		if configured[p.Provider] {
+			available = append(available, p)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"prices": available, "providers": h.billing.Providers()})
+}
+
+// Checkout starts a plan purchase
+// POST /api/billing/checkout
+func (h *BillingHandler) Checkout(c *gin.Context) {
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	user, err := h.db.GetUserByID(c.Request.Context(), userID)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	checkout, url, err := h.billing.Checkout(c.Request.Context(), billing.CheckoutRequest{
+		UserID:   userID,
+		Email:    user.Email,
+		PlanCode: req.PlanCode,
+		Provider: strings.ToLower(req.Provider),
+	})
+	switch {
+	case errors.Is(err, billing.ErrUnknownProvider), errors.Is(err, billing.ErrPlanUnavailable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Checkout failed for user %s: %v", userID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start checkout"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CheckoutResponse{
+		Reference:   checkout.Reference,
+		Provider:    checkout.Provider,
+		PlanCode:    checkout.PlanCode,
+		Currency:    checkout.Currency,
+		Amount:      checkout.Amount,
+		CheckoutURL: url,
+	})
+}
+
+// GetHistory returns the user's payments, newest first
+// GET /api/billing/history?limit=50
+func (h *BillingHandler) GetHistory(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	payments, err := h.db.ListBillingPayments(c.Request.Context(), middleware.GetUserID(c), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load billing history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payments": payments})
+}
+
+// Webhook receives a payment provider's signed webhook
+// POST /api/billing/webhooks/:provider
+func (h *BillingHandler) Webhook(c *gin.Context) {
+	provider := c.Param("provider")
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+		return
+	}
+
+	err = h.billing.HandleWebhook(c.Request.Context(), provider, c.Request.Header, body)
+	switch {
+	case errors.Is(err, billing.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, billing.ErrInvalidSignature):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid signature"})
+		return
+	case err != nil:
+		log.Printf("Failed to process %s webhook: %v", provider, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// PlanPriceRequest sets a plan's price with a provider
+type PlanPriceRequest struct {
+	Currency        string  `json:"currency" binding:"required,len=3"`
+	Amount          int64   `json:"amount" binding:"required,gt=0"` // Minor units (cents, kobo)
+	Interval        string  `json:"interval" binding:"omitempty,oneof=month year"`
+	ProviderPriceID *string `json:"provider_price_id"` // Stripe price ID or Paystack plan code
+	Active          *bool   `json:"active"`
+}
+
+// SetPlanPrice sets what a plan costs with a provider (admin only)
+// PUT /api/admin/plans/:planId/prices/:provider
+func (h *BillingHandler) SetPlanPrice(c *gin.Context) {
+	planID, err := strconv.Atoi(c.Param("planId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan ID"})
+		return
+	}
+	provider := strings.ToLower(c.Param("provider"))
+	if provider != billing.ProviderStripe && provider != billing.ProviderPaystack {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider must be stripe or paystack"})
+		return
+	}
+
+	var req PlanPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	price := &db.PlanPrice{
+		PlanID:          planID,
+		Provider:        provider,
+		Currency:        strings.ToUpper(req.Currency),
+		Amount:          req.Amount,
+		Interval:        req.Interval,
+		ProviderPriceID: req.ProviderPriceID,
+		Active:          req.Active == nil || *req.Active,
+	}
+	if price.Interval == "" {
+		price.Interval = "month"
+	}
+	if err := h.db.UpsertPlanPrice(c.Request.Context(), price); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save plan price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/billing"
+)
+
+type recordedPlans struct {
+	activated map[string]time.Time
+}
+
+func (r *recordedPlans) ActivatePlan(_ context.Context, userID, planCode string, endsAt time.Time) error {
+	r.activated[userID+"/"+planCode] = endsAt
+	return nil
+}
+
+func (r *recordedPlans) CancelPlanAt(context.Context, string, string, time.Time) error { return nil }
+
+var planPriceColumns = []string{"id", "plan_id", "code", "name", "provider", "currency", "amount",
+	"billing_interval", "provider_price_id", "active", "updated_at"}
+
+func TestBillingCheckout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	provider := billing.NewFakeProvider("paystack", "secret")
+	handler := NewBillingHandler(database, billing.NewService(database, &recordedPlans{}, provider))
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("user-1").
+		WillReturnRows(mockUserRows("user-1", "mom@example.com"))
+	mock.ExpectQuery(`FROM plan_prices pp`).
+		WithArgs("premium", "paystack").
+		WillReturnRows(sqlmock.NewRows(planPriceColumns).
+			AddRow(1, 2, "premium", "Premium", "paystack", "NGN", 500000, "month", "PLN_1", true, now))
+	mock.ExpectQuery(`INSERT INTO billing_checkouts`).
+		WithArgs(sqlmock.AnyArg(), "user-1", "premium", "paystack", "NGN", int64(500000), "month").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow("checkout-1", "pending", now))
+
+	r := ginWithUserID("user-1")
+	r.POST("/billing/checkout", handler.Checkout)
+
+	req, _ := jsonRequest(http.MethodPost, "/billing/checkout", map[string]any{"plan_code": "premium", "provider": "Paystack"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp CheckoutResponse
+	decodeJSONBody(t, w, &resp)
+	if resp.Provider != "paystack" || resp.Amount != 500000 || !strings.HasSuffix(resp.CheckoutURL, "/"+resp.Reference) {
+		t.Errorf("response = %+v", resp)
+	}
+	if sessions := provider.Sessions(); len(sessions) != 1 || sessions[0].Email != "mom@example.com" {
+		t.Errorf("sessions = %+v", sessions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBillingCheckout_RejectsUnavailablePlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	handler := NewBillingHandler(database, billing.NewService(database, &recordedPlans{}, billing.NewFakeProvider("stripe", "secret")))
+
+	mock.ExpectQuery(`FROM users`).WithArgs("user-1").WillReturnRows(mockUserRows("user-1", "mom@example.com"))
+	mock.ExpectQuery(`FROM plan_prices pp`).
+		WithArgs("free", "stripe").
+		WillReturnRows(sqlmock.NewRows(planPriceColumns))
+
+	r := ginWithUserID("user-1")
+	r.POST("/billing/checkout", handler.Checkout)
+
+	req, _ := jsonRequest(http.MethodPost, "/billing/checkout", map[string]any{"plan_code": "free", "provider": "stripe"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestBillingWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	provider := billing.NewFakeProvider("paystack", "secret")
+	plans := &recordedPlans{activated: make(map[string]time.Time)}
+	handler := NewBillingHandler(database, billing.NewService(database, plans, provider))
+
+	r := gin.New()
+	r.POST("/billing/webhooks/:provider", handler.Webhook)
+
+	periodEnd := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	body, header := provider.Webhook(billing.Event{
+		ID: "charge.success:mlp_ref", Type: billing.EventPaymentSucceeded, ProviderType: "charge.success",
+		Reference: "mlp_ref", CustomerID: "CUS_1", PaymentReference: "mlp_ref", Amount: 500000, Currency: "NGN",
+		PeriodEnd: &periodEnd,
+	})
+
+	mock.ExpectQuery(`INSERT INTO billing_events`).
+		WithArgs("paystack", "charge.success:mlp_ref", "charge.success", body).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("event-1"))
+	mock.ExpectQuery(`UPDATE billing_events\s+SET status = 'processing'`).
+		WithArgs("event-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("event-1"))
+	mock.ExpectQuery(`FROM billing_checkouts`).
+		WithArgs("paystack", "mlp_ref", "", "CUS_1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "user_id", "plan_code", "provider", "currency", "amount",
+			"billing_interval", "status", "provider_customer_id", "provider_subscription_id", "created_at", "completed_at"}).
+			AddRow("checkout-1", "mlp_ref", "user-1", "premium", "paystack", "NGN", 500000, "month", "pending", nil, nil, now, nil))
+	mock.ExpectExec(`UPDATE billing_checkouts`).
+		WithArgs("checkout-1", "CUS_1", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO billing_payments`).
+		WithArgs("user-1", "checkout-1", "paystack", "mlp_ref", "premium", "NGN", int64(500000), "succeeded", periodEnd).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE billing_events`).
+		WithArgs("event-1", "processed", "user-1", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req := httptest.NewRequest(http.MethodPost, "/billing/webhooks/paystack", bytes.NewReader(body))
+	req.Header = header
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if !plans.activated["user-1/premium"].Equal(periodEnd) {
+		t.Errorf("activated = %v", plans.activated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBillingWebhook_SkipsEventBeingProcessed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	provider := billing.NewFakeProvider("paystack", "secret")
+	plans := &recordedPlans{activated: make(map[string]time.Time)}
+	handler := NewBillingHandler(database, billing.NewService(database, plans, provider))
+
+	r := gin.New()
+	r.POST("/billing/webhooks/:provider", handler.Webhook)
+
+	body, header := provider.Webhook(billing.Event{
+		ID: "charge.success:mlp_ref", Type: billing.EventPaymentSucceeded, ProviderType: "charge.success",
+		Reference: "mlp_ref", PaymentReference: "mlp_ref", Amount: 500000, Currency: "NGN",
+	})
+
+	// A retry arriving while the first delivery is applied finds it claimed
+	mock.ExpectQuery(`INSERT INTO billing_events`).
+		WithArgs("paystack", "charge.success:mlp_ref", "charge.success", body).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("event-1"))
+	mock.ExpectQuery(`UPDATE billing_events\s+SET status = 'processing'`).
+		WithArgs("event-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	req := httptest.NewRequest(http.MethodPost, "/billing/webhooks/paystack", bytes.NewReader(body))
+	req.Header = header
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if len(plans.activated) != 0 {
+		t.Errorf("activated = %v, want nothing", plans.activated)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBillingWebhook_RejectsUnsigned(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, _ := newMockDB(t)
+	handler := NewBillingHandler(database, billing.NewService(database, &recordedPlans{}, billing.NewFakeProvider("stripe", "secret")))
+
+	r := gin.New()
+	r.POST("/billing/webhooks/:provider", handler.Webhook)
+
+	cases := map[string]int{
+		"/billing/webhooks/stripe": http.StatusBadRequest,
+		"/billing/webhooks/paypal": http.StatusNotFound,
+	}
+	for path, want := range cases {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"id":"evt_1","type":"payment_succeeded"}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+func TestBillingHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	handler := NewBillingHandler(database, billing.NewService(database, &recordedPlans{}))
+
+	mock.ExpectQuery(`FROM billing_payments`).
+		WithArgs("user-1", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "provider", "provider_reference", "plan_code", "currency", "amount", "status", "period_end", "created_at"}).
+			AddRow("pay-1", "paystack", "mlp_ref", "premium", "NGN", 500000, "succeeded", now.AddDate(0, 1, 0), now))
+
+	r := ginWithUserID("user-1")
+	r.GET("/billing/history", handler.GetHistory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/billing/history", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Payments []struct {
+			Reference string `json:"reference"`
+			Amount    int64  `json:"amount"`
+			Status    string `json:"status"`
+		} `json:"payments"`
+	}
+	decodeJSONBody(t, w, &resp)
+	if len(resp.Payments) != 1 || resp.Payments[0].Reference != "mlp_ref" || resp.Payments[0].Status != "succeeded" {
+		t.Errorf("payments = %+v", resp.Payments)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
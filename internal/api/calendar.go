@@ -1,23 +1,39 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/recurrence"
 )
 
+// Update scopes for recurring reminders
+const (
+	ScopeAll              = "all"
+	ScopeThisAndFollowing = "this_and_following"
+)
+
+// maxReminderWindow bounds GET /api/reminders?from=&to= expansion
+const maxReminderWindow = 366 * 24 * time.Hour
+
 // CalendarHandler handles calendar/reminder endpoints
 type CalendarHandler struct {
-	db *db.DB
+	db          *db.DB
+	suggestions *calendar.Resolver
 }
 
 // NewCalendarHandler creates a new calendar handler
 func NewCalendarHandler(database *db.DB) *CalendarHandler {
 	return &CalendarHandler{
-		db: database,
+		db:          database,
+		suggestions: calendar.NewResolver(database),
 	}
 }
 
@@ -25,7 +41,9 @@ func NewCalendarHandler(database *db.DB) *CalendarHandler {
 type CreateReminderRequest struct {
 	Title        string    `json:"title" binding:"required"`
 	Description  string    `json:"description"`
-	ReminderTime time.Time `json:"reminder_time" binding:"required"`
+	ReminderTime time.Time `json:"reminder_time" binding:"required"` // First occurrence if recurring
+	RRule        string    `json:"rrule"`                            // e.g. FREQ=DAILY;COUNT=30
+	Timezone     string    `json:"timezone"`                         // IANA zone, default the user's
 }
 
 // UpdateReminderRequest represents a reminder update request
@@ -34,20 +52,38 @@ type UpdateReminderRequest struct {
 	Description  *string    `json:"description"`
 	ReminderTime *time.Time `json:"reminder_time"`
 	IsCompleted  *bool      `json:"is_completed"`
+	RRule        *string    `json:"rrule"` // Empty string stops repeating
+	Timezone     *string    `json:"timezone"`
+	// Scope applies the update to the whole series (default) or, with
+	// OccurrenceTime, to that occurrence and the ones after it
+	Scope          string     `json:"scope" binding:"omitempty,oneof=all this_and_following"`
+	OccurrenceTime *time.Time `json:"occurrence_time"`
+}
+
+// CompleteOccurrenceRequest marks one occurrence of a recurring reminder
+type CompleteOccurrenceRequest struct {
+	OccurrenceTime time.Time `json:"occurrence_time" binding:"required"`
+	IsCompleted    *bool     `json:"is_completed" binding:"required"`
 }
 
 // ReminderResponse represents a reminder response
 type ReminderResponse struct {
-	ID               string    `json:"id"`
-	UserID           string    `json:"user_id"`
-	Title            string    `json:"title"`
-	Description      string    `json:"description,omitempty"`
-	ReminderTime     time.Time `json:"reminder_time"`
-	Priority         string    `json:"priority"` // Added default priority
-	IsCompleted      bool      `json:"is_completed"`
-	CommunityEventID *string   `json:"community_event_id,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description,omitempty"`
+	ReminderTime     time.Time  `json:"reminder_time"`
+	Priority         string     `json:"priority"` // Added default priority
+	IsCompleted      bool       `json:"is_completed"`
+	CommunityEventID *string    `json:"community_event_id,omitempty"`
+	RRule            string     `json:"rrule,omitempty"`
+	Timezone         string     `json:"timezone"`
+	IsRecurring      bool       `json:"is_recurring"`
+	OccurrenceTime   *time.Time `json:"occurrence_time,omitempty"` // Set on expanded occurrences
+	Source           string     `json:"source"`                    // user or care_plan
+	CarePlanItem     *string    `json:"care_plan_item,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
 // CreateReminder creates a new reminder
@@ -64,8 +100,19 @@ func (h *CalendarHandler) CreateReminder(c *gin.Context) {
 		UserID:       userID,
 		Title:        req.Title,
 		Description:  &req.Description,
-		ReminderTime: req.ReminderTime,
+		ReminderTime: req.ReminderTime.UTC(),
 		IsCompleted:  false,
+		Timezone:     req.Timezone,
+	}
+	if reminder.Timezone == "" {
+		reminder.Timezone = userTimezone(c.Request.Context(), h.db, userID)
+	}
+	if req.RRule != "" {
+		reminder.RRule = &req.RRule
+	}
+	if err := normalizeRecurrence(reminder); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	if err := h.db.CreateReminder(c.Request.Context(), reminder); err != nil {
@@ -76,87 +123,249 @@ func (h *CalendarHandler) CreateReminder(c *gin.Context) {
 	c.JSON(http.StatusCreated, reminderToResponse(reminder))
 }
 
-// GetReminders retrieves all reminders for the current user
+// GetReminders retrieves all reminders for the current user. With from and
+// to (RFC 3339), recurring reminders are expanded into their occurrences in
+// that window.
 func (h *CalendarHandler) GetReminders(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
+	var from, to time.Time
+	expand := c.Query("from") != "" || c.Query("to") != ""
+	if expand {
+		var errFrom, errTo error
+		from, errFrom = time.Parse(time.RFC3339, c.Query("from"))
+		to, errTo = time.Parse(time.RFC3339, c.Query("to"))
+		if errFrom != nil || errTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must both be RFC 3339 timestamps"})
+			return
+		}
+		if !to.After(from) || to.Sub(from) > maxReminderWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from, within 366 days"})
+			return
+		}
+	}
+
 	reminders, err := h.db.GetUserReminders(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reminders"})
 		return
 	}
 
+	if !expand {
+		response := make([]ReminderResponse, 0, len(reminders))
+		for i := range reminders {
+			response = append(response, reminderToResponse(&reminders[i]))
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	response, err := h.expandReminders(c, reminders, from.UTC(), to.UTC())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reminders"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// expandReminders returns the reminders and recurring occurrences in [from, to)
+func (h *CalendarHandler) expandReminders(c *gin.Context, reminders []db.Reminder, from, to time.Time) ([]ReminderResponse, error) {
+	var recurringIDs []string
+	for _, r := range reminders {
+		if r.RRule != nil {
+			recurringIDs = append(recurringIDs, r.ID)
+		}
+	}
+
+	completed := make(map[string]bool)
+	if len(recurringIDs) > 0 {
+		occurrences, err := h.db.GetReminderOccurrences(c.Request.Context(), recurringIDs, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range occurrences {
+			completed[occurrenceKey(o.ReminderID, o.OccurrenceTime)] = true
+		}
+	}
+
 	response := make([]ReminderResponse, 0, len(reminders))
 	for i := range reminders {
-		response = append(response, reminderToResponse(&reminders[i]))
+		r := &reminders[i]
+		if r.RRule == nil {
+			if !r.ReminderTime.Before(from) && r.ReminderTime.Before(to) {
+				response = append(response, reminderToResponse(r))
+			}
+			continue
+		}
+
+		rule, err := recurrence.Parse(*r.RRule, r.ReminderTime, r.Timezone)
+		if err != nil {
+			continue // Rules are validated on write
+		}
+		for _, t := range rule.Between(from, to) {
+			// Completing the series ends it; later occurrences are gone
+			if r.IsCompleted && t.After(r.UpdatedAt) {
+				break
+			}
+			occurrence := reminderToResponse(r)
+			occurrence.ReminderTime = t
+			occurrence.OccurrenceTime = &t
+			occurrence.IsCompleted = completed[occurrenceKey(r.ID, t)]
+			response = append(response, occurrence)
+		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i].ReminderTime.Before(response[j].ReminderTime)
+	})
+	return response, nil
 }
 
-// UpdateReminder updates a reminder
+// UpdateReminder updates a reminder. For a recurring reminder, scope
+// this_and_following splits the series at occurrence_time and applies the
+// update to a new series from there on.
 func (h *CalendarHandler) UpdateReminder(c *gin.Context) {
-	userID := middleware.GetUserID(c)
-	reminderID := c.Param("id")
-
 	var req UpdateReminderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get existing reminder to verify ownership
-	reminder, err := h.db.GetReminderByID(c.Request.Context(), reminderID)
-	if err != nil || reminder == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+	reminder, ok := h.ownedReminder(c)
+	if !ok {
 		return
 	}
 
-	if reminder.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	if req.Scope == ScopeThisAndFollowing {
+		h.updateFollowing(c, reminder, req)
 		return
 	}
 
-	// Update fields if provided
-	if req.Title != nil {
-		reminder.Title = *req.Title
+	applyReminderUpdate(reminder, req)
+	if err := normalizeRecurrence(reminder); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if req.Description != nil {
-		reminder.Description = req.Description
+
+	if err := h.db.UpdateReminder(c.Request.Context(), reminder); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder"})
+		return
 	}
-	if req.ReminderTime != nil {
-		reminder.ReminderTime = *req.ReminderTime
+
+	c.JSON(http.StatusOK, reminderToResponse(reminder))
+}
+
+// updateFollowing ends the series before the chosen occurrence and starts a
+// new one there with the update applied. It returns the new series.
+func (h *CalendarHandler) updateFollowing(c *gin.Context, reminder *db.Reminder, req UpdateReminderRequest) {
+	if reminder.RRule == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reminder is not recurring"})
+		return
 	}
-	if req.IsCompleted != nil {
-		reminder.IsCompleted = *req.IsCompleted
+	if req.OccurrenceTime == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence_time is required for this_and_following"})
+		return
 	}
 
-	if err := h.db.UpdateReminder(c.Request.Context(), reminder); err != nil {
+	rule, err := recurrence.Parse(*reminder.RRule, reminder.ReminderTime, reminder.Timezone)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder"})
 		return
 	}
+	splitAt := req.OccurrenceTime.UTC()
+	if !rule.IsOccurrence(splitAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence_time is not an occurrence of this reminder"})
+		return
+	}
 
-	c.JSON(http.StatusOK, reminderToResponse(reminder))
+	headRule, tailRule := rule.Split(splitAt)
+	if headRule == "" {
+		// Editing from the first occurrence is editing the whole series
+		req.Scope = ScopeAll
+		applyReminderUpdate(reminder, req)
+		if err := normalizeRecurrence(reminder); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := h.db.UpdateReminder(c.Request.Context(), reminder); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder"})
+			return
+		}
+		c.JSON(http.StatusOK, reminderToResponse(reminder))
+		return
+	}
+
+	next := *reminder
+	next.ID = ""
+	next.IsCompleted = false
+	next.ReminderTime = splitAt
+	next.RRule = &tailRule
+	next.ICalUID = nil // The imported UID and care plan item stay with the original series
+	next.CarePlanItem = nil
+	applyReminderUpdate(&next, req)
+	if err := normalizeRecurrence(&next); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminder.RRule = &headRule
+	if err := h.db.SplitReminder(c.Request.Context(), reminder, &next, splitAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reminder"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reminderToResponse(&next))
 }
 
-// DeleteReminder deletes a reminder
-func (h *CalendarHandler) DeleteReminder(c *gin.Context) {
-	userID := middleware.GetUserID(c)
-	reminderID := c.Param("id")
+// CompleteOccurrence marks a single occurrence of a recurring reminder as
+// completed or not
+func (h *CalendarHandler) CompleteOccurrence(c *gin.Context) {
+	var req CompleteOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Get existing reminder to verify ownership
-	reminder, err := h.db.GetReminderByID(c.Request.Context(), reminderID)
-	if err != nil || reminder == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+	reminder, ok := h.ownedReminder(c)
+	if !ok {
+		return
+	}
+	if reminder.RRule == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reminder is not recurring; update is_completed instead"})
 		return
 	}
 
-	if reminder.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+	rule, err := recurrence.Parse(*reminder.RRule, reminder.ReminderTime, reminder.Timezone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update occurrence"})
+		return
+	}
+	occurrence := req.OccurrenceTime.UTC()
+	if !rule.IsOccurrence(occurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "occurrence_time is not an occurrence of this reminder"})
 		return
 	}
 
-	if err := h.db.DeleteReminder(c.Request.Context(), reminderID); err != nil {
+	if err := h.db.SetReminderOccurrenceCompleted(c.Request.Context(), reminder.ID, occurrence, *req.IsCompleted); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update occurrence"})
+		return
+	}
+
+	response := reminderToResponse(reminder)
+	response.ReminderTime = occurrence
+	response.OccurrenceTime = &occurrence
+	response.IsCompleted = *req.IsCompleted
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteReminder deletes a reminder
+func (h *CalendarHandler) DeleteReminder(c *gin.Context) {
+	reminder, ok := h.ownedReminder(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteReminder(c.Request.Context(), reminder.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reminder"})
 		return
 	}
@@ -164,12 +373,94 @@ func (h *CalendarHandler) DeleteReminder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Reminder deleted successfully"})
 }
 
+// ownedReminder loads the :id reminder, writing a 404 or 403 response if it
+// does not exist or belongs to someone else
+func (h *CalendarHandler) ownedReminder(c *gin.Context) (*db.Reminder, bool) {
+	reminder, err := h.db.GetReminderByID(c.Request.Context(), c.Param("id"))
+	if err != nil || reminder == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+		return nil, false
+	}
+
+	if reminder.UserID != middleware.GetUserID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+	return reminder, true
+}
+
+func applyReminderUpdate(reminder *db.Reminder, req UpdateReminderRequest) {
+	if req.Title != nil {
+		reminder.Title = *req.Title
+	}
+	if req.Description != nil {
+		reminder.Description = req.Description
+	}
+	if req.ReminderTime != nil {
+		reminder.ReminderTime = req.ReminderTime.UTC()
+	}
+	if req.IsCompleted != nil {
+		reminder.IsCompleted = *req.IsCompleted
+	}
+	if req.RRule != nil {
+		reminder.RRule = req.RRule
+	}
+	if req.Timezone != nil {
+		reminder.Timezone = *req.Timezone
+	}
+}
+
+// userTimezone returns the user's timezone for requests that do not name one,
+// falling back to UTC
+func userTimezone(ctx context.Context, database *db.DB, userID string) string {
+	tz, err := database.GetUserTimezone(ctx, userID)
+	if err != nil || tz == "" {
+		return "UTC"
+	}
+	return tz
+}
+
+// normalizeRecurrence validates a reminder's time zone and rule, storing the
+// rule in canonical form (or nil when it does not repeat)
+func normalizeRecurrence(reminder *db.Reminder) error {
+	if reminder.Timezone == "" {
+		reminder.Timezone = "UTC"
+	}
+	if _, err := recurrence.LoadLocation(reminder.Timezone); err != nil {
+		return err
+	}
+	if reminder.RRule == nil || *reminder.RRule == "" {
+		reminder.RRule = nil
+		return nil
+	}
+
+	rule, err := recurrence.Parse(*reminder.RRule, reminder.ReminderTime, reminder.Timezone)
+	if err != nil {
+		return err
+	}
+	// Expansion, splitting and notifications all assume the series starts at reminder_time
+	if !rule.IsOccurrence(rule.Start()) {
+		return errors.New("reminder_time must be the first occurrence of rrule (e.g. a Monday for BYDAY=MO)")
+	}
+	normalized := rule.String()
+	reminder.RRule = &normalized
+	return nil
+}
+
+func occurrenceKey(reminderID string, t time.Time) string {
+	return reminderID + "|" + t.UTC().Format(time.RFC3339)
+}
+
 // reminderToResponse converts a db.Reminder to ReminderResponse
 func reminderToResponse(reminder *db.Reminder) ReminderResponse {
 	description := ""
 	if reminder.Description != nil {
 		description = *reminder.Description
 	}
+	rrule := ""
+	if reminder.RRule != nil {
+		rrule = *reminder.RRule
+	}
 
 	return ReminderResponse{
 		ID:               reminder.ID,
@@ -180,6 +471,11 @@ func reminderToResponse(reminder *db.Reminder) ReminderResponse {
 		Priority:         "medium", // Default until added to DB
 		IsCompleted:      reminder.IsCompleted,
 		CommunityEventID: reminder.CommunityEventID,
+		RRule:            rrule,
+		Timezone:         reminder.Timezone,
+		IsRecurring:      reminder.RRule != nil,
+		Source:           reminder.Source,
+		CarePlanItem:     reminder.CarePlanItem,
 		CreatedAt:        reminder.CreatedAt,
 		UpdatedAt:        reminder.UpdatedAt,
 	}
@@ -0,0 +1,461 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/recurrence"
+	"github.com/themobileprof/momlaunchpad-be/pkg/ical"
+)
+
+const (
+	// feedLookback is how far back one-off entries stay in the feed
+	feedLookback = 90 * 24 * time.Hour
+	// maxImportSize bounds an uploaded ICS file
+	maxImportSize = 1 << 20
+	// maxImportEvents bounds the events read from one file
+	maxImportEvents = 500
+	// importAllDayHour is when all-day events are reminded of, in the import's time zone
+	importAllDayHour = 9
+
+	feedProdID        = "-//MomLaunchpad//Calendar Feed//EN"
+	feedUIDDomain     = "@momlaunchpad"
+	appointmentLength = time.Hour
+	eventLength       = 2 * time.Hour // For community events without an end
+)
+
+// CalendarFeedHandler serves the iCalendar subscription feed and imports ICS files
+type CalendarFeedHandler struct {
+	db       *db.DB
+	features middleware.FeatureChecker
+}
+
+// NewCalendarFeedHandler creates a new calendar feed handler. The feed is only
+// served while the user's plan includes the calendar feature.
+func NewCalendarFeedHandler(database *db.DB, features middleware.FeatureChecker) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		db:       database,
+		features: features,
+	}
+}
+
+// CalendarFeedResponse describes the user's feed. The URLs are only returned
+// when the feed is created or rotated, as only a hash of the token is kept.
+type CalendarFeedResponse struct {
+	Enabled        bool       `json:"enabled"`
+	URL            string     `json:"url,omitempty"`
+	WebcalURL      string     `json:"webcal_url,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// ImportResponse summarises an ICS import
+type ImportResponse struct {
+	Imported int `json:"imported"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+}
+
+// GetFeed reports whether the user's feed is enabled
+func (h *CalendarFeedHandler) GetFeed(c *gin.Context) {
+	feed, err := h.db.GetCalendarFeed(c.Request.Context(), middleware.GetUserID(c))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusOK, CalendarFeedResponse{Enabled: false})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CalendarFeedResponse{
+		Enabled:        true,
+		CreatedAt:      &feed.CreatedAt,
+		LastAccessedAt: feed.LastAccessedAt,
+	})
+}
+
+// CreateFeed enables the user's feed with a new secret URL. Calling it again
+// rotates the token: the previous URL stops working.
+func (h *CalendarFeedHandler) CreateFeed(c *gin.Context) {
+	token, err := newFeedToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	feed, err := h.db.SaveCalendarFeedToken(c.Request.Context(), middleware.GetUserID(c), hashFeedToken(token))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+
+	url := publicBaseURL(c) + "/calendar/" + token + ".ics"
+	webcal := url
+	if i := strings.Index(url, "://"); i >= 0 {
+		webcal = "webcal" + url[i:]
+	}
+	c.JSON(http.StatusCreated, CalendarFeedResponse{
+		Enabled:   true,
+		URL:       url,
+		WebcalURL: webcal,
+		CreatedAt: &feed.CreatedAt,
+	})
+}
+
+// DeleteFeed disables the user's feed
+func (h *CalendarFeedHandler) DeleteFeed(c *gin.Context) {
+	err := h.db.DeleteCalendarFeed(c.Request.Context(), middleware.GetUserID(c))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not enabled"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar feed disabled"})
+}
+
+// ServeFeed serves GET /calendar/:file, where file is the feed token with
+// an .ics extension. It is public: the token is the credential.
+func (h *CalendarFeedHandler) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("file"), ".ics")
+	if token == "" || token == c.Param("file") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, err := h.db.GetUserIDByCalendarFeedToken(ctx, hashFeedToken(token))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+
+	// The URL outlives the plan: a downgraded user keeps the token, not the feed
+	allowed, err := h.features.HasFeature(ctx, userID, "calendar")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "feature not available", "feature": "calendar"})
+		return
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-feedLookback)
+
+	reminders, err := h.db.GetUserReminders(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+	appointments, err := h.db.GetUpcomingAppointments(ctx, userID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+	events, err := h.db.GetInterestedCommunityEvents(ctx, userID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar"})
+		return
+	}
+
+	cal := &ical.Calendar{
+		ProdID:  feedProdID,
+		Name:    "MomLaunchpad",
+		Refresh: time.Hour,
+	}
+	for i := range reminders {
+		if event, ok := reminderEvent(&reminders[i], since); ok {
+			cal.Events = append(cal.Events, event)
+		}
+	}
+	for i := range appointments {
+		cal.Events = append(cal.Events, appointmentEvent(&appointments[i]))
+	}
+	for i := range events {
+		cal.Events = append(cal.Events, communityEventEvent(&events[i]))
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="momlaunchpad.ics"`)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	if err := cal.WriteTo(c.Writer, now); err != nil {
+		log.Printf("Failed to write calendar feed for user %s: %v", userID, err)
+	}
+}
+
+// reminderEvent converts a reminder to a feed event. Reminders created for
+// community events are left out: the event itself is in the feed.
+func reminderEvent(r *db.Reminder, since time.Time) (ical.Event, bool) {
+	if r.CommunityEventID != nil {
+		return ical.Event{}, false
+	}
+
+	uid := "reminder-" + r.ID + feedUIDDomain
+	if r.ICalUID != nil {
+		uid = *r.ICalUID
+	}
+	event := ical.Event{
+		UID:     uid,
+		Summary: r.Title,
+		Start:   r.ReminderTime,
+		TZID:    r.Timezone,
+		Updated: r.UpdatedAt,
+	}
+	if r.Description != nil {
+		event.Description = *r.Description
+	}
+
+	if r.RRule == nil {
+		return event, !r.ReminderTime.Before(since)
+	}
+	rule, err := recurrence.Parse(*r.RRule, r.ReminderTime, r.Timezone)
+	if err != nil {
+		return ical.Event{}, false // Rules are validated on write
+	}
+	event.RRule = rule.String()
+	// Completing the series ends it; later occurrences are gone
+	if r.IsCompleted {
+		if next, ok := rule.After(r.UpdatedAt); ok {
+			head, _ := rule.Split(next)
+			if head == "" {
+				return ical.Event{}, false
+			}
+			event.RRule = head
+		}
+	}
+	return event, true
+}
+
+func appointmentEvent(v *db.DoctorVisit) ical.Event {
+	summary := "Doctor appointment"
+	if v.ProviderName != nil && *v.ProviderName != "" {
+		summary += " with " + *v.ProviderName
+	}
+	event := ical.Event{
+		UID:     "appointment-" + v.ID + feedUIDDomain,
+		Summary: summary,
+		Start:   *v.NextAppointmentAt,
+		End:     v.NextAppointmentAt.Add(appointmentLength),
+		Updated: v.UpdatedAt,
+	}
+	if v.NextAppointmentNotes != nil {
+		event.Description = *v.NextAppointmentNotes
+	}
+	if v.FacilityName != nil {
+		event.Location = *v.FacilityName
+	}
+	return event
+}
+
+func communityEventEvent(e *db.CommunityEvent) ical.Event {
+	event := ical.Event{
+		UID:     "community-event-" + e.ID + feedUIDDomain,
+		Summary: e.Title,
+		Start:   e.StartsAt,
+		End:     e.StartsAt.Add(eventLength),
+		Status:  "CONFIRMED",
+	}
+	if e.EndsAt != nil && e.EndsAt.After(e.StartsAt) {
+		event.End = *e.EndsAt
+	}
+	if e.Description != nil {
+		event.Description = *e.Description
+	}
+
+	var location []string
+	for _, part := range []*string{e.Venue, e.City, e.StateProvince, e.Country} {
+		if part != nil && strings.TrimSpace(*part) != "" {
+			location = append(location, strings.TrimSpace(*part))
+		}
+	}
+	event.Location = strings.Join(location, ", ")
+	return event
+}
+
+// Import creates reminders from an ICS file, sent as the multipart field
+// "file" or as a text/calendar request body. Events imported before (by UID)
+// are updated. Floating times and all-day events are read in the timezone
+// parameter (default the user's timezone); all-day events are reminded of at 09:00.
+func (h *CalendarFeedHandler) Import(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	tz := c.DefaultPostForm("timezone", c.Query("timezone"))
+	if tz == "" {
+		tz = userTimezone(c.Request.Context(), h.db, userID)
+	}
+	loc, err := recurrence.LoadLocation(tz)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body, err := importBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxImportSize+1))
+	_ = body.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read calendar file"})
+		return
+	}
+	if len(data) > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file must be 1MB or smaller"})
+		return
+	}
+
+	events, invalid, err := ical.Parse(bytes.NewReader(data), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read calendar file: " + err.Error()})
+		return
+	}
+
+	resp := ImportResponse{Skipped: invalid}
+	if len(events) > maxImportEvents {
+		resp.Skipped += len(events) - maxImportEvents
+		events = events[:maxImportEvents]
+	}
+
+	now := time.Now().UTC()
+	for i := range events {
+		reminder, ok := importedReminder(&events[i], userID, loc, now)
+		if !ok {
+			resp.Skipped++
+			continue
+		}
+		inserted, err := h.db.UpsertImportedReminder(c.Request.Context(), reminder)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import reminders"})
+			return
+		}
+		if inserted {
+			resp.Imported++
+		} else {
+			resp.Updated++
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// importBody returns the uploaded file, or the raw request body
+func importBody(c *gin.Context) (io.ReadCloser, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("file is required")
+		}
+		if header.Size > maxImportSize {
+			return nil, errors.New("file must be 1MB or smaller")
+		}
+		return header.Open()
+	}
+	if c.Request.ContentLength > maxImportSize {
+		return nil, errors.New("file must be 1MB or smaller")
+	}
+	return c.Request.Body, nil
+}
+
+// importedReminder converts an imported event to a reminder, reporting false
+// for events that cannot or need not be reminded of: cancelled, already past,
+// or with an unusable rule
+func importedReminder(e *ical.Event, userID string, loc *time.Location, now time.Time) (*db.Reminder, bool) {
+	if e.UID == "" || len(e.UID) > 255 || e.Status == "CANCELLED" {
+		return nil, false
+	}
+
+	start := e.Start
+	tz := e.TZID
+	if tz == "" {
+		tz = loc.String()
+	}
+	if e.AllDay {
+		day := e.Start.In(loc)
+		start = time.Date(day.Year(), day.Month(), day.Day(), importAllDayHour, 0, 0, 0, loc)
+		tz = loc.String()
+	}
+	start = start.UTC().Truncate(time.Second)
+
+	title := e.Summary
+	if title == "" {
+		title = "Calendar event"
+	}
+	description := e.Description
+	if e.Location != "" {
+		description = strings.TrimSpace(description + "\n\n" + e.Location)
+	}
+
+	reminder := &db.Reminder{
+		UserID:       userID,
+		Title:        title,
+		Description:  &description,
+		ReminderTime: start,
+		Timezone:     tz,
+		ICalUID:      &e.UID,
+	}
+
+	if e.RRule == "" {
+		return reminder, start.After(now)
+	}
+
+	rule, err := recurrence.Parse(e.RRule, start, tz)
+	if err != nil {
+		return nil, false
+	}
+	// A reminder's series starts at its first occurrence, which DTSTART
+	// need not be (e.g. DTSTART on a Sunday with BYDAY=MO)
+	if !rule.IsOccurrence(start) {
+		first, ok := rule.After(start)
+		if !ok {
+			return nil, false
+		}
+		if rule, err = recurrence.Parse(e.RRule, first, tz); err != nil {
+			return nil, false
+		}
+		reminder.ReminderTime = first
+	}
+	if _, ok := rule.After(now); !ok {
+		return nil, false // Series already finished
+	}
+	normalized := rule.String()
+	reminder.RRule = &normalized
+	return reminder, true
+}
+
+// newFeedToken returns a random URL-safe feed token
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateFeed_RotatesToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PUBLIC_BASE_URL", "https://api.example.com")
+	database, mock := newMockDB(t)
+	now := time.Now()
+
+	var hashes []string
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(`INSERT INTO calendar_feeds`).
+			WithArgs("user-1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(now))
+	}
+
+	r := ginWithUserID("user-1")
+	r.POST("/calendar/feed", NewCalendarFeedHandler(database, fakeFeatures{"calendar": true}).CreateFeed)
+
+	var urls []string
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/calendar/feed", nil))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		var resp CalendarFeedResponse
+		decodeJSONBody(t, w, &resp)
+		if !strings.HasPrefix(resp.URL, "https://api.example.com/calendar/") || !strings.HasSuffix(resp.URL, ".ics") {
+			t.Errorf("url = %q", resp.URL)
+		}
+		if resp.WebcalURL != "webcal"+strings.TrimPrefix(resp.URL, "https") {
+			t.Errorf("webcal_url = %q", resp.WebcalURL)
+		}
+		urls = append(urls, resp.URL)
+		token := strings.TrimSuffix(strings.TrimPrefix(resp.URL, "https://api.example.com/calendar/"), ".ics")
+		hashes = append(hashes, hashFeedToken(token))
+	}
+	if urls[0] == urls[1] || hashes[0] == hashes[1] {
+		t.Error("rotating returned the same token")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeFeatures grants the features set to true
+type fakeFeatures map[string]bool
+
+func (f fakeFeatures) HasFeature(_ context.Context, _, featureKey string) (bool, error) {
+	return f[featureKey], nil
+}
+
+func TestServeFeed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	token := "feed-token"
+	start := time.Date(2030, 3, 4, 7, 0, 0, 0, time.UTC) // 08:00 in Lagos
+	appointment := time.Date(2030, 3, 10, 9, 30, 0, 0, time.UTC)
+	eventStart := time.Date(2030, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`UPDATE calendar_feeds`).
+		WithArgs(hashFeedToken(token)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+	mock.ExpectQuery(`FROM reminders`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(reminderColumns).
+			AddRow("iron", "user-1", "Iron, with juice", nil, start, false, nil, "FREQ=DAILY", "Africa/Lagos", nil, "user", nil, now, now).
+			AddRow("community", "user-1", "Meetup", nil, eventStart, false, "event-1", nil, "UTC", nil, "user", nil, now, now))
+	mock.ExpectQuery(`FROM doctor_visits`).
+		WithArgs("user-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "visit_date", "visit_type", "provider_name", "facility_name",
+			"chief_complaint", "clinical_notes", "diagnosis", "treatment_plan", "follow_up_instructions",
+			"blood_pressure_systolic", "blood_pressure_diastolic", "weight_kg", "heart_rate_bpm",
+			"temperature_celsius", "fundal_height_cm", "fetal_heart_rate_bpm", "gestational_age_weeks",
+			"medications", "lab_results", "next_appointment_at", "next_appointment_notes",
+			"recorded_by", "provider_user_id", "created_at", "updated_at",
+		}).AddRow(
+			"visit-1", "user-1", now, "prenatal", "Dr. Ada", "City Clinic",
+			nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil,
+			[]byte("[]"), []byte("[]"), appointment, "Bring scan results",
+			"user", nil, now, now,
+		))
+	mock.ExpectQuery(`FROM community_events e`).
+		WithArgs("user-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "post_id", "event_type", "title", "description", "venue", "starts_at", "ends_at",
+			"country", "state_province", "city", "interested_count", "created_at",
+		}).AddRow("event-1", "post-1", nil, "Moms meetup", nil, "Town Hall", eventStart, nil, "Nigeria", nil, "Lagos", 3, now))
+
+	r := gin.New()
+	r.GET("/calendar/:file", NewCalendarFeedHandler(database, fakeFeatures{"calendar": true}).ServeFeed)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/calendar/"+token+".ics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"UID:reminder-iron@momlaunchpad\r\n",
+		"DTSTART;TZID=Africa/Lagos:20300304T080000\r\n",
+		"RRULE:FREQ=DAILY\r\n",
+		`SUMMARY:Iron\, with juice`,
+		"BEGIN:VTIMEZONE\r\nTZID:Africa/Lagos\r\n",
+		"UID:appointment-visit-1@momlaunchpad\r\n",
+		"SUMMARY:Doctor appointment with Dr. Ada\r\n",
+		"DTEND:20300310T103000Z\r\n",
+		"UID:community-event-event-1@momlaunchpad\r\n",
+		`LOCATION:Town Hall\, Lagos\, Nigeria`,
+		"DTEND:20300401T120000Z\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "reminder-community") {
+		t.Error("community event reminder should not be in the feed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeFeed_WithoutCalendarFeature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectQuery(`UPDATE calendar_feeds`).
+		WithArgs(hashFeedToken("feed-token")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+
+	r := gin.New()
+	r.GET("/calendar/:file", NewCalendarFeedHandler(database, fakeFeatures{}).ServeFeed)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/calendar/feed-token.ics", nil))
+
+	if w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), "BEGIN:VCALENDAR") {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServeFeed_UnknownToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectQuery(`UPDATE calendar_feeds`).
+		WithArgs(hashFeedToken("revoked")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	r := gin.New()
+	r.GET("/calendar/:file", NewCalendarFeedHandler(database, fakeFeatures{"calendar": true}).ServeFeed)
+
+	for _, path := range []string{"/calendar/revoked.ics", "/calendar/revoked"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, w.Code)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const importICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:scan@example.com\r\n" +
+	"DTSTART;TZID=Europe/London:20300615T143000\r\n" +
+	"SUMMARY:Anatomy scan\r\n" +
+	"LOCATION:St Mary's\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:class@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20300602\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=4\r\n" +
+	"SUMMARY:Antenatal class\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:old@example.com\r\n" +
+	"DTSTART:20200101T090000Z\r\n" +
+	"SUMMARY:Long ago\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@example.com\r\n" +
+	"DTSTART:20300101T090000Z\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+
+	// 14:30 BST
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Anatomy scan", sqlmock.AnyArg(), time.Date(2030, 6, 15, 13, 30, 0, 0, time.UTC),
+			nil, "Europe/London", "scan@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "inserted"}).AddRow("rem-1", now, now, true))
+	// All-day on Sunday 2 June: the series starts on Monday 3 June at 09:00 WAT
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Antenatal class", sqlmock.AnyArg(), time.Date(2030, 6, 3, 8, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY;COUNT=4;BYDAY=MO", "Africa/Lagos", "class@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at", "inserted"}).AddRow("rem-2", now, now, false))
+
+	r := ginWithUserID("user-1")
+	r.POST("/calendar/import", NewCalendarFeedHandler(database, fakeFeatures{"calendar": true}).Import)
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	_ = form.WriteField("timezone", "Africa/Lagos")
+	part, _ := form.CreateFormFile("file", "calendar.ics")
+	_, _ = part.Write([]byte(importICS))
+	_ = form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/calendar/import", &buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp ImportResponse
+	decodeJSONBody(t, w, &resp)
+	if resp != (ImportResponse{Imported: 1, Updated: 1, Skipped: 2}) {
+		t.Errorf("response = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImport_RejectsInvalidInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, _ := newMockDB(t)
+
+	r := ginWithUserID("user-1")
+	r.POST("/calendar/import", NewCalendarFeedHandler(database, fakeFeatures{"calendar": true}).Import)
+
+	cases := map[string]string{
+		"/calendar/import":                  "not a calendar",
+		"/calendar/import?timezone=Lagos":   importICS,
+		"/calendar/import?timezone=Nowhere": importICS,
+	}
+	for path, body := range cases {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/calendar")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// ListSuggestions returns the user's pending calendar suggestions
+// GET /api/reminders/suggestions?limit=50
+func (h *CalendarHandler) ListSuggestions(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	suggestions, err := h.db.ListPendingCalendarSuggestions(c.Request.Context(), middleware.GetUserID(c), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+}
+
+// AcceptSuggestion turns a calendar suggestion into a reminder. The optional
+// body changes the title, description, reminder_time or timezone first.
+// POST /api/reminders/suggestions/:id/accept
+func (h *CalendarHandler) AcceptSuggestion(c *gin.Context) {
+	var edit calendar.Edit
+	if err := c.ShouldBindJSON(&edit); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reminder, err := h.suggestions.Accept(c.Request.Context(), middleware.GetUserID(c), c.Param("id"), edit)
+	if err != nil {
+		respondSuggestionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminderToResponse(reminder))
+}
+
+// DismissSuggestion dismisses a calendar suggestion
+// POST /api/reminders/suggestions/:id/dismiss
+func (h *CalendarHandler) DismissSuggestion(c *gin.Context) {
+	if err := h.suggestions.Dismiss(c.Request.Context(), middleware.GetUserID(c), c.Param("id")); err != nil {
+		respondSuggestionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suggestion dismissed"})
+}
+
+func respondSuggestionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suggestion not found"})
+	case errors.Is(err, db.ErrSuggestionResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": "Suggestion was already accepted or dismissed"})
+	case errors.Is(err, calendar.ErrInvalidEdit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update suggestion"})
+	}
+}
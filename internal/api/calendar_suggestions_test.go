@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+var calendarSuggestionColumns = []string{
+	"id", "user_id", "conversation_id", "message_id", "type", "visit_type", "priority", "title", "description",
+	"suggested_time", "timezone", "location", "provider", "time_source", "status", "edited", "reminder_id",
+	"created_at", "resolved_at",
+}
+
+func mockSuggestionRow(status string, suggestedTime time.Time) *sqlmock.Rows {
+	return sqlmock.NewRows(calendarSuggestionColumns).AddRow(
+		"sug-1", "user-1", "conv-1", "msg-1", "appointment", "ultrasound", "high", "Ultrasound scan at City Clinic", "At City Clinic",
+		suggestedTime, "Africa/Lagos", "City Clinic", nil, "message", status, false, nil,
+		time.Now(), nil,
+	)
+}
+
+func newSuggestionRouter(h *CalendarHandler) *gin.Engine {
+	r := ginWithUserID("user-1")
+	r.PUT("/reminders/:id", h.UpdateReminder)
+	r.GET("/reminders/suggestions", h.ListSuggestions)
+	r.POST("/reminders/suggestions/:id/accept", h.AcceptSuggestion)
+	r.POST("/reminders/suggestions/:id/dismiss", h.DismissSuggestion)
+	return r
+}
+
+func TestAcceptSuggestion_EditAndAccept(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	suggested := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	edited := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT .+ FROM calendar_suggestions WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("sug-1", "user-1").
+		WillReturnRows(mockSuggestionRow("pending", suggested))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM calendar_suggestions .+ FOR UPDATE`).
+		WithArgs("sug-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending"))
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Ultrasound scan at City Clinic", sqlmock.AnyArg(), edited, false, nil, nil, "Africa/Lagos", nil, "user", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("rem-1", now, now))
+	mock.ExpectExec(`UPDATE calendar_suggestions`).
+		WithArgs("accepted", true, "rem-1", "sug-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	req, _ := jsonRequest(http.MethodPost, "/reminders/suggestions/sug-1/accept", map[string]any{"reminder_time": edited})
+	w := httptest.NewRecorder()
+	newSuggestionRouter(NewCalendarHandler(database)).ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp ReminderResponse
+	decodeJSONBody(t, w, &resp)
+	if resp.ID != "rem-1" || !resp.ReminderTime.Equal(edited) || resp.Description != "At City Clinic" {
+		t.Errorf("response = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcceptSuggestion_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	suggested := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	r := newSuggestionRouter(NewCalendarHandler(database))
+
+	tests := []struct {
+		name   string
+		rows   *sqlmock.Rows
+		body   map[string]any
+		status int
+	}{
+		{"missing", sqlmock.NewRows(calendarSuggestionColumns), nil, http.StatusNotFound},
+		{"already accepted", mockSuggestionRow("accepted", suggested), nil, http.StatusConflict},
+		{"invalid timezone", mockSuggestionRow("pending", suggested), map[string]any{"timezone": "Lagos"}, http.StatusBadRequest},
+		{"empty title", mockSuggestionRow("pending", suggested), map[string]any{"title": "  "}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(`SELECT .+ FROM calendar_suggestions`).WithArgs("sug-1", "user-1").WillReturnRows(tt.rows)
+
+			req, _ := jsonRequest(http.MethodPost, "/reminders/suggestions/sug-1/accept", tt.body)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d, body: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDismissSuggestion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	r := newSuggestionRouter(NewCalendarHandler(database))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM calendar_suggestions .+ FOR UPDATE`).
+		WithArgs("sug-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending"))
+	mock.ExpectExec(`UPDATE calendar_suggestions SET status = \$1`).
+		WithArgs("dismissed", "sug-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	req, _ := jsonRequest(http.MethodPost, "/reminders/suggestions/sug-1/dismiss", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// Dismissing again conflicts
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM calendar_suggestions .+ FOR UPDATE`).
+		WithArgs("sug-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("dismissed"))
+	mock.ExpectRollback()
+
+	req, _ = jsonRequest(http.MethodPost, "/reminders/suggestions/sug-1/dismiss", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
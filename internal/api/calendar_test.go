@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+var reminderColumns = []string{
+	"id", "user_id", "title", "description", "reminder_time", "is_completed",
+	"community_event_id", "rrule", "timezone", "ical_uid", "source", "care_plan_item", "created_at", "updated_at",
+}
+
+func TestCreateReminder_Recurring(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC) // Monday
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Kick count", sqlmock.AnyArg(), start, false, nil, "FREQ=WEEKLY;BYDAY=MO", "Africa/Lagos", nil, "user", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("rem-1", now, now))
+
+	r := ginWithUserID("user-1")
+	r.POST("/reminders", NewCalendarHandler(database).CreateReminder)
+
+	req, _ := jsonRequest(http.MethodPost, "/reminders", map[string]any{
+		"title":         "Kick count",
+		"reminder_time": start,
+		"rrule":         "RRULE:freq=weekly;byday=MO",
+		"timezone":      "Africa/Lagos",
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp ReminderResponse
+	decodeJSONBody(t, w, &resp)
+	if !resp.IsRecurring || resp.RRule != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("response = %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateReminder_DefaultsToUserTimezone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	start := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT timezone FROM users`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow("America/Los_Angeles"))
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Call the clinic", sqlmock.AnyArg(), start, false, nil, nil, "America/Los_Angeles", nil, "user", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("rem-1", now, now))
+
+	r := ginWithUserID("user-1")
+	r.POST("/reminders", NewCalendarHandler(database).CreateReminder)
+
+	req, _ := jsonRequest(http.MethodPost, "/reminders", map[string]any{
+		"title":         "Call the clinic",
+		"reminder_time": start,
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp ReminderResponse
+	decodeJSONBody(t, w, &resp)
+	if resp.Timezone != "America/Los_Angeles" {
+		t.Errorf("timezone = %q, want America/Los_Angeles", resp.Timezone)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateReminder_RejectsInvalidRecurrence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, _ := newMockDB(t)
+	tuesday := time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC)
+
+	r := ginWithUserID("user-1")
+	r.POST("/reminders", NewCalendarHandler(database).CreateReminder)
+
+	bodies := []map[string]any{
+		{"title": "x", "reminder_time": tuesday, "rrule": "FREQ=FORTNIGHTLY"},
+		{"title": "x", "reminder_time": tuesday, "rrule": "FREQ=WEEKLY;BYDAY=MO"},
+		{"title": "x", "reminder_time": tuesday, "timezone": "Lagos"},
+	}
+	for _, body := range bodies {
+		req, _ := jsonRequest(http.MethodPost, "/reminders", body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", body, w.Code)
+		}
+	}
+}
+
+func TestGetReminders_ExpandsWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM reminders`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(reminderColumns).
+			AddRow("daily", "user-1", "Vitamins", nil, start, false, nil, "FREQ=DAILY", "UTC", nil, "user", nil, now, now).
+			AddRow("once", "user-1", "Scan", nil, start.Add(30*time.Hour), false, nil, nil, "UTC", nil, "user", nil, now, now).
+			AddRow("later", "user-1", "Checkup", nil, start.AddDate(0, 1, 0), false, nil, nil, "UTC", nil, "user", nil, now, now))
+	mock.ExpectQuery(`FROM reminder_occurrences`).
+		WillReturnRows(sqlmock.NewRows([]string{"reminder_id", "occurrence_time", "completed_at"}).
+			AddRow("daily", start.AddDate(0, 0, 1), now))
+
+	r := ginWithUserID("user-1")
+	r.GET("/reminders", NewCalendarHandler(database).GetReminders)
+
+	req := httptest.NewRequest(http.MethodGet, "/reminders?from=2026-03-01T00:00:00Z&to=2026-03-04T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var items []ReminderResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three daily occurrences plus the one-off scan, in time order
+	if len(items) != 4 {
+		t.Fatalf("got %d items, want 4: %s", len(items), w.Body.String())
+	}
+	if items[1].ID != "daily" || !items[1].IsCompleted || items[1].OccurrenceTime == nil {
+		t.Errorf("second occurrence = %+v, want completed daily", items[1])
+	}
+	if items[2].ID != "once" {
+		t.Errorf("third item = %s, want once", items[2].ID)
+	}
+	if items[0].IsCompleted || items[3].IsCompleted {
+		t.Error("only the ticked-off occurrence should be completed")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetReminders_InvalidWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, _ := newMockDB(t)
+
+	r := ginWithUserID("user-1")
+	r.GET("/reminders", NewCalendarHandler(database).GetReminders)
+
+	for _, query := range []string{
+		"?from=2026-03-01T00:00:00Z",
+		"?from=2026-03-04T00:00:00Z&to=2026-03-01T00:00:00Z",
+		"?from=2026-01-01T00:00:00Z&to=2027-06-01T00:00:00Z",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reminders"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestUpdateReminder_ThisAndFollowing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	splitAt := start.AddDate(0, 0, 4)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM reminders`).
+		WithArgs("daily").
+		WillReturnRows(sqlmock.NewRows(reminderColumns).
+			AddRow("daily", "user-1", "Iron", nil, start, false, nil, "FREQ=DAILY;COUNT=10", "UTC", nil, "user", nil, now, now))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE reminders`).
+		WithArgs("FREQ=DAILY;COUNT=4", splitAt, "daily").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO reminders`).
+		WithArgs("user-1", "Iron with orange juice", nil, splitAt.Add(time.Hour), false, nil, "FREQ=DAILY;COUNT=6", "UTC", nil, "user", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("daily-2", now, now))
+	mock.ExpectExec(`UPDATE reminder_occurrences`).
+		WithArgs("daily-2", "daily", splitAt).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	r := ginWithUserID("user-1")
+	r.PUT("/reminders/:id", NewCalendarHandler(database).UpdateReminder)
+
+	req, _ := jsonRequest(http.MethodPut, "/reminders/daily", map[string]any{
+		"title":           "Iron with orange juice",
+		"reminder_time":   splitAt.Add(time.Hour),
+		"scope":           "this_and_following",
+		"occurrence_time": splitAt,
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp ReminderResponse
+	decodeJSONBody(t, w, &resp)
+	if resp.ID != "daily-2" || resp.RRule != "FREQ=DAILY;COUNT=6" {
+		t.Errorf("response = %+v, want the new series", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompleteOccurrence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	reminderRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows(reminderColumns).
+			AddRow("daily", "user-1", "Vitamins", nil, start, false, nil, "FREQ=DAILY", "UTC", nil, "user", nil, now, now)
+	}
+	mock.ExpectQuery(`FROM reminders`).WithArgs("daily").WillReturnRows(reminderRow())
+	mock.ExpectExec(`INSERT INTO reminder_occurrences`).
+		WithArgs("daily", start.AddDate(0, 0, 2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM reminders`).WithArgs("daily").WillReturnRows(reminderRow())
+
+	r := ginWithUserID("user-1")
+	r.PUT("/reminders/:id/occurrences", NewCalendarHandler(database).CompleteOccurrence)
+
+	req, _ := jsonRequest(http.MethodPut, "/reminders/daily/occurrences", map[string]any{
+		"occurrence_time": start.AddDate(0, 0, 2),
+		"is_completed":    true,
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// Not an occurrence: 08:30 instead of 08:00
+	req, _ = jsonRequest(http.MethodPut, "/reminders/daily/occurrences", map[string]any{
+		"occurrence_time": start.Add(30 * time.Minute),
+		"is_completed":    true,
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
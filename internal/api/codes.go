@@ -0,0 +1,345 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/codes"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
+)
+
+// CodesHandler handles promo code and referral redemption, and the admin
+// endpoints for promo codes, sponsors and redemption analytics
+type CodesHandler struct {
+	db    *db.DB
+	codes *codes.Service
+}
+
+// NewCodesHandler creates a new codes handler
+func NewCodesHandler(database *db.DB, service *codes.Service) *CodesHandler {
+	return &CodesHandler{
+		db:    database,
+		codes: service,
+	}
+}
+
+// RedeemCodeRequest is a promo, sponsor or referral code entered by a user
+type RedeemCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// Redeem applies a promo, sponsor or referral code to the user
+// POST /api/codes/redeem
+func (h *CodesHandler) Redeem(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req RedeemCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redemption, err := h.codes.Redeem(c.Request.Context(), userID, req.Code)
+	switch {
+	case errors.Is(err, codes.ErrCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Code not found"})
+	case errors.Is(err, codes.ErrCodeUnavailable):
+		c.JSON(http.StatusGone, gin.H{"error": "This code has expired or been used up"})
+	case errors.Is(err, codes.ErrAlreadyRedeemed), errors.Is(err, codes.ErrAlreadyReferred):
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already used this code"})
+	case errors.Is(err, codes.ErrSelfReferral), errors.Is(err, codes.ErrReferralNotEligible):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, subscription.ErrPlanConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "This code can't be used while you're on another paid plan"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem code"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"redemption": redemption})
+	}
+}
+
+// GetReferral returns the user's referral code and share link
+// GET /api/codes/referral
+func (h *CodesHandler) GetReferral(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	referral, err := h.codes.ReferralCode(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get referral code"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"referral": referral})
+}
+
+// PromoCodeRequest creates a promo code; an empty code is generated
+type PromoCodeRequest struct {
+	Code           string     `json:"code" binding:"omitempty,min=4,max=32,alphanum"`
+	Description    string     `json:"description" binding:"max=500"`
+	GrantType      string     `json:"grant_type" binding:"required,oneof=plan feature"`
+	GrantTarget    string     `json:"grant_target" binding:"required"`
+	DurationDays   int        `json:"duration_days" binding:"required,min=1,max=730"`
+	MaxRedemptions *int       `json:"max_redemptions" binding:"omitempty,min=1"` // omit for unlimited
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// UpdatePromoCodeRequest changes a promo code; omitted fields are unchanged
+type UpdatePromoCodeRequest struct {
+	Description    *string    `json:"description" binding:"omitempty,max=500"`
+	MaxRedemptions *int       `json:"max_redemptions" binding:"omitempty,min=1"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	Active         *bool      `json:"active"`
+}
+
+// ListPromoCodes returns promo codes not issued to sponsors (admin only)
+// GET /api/admin/promo-codes
+func (h *CodesHandler) ListPromoCodes(c *gin.Context) {
+	list, err := h.db.ListPromoCodes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list promo codes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"promo_codes": list})
+}
+
+// CreatePromoCode creates a promo code (admin only)
+// POST /api/admin/promo-codes
+func (h *CodesHandler) CreatePromoCode(c *gin.Context) {
+	var req PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promo := &db.PromoCode{
+		Code:           req.Code,
+		Description:    req.Description,
+		GrantType:      req.GrantType,
+		GrantTarget:    req.GrantTarget,
+		DurationDays:   req.DurationDays,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+	}
+	switch err := h.codes.CreatePromoCode(c.Request.Context(), promo); {
+	case errors.Is(err, db.ErrNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown " + req.GrantType + " " + req.GrantTarget})
+	case errors.Is(err, db.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Code already exists"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promo code"})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"promo_code": promo})
+	}
+}
+
+// UpdatePromoCode changes a promo code's limits or deactivates it (admin only)
+// PUT /api/admin/promo-codes/:id
+func (h *CodesHandler) UpdatePromoCode(c *gin.Context) {
+	var req UpdatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promo, err := h.db.UpdatePromoCode(c.Request.Context(), c.Param("id"), db.PromoCodeUpdate{
+		Description:    req.Description,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+		Active:         req.Active,
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promo code not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update promo code"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"promo_code": promo})
+}
+
+// ListRedemptions returns who redeemed a promo code (admin only)
+// GET /api/admin/promo-codes/:id/redemptions?limit=100
+func (h *CodesHandler) ListRedemptions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		limit = 100
+	}
+
+	redemptions, err := h.db.ListCodeRedemptions(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list redemptions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"redemptions": redemptions})
+}
+
+// SponsorRequest creates a sponsor
+type SponsorRequest struct {
+	Name         string  `json:"name" binding:"required,max=200"`
+	Kind         string  `json:"kind" binding:"required,oneof=ngo employer other"`
+	ContactEmail *string `json:"contact_email" binding:"omitempty,email"`
+	Seats        int     `json:"seats" binding:"required,min=1,max=100000"`
+	PlanCode     string  `json:"plan_code" binding:"required"`
+	DurationDays int     `json:"duration_days" binding:"required,min=1,max=730"`
+}
+
+// UpdateSponsorRequest changes a sponsor; omitted fields are unchanged.
+// Plan and duration changes apply to codes issued afterwards.
+type UpdateSponsorRequest struct {
+	Name         *string `json:"name" binding:"omitempty,max=200"`
+	ContactEmail *string `json:"contact_email" binding:"omitempty,email"`
+	Seats        *int    `json:"seats" binding:"omitempty,min=1,max=100000"`
+	PlanCode     *string `json:"plan_code"`
+	DurationDays *int    `json:"duration_days" binding:"omitempty,min=1,max=730"`
+	Active       *bool   `json:"active"`
+}
+
+// IssueCodesRequest asks for a number of sponsor seat codes
+type IssueCodesRequest struct {
+	Count int `json:"count" binding:"required,min=1,max=1000"`
+}
+
+// ListSponsors returns all sponsors (admin only)
+// GET /api/admin/sponsors
+func (h *CodesHandler) ListSponsors(c *gin.Context) {
+	sponsors, err := h.db.ListSponsors(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sponsors"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sponsors": sponsors})
+}
+
+// CreateSponsor creates a sponsor organization (admin only)
+// POST /api/admin/sponsors
+func (h *CodesHandler) CreateSponsor(c *gin.Context) {
+	var req SponsorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sponsor := &db.Sponsor{
+		Name:         req.Name,
+		Kind:         req.Kind,
+		ContactEmail: req.ContactEmail,
+		Seats:        req.Seats,
+		PlanCode:     req.PlanCode,
+		DurationDays: req.DurationDays,
+	}
+	if err := h.db.CreateSponsor(c.Request.Context(), sponsor); errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown plan " + req.PlanCode})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sponsor"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"sponsor": sponsor})
+}
+
+// UpdateSponsor changes a sponsor; deactivating it disables its codes (admin only)
+// PUT /api/admin/sponsors/:id
+func (h *CodesHandler) UpdateSponsor(c *gin.Context) {
+	var req UpdateSponsorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sponsor, err := h.db.UpdateSponsor(c.Request.Context(), c.Param("id"), db.SponsorUpdate{
+		Name:         req.Name,
+		ContactEmail: req.ContactEmail,
+		Seats:        req.Seats,
+		PlanCode:     req.PlanCode,
+		DurationDays: req.DurationDays,
+		Active:       req.Active,
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sponsor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sponsor"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sponsor": sponsor})
+}
+
+// ListSponsorCodes returns a sponsor's seat codes (admin only)
+// GET /api/admin/sponsors/:id/codes
+func (h *CodesHandler) ListSponsorCodes(c *gin.Context) {
+	list, err := h.db.ListSponsorCodes(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sponsor codes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"codes": list})
+}
+
+// IssueSponsorCodes creates single-use seat codes for a sponsor (admin only)
+// POST /api/admin/sponsors/:id/codes
+func (h *CodesHandler) IssueSponsorCodes(c *gin.Context) {
+	var req IssueCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	issued, err := h.codes.IssueSponsorCodes(c.Request.Context(), c.Param("id"), req.Count)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sponsor not found"})
+	case errors.Is(err, db.ErrNoSeats):
+		c.JSON(http.StatusConflict, gin.H{"error": "Sponsor is inactive or has no seats left"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue codes"})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"codes": issued})
+	}
+}
+
+// GetSponsorReport returns how a sponsor's seats are used (admin only)
+// GET /api/admin/sponsors/:id/report
+func (h *CodesHandler) GetSponsorReport(c *gin.Context) {
+	report, err := h.db.GetSponsorReport(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sponsor not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build sponsor report"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// GetRedemptionAnalytics summarizes promo, sponsor and referral redemptions (admin only)
+// GET /api/admin/analytics/redemptions?days=30
+func (h *CodesHandler) GetRedemptionAnalytics(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	stats, err := h.db.GetRedemptionStats(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load redemption analytics"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"analytics": stats})
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/codes"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
+)
+
+type recordedGrants struct {
+	features map[string]string
+}
+
+func (r *recordedGrants) GrantPlan(_ context.Context, _, planCode string, days int) (*subscription.Subscription, error) {
+	endsAt := time.Now().AddDate(0, 0, days)
+	return &subscription.Subscription{PlanCode: planCode, EndsAt: &endsAt}, nil
+}
+
+func (r *recordedGrants) GrantFeatureUntil(_ context.Context, userID, featureKey, source string, _ *time.Time) error {
+	r.features[userID+"/"+featureKey] = source
+	return nil
+}
+
+var promoCodeRowColumns = []string{"id", "code", "description", "grant_type", "grant_target", "duration_days",
+	"max_redemptions", "redemption_count", "expires_at", "active", "sponsor_id", "created_at", "updated_at"}
+
+func TestCodesRedeem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	grants := &recordedGrants{features: make(map[string]string)}
+	handler := NewCodesHandler(database, codes.NewService(database, grants, codes.Config{}))
+
+	mock.ExpectQuery(`FROM promo_codes WHERE code = \$1`).
+		WithArgs("CALLS7").
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).
+			AddRow("code-1", "CALLS7", "Voice week", "feature", "voice_calls", 7, nil, 3, nil, true, nil, now, now))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO code_redemptions`).
+		WithArgs("code-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("redemption-1"))
+	mock.ExpectExec(`UPDATE promo_codes`).
+		WithArgs("code-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r := ginWithUserID("user-1")
+	r.POST("/codes/redeem", handler.Redeem)
+
+	req, _ := jsonRequest(http.MethodPost, "/codes/redeem", map[string]any{"code": "calls7"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Redemption codes.Redemption `json:"redemption"`
+	}
+	decodeJSONBody(t, w, &resp)
+	if resp.Redemption.Kind != codes.KindPromo || resp.Redemption.GrantTarget != "voice_calls" || resp.Redemption.ExpiresAt == nil {
+		t.Errorf("redemption = %+v", resp.Redemption)
+	}
+	if grants.features["user-1/voice_calls"] != subscription.GrantPromo {
+		t.Errorf("features = %v", grants.features)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCodesRedeem_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	handler := NewCodesHandler(database, codes.NewService(database, &recordedGrants{}, codes.Config{}))
+
+	r := ginWithUserID("user-1")
+	r.POST("/codes/redeem", handler.Redeem)
+
+	// Neither a promo nor a referral code
+	mock.ExpectQuery(`FROM promo_codes WHERE code = \$1`).WithArgs("NOPE").WillReturnRows(sqlmock.NewRows(promoCodeRowColumns))
+	mock.ExpectQuery(`FROM referral_codes WHERE code = \$1`).WithArgs("NOPE").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	// Single-use code someone else already redeemed
+	mock.ExpectQuery(`FROM promo_codes WHERE code = \$1`).
+		WithArgs("SEAT1").
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).
+			AddRow("code-2", "SEAT1", "", "plan", "premium", 90, 1, 1, nil, true, "sponsor-1", now, now))
+
+	// Own referral code
+	mock.ExpectQuery(`FROM promo_codes WHERE code = \$1`).WithArgs("MINE").WillReturnRows(sqlmock.NewRows(promoCodeRowColumns))
+	mock.ExpectQuery(`FROM referral_codes WHERE code = \$1`).WithArgs("MINE").WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+
+	cases := []struct {
+		code string
+		want int
+	}{
+		{"nope", http.StatusNotFound},
+		{"seat1", http.StatusGone},
+		{"mine", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req, _ := jsonRequest(http.MethodPost, "/codes/redeem", map[string]any{"code": tc.code})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d, body: %s", tc.code, w.Code, tc.want, w.Body.String())
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var sponsorRowColumns = []string{"id", "name", "kind", "contact_email", "seats", "plan_code", "duration_days",
+	"active", "created_at", "updated_at"}
+
+func TestIssueSponsorCodes_NoSeatsLeft(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	handler := NewCodesHandler(database, codes.NewService(database, &recordedGrants{}, codes.Config{}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM sponsors WHERE id = \$1 FOR UPDATE`).
+		WithArgs("sponsor-1").
+		WillReturnRows(sqlmock.NewRows(sponsorRowColumns).
+			AddRow("sponsor-1", "Mothers First", "ngo", nil, 10, "premium", 90, true, now, now))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM promo_codes WHERE sponsor_id = \$1`).
+		WithArgs("sponsor-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(8))
+	mock.ExpectRollback()
+
+	r := gin.New()
+	r.POST("/admin/sponsors/:id/codes", handler.IssueSponsorCodes)
+
+	req, _ := jsonRequest(http.MethodPost, "/admin/sponsors/sponsor-1/codes", map[string]any{"count": 3})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetSponsorReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+	handler := NewCodesHandler(database, codes.NewService(database, &recordedGrants{}, codes.Config{}))
+
+	mock.ExpectQuery(`FROM sponsors s`).
+		WithArgs("sponsor-1").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "seats", "issued", "redeemed"}).AddRow("Mothers First", 10, 5, 2))
+	mock.ExpectQuery(`FROM code_redemptions r`).
+		WithArgs("sponsor-1").
+		WillReturnRows(sqlmock.NewRows([]string{"code", "user_id", "redeemed_at", "active"}).
+			AddRow("SEAT2", "user-2", now, true).
+			AddRow("SEAT1", "user-1", now.Add(-time.Hour), false))
+
+	r := gin.New()
+	r.GET("/admin/sponsors/:id/report", handler.GetSponsorReport)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/sponsors/sponsor-1/report", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Report struct {
+			Seats         int `json:"seats"`
+			CodesIssued   int `json:"codes_issued"`
+			CodesRedeemed int `json:"codes_redeemed"`
+			ActiveMembers int `json:"active_members"`
+			Redemptions   []struct {
+				Code string `json:"code"`
+			} `json:"redemptions"`
+		} `json:"report"`
+	}
+	decodeJSONBody(t, w, &resp)
+	if resp.Report.Seats != 10 || resp.Report.CodesIssued != 5 || resp.Report.CodesRedeemed != 2 ||
+		resp.Report.ActiveMembers != 1 || len(resp.Report.Redemptions) != 2 {
+		t.Errorf("report = %+v", resp.Report)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetRedemptionAnalytics_RejectsBadWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, _ := newMockDB(t)
+	handler := NewCodesHandler(database, codes.NewService(database, &recordedGrants{}, codes.Config{}))
+
+	r := gin.New()
+	r.GET("/admin/analytics/redemptions", handler.GetRedemptionAnalytics)
+
+	for _, days := range []string{"0", "400", "week"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/analytics/redemptions?days="+days, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("days=%s: status = %d, want 400", days, w.Code)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
 	"github.com/themobileprof/momlaunchpad-be/internal/community"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
 // CommunityHandler serves the parenting community API.
@@ -386,7 +387,7 @@ func (h *CommunityHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
-	analysis := h.processor.AnalyzePost(ctx, body, validCategories)
+	analysis := h.processor.AnalyzePost(llm.WithUser(ctx, userID), body, validCategories)
 	if req.Event != nil {
 		ok, err := h.db.IsEnabledEventType(ctx, req.Event.EventType)
 		if err != nil || !ok {
@@ -1,18 +1,29 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/memory"
 )
 
 // DoctorVisitHandler handles patient and provider visit record endpoints.
 type DoctorVisitHandler struct {
-	db *db.DB
+	db     *db.DB
+	memory MemoryIndexer
+}
+
+// MemoryIndexer adds records to the assistant's long-term semantic memory.
+type MemoryIndexer interface {
+	Index(ctx context.Context, userID, source, sourceID, content string) error
 }
 
 // NewDoctorVisitHandler creates a new doctor visit handler.
@@ -20,6 +31,12 @@ func NewDoctorVisitHandler(database *db.DB) *DoctorVisitHandler {
 	return &DoctorVisitHandler{db: database}
 }
 
+// WithMemory indexes visit notes so the chat assistant can recall them.
+func (h *DoctorVisitHandler) WithMemory(indexer MemoryIndexer) *DoctorVisitHandler {
+	h.memory = indexer
+	return h
+}
+
 // VisitMedication represents a prescribed medication entry.
 type VisitMedication struct {
 	Name         string `json:"name"`
@@ -187,6 +204,7 @@ func (h *DoctorVisitHandler) CreateVisit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create visit record"})
 		return
 	}
+	h.rememberVisit(visit)
 
 	c.JSON(http.StatusCreated, visitToResponse(visit))
 }
@@ -218,6 +236,7 @@ func (h *DoctorVisitHandler) UpdateVisit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update visit record"})
 		return
 	}
+	h.rememberVisit(visit)
 
 	c.JSON(http.StatusOK, visitToResponse(visit))
 }
@@ -287,6 +306,7 @@ func (h *DoctorVisitHandler) ProviderCreateVisit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create visit record"})
 		return
 	}
+	h.rememberVisit(visit)
 
 	c.JSON(http.StatusCreated, visitToResponse(visit))
 }
@@ -316,6 +336,7 @@ func (h *DoctorVisitHandler) ProviderUpdateVisit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update visit record"})
 		return
 	}
+	h.rememberVisit(visit)
 
 	c.JSON(http.StatusOK, visitToResponse(visit))
 }
@@ -383,6 +404,48 @@ func payloadToVisit(
 	}, nil
 }
 
+// rememberVisit indexes a visit's notes into long-term memory in the background.
+func (h *DoctorVisitHandler) rememberVisit(visit *db.DoctorVisit) {
+	if h.memory == nil {
+		return
+	}
+	content := visitMemoryText(visit)
+	if content == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := h.memory.Index(ctx, visit.UserID, memory.SourceDoctorVisit, visit.ID, content); err != nil {
+			log.Printf("Failed to index doctor visit %s into memory: %v", visit.ID, err)
+		}
+	}()
+}
+
+// visitMemoryText summarizes the free-text parts of a visit for recall.
+// Returns "" when the visit has no notes worth remembering.
+func visitMemoryText(visit *db.DoctorVisit) string {
+	parts := make([]string, 0, 5)
+	for _, field := range []struct {
+		label string
+		value *string
+	}{
+		{"Reason", visit.ChiefComplaint},
+		{"Diagnosis", visit.Diagnosis},
+		{"Notes", visit.ClinicalNotes},
+		{"Plan", visit.TreatmentPlan},
+		{"Follow-up", visit.FollowUpInstructions},
+	} {
+		if field.value != nil && strings.TrimSpace(*field.value) != "" {
+			parts = append(parts, field.label+": "+strings.TrimSpace(*field.value))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s visit on %s. %s", visit.VisitType, visit.VisitDate.Format("Jan 2, 2006"), strings.Join(parts, ". "))
+}
+
 func applyVisitUpdates(visit *db.DoctorVisit, req UpdateDoctorVisitRequest) {
 	if req.VisitDate != nil {
 		visit.VisitDate = *req.VisitDate
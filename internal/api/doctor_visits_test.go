@@ -200,3 +200,23 @@ func TestApplyVisitUpdates(t *testing.T) {
 		t.Fatalf("visit type = %q", visit.VisitType)
 	}
 }
+
+func TestVisitMemoryText(t *testing.T) {
+	diagnosis := "Gestational diabetes"
+	plan := "  Low-sugar diet, check glucose daily "
+	visit := &db.DoctorVisit{
+		VisitType:     "prenatal",
+		VisitDate:     time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		Diagnosis:     &diagnosis,
+		TreatmentPlan: &plan,
+	}
+
+	want := "prenatal visit on Mar 4, 2026. Diagnosis: Gestational diabetes. Plan: Low-sugar diet, check glucose daily"
+	if got := visitMemoryText(visit); got != want {
+		t.Errorf("visitMemoryText = %q, want %q", got, want)
+	}
+
+	if got := visitMemoryText(&db.DoctorVisit{VisitType: "ultrasound"}); got != "" {
+		t.Errorf("expected no memory for a visit without notes, got %q", got)
+	}
+}
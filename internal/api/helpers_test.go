@@ -20,10 +20,12 @@ var userRowColumns = []string{
 	"is_first_pregnancy", "primary_concern", "diet_preference",
 	"journey_stage", "journey_stage_since", "baby_birth_date", "loss_date",
 	"profile_photo_url", "country", "country_code", "state_province", "city",
-	"community_onboarding_completed_at",
+	"timezone", "community_onboarding_completed_at",
 	"savings_goal", "is_admin", "onboarding_completed_at", "created_at", "updated_at",
 }
 
+var userPhoneColumns = []string{"phone_number", "verified_at", "voice_pin_hash", "pin_failures", "pin_failed_since"}
+
 func newMockDB(t *testing.T) (*db.DB, sqlmock.Sqlmock) {
 	t.Helper()
 	sqlDB, mock, err := sqlmock.New()
@@ -40,7 +42,7 @@ func mockUserRows(userID, email string) *sqlmock.Rows {
 	return sqlmock.NewRows(userRowColumns).AddRow(
 		userID, email, "", name, "en", "", nil, nil, nil, nil, nil, nil,
 		nil, nil, nil, nil,
-		nil, nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, nil, "UTC", nil,
 		nil, false, nil, now, now,
 	)
 }
@@ -54,7 +56,7 @@ func mockUserRowsWithPassword(userID, email, passwordHash string, isAdmin bool)
 	return sqlmock.NewRows(userRowColumns).AddRow(
 		userID, email, passwordHash, name, "en", "", nil, nil, nil, nil, nil, nil,
 		nil, nil, nil, nil,
-		nil, nil, nil, nil, nil, nil,
+		nil, nil, nil, nil, nil, "UTC", nil,
 		nil, isAdmin, nil, now, now,
 	)
 }
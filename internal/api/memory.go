@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/facts"
+)
+
+// userCorrectionConfidence outranks anything extracted from chat
+const userCorrectionConfidence = 1.0
+
+// MemoryCache is the chat engine's in-process memory, which must drop
+// anything the user changes so the next message sees it.
+type MemoryCache interface {
+	RemoveFact(userID, key string)
+	Forget(userID string)
+}
+
+// MemoryHandler lets users see, correct and delete what the assistant
+// knows about them.
+type MemoryHandler struct {
+	db    *db.DB
+	cache MemoryCache
+}
+
+// NewMemoryHandler creates a new memory handler. The cache may be nil.
+func NewMemoryHandler(database *db.DB, cache MemoryCache) *MemoryHandler {
+	return &MemoryHandler{db: database, cache: cache}
+}
+
+// FactResponse is the API representation of a learned fact.
+type FactResponse struct {
+	Key        string    `json:"key"`
+	Value      string    `json:"value"`
+	Confidence float64   `json:"confidence"`
+	Source     string    `json:"source"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CorrectFactRequest is the body for correcting a fact.
+type CorrectFactRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// ListFacts returns everything the assistant has learned about the user.
+func (h *MemoryHandler) ListFacts(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	stored, err := h.db.GetUserFacts(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch facts"})
+		return
+	}
+
+	response := make([]FactResponse, 0, len(stored))
+	for i := range stored {
+		response = append(response, factToResponse(&stored[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"facts": response,
+		"count": len(response),
+	})
+}
+
+// CorrectFact replaces a fact's value. Corrections are stored with full
+// confidence so later chat extraction cannot overwrite them.
+func (h *MemoryHandler) CorrectFact(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	key := c.Param("key")
+
+	var req CorrectFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	value, err := facts.Normalize(key, req.Value, time.Now())
+	if errors.Is(err, facts.ErrUnknownKey) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown fact"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fact, err := h.db.SaveOrUpdateFact(c.Request.Context(), userID, key, value, userCorrectionConfidence, db.FactSourceUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save fact"})
+		return
+	}
+	h.invalidate(c.Request.Context(), userID, key)
+
+	c.JSON(http.StatusOK, factToResponse(fact))
+}
+
+// DeleteFact makes the assistant forget a single fact.
+func (h *MemoryHandler) DeleteFact(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	key := c.Param("key")
+
+	err := h.db.DeleteUserFact(c.Request.Context(), userID, key)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fact not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fact"})
+		return
+	}
+	h.invalidate(c.Request.Context(), userID, key)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fact deleted"})
+}
+
+// WipeMemory makes the assistant forget everything it learned about the
+// user: facts, long-term memories and recent chat context. Conversations
+// stay visible in the user's history.
+func (h *MemoryHandler) WipeMemory(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	if err := h.db.WipeUserMemory(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to wipe memory"})
+		return
+	}
+	h.invalidate(c.Request.Context(), userID, "")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Memory wiped"})
+}
+
+// RedirectFacts sends requests to the original facts routes on to the
+// memory routes that replaced them, keeping the method and body.
+// GET /api/users/me/facts, PUT /api/users/me/facts/:key
+func (h *MemoryHandler) RedirectFacts(c *gin.Context) {
+	base, _, _ := strings.Cut(c.Request.URL.Path, "/facts")
+	target := base + "/memory"
+	if key := c.Param("key"); key != "" {
+		target += "/facts/" + url.PathEscape(key)
+	}
+	if c.Request.URL.RawQuery != "" {
+		target += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusPermanentRedirect, target)
+}
+
+// invalidate drops cached copies of a fact (or, for an empty key, all of
+// the user's memory) and the welcome messages personalized from them.
+func (h *MemoryHandler) invalidate(ctx context.Context, userID, key string) {
+	if h.cache != nil {
+		if key == "" {
+			h.cache.Forget(userID)
+		} else {
+			h.cache.RemoveFact(userID, key)
+		}
+	}
+	if err := h.db.DeleteWelcomeMessagesForUser(ctx, userID); err != nil {
+		log.Printf("Failed to invalidate welcome messages for user=%s: %v", userID, err)
+	}
+}
+
+func factToResponse(f *db.UserFact) FactResponse {
+	return FactResponse{
+		Key:        f.Key,
+		Value:      f.Value,
+		Confidence: f.Confidence,
+		Source:     f.Source,
+		UpdatedAt:  f.UpdatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMemoryCache struct {
+	removed   []string
+	forgotten []string
+}
+
+func (f *fakeMemoryCache) RemoveFact(userID, key string) {
+	f.removed = append(f.removed, userID+"/"+key)
+}
+func (f *fakeMemoryCache) Forget(userID string) { f.forgotten = append(f.forgotten, userID) }
+
+func TestCorrectFact_StoresUserValueWithFullConfidence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO user_facts`).
+		WithArgs(userID, "allergies", "penicillin, latex", 1.0, "user").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "key", "value", "confidence", "source", "created_at", "updated_at"}).
+			AddRow("fact-1", userID, "allergies", "penicillin, latex", 1.0, "user", now, now))
+	mock.ExpectExec(`DELETE FROM user_welcome_messages`).
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cache := &fakeMemoryCache{}
+	r := ginWithUserID(userID)
+	r.PUT("/facts/:key", NewMemoryHandler(database, cache).CorrectFact)
+
+	req, err := jsonRequest(http.MethodPut, "/facts/allergies", map[string]any{"value": "penicillin,  latex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"source":"user"`) {
+		t.Errorf("expected user source, body: %s", w.Body.String())
+	}
+	if len(cache.removed) != 1 || cache.removed[0] != userID+"/allergies" {
+		t.Errorf("cached fact not invalidated: %v", cache.removed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCorrectFact_RejectsInvalidValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	r := ginWithUserID("user-1")
+	r.PUT("/facts/:key", NewMemoryHandler(database, nil).CorrectFact)
+
+	tests := []struct {
+		path, value string
+		want        int
+	}{
+		{"/facts/due_date", "soon", http.StatusBadRequest},
+		{"/facts/pregnancy_week", "50", http.StatusBadRequest},
+		{"/facts/shoe_size", "38", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, err := jsonRequest(http.MethodPut, tt.path, map[string]any{"value": tt.value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s = %d, want %d (body: %s)", tt.path, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteFact_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectExec(`DELETE FROM user_facts`).
+		WithArgs("user-1", "diet").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := ginWithUserID("user-1")
+	r.DELETE("/memory/facts/:key", NewMemoryHandler(database, nil).DeleteFact)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/memory/facts/diet", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWipeMemory_ForgetsEverything(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM user_facts`).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`DELETE FROM memory_embeddings`).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectExec(`UPDATE users SET memory_reset_at`).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`DELETE FROM user_welcome_messages`).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cache := &fakeMemoryCache{}
+	r := ginWithUserID(userID)
+	r.DELETE("/memory", NewMemoryHandler(database, cache).WipeMemory)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/memory", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if len(cache.forgotten) != 1 || cache.forgotten[0] != userID {
+		t.Errorf("cache not invalidated: %v", cache.forgotten)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedirectFacts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewMemoryHandler(nil, nil)
+	r := gin.New()
+	r.GET("/api/users/me/facts", h.RedirectFacts)
+	r.PUT("/api/users/me/facts/:key", h.RedirectFacts)
+
+	tests := []struct {
+		method, target, want string
+	}{
+		{http.MethodGet, "/api/users/me/facts", "/api/users/me/memory"},
+		{http.MethodPut, "/api/users/me/facts/allergies", "/api/users/me/memory/facts/allergies"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != tt.want {
+			t.Errorf("%s %s = %d to %q, want 308 to %q", tt.method, tt.target, w.Code, w.Header().Get("Location"), tt.want)
+		}
+	}
+}
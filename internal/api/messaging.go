@@ -0,0 +1,419 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
+	"github.com/themobileprof/momlaunchpad-be/internal/chat"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/privacy"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
+	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
+)
+
+// MessagingIdleTimeout is how long an SMS or WhatsApp conversation carries on
+// without messages; the next message after that starts a new conversation
+const MessagingIdleTimeout = 24 * time.Hour
+
+// MessagingWelcomeInterval is how often a number that isn't linked to an
+// account is sent the welcome message; other messages from it get no reply
+const MessagingWelcomeInterval = 7 * 24 * time.Hour
+
+// Longest message we send in one piece, in characters. SMS allows three
+// concatenated GSM-7 parts so feature phones reassemble it reliably.
+const (
+	smsSegmentLimit      = 459
+	whatsAppSegmentLimit = 4096
+)
+
+// messagingReplyTimeout bounds the chat turn run after the webhook returns
+const messagingReplyTimeout = 2 * time.Minute
+
+// Replies run on a fixed number of workers, one at a time per number so each
+// sees the session the previous one saved. Messages beyond the queue limit
+// are dropped.
+const (
+	messagingWorkers    = 8
+	messagingQueueLimit = 500
+)
+
+// MessageSender sends replies (implemented by *twilio.MessagingClient)
+type MessageSender interface {
+	SendSMS(ctx context.Context, to, body string) (string, error)
+	SendWhatsApp(ctx context.Context, to, body string) (string, error)
+}
+
+// MessagingHandler handles inbound SMS and WhatsApp messages (Twilio webhooks)
+// and replies through the chat engine
+type MessagingHandler struct {
+	chatEngine  *chat.Engine
+	db          *db.DB
+	subManager  *subscription.Manager
+	suggestions *calendar.Resolver
+	sender      MessageSender
+	now         func() time.Time
+	dispatch    func(key string, job func()) bool // Queues the reply to run after the webhook returns
+}
+
+// NewMessagingHandler creates a new messaging handler
+func NewMessagingHandler(chatEngine *chat.Engine, database *db.DB, subMgr *subscription.Manager, sender MessageSender) *MessagingHandler {
+	return &MessagingHandler{
+		chatEngine:  chatEngine,
+		db:          database,
+		subManager:  subMgr,
+		suggestions: calendar.NewResolver(database),
+		sender:      sender,
+		now:         time.Now,
+		dispatch:    newReplyQueue(messagingWorkers, messagingQueueLimit).Add,
+	}
+}
+
+// HandleIncoming acknowledges an inbound message at once and replies with
+// separate outbound messages, since a chat turn can outlast Twilio's webhook
+// timeout
+// POST /api/messaging/incoming
+func (h *MessagingHandler) HandleIncoming(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		log.Printf("Failed to parse form: %v", err)
+		c.String(http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	params := twilio.ParseIncomingMessage(c.Request.Form)
+	channel, phone := messagingAddress(params.From)
+	text := strings.TrimSpace(params.Body)
+	log.Printf("Incoming %s message: MessageSid=%s, From=%s", channel, params.MessageSid, privacy.MaskPhone(phone))
+
+	if text != "" {
+		ctx := context.WithoutCancel(c.Request.Context())
+		queued := h.dispatch(channel+":"+phone, func() {
+			ctx, cancel := context.WithTimeout(ctx, messagingReplyTimeout)
+			defer cancel()
+			h.reply(ctx, channel, phone, text)
+		})
+		if !queued {
+			log.Printf("Dropped %s message %s: reply queue is full", channel, params.MessageSid)
+		}
+	}
+
+	c.Header("Content-Type", "application/xml")
+	c.String(http.StatusOK, twilio.NewTwiMLResponse().String())
+}
+
+// reply runs one inbound message through the user's conversation
+func (h *MessagingHandler) reply(ctx context.Context, channel, phone, text string) {
+	user, err := h.db.GetUserByPhone(ctx, phone)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("Failed to look up %s sender %s: %v", channel, privacy.MaskPhone(phone), err)
+			return
+		}
+		claimed, err := h.db.ClaimMessagingWelcome(ctx, channel, phone, h.now(), MessagingWelcomeInterval)
+		if err != nil {
+			log.Printf("Failed to record %s welcome for %s: %v", channel, privacy.MaskPhone(phone), err)
+			return
+		}
+		if claimed {
+			h.send(ctx, channel, phone, messagingText("unregistered", "en"))
+		}
+		return
+	}
+
+	session, err := h.db.GetMessagingSession(ctx, channel, phone)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		log.Printf("Failed to load %s session for %s: %v", channel, privacy.MaskPhone(phone), err)
+		return
+	}
+	if session == nil || session.UserID != user.ID || h.now().Sub(session.UpdatedAt) > MessagingIdleTimeout {
+		session = &db.MessagingSession{Channel: channel, Phone: phone, UserID: user.ID}
+	}
+
+	// A YES or NO right after a calendar suggestion answers it
+	if pending := session.PendingSuggestionID; pending != "" {
+		session.PendingSuggestionID = ""
+		if answer := suggestionAnswer(text); answer != "" {
+			h.answerSuggestion(ctx, session, user, pending, answer)
+			h.saveSession(ctx, session)
+			return
+		}
+	}
+
+	reservation, err := h.subManager.Reserve(ctx, user.ID, "chat")
+	if err != nil {
+		if !errors.Is(err, subscription.ErrQuotaExceeded) && !errors.Is(err, subscription.ErrNoAccess) {
+			log.Printf("Failed to reserve quota for user %s: %v", user.ID, err)
+		}
+		h.saveSession(ctx, session)
+		h.send(ctx, channel, phone, messagingText("quota", user.Language))
+		return
+	}
+
+	responder := NewMessagingResponder(session, user.Language)
+	req := chat.ProcessRequest{
+		UserID:         user.ID,
+		ConversationID: session.ConversationID,
+		Message:        text,
+		Language:       user.Language,
+		Responder:      responder,
+		Timezone:       user.Timezone,
+	}
+	if user.CountryCode != nil {
+		req.CountryCode = *user.CountryCode
+	}
+
+	if _, err := h.chatEngine.ProcessMessage(ctx, req); err != nil {
+		log.Printf("Failed to process %s message: %v", channel, err)
+		if err := reservation.Release(ctx); err != nil {
+			log.Printf("Failed to release quota for user %s: %v", user.ID, err)
+		}
+		h.saveSession(ctx, session)
+		h.send(ctx, channel, phone, messagingText("error", user.Language))
+		return
+	}
+	if err := reservation.Commit(ctx); err != nil {
+		log.Printf("Failed to commit quota for user %s: %v", user.ID, err)
+	}
+
+	h.saveSession(ctx, session)
+	h.send(ctx, channel, phone, responder.GetResponse())
+}
+
+// answerSuggestion accepts or dismisses the suggestion the user was offered
+func (h *MessagingHandler) answerSuggestion(ctx context.Context, session *db.MessagingSession, user *db.User, suggestionID, answer string) {
+	var err error
+	var reply string
+	if answer == "yes" {
+		var reminder *db.Reminder
+		reminder, err = h.suggestions.Accept(ctx, user.ID, suggestionID, calendar.Edit{})
+		if err == nil {
+			reply = fmt.Sprintf(messagingText("reminder_added", user.Language),
+				reminder.Title, formatSuggestionTime(reminder.ReminderTime, reminder.Timezone))
+		}
+	} else {
+		err = h.suggestions.Dismiss(ctx, user.ID, suggestionID)
+		reply = messagingText("reminder_dismissed", user.Language)
+	}
+
+	switch {
+	case errors.Is(err, db.ErrNotFound), errors.Is(err, db.ErrSuggestionResolved):
+		reply = messagingText("suggestion_gone", user.Language)
+	case err != nil:
+		log.Printf("Failed to answer suggestion %s: %v", suggestionID, err)
+		reply = messagingText("error", user.Language)
+	}
+	h.send(ctx, session.Channel, session.Phone, reply)
+}
+
+func (h *MessagingHandler) saveSession(ctx context.Context, session *db.MessagingSession) {
+	if err := h.db.SaveMessagingSession(ctx, session); err != nil {
+		log.Printf("Failed to save %s session for %s: %v", session.Channel, privacy.MaskPhone(session.Phone), err)
+	}
+}
+
+// send delivers text in as many messages as the channel needs
+func (h *MessagingHandler) send(ctx context.Context, channel, phone, text string) {
+	limit := smsSegmentLimit
+	if channel == db.MessagingWhatsApp {
+		limit = whatsAppSegmentLimit
+	}
+
+	for _, segment := range splitMessage(text, limit) {
+		var err error
+		if channel == db.MessagingWhatsApp {
+			_, err = h.sender.SendWhatsApp(ctx, phone, segment)
+		} else {
+			_, err = h.sender.SendSMS(ctx, phone, segment)
+		}
+		if err != nil {
+			log.Printf("Failed to send %s reply to %s: %v", channel, privacy.MaskPhone(phone), err)
+			return
+		}
+	}
+}
+
+// messagingAddress splits a Twilio From address into channel and E.164 number
+func messagingAddress(from string) (channel, phone string) {
+	if strings.HasPrefix(from, twilio.WhatsAppPrefix) {
+		return db.MessagingWhatsApp, strings.TrimPrefix(from, twilio.WhatsAppPrefix)
+	}
+	return db.MessagingSMS, from
+}
+
+// suggestionAnswer reads a reply to a calendar suggestion as "yes", "no" or
+// "" for anything else
+func suggestionAnswer(text string) string {
+	switch strings.ToLower(strings.Trim(text, " .!")) {
+	case "yes", "y", "si", "sí":
+		return "yes"
+	case "no", "n":
+		return "no"
+	}
+	return ""
+}
+
+// splitMessage breaks text into parts of at most limit characters, preferring
+// paragraph, sentence and word boundaries, and numbers the parts
+func splitMessage(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return []string{text}
+	}
+
+	const suffixLen = 8 // " (12/15)"
+	size := limit - suffixLen
+	var parts []string
+	for len(runes) > 0 {
+		if len(runes) <= size {
+			parts = append(parts, strings.TrimSpace(string(runes)))
+			break
+		}
+		cut := splitPoint(runes[:size])
+		parts = append(parts, strings.TrimSpace(string(runes[:cut])))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " \n"))
+	}
+
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%s (%d/%d)", parts[i], i+1, len(parts))
+	}
+	return parts
+}
+
+// splitPoint returns where to end a part taken from chunk: after the last
+// paragraph, sentence or word break in its second half, else at its end
+func splitPoint(chunk []rune) int {
+	s := string(chunk)
+	half := len(s) / 2
+	for _, sep := range []string{"\n\n", "\n", ". ", "! ", "? ", " "} {
+		if i := strings.LastIndex(s, sep); i >= half {
+			return len([]rune(s[:i+len(sep)]))
+		}
+	}
+	return len(chunk)
+}
+
+func formatSuggestionTime(t time.Time, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("Mon 2 Jan, 15:04")
+}
+
+// MessagingResponder implements chat.Responder for SMS and WhatsApp
+type MessagingResponder struct {
+	session  *db.MessagingSession
+	language string
+	response strings.Builder
+	mu       sync.Mutex
+}
+
+// NewMessagingResponder creates a new messaging responder
+func NewMessagingResponder(session *db.MessagingSession, language string) *MessagingResponder {
+	return &MessagingResponder{
+		session:  session,
+		language: language,
+	}
+}
+
+// SetConversationID updates the session with the conversation ID
+func (r *MessagingResponder) SetConversationID(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.ConversationID = id
+}
+
+// SendMessage accumulates the reply
+func (r *MessagingResponder) SendMessage(content string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.response.WriteString(content)
+	return nil
+}
+
+// SendDelta accumulates streamed chunks; the reply is sent once complete
+func (r *MessagingResponder) SendDelta(content string) error {
+	return r.SendMessage(content)
+}
+
+// SendCalendarSuggestion offers the suggestion as text; a YES reply accepts it
+func (r *MessagingResponder) SendCalendarSuggestion(suggestion calendar.Suggestion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if suggestion.ID == "" {
+		return nil // Not stored, so it can't be accepted
+	}
+	r.session.PendingSuggestionID = suggestion.ID
+	r.response.WriteString("\n\n")
+	r.response.WriteString(fmt.Sprintf(messagingText("suggestion", r.language),
+		suggestion.Title, formatSuggestionTime(suggestion.SuggestedTime, suggestion.Timezone)))
+	return nil
+}
+
+// SendEmergency is a no-op for messaging: the urgent-care message, including
+// the emergency number, follows via SendMessage.
+func (r *MessagingResponder) SendEmergency(alert redflag.Alert) error {
+	return nil
+}
+
+// SendError sends error message
+func (r *MessagingResponder) SendError(message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.response.WriteString(message)
+	return nil
+}
+
+// SendDone signals completion
+func (r *MessagingResponder) SendDone() error {
+	return nil
+}
+
+// SendTitleUpdated is a no-op for messaging sessions.
+func (r *MessagingResponder) SendTitleUpdated(title string) error {
+	return nil
+}
+
+// GetResponse returns the accumulated reply
+func (r *MessagingResponder) GetResponse() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.response.String()
+}
+
+var messagingTexts = map[string]map[string]string{
+	"en": {
+		"unregistered":       "Welcome to MomLaunchpad. Verify this number in the app under Settings > Phone number to chat with us by text.",
+		"quota":              "You've reached your question limit for this period. Please upgrade your plan in the app or try again later.",
+		"error":              "Sorry, something went wrong. Please try again.",
+		"suggestion":         "Reply YES to add a reminder: %s, %s.",
+		"reminder_added":     "Reminder added: %s, %s.",
+		"reminder_dismissed": "OK, no reminder.",
+		"suggestion_gone":    "That suggestion was already answered in the app.",
+	},
+	"es": {
+		"unregistered":       "Bienvenida a MomLaunchpad. Verifica este número en la aplicación en Ajustes > Número de teléfono para chatear por mensaje.",
+		"quota":              "Has alcanzado tu límite de preguntas para este período. Mejora tu plan en la aplicación o inténtalo más tarde.",
+		"error":              "Lo siento, algo salió mal. Inténtalo de nuevo.",
+		"suggestion":         "Responde SÍ para agregar un recordatorio: %s, %s.",
+		"reminder_added":     "Recordatorio agregado: %s, %s.",
+		"reminder_dismissed": "De acuerdo, sin recordatorio.",
+		"suggestion_gone":    "Esa sugerencia ya fue respondida en la aplicación.",
+	},
+}
+
+func messagingText(key, language string) string {
+	if texts, ok := messagingTexts[language]; ok {
+		return texts[key]
+	}
+	return messagingTexts["en"][key]
+}
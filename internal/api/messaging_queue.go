@@ -0,0 +1,67 @@
+package api
+
+import "sync"
+
+// replyQueue runs jobs on a fixed number of workers, one at a time per key,
+// in the order they were added. It holds at most limit jobs; Add refuses more.
+type replyQueue struct {
+	mu      sync.Mutex
+	pending map[string][]func() // Present while a key has a job queued or running
+	ready   chan string         // Keys waiting for a worker
+	size    int
+	limit   int
+}
+
+// newReplyQueue creates a queue and starts its workers
+func newReplyQueue(workers, limit int) *replyQueue {
+	q := &replyQueue{
+		pending: make(map[string][]func()),
+		ready:   make(chan string, limit),
+		limit:   limit,
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Add queues job behind any others for key. It returns false if the queue is full.
+func (q *replyQueue) Add(key string, job func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size >= q.limit {
+		return false
+	}
+	q.size++
+	jobs, active := q.pending[key]
+	q.pending[key] = append(jobs, job)
+	if !active {
+		// Never blocks: there are no more active keys than queued jobs
+		q.ready <- key
+	}
+	return true
+}
+
+// work takes a key and runs its jobs until none are left
+func (q *replyQueue) work() {
+	for key := range q.ready {
+		for {
+			q.mu.Lock()
+			jobs := q.pending[key]
+			if len(jobs) == 0 {
+				delete(q.pending, key)
+				q.mu.Unlock()
+				break
+			}
+			job := jobs[0]
+			q.pending[key] = jobs[1:]
+			q.mu.Unlock()
+
+			job()
+
+			q.mu.Lock()
+			q.size--
+			q.mu.Unlock()
+		}
+	}
+}
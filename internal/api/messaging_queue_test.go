@@ -0,0 +1,89 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReplyQueue_SerializesPerKey(t *testing.T) {
+	q := newReplyQueue(4, 100)
+
+	var mu sync.Mutex
+	var order []int
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		i := i
+		wg.Add(1)
+		if !q.Add("sms:+2348012345678", func() {
+			defer wg.Done()
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			order = append(order, i)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}) {
+			t.Fatalf("Add(%d) refused", i)
+		}
+	}
+	wg.Wait()
+
+	if maxRunning != 1 {
+		t.Errorf("%d jobs ran at once for one number, want 1", maxRunning)
+	}
+	for i, got := range order {
+		if got != i {
+			t.Fatalf("order = %v, want the order added", order)
+		}
+	}
+}
+
+func TestReplyQueue_BoundsWorkersAndSize(t *testing.T) {
+	q := newReplyQueue(2, 3)
+
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c"} {
+		key := key
+		wg.Add(1)
+		if !q.Add(key, func() {
+			defer wg.Done()
+			started <- key
+			<-release
+		}) {
+			t.Fatalf("Add(%s) refused", key)
+		}
+	}
+
+	<-started
+	<-started
+	select {
+	case key := <-started:
+		t.Errorf("job %s started with both workers busy", key)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if q.Add("d", func() {}) {
+		t.Error("Add() accepted a job beyond the limit")
+	}
+
+	close(release)
+	wg.Wait()
+	// Jobs leave the queue just after they return
+	deadline := time.Now().Add(time.Second)
+	for !q.Add("d", func() {}) {
+		if time.Now().After(deadline) {
+			t.Fatal("Add() refused a job after the queue drained")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+type sentMessage struct {
+	channel, to, body string
+}
+
+type fakeMessageSender struct {
+	sent []sentMessage
+}
+
+func (f *fakeMessageSender) SendSMS(_ context.Context, to, body string) (string, error) {
+	f.sent = append(f.sent, sentMessage{db.MessagingSMS, to, body})
+	return "SM1", nil
+}
+
+func (f *fakeMessageSender) SendWhatsApp(_ context.Context, to, body string) (string, error) {
+	f.sent = append(f.sent, sentMessage{db.MessagingWhatsApp, to, body})
+	return "SM1", nil
+}
+
+func newTestMessagingHandler(database *db.DB, sender MessageSender) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewMessagingHandler(nil, database, nil, sender)
+	h.dispatch = func(_ string, job func()) bool {
+		job()
+		return true
+	}
+
+	r := gin.New()
+	r.POST("/api/messaging/incoming", h.HandleIncoming)
+	return r
+}
+
+func TestMessagingIncoming_UnregisteredNumber(t *testing.T) {
+	database, mock := newMockDB(t)
+	sender := &fakeMessageSender{}
+
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`INSERT INTO messaging_welcomes`).
+		WithArgs(db.MessagingWhatsApp, "+2348012345678", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sent_at"}).AddRow(time.Now()))
+
+	w := httptest.NewRecorder()
+	newTestMessagingHandler(database, sender).ServeHTTP(w, voiceForm("/api/messaging/incoming",
+		url.Values{"MessageSid": {"SM9"}, "From": {"whatsapp:+2348012345678"}, "Body": {"hello"}}))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<Response></Response>") {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if len(sender.sent) != 1 || sender.sent[0].channel != db.MessagingWhatsApp ||
+		sender.sent[0].to != "+2348012345678" || !strings.Contains(sender.sent[0].body, "Welcome to MomLaunchpad") {
+		t.Errorf("sent = %+v", sender.sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMessagingIncoming_UnregisteredNumberAlreadyWelcomed(t *testing.T) {
+	database, mock := newMockDB(t)
+	sender := &fakeMessageSender{}
+
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`INSERT INTO messaging_welcomes`).
+		WithArgs(db.MessagingSMS, "+2348012345678", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	newTestMessagingHandler(database, sender).ServeHTTP(w, voiceForm("/api/messaging/incoming",
+		url.Values{"From": {"+2348012345678"}, "Body": {"hello again"}}))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d", w.Code)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("sent = %+v, want no reply within the welcome interval", sender.sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMessagingIncoming_DeclinesSuggestion(t *testing.T) {
+	database, mock := newMockDB(t)
+	sender := &fakeMessageSender{}
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnRows(mockUserRows(userID, "ada@example.com"))
+	mock.ExpectQuery(`FROM messaging_sessions`).
+		WithArgs(db.MessagingSMS, "+2348012345678").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "conversation_id", "pending_suggestion_id", "updated_at"}).
+			AddRow(userID, "conv-1", "sugg-1", time.Now().Add(-time.Hour)))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM calendar_suggestions`).
+		WithArgs("sugg-1", userID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(db.SuggestionPending))
+	mock.ExpectExec(`UPDATE calendar_suggestions SET status`).
+		WithArgs(db.SuggestionDismissed, "sugg-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`INSERT INTO messaging_sessions`).
+		WithArgs(db.MessagingSMS, "+2348012345678", userID, "conv-1", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	w := httptest.NewRecorder()
+	newTestMessagingHandler(database, sender).ServeHTTP(w, voiceForm("/api/messaging/incoming",
+		url.Values{"From": {"+2348012345678"}, "Body": {" No. "}}))
+
+	if len(sender.sent) != 1 || sender.sent[0].body != "OK, no reminder." {
+		t.Errorf("sent = %+v", sender.sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMessagingResponder_CalendarSuggestion(t *testing.T) {
+	session := &db.MessagingSession{}
+	r := NewMessagingResponder(session, "en")
+
+	_ = r.SendMessage("Book your next antenatal visit soon.")
+	_ = r.SendCalendarSuggestion(calendar.Suggestion{
+		ID:            "sugg-1",
+		Title:         "Antenatal visit",
+		SuggestedTime: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		Timezone:      "Africa/Lagos",
+	})
+
+	want := "Book your next antenatal visit soon.\n\nReply YES to add a reminder: Antenatal visit, Mon 2 Mar, 09:00."
+	if got := r.GetResponse(); got != want {
+		t.Errorf("response = %q, want %q", got, want)
+	}
+	if session.PendingSuggestionID != "sugg-1" {
+		t.Errorf("pending suggestion = %q", session.PendingSuggestionID)
+	}
+}
+
+func TestSuggestionAnswer(t *testing.T) {
+	for text, want := range map[string]string{
+		"YES": "yes", "Sí!": "yes", "y": "yes", "no.": "no", "N": "no",
+		"yes please remind me": "", "": "",
+	} {
+		if got := suggestionAnswer(text); got != want {
+			t.Errorf("suggestionAnswer(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	if got := splitMessage("  Short reply. ", 160); len(got) != 1 || got[0] != "Short reply." {
+		t.Errorf("short message = %q", got)
+	}
+
+	sentence := "Drink plenty of water and rest when you can. "
+	text := strings.Repeat(sentence, 12)
+	parts := splitMessage(text, 160)
+	if len(parts) < 4 {
+		t.Fatalf("got %d parts", len(parts))
+	}
+
+	var rebuilt []string
+	for i, part := range parts {
+		if n := len([]rune(part)); n > 160 {
+			t.Errorf("part %d has %d characters", i+1, n)
+		}
+		suffix := " (" + string(rune('1'+i)) + "/" + string(rune('0'+len(parts))) + ")"
+		if !strings.HasSuffix(part, suffix) {
+			t.Errorf("part %d = %q, want suffix %q", i+1, part, suffix)
+		}
+		body := strings.TrimSuffix(part, suffix)
+		if !strings.HasSuffix(body, ".") {
+			t.Errorf("part %d not split at a sentence: %q", i+1, body)
+		}
+		rebuilt = append(rebuilt, body)
+	}
+	if strings.Join(rebuilt, " ") != strings.TrimSpace(text) {
+		t.Error("parts do not add up to the message")
+	}
+}
@@ -2,17 +2,21 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 )
 
 type FeatureChecker interface {
 	HasFeature(ctx context.Context, userID string, featureKey string) (bool, error)
 }
 
-type QuotaChecker interface {
-	CheckQuota(ctx context.Context, userID string, featureCode string) (bool, error)
+// QuotaReserver takes a unit of quota that is committed or released once the request completes.
+type QuotaReserver interface {
+	Reserve(ctx context.Context, userID string, featureCode string) (*subscription.Reservation, error)
 }
 
 func RequireFeature(checker FeatureChecker, featureKey string) gin.HandlerFunc {
@@ -38,15 +42,17 @@ func RequireFeature(checker FeatureChecker, featureKey string) gin.HandlerFunc {
 			return
 		}
 		if !allowed {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "feature not available"})
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "feature not available", "feature": featureKey})
 			return
 		}
 		c.Next()
 	}
 }
 
-// CheckQuota validates user quota for a feature (pure function, deterministic)
-func CheckQuota(checker QuotaChecker, featureCode string) gin.HandlerFunc {
+// CheckQuota reserves one unit of a feature's quota for the request. The
+// reservation is committed when the handler succeeds and released when it
+// responds with an error, so failed requests are not charged.
+func CheckQuota(reserver QuotaReserver, featureCode string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract userID from context (set by JWT middleware upstream)
 		userIDVal, ok := c.Get("user_id")
@@ -61,18 +67,30 @@ func CheckQuota(checker QuotaChecker, featureCode string) gin.HandlerFunc {
 			return
 		}
 
-		// Deterministic quota check (backend logic, not AI)
-		withinQuota, err := checker.CheckQuota(c.Request.Context(), userID, featureCode)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "quota check failed"})
+		// Deterministic quota reservation (backend logic, not AI)
+		reservation, err := reserver.Reserve(c.Request.Context(), userID, featureCode)
+		switch {
+		case errors.Is(err, subscription.ErrNoAccess):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "feature not available"})
 			return
-		}
-
-		if !withinQuota {
+		case errors.Is(err, subscription.ErrQuotaExceeded):
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "quota exceeded"})
 			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "quota check failed"})
+			return
 		}
 
 		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			if err := reservation.Release(context.WithoutCancel(c.Request.Context())); err != nil {
+				log.Printf("Failed to release %s quota for user %s: %v", featureCode, userID, err)
+			}
+			return
+		}
+		if err := reservation.Commit(context.WithoutCancel(c.Request.Context())); err != nil {
+			log.Printf("Failed to commit %s quota for user %s: %v", featureCode, userID, err)
+		}
 	}
 }
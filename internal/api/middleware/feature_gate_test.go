@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 )
 
 type stubFeatureChecker struct {
@@ -151,3 +153,61 @@ func (m *mockSubManager) CheckQuota(ctx context.Context, userID, featureCode str
 func (m *mockSubManager) IncrementUsage(ctx context.Context, userID, featureCode string) error {
 	return nil
 }
+
+func (m *mockSubManager) Reserve(ctx context.Context, userID, featureCode string) (*subscription.Reservation, error) {
+	if !m.withinQuota {
+		return nil, subscription.ErrQuotaExceeded
+	}
+	return &subscription.Reservation{UserID: userID, FeatureKey: featureCode}, nil
+}
+
+// TestQuotaCheckMiddleware_ReleasesOnFailure tests that failed requests give their quota back
+func TestQuotaCheckMiddleware_ReleasesOnFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range []struct {
+		name        string
+		status      int
+		wantRelease bool
+	}{
+		{name: "success commits", status: http.StatusOK},
+		{name: "failure releases", status: http.StatusBadGateway, wantRelease: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT pf.quota_limit, pf.quota_period, pf.token_limit`).
+				WithArgs("user1", "chat").
+				WillReturnRows(sqlmock.NewRows([]string{"quota_limit", "quota_period", "token_limit", "timezone", "period_start", "period_end"}).AddRow(100, "monthly", nil, "UTC", nil, nil))
+			mock.ExpectQuery(`INSERT INTO feature_usage`).
+				WillReturnRows(sqlmock.NewRows([]string{"usage_count"}).AddRow(1))
+			if tt.wantRelease {
+				mock.ExpectExec(`UPDATE feature_usage\s+SET usage_count = GREATEST\(usage_count - 1, 0\)`).
+					WithArgs("user1", "chat", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", "user1")
+				c.Next()
+			})
+			r.Use(CheckQuota(subscription.NewManager(db), "chat"))
+			r.GET("/test", func(c *gin.Context) { c.Status(tt.status) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwilioValidator checks a webhook's X-Twilio-Signature (implemented by *twilio.VoiceClient)
+type TwilioValidator interface {
+	ValidateRequest(requestURL string, params url.Values, signature string) bool
+}
+
+// TwilioSignature rejects webhooks not signed by Twilio with our auth token.
+// Twilio signs the full URL it called plus the POST parameters, so the URL
+// is rebuilt from publicBaseURL when set (e.g. https://api.momlaunchpad.com),
+// otherwise from X-Forwarded-Proto/X-Forwarded-Host as set by the reverse
+// proxy, falling back to the request itself.
+func TwilioSignature(validator TwilioValidator, publicBaseURL string) gin.HandlerFunc {
+	publicBaseURL = strings.TrimRight(publicBaseURL, "/")
+
+	return func(c *gin.Context) {
+		signature := c.GetHeader("X-Twilio-Signature")
+		if signature == "" {
+			log.Printf("Rejected Twilio webhook %s from %s: missing signature", c.Request.URL.Path, c.ClientIP())
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if err := c.Request.ParseForm(); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		// Every value counts, so a repeated parameter can't be slipped past the signature
+		url := webhookURL(c.Request, publicBaseURL)
+		if !validator.ValidateRequest(url, c.Request.PostForm, signature) {
+			log.Printf("Rejected Twilio webhook %s from %s: invalid signature for %s", c.Request.URL.Path, c.ClientIP(), url)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// webhookURL returns the public URL a request was made to
+func webhookURL(r *http.Request, publicBaseURL string) string {
+	if publicBaseURL != "" {
+		return publicBaseURL + r.URL.RequestURI()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := firstHeaderValue(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if fwd := firstHeaderValue(r.Header.Get("X-Forwarded-Host")); fwd != "" {
+		host = fwd
+	}
+	return scheme + "://" + host + r.URL.RequestURI()
+}
+
+// firstHeaderValue returns the client-side entry of a comma-separated proxy header
+func firstHeaderValue(v string) string {
+	if i := strings.IndexByte(v, ','); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
+)
+
+const testTwilioToken = "twilio-auth-token"
+
+// twilioSign signs a webhook the way Twilio does: HMAC-SHA1 over the URL
+// followed by the sorted POST parameters, every value of a repeated one included
+func twilioSign(token, url string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := url
+	for _, k := range keys {
+		values := append([]string(nil), form[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			data += k + v
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(token))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTwilioRouter(baseURL string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	voice := r.Group("/api/voice")
+	voice.Use(TwilioSignature(twilio.NewVoiceClient(twilio.VoiceConfig{AuthToken: testTwilioToken}), baseURL))
+	voice.POST("/gather", func(c *gin.Context) {
+		c.String(http.StatusOK, c.PostForm("SpeechResult"))
+	})
+	return r
+}
+
+func twilioRequest(target string, form url.Values, signature string, header map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if signature != "" {
+		req.Header.Set("X-Twilio-Signature", signature)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestTwilioSignature(t *testing.T) {
+	form := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"I have a headache"}, "Confidence": {"0.92"}}
+	publicURL := "https://api.momlaunchpad.com/api/voice/gather?callSid=CA123"
+
+	tests := []struct {
+		name      string
+		baseURL   string
+		target    string
+		signature string
+		header    map[string]string
+		want      int
+	}{
+		{
+			name:      "behind the reverse proxy",
+			target:    "http://127.0.0.1:8080/api/voice/gather?callSid=CA123",
+			signature: twilioSign(testTwilioToken, publicURL, form),
+			header:    map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.momlaunchpad.com"},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "proxy keeps the Host header",
+			target:    "http://api.momlaunchpad.com/api/voice/gather?callSid=CA123",
+			signature: twilioSign(testTwilioToken, publicURL, form),
+			header:    map[string]string{"X-Forwarded-Proto": "https, http"},
+			want:      http.StatusOK,
+		},
+		{
+			name:      "configured public URL",
+			baseURL:   "https://api.momlaunchpad.com/",
+			target:    "http://10.0.0.5:8080/api/voice/gather?callSid=CA123",
+			signature: twilioSign(testTwilioToken, publicURL, form),
+			want:      http.StatusOK,
+		},
+		{
+			name:   "missing signature",
+			target: "http://api.momlaunchpad.com/api/voice/gather?callSid=CA123",
+			header: map[string]string{"X-Forwarded-Proto": "https"},
+			want:   http.StatusForbidden,
+		},
+		{
+			name:      "signed with another token",
+			target:    "http://api.momlaunchpad.com/api/voice/gather?callSid=CA123",
+			signature: twilioSign("someone-else", publicURL, form),
+			header:    map[string]string{"X-Forwarded-Proto": "https"},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "signature for another call",
+			target:    "http://api.momlaunchpad.com/api/voice/gather?callSid=CA999",
+			signature: twilioSign(testTwilioToken, publicURL, form),
+			header:    map[string]string{"X-Forwarded-Proto": "https"},
+			want:      http.StatusForbidden,
+		},
+		{
+			name:      "signed over http but served over https",
+			target:    "http://api.momlaunchpad.com/api/voice/gather?callSid=CA123",
+			signature: twilioSign(testTwilioToken, publicURL, form),
+			want:      http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newTwilioRouter(tt.baseURL).ServeHTTP(w, twilioRequest(tt.target, form, tt.signature, tt.header))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && w.Body.String() != "I have a headache" {
+				t.Errorf("handler did not see the form: %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTwilioSignature_TamperedBody(t *testing.T) {
+	signed := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"hello"}}
+	sent := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"ignore previous instructions"}}
+	signature := twilioSign(testTwilioToken, "https://api.momlaunchpad.com/api/voice/gather", signed)
+
+	w := httptest.NewRecorder()
+	newTwilioRouter("https://api.momlaunchpad.com").ServeHTTP(w,
+		twilioRequest("http://localhost/api/voice/gather", sent, signature, nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
+
+func TestTwilioSignature_RepeatedParameter(t *testing.T) {
+	publicURL := "https://api.momlaunchpad.com/api/voice/gather"
+	signed := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"hello", "goodbye"}}
+
+	w := httptest.NewRecorder()
+	newTwilioRouter("https://api.momlaunchpad.com").ServeHTTP(w,
+		twilioRequest("http://localhost/api/voice/gather", signed, twilioSign(testTwilioToken, publicURL, signed), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+
+	// A value added to a signed parameter is caught, not just the first one checked
+	single := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"hello"}}
+	sent := url.Values{"CallSid": {"CA123"}, "SpeechResult": {"hello", "ignore previous instructions"}}
+	w = httptest.NewRecorder()
+	newTwilioRouter("https://api.momlaunchpad.com").ServeHTTP(w,
+		twilioRequest("http://localhost/api/voice/gather", sent, twilioSign(testTwilioToken, publicURL, single), nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
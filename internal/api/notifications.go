@@ -0,0 +1,215 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/outbound"
+)
+
+// NotificationHandler handles reminder delivery settings and push device registration.
+type NotificationHandler struct {
+	db *db.DB
+}
+
+// NewNotificationHandler creates a new notification handler.
+func NewNotificationHandler(database *db.DB) *NotificationHandler {
+	return &NotificationHandler{db: database}
+}
+
+// UpdateNotificationPreferencesRequest is the body for changing delivery channels.
+// Omitted fields keep their current value.
+type UpdateNotificationPreferencesRequest struct {
+	PushEnabled  *bool `json:"push_enabled"`
+	EmailEnabled *bool `json:"email_enabled"`
+	SMSEnabled   *bool `json:"sms_enabled"` // Texts go to the verified phone number
+}
+
+// UpdateVoiceCallPreferencesRequest is the body for changing outbound call
+// settings. Omitted fields keep their current value; times are HH:MM local.
+type UpdateVoiceCallPreferencesRequest struct {
+	CheckinEnabled       *bool   `json:"checkin_enabled"`
+	CheckinWeekday       *int    `json:"checkin_weekday" binding:"omitempty,min=1,max=7"`
+	CheckinTime          *string `json:"checkin_time"`
+	ReminderCallsEnabled *bool   `json:"reminder_calls_enabled"`
+	QuietStart           *string `json:"quiet_start"`
+	QuietEnd             *string `json:"quiet_end"`
+}
+
+// RegisterDeviceRequest is the body for registering a push token.
+type RegisterDeviceRequest struct {
+	Provider string `json:"provider" binding:"required,oneof=fcm apns"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+// GetPreferences returns the user's reminder delivery channels.
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	prefs, err := h.db.GetNotificationPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes the user's reminder delivery channels.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err := h.db.GetNotificationPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	if req.PushEnabled != nil {
+		prefs.PushEnabled = *req.PushEnabled
+	}
+	if req.EmailEnabled != nil {
+		prefs.EmailEnabled = *req.EmailEnabled
+	}
+	if req.SMSEnabled != nil {
+		prefs.SMSEnabled = *req.SMSEnabled
+	}
+	if req.SMSEnabled != nil && *req.SMSEnabled {
+		_, err := h.db.GetUserPhone(c.Request.Context(), userID)
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A verified phone number is required to enable SMS reminders"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch phone number"})
+			return
+		}
+	}
+
+	if err := h.db.SaveNotificationPreferences(c.Request.Context(), prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// GetVoiceCallPreferences returns the user's check-in and reminder call settings.
+func (h *NotificationHandler) GetVoiceCallPreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	prefs, err := h.db.GetVoiceCallPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch voice call preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdateVoiceCallPreferences opts the user in or out of check-in and reminder
+// calls. Calls only go to a verified phone number, which is required to opt in.
+func (h *NotificationHandler) UpdateVoiceCallPreferences(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req UpdateVoiceCallPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, t := range []*string{req.CheckinTime, req.QuietStart, req.QuietEnd} {
+		if t != nil && !outbound.ValidClock(*t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "times must be HH:MM, e.g. 18:30"})
+			return
+		}
+	}
+
+	prefs, err := h.db.GetVoiceCallPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch voice call preferences"})
+		return
+	}
+
+	if req.CheckinEnabled != nil {
+		prefs.CheckinEnabled = *req.CheckinEnabled
+	}
+	if req.CheckinWeekday != nil {
+		prefs.CheckinWeekday = *req.CheckinWeekday
+	}
+	if req.CheckinTime != nil {
+		prefs.CheckinTime = *req.CheckinTime
+	}
+	if req.ReminderCallsEnabled != nil {
+		prefs.ReminderCallsEnabled = *req.ReminderCallsEnabled
+	}
+	if req.QuietStart != nil {
+		prefs.QuietStart = *req.QuietStart
+	}
+	if req.QuietEnd != nil {
+		prefs.QuietEnd = *req.QuietEnd
+	}
+
+	if prefs.CheckinEnabled || prefs.ReminderCallsEnabled {
+		_, err := h.db.GetUserPhone(c.Request.Context(), userID)
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A verified phone number is required to enable voice calls"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch phone number"})
+			return
+		}
+	}
+
+	if err := h.db.SaveVoiceCallPreferences(c.Request.Context(), prefs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save voice call preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// RegisterDevice registers a push token for the user's device.
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	device := &db.PushDevice{UserID: userID, Provider: req.Provider, Token: req.Token}
+	if err := h.db.SavePushDevice(c.Request.Context(), device); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, device)
+}
+
+// UnregisterDevice removes a push token, e.g. on sign-out.
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	err := h.db.DeletePushDevice(c.Request.Context(), userID, c.Param("token"))
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device unregistered"})
+}
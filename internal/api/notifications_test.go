@@ -0,0 +1,180 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdateNotificationPreferences_EnablesSMS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM notification_preferences`).
+		WithArgs(userID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348012345678", time.Now(), nil, 0, nil))
+	mock.ExpectQuery(`INSERT INTO notification_preferences`).
+		WithArgs(userID, true, false, true).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	r := ginWithUserID(userID)
+	r.PUT("/notifications", NewNotificationHandler(database).UpdatePreferences)
+
+	req, err := jsonRequest(http.MethodPut, "/notifications", map[string]any{
+		"email_enabled": false,
+		"sms_enabled":   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateNotificationPreferences_SMSNeedsVerifiedPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+
+	mock.ExpectQuery(`FROM notification_preferences`).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs("user-1").
+		WillReturnError(sql.ErrNoRows)
+
+	r := ginWithUserID("user-1")
+	r.PUT("/notifications", NewNotificationHandler(database).UpdatePreferences)
+
+	req, err := jsonRequest(http.MethodPut, "/notifications", map[string]any{"sms_enabled": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterDevice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO push_devices`).
+		WithArgs("user-1", "apns", "abc123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("dev-1", now, now))
+
+	r := ginWithUserID("user-1")
+	h := NewNotificationHandler(database)
+	r.POST("/devices", h.RegisterDevice)
+
+	req, _ := jsonRequest(http.MethodPost, "/devices", map[string]any{"provider": "apns", "token": "abc123"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	req, _ = jsonRequest(http.MethodPost, "/devices", map[string]any{"provider": "webpush", "token": "abc123"})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown provider status = %d, want 400", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateVoiceCallPreferences_OptsIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM voice_call_preferences`).
+		WithArgs(userID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348012345678", time.Now(), nil, 0, nil))
+	mock.ExpectQuery(`INSERT INTO voice_call_preferences`).
+		WithArgs(userID, true, 3, "18:30", false, "21:00", "08:00").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	r := ginWithUserID(userID)
+	r.PUT("/voice-calls", NewNotificationHandler(database).UpdateVoiceCallPreferences)
+
+	req, err := jsonRequest(http.MethodPut, "/voice-calls", map[string]any{
+		"checkin_enabled": true,
+		"checkin_weekday": 3,
+		"checkin_time":    "18:30",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdateVoiceCallPreferences_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"weekday out of range", map[string]any{"checkin_weekday": 8}},
+		{"bad time", map[string]any{"quiet_start": "9pm"}},
+		{"no verified phone", map[string]any{"reminder_calls_enabled": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, mock := newMockDB(t)
+			mock.ExpectQuery(`FROM voice_call_preferences`).WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery(`FROM user_phones`).WillReturnError(sql.ErrNoRows)
+
+			r := ginWithUserID("user-1")
+			r.PUT("/voice-calls", NewNotificationHandler(database).UpdateVoiceCallPreferences)
+
+			req, err := jsonRequest(http.MethodPut, "/voice-calls", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400, body: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/phone"
+)
+
+// PhoneHandler handles verifying the phone number used for voice calls,
+// SMS and WhatsApp, and the optional voice PIN
+type PhoneHandler struct {
+	db    *db.DB
+	phone *phone.Service
+}
+
+// NewPhoneHandler creates a new phone handler
+func NewPhoneHandler(database *db.DB, service *phone.Service) *PhoneHandler {
+	return &PhoneHandler{
+		db:    database,
+		phone: service,
+	}
+}
+
+// StartPhoneVerificationRequest is a number to text a code to, with country code
+type StartPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,max=32"`
+}
+
+// VerifyPhoneRequest is the code the user received
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,max=16"`
+}
+
+// SetVoicePinRequest is a 4 to 6 digit PIN; empty removes it
+type SetVoicePinRequest struct {
+	Pin string `json:"pin" binding:"max=6"`
+}
+
+// phoneResponse is a verified number without the PIN hash
+func phoneResponse(p *db.UserPhone) gin.H {
+	return gin.H{
+		"phone_number": p.PhoneNumber,
+		"verified_at":  p.VerifiedAt,
+		"has_pin":      p.PinHash != nil,
+	}
+}
+
+// GetPhone returns the user's verified phone number
+// GET /api/users/me/phone
+func (h *PhoneHandler) GetPhone(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	p, err := h.db.GetUserPhone(c.Request.Context(), userID)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No verified phone number"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch phone number"})
+		return
+	}
+
+	c.JSON(http.StatusOK, phoneResponse(p))
+}
+
+// StartVerification texts a one-time code to the number
+// POST /api/users/me/phone
+func (h *PhoneHandler) StartVerification(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req StartPhoneVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.db.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	v, err := h.phone.StartVerification(c.Request.Context(), userID, req.PhoneNumber, user.Language)
+	switch {
+	case errors.Is(err, phone.ErrUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Phone verification is not available"})
+	case errors.Is(err, phone.ErrInvalidNumber):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "phone_number must include the country code, e.g. +2348012345678"})
+	case errors.Is(err, phone.ErrNumberTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "This number is already verified on another account"})
+	case errors.Is(err, phone.ErrTooSoon):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait a minute before requesting another code"})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send verification code"})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"phone_number": v.PhoneNumber, "expires_at": v.ExpiresAt})
+	}
+}
+
+// Verify checks the code and saves the number as verified
+// POST /api/users/me/phone/verify
+func (h *PhoneHandler) Verify(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.phone.Verify(c.Request.Context(), userID, req.Code)
+	switch {
+	case errors.Is(err, phone.ErrUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Phone verification is not available"})
+	case errors.Is(err, phone.ErrWrongCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect code"})
+	case errors.Is(err, phone.ErrNoPendingCode):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No code has been sent"})
+	case errors.Is(err, phone.ErrCodeExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "This code has expired, please request a new one"})
+	case errors.Is(err, phone.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, please request a new code"})
+	case errors.Is(err, phone.ErrNumberTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "This number is already verified on another account"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify phone number"})
+	default:
+		c.JSON(http.StatusOK, phoneResponse(p))
+	}
+}
+
+// DeletePhone removes the user's verified number; calls and texts from it
+// are no longer linked to the account
+// DELETE /api/users/me/phone
+func (h *PhoneHandler) DeletePhone(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	err := h.db.DeleteUserPhone(c.Request.Context(), userID)
+	if errors.Is(err, db.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No verified phone number"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove phone number"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Phone number removed"})
+}
+
+// SetVoicePin sets or clears the PIN asked for when calling from the
+// verified number, for phones shared with others
+// PUT /api/users/me/phone/pin
+func (h *PhoneHandler) SetVoicePin(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req SetVoicePinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.phone.SetPin(c.Request.Context(), userID, req.Pin)
+	switch {
+	case errors.Is(err, phone.ErrInvalidPin):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pin must be 4 to 6 digits"})
+	case errors.Is(err, db.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Verify a phone number before setting a PIN"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save PIN"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"has_pin": req.Pin != ""})
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/phone"
+)
+
+func TestStartPhoneVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	sender := phone.NewFakeSender()
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs(userID).
+		WillReturnRows(mockUserRows(userID, "ada@example.com"))
+	mock.ExpectQuery(`SELECT user_id FROM user_phones`).
+		WithArgs("+2348012345678").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM phone_verifications`).
+		WithArgs(userID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(`INSERT INTO phone_verifications`).
+		WithArgs(userID, "+2348012345678", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := ginWithUserID(userID)
+	r.POST("/phone", NewPhoneHandler(database, phone.NewService(database, sender, phone.Config{})).StartVerification)
+
+	req, err := jsonRequest(http.MethodPost, "/phone", map[string]any{"phone_number": "+234 801 234 5678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"phone_number":"+2348012345678"`) {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if sent := sender.Sent(); len(sent) != 1 || sent[0].To != "+2348012345678" || !strings.Contains(sent[0].Body, "Your MomLaunchpad code is") {
+		t.Errorf("sent = %+v", sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartPhoneVerification_NoSMSProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs(userID).
+		WillReturnRows(mockUserRows(userID, "ada@example.com"))
+
+	r := ginWithUserID(userID)
+	r.POST("/phone", NewPhoneHandler(database, phone.NewService(database, nil, phone.Config{})).StartVerification)
+
+	req, err := jsonRequest(http.MethodPost, "/phone", map[string]any{"phone_number": "+2348012345678"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPhone_WrongCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM phone_verifications`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"phone_number", "code_hash", "attempts", "sent_at", "expires_at"}).
+			AddRow("+2348012345678", "not-the-hash", 0, now, now.Add(5*time.Minute)))
+	mock.ExpectQuery(`UPDATE phone_verifications SET attempts`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}).AddRow(1))
+
+	r := ginWithUserID(userID)
+	r.POST("/phone/verify", NewPhoneHandler(database, phone.NewService(database, phone.NewFakeSender(), phone.Config{})).Verify)
+
+	req, err := jsonRequest(http.MethodPost, "/phone/verify", map[string]any{"code": "123456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348012345678", time.Now(), "$2a$10$hash", 0, nil))
+
+	r := ginWithUserID(userID)
+	r.GET("/phone", NewPhoneHandler(database, nil).GetPhone)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/phone", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"has_pin":true`) || strings.Contains(body, "$2a$") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestSetVoicePin_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectExec(`UPDATE user_phones SET voice_pin_hash`).
+		WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := ginWithUserID(userID)
+	r.PUT("/phone/pin", NewPhoneHandler(database, phone.NewService(database, phone.NewFakeSender(), phone.Config{})).SetVoicePin)
+
+	for pin, want := range map[string]int{"12a4": http.StatusBadRequest, "1234567": http.StatusBadRequest, "4821": http.StatusNotFound} {
+		req, err := jsonRequest(http.MethodPut, "/phone/pin", map[string]any{"pin": pin})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("pin %q: status = %d, want %d, body: %s", pin, w.Code, want, w.Body.String())
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
@@ -29,8 +30,14 @@ var profileFactKeys = map[string]bool{
 
 // ProfileHandler handles user profile and onboarding endpoints.
 type ProfileHandler struct {
-	db     *db.DB
-	photos *storage.ProfilePhotoStore
+	db       *db.DB
+	photos   *storage.ProfilePhotoStore
+	carePlan CarePlanGenerator
+}
+
+// CarePlanGenerator schedules the standard care reminders for a user's journey stage.
+type CarePlanGenerator interface {
+	Generate(ctx context.Context, user *db.User) error
 }
 
 // NewProfileHandler creates a new profile handler.
@@ -38,6 +45,13 @@ func NewProfileHandler(database *db.DB, photos *storage.ProfilePhotoStore) *Prof
 	return &ProfileHandler{db: database, photos: photos}
 }
 
+// WithCarePlan regenerates care plan reminders when onboarding completes or
+// the journey stage, due date or baby's birth date changes.
+func (h *ProfileHandler) WithCarePlan(generator CarePlanGenerator) *ProfileHandler {
+	h.carePlan = generator
+	return h
+}
+
 // ProfileResponse is the user's profile and known facts for personalization.
 type ProfileResponse struct {
 	Name                         string            `json:"name"`
@@ -58,6 +72,7 @@ type ProfileResponse struct {
 	CountryCode                  *string           `json:"country_code,omitempty"`
 	StateProvince                *string           `json:"state_province,omitempty"`
 	City                         *string           `json:"city,omitempty"`
+	Timezone                     string            `json:"timezone"`
 	CommunityOnboardingCompleted bool              `json:"community_onboarding_completed"`
 	CommunityInterests           []string          `json:"community_interests,omitempty"`
 	LearnedFacts                 map[string]string `json:"learned_facts,omitempty"`
@@ -81,6 +96,7 @@ type ProfileSaveRequest struct {
 	CountryCode          *string    `json:"country_code"`
 	StateProvince        *string    `json:"state_province"`
 	City                 *string    `json:"city"`
+	Timezone             *string    `json:"timezone"` // IANA zone, e.g. Africa/Lagos
 }
 
 // GetProfile returns the current user's profile and personalization facts.
@@ -247,7 +263,8 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 			strings.Contains(err.Error(), "baby_birth_date") ||
 			strings.Contains(err.Error(), "pregnancy_week") ||
 			strings.Contains(err.Error(), "profile photo") ||
-			strings.Contains(err.Error(), "image URL") {
+			strings.Contains(err.Error(), "image URL") ||
+			strings.Contains(err.Error(), "invalid timezone") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -282,7 +299,8 @@ func (h *ProfileHandler) CompleteOnboarding(c *gin.Context) {
 
 	user, facts, err := h.saveProfile(c, userID, req, true)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid journey transition") {
+		if strings.Contains(err.Error(), "invalid journey transition") ||
+			strings.Contains(err.Error(), "invalid timezone") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -346,6 +364,13 @@ func (h *ProfileHandler) saveProfile(
 		update.Country = trimOptionalString(req.Country)
 	}
 
+	if tz := trimOptionalString(req.Timezone); tz != nil && *tz != "" {
+		if _, err := time.LoadLocation(*tz); err != nil || *tz == "Local" {
+			return nil, nil, fmt.Errorf("invalid timezone %q", *tz)
+		}
+		update.Timezone = tz
+	}
+
 	if update.ProfilePhotoURL != nil && *update.ProfilePhotoURL != "" &&
 		!storage.IsManagedProfilePhotoPath(*update.ProfilePhotoURL) {
 		urls, err := validateHTTPSImageURLs([]string{*update.ProfilePhotoURL}, 1)
@@ -432,18 +457,49 @@ func (h *ProfileHandler) saveProfile(
 		return nil, nil, fmt.Errorf("failed to invalidate welcome message: %w", err)
 	}
 
+	if h.carePlan != nil && user.OnboardingCompletedAt != nil && (markComplete || carePlanChanged(currentUser, user)) {
+		// The profile is saved; a failed schedule is regenerated on the next change
+		if err := h.carePlan.Generate(ctx, user); err != nil {
+			log.Printf("Failed to generate care plan for user %s: %v", userID, err)
+		}
+	}
+
 	return user, facts, nil
 }
 
+// carePlanChanged reports whether a profile update moved the dates or the
+// timezone the care plan is scheduled from
+func carePlanChanged(before, after *db.User) bool {
+	return !sameOptionalString(before.JourneyStage, after.JourneyStage) ||
+		!sameOptionalString(before.CountryCode, after.CountryCode) ||
+		before.Timezone != after.Timezone ||
+		!sameOptionalDate(before.ExpectedDeliveryDate, after.ExpectedDeliveryDate) ||
+		!sameOptionalDate(before.BabyBirthDate, after.BabyBirthDate)
+}
+
+func sameOptionalString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameOptionalDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return dateOnly(*a).Equal(dateOnly(*b))
+}
+
 func (h *ProfileHandler) syncProfileFacts(ctx context.Context, userID string, update db.UserProfileUpdate) error {
 	if update.JourneyStage != nil {
-		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "journey_stage", *update.JourneyStage, profileFactConfidence); err != nil {
+		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "journey_stage", *update.JourneyStage, profileFactConfidence, db.FactSourceProfile); err != nil {
 			return err
 		}
 	}
 
 	if update.PregnancyWeek != nil {
-		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "pregnancy_week", strconv.Itoa(*update.PregnancyWeek), profileFactConfidence); err != nil {
+		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "pregnancy_week", strconv.Itoa(*update.PregnancyWeek), profileFactConfidence, db.FactSourceProfile); err != nil {
 			return err
 		}
 	}
@@ -453,19 +509,19 @@ func (h *ProfileHandler) syncProfileFacts(ctx context.Context, userID string, up
 		if *update.IsFirstPregnancy {
 			value = "yes"
 		}
-		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "is_first_pregnancy", value, profileFactConfidence); err != nil {
+		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "is_first_pregnancy", value, profileFactConfidence, db.FactSourceProfile); err != nil {
 			return err
 		}
 	}
 
 	if update.PrimaryConcern != nil && *update.PrimaryConcern != "" {
-		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "primary_concern", *update.PrimaryConcern, profileFactConfidence); err != nil {
+		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "primary_concern", *update.PrimaryConcern, profileFactConfidence, db.FactSourceProfile); err != nil {
 			return err
 		}
 	}
 
 	if update.DietPreference != nil && *update.DietPreference != "" {
-		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "diet", *update.DietPreference, profileFactConfidence); err != nil {
+		if _, err := h.db.SaveOrUpdateFact(ctx, userID, "diet", *update.DietPreference, profileFactConfidence, db.FactSourceProfile); err != nil {
 			return err
 		}
 	}
@@ -533,6 +589,7 @@ func buildProfileResponse(user *db.User, facts []db.UserFact) ProfileResponse {
 		CountryCode:                  user.CountryCode,
 		StateProvince:                user.StateProvince,
 		City:                         user.City,
+		Timezone:                     user.Timezone,
 		CommunityOnboardingCompleted: user.CommunityOnboardingAt != nil,
 		LearnedFacts:                 learnedFacts,
 		Facts:                        allFacts,
@@ -154,7 +154,7 @@ func TestProfileGetProfile_Success(t *testing.T) {
 		WillReturnRows(mockUserRows(userID, "user@example.com"))
 	mock.ExpectQuery(`FROM user_facts`).
 		WithArgs(userID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "key", "value", "confidence", "created_at", "updated_at"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "key", "value", "confidence", "source", "created_at", "updated_at"}))
 
 	r := ginWithUserID(userID)
 	r.GET("/profile", NewProfileHandler(database, nil).GetProfile)
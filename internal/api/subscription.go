@@ -1,9 +1,11 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/api/middleware"
 	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 )
 
@@ -22,7 +24,7 @@ func NewSubscriptionHandler(subMgr *subscription.Manager) *SubscriptionHandler {
 // GetMyQuota returns the current user's quota status for a feature
 // GET /api/subscription/quota/:feature
 func (h *SubscriptionHandler) GetMyQuota(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := middleware.GetUserID(c)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
@@ -64,20 +66,23 @@ func (h *SubscriptionHandler) GetMyQuota(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"feature":      featureCode,
-		"has_access":   true,
-		"within_quota": withinQuota,
-		"quota_limit":  quotaInfo.QuotaLimit,
-		"quota_used":   quotaInfo.UsageCount,
-		"quota_period": quotaInfo.QuotaPeriod,
-		"period_end":   quotaInfo.PeriodEnd,
+		"feature":          featureCode,
+		"has_access":       true,
+		"within_quota":     withinQuota,
+		"quota_limit":      quotaInfo.QuotaLimit,
+		"quota_used":       quotaInfo.UsageCount,
+		"quota_period":     quotaInfo.QuotaPeriod,
+		"token_limit":      quotaInfo.TokenLimit,
+		"tokens_used":      quotaInfo.TokensUsed,
+		"tokens_remaining": quotaInfo.TokensRemaining,
+		"period_end":       quotaInfo.PeriodEnd,
 	})
 }
 
 // GetMyFeatures returns all features available to the current user
 // GET /api/subscription/features
 func (h *SubscriptionHandler) GetMyFeatures(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := middleware.GetUserID(c)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
@@ -97,7 +102,7 @@ func (h *SubscriptionHandler) GetMyFeatures(c *gin.Context) {
 // GetMySubscription returns the current user's active subscription
 // GET /api/subscription/me
 func (h *SubscriptionHandler) GetMySubscription(c *gin.Context) {
-	userID := c.GetString("userID")
+	userID := middleware.GetUserID(c)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
@@ -119,6 +124,41 @@ func (h *SubscriptionHandler) GetMySubscription(c *gin.Context) {
 	})
 }
 
+// StartTrial starts a free trial of a plan for the current user
+// POST /api/subscription/trial
+func (h *SubscriptionHandler) StartTrial(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req struct {
+		PlanCode string `json:"plan_code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	sub, err := h.subManager.StartTrial(c.Request.Context(), userID, req.PlanCode)
+	switch {
+	case errors.Is(err, subscription.ErrNoTrial):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "plan has no trial"})
+		return
+	case errors.Is(err, subscription.ErrTrialNotAllowed):
+		c.JSON(http.StatusConflict, gin.H{"error": "trial not available"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start trial"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"subscription": sub,
+	})
+}
+
 // Admin endpoints
 
 // ListAllPlans returns all available subscription plans (admin only)
@@ -280,6 +320,10 @@ func (h *SubscriptionHandler) GrantFeature(c *gin.Context) {
 	}
 
 	if err := h.subManager.GrantFeature(c.Request.Context(), targetUserID, req.FeatureKey, req.ExpiresAt); err != nil {
+		if errors.Is(err, subscription.ErrUnknownFeature) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "feature not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to grant feature"})
 		return
 	}
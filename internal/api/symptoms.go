@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
 	"github.com/themobileprof/momlaunchpad-be/internal/symptoms"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
 const maxSummariesPerRequest = 2
@@ -50,7 +51,7 @@ func (h *SymptomHandler) GetSymptomHistory(c *gin.Context) {
 		return
 	}
 
-	h.ensureSummaries(c.Request.Context(), symptoms)
+	h.ensureSummaries(llm.WithUser(c.Request.Context(), userID.(string)), symptoms)
 
 	c.JSON(http.StatusOK, gin.H{
 		"symptoms": symptoms,
@@ -79,7 +80,7 @@ func (h *SymptomHandler) GetRecentSymptoms(c *gin.Context) {
 		return
 	}
 
-	h.ensureSummaries(c.Request.Context(), records)
+	h.ensureSummaries(llm.WithUser(c.Request.Context(), userID.(string)), records)
 
 	c.JSON(http.StatusOK, gin.H{
 		"symptoms": records,
@@ -2,16 +2,22 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
 	"github.com/themobileprof/momlaunchpad-be/internal/chat"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/phone"
+	"github.com/themobileprof/momlaunchpad-be/internal/privacy"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
 )
 
@@ -20,30 +26,39 @@ type VoiceHandler struct {
 	twilioClient *twilio.VoiceClient
 	chatEngine   *chat.Engine
 	db           *db.DB
-	callSessions *sync.Map // Store call session data (callSid -> session)
+	subManager   *subscription.Manager
+	sessions     VoiceSessionStore // Call state by CallSid, shared across replicas
+	outbound     OutboundCalls     // Nil unless outbound calling is enabled
+	now          func() time.Time
 }
 
-// VoiceSession stores data for an active voice call
-type VoiceSession struct {
-	UserID         string
-	CallSid        string
-	Language       string
-	From           string
-	Messages       []string // Store conversation history
-	ConversationID string
-	mu             sync.RWMutex
+// OutboundCalls tracks the calls we place (implemented by *outbound.Caller)
+type OutboundCalls interface {
+	// Answered returns the call Twilio has just connected and marks it in progress
+	Answered(ctx context.Context, callID string) (*db.OutboundCall, error)
+	// Call returns the call we placed with callSid; db.ErrNotFound for inbound calls
+	Call(ctx context.Context, callSid string) (*db.OutboundCall, error)
+	// CallStatus records a call's final status; db.ErrNotFound for inbound calls
+	CallStatus(ctx context.Context, callSid string, status twilio.CallStatus, duration int) error
 }
 
 // NewVoiceHandler creates a new voice handler
-func NewVoiceHandler(twilioClient *twilio.VoiceClient, chatEngine *chat.Engine, database *db.DB) *VoiceHandler {
+func NewVoiceHandler(twilioClient *twilio.VoiceClient, chatEngine *chat.Engine, database *db.DB, subMgr *subscription.Manager, sessions VoiceSessionStore) *VoiceHandler {
 	return &VoiceHandler{
 		twilioClient: twilioClient,
 		chatEngine:   chatEngine,
 		db:           database,
-		callSessions: &sync.Map{},
+		subManager:   subMgr,
+		sessions:     sessions,
+		now:          time.Now,
 	}
 }
 
+// EnableOutbound lets the handler answer and track calls placed by the outbound caller
+func (h *VoiceHandler) EnableOutbound(calls OutboundCalls) {
+	h.outbound = calls
+}
+
 // HandleIncoming handles incoming voice calls (Twilio webhook)
 func (h *VoiceHandler) HandleIncoming(c *gin.Context) {
 	// Parse request body
@@ -57,15 +72,29 @@ func (h *VoiceHandler) HandleIncoming(c *gin.Context) {
 	callParams := twilio.ParseIncomingCall(c.Request.Form)
 
 	log.Printf("Incoming call: CallSid=%s, From=%s, To=%s",
-		callParams.CallSid, callParams.From, callParams.To)
+		callParams.CallSid, privacy.MaskPhone(callParams.From), callParams.To)
+
+	// Only a verified number identifies the caller
+	user, err := h.callerByPhone(c.Request.Context(), callParams.From)
+	if errors.Is(err, db.ErrNotFound) {
+		log.Printf("No verified user for phone %s", privacy.MaskPhone(callParams.From))
+		twiml := twilio.NewTwiMLResponse().
+			Say("Welcome to MomLaunchpad. Please register through our app and verify this phone number to use voice services.", "", "en-US").
+			Hangup().
+			String()
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, twiml)
+		return
+	}
 
-	// Look up user by phone number
-	user, err := h.getUserByPhone(c.Request.Context(), callParams.From)
+	var userPhone *db.UserPhone
+	if err == nil {
+		userPhone, err = h.db.GetUserPhone(c.Request.Context(), user.ID)
+	}
 	if err != nil {
-		log.Printf("User not found for phone %s: %v", callParams.From, err)
-		// User not registered
+		log.Printf("Failed to look up caller %s: %v", callParams.From, err)
 		twiml := twilio.NewTwiMLResponse().
-			Say("Welcome to MomLaunchpad. Please register through our app to use voice services.", "", "en-US").
+			Say("Sorry, we can't take your call right now. Please try again later.", "", "en-US").
 			Hangup().
 			String()
 		c.Header("Content-Type", "application/xml")
@@ -73,36 +102,331 @@ func (h *VoiceHandler) HandleIncoming(c *gin.Context) {
 		return
 	}
 
-	// Create session for this call
-	session := &VoiceSession{
-		UserID:   user.ID,
-		CallSid:  callParams.CallSid,
-		Language: user.Language,
-		From:     callParams.From,
-		Messages: []string{},
+	// Too many wrong PINs lately, on this or earlier calls
+	if userPhone.PinHash != nil && phone.PinLocked(userPhone, h.now()) {
+		log.Printf("Voice PIN locked for user %s", user.ID)
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, h.pinLockedOut(user.Language))
+		return
 	}
-	h.callSessions.Store(callParams.CallSid, session)
 
-	// Determine language and voice
-	twilioLang := twilio.GetTwilioLanguageCode(user.Language)
-	voice := twilio.GetVoiceForLanguage(user.Language)
+	// Create session for this call; a PIN guards shared phones
+	session := &db.VoiceSession{
+		UserID:     user.ID,
+		CallSid:    callParams.CallSid,
+		Language:   user.Language,
+		From:       callParams.From,
+		Messages:   []string{},
+		PinPending: userPhone.PinHash != nil,
+		ExpiresAt:  h.now().Add(VoiceSessionTTL),
+	}
+	if user.CountryCode != nil {
+		session.CountryCode = *user.CountryCode
+	}
+	if err := h.sessions.SaveVoiceSession(c.Request.Context(), session); err != nil {
+		log.Printf("Failed to save voice session %s: %v", callParams.CallSid, err)
+		twiml := twilio.NewTwiMLResponse().
+			Say("Sorry, we can't take your call right now. Please try again later.", "", "en-US").
+			Hangup().
+			String()
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, twiml)
+		return
+	}
 
-	// Build webhook URL for gathering speech
-	gatherURL := fmt.Sprintf("/api/voice/gather?callSid=%s", callParams.CallSid)
+	c.Header("Content-Type", "application/xml")
+	if session.PinPending {
+		c.String(http.StatusOK, h.askPin(session, h.getPinPrompt(user.Language)))
+		return
+	}
+	c.String(http.StatusOK, h.greet(session))
+}
 
-	// Generate greeting TwiML
-	greeting := h.getGreeting(user.Language)
-	twiml := twilio.NewTwiMLResponse().
-		Say(greeting, voice, twilioLang).
+// HandlePin checks the voice PIN a caller keyed in or spoke before the
+// conversation starts; too many wrong PINs end the call
+// POST /api/voice/pin?callSid=
+func (h *VoiceHandler) HandlePin(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		log.Printf("Failed to parse form: %v", err)
+		c.String(http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	gatherParams := twilio.ParseGather(c.Request.Form)
+	callSid := c.Query("callSid")
+	if callSid == "" {
+		callSid = gatherParams.CallSid
+	}
+
+	c.Header("Content-Type", "application/xml")
+	session, err := h.sessions.GetVoiceSession(c.Request.Context(), callSid)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("Failed to load voice session %s: %v", callSid, err)
+		}
+		c.String(http.StatusOK, twilio.NewTwiMLResponse().
+			Say("Session expired. Please call again.", "", "en-US").
+			Hangup().
+			String())
+		return
+	}
+	if !session.PinPending {
+		c.String(http.StatusOK, h.greetAfterPin(c.Request.Context(), session))
+		return
+	}
+
+	userPhone, err := h.db.GetUserPhone(c.Request.Context(), session.UserID)
+	if err != nil {
+		// The number may have been removed during the call
+		log.Printf("Failed to load phone for user %s: %v", session.UserID, err)
+		_ = h.sessions.DeleteVoiceSession(c.Request.Context(), callSid)
+		c.String(http.StatusOK, twilio.NewTwiMLResponse().
+			Say("Sorry, we can't take your call right now. Please try again later.", "", "en-US").
+			Hangup().
+			String())
+		return
+	}
+	if phone.PinLocked(userPhone, h.now()) {
+		_ = h.sessions.DeleteVoiceSession(c.Request.Context(), callSid)
+		c.String(http.StatusOK, h.pinLockedOut(session.Language))
+		return
+	}
+
+	pin := gatherParams.Digits
+	if pin == "" {
+		pin = spokenDigits(gatherParams.SpeechResult)
+	}
+	if userPhone.PinHash == nil || phone.CheckPin(*userPhone.PinHash, pin) {
+		if userPhone.PinFailures > 0 {
+			if err := h.db.ResetVoicePinFailures(c.Request.Context(), session.UserID); err != nil {
+				log.Printf("Failed to reset voice PIN failures for user %s: %v", session.UserID, err)
+			}
+		}
+		session.PinPending = false
+		session.PinAttempts = 0
+		session.ExpiresAt = h.now().Add(VoiceSessionTTL)
+		if err := h.sessions.SaveVoiceSession(c.Request.Context(), session); err != nil {
+			log.Printf("Failed to save voice session %s: %v", callSid, err)
+			c.String(http.StatusOK, twilio.NewTwiMLResponse().
+				Say("Sorry, we can't take your call right now. Please try again later.", "", "en-US").
+				Hangup().
+				String())
+			return
+		}
+		c.String(http.StatusOK, h.greetAfterPin(c.Request.Context(), session))
+		return
+	}
+
+	// Failures are counted per user so hanging up and redialling doesn't reset them
+	failures, err := h.db.RecordVoicePinFailure(c.Request.Context(), session.UserID, h.now(), phone.PinLockoutWindow)
+	if err != nil {
+		log.Printf("Failed to record voice PIN failure for user %s: %v", session.UserID, err)
+		failures = phone.MaxPinFailures
+	}
+	session.PinAttempts++
+	log.Printf("Wrong voice PIN for user %s (attempt %d, %d recently)", session.UserID, session.PinAttempts, failures)
+	if session.PinAttempts >= maxVoicePinAttempts || failures >= phone.MaxPinFailures {
+		if err := h.sessions.DeleteVoiceSession(c.Request.Context(), callSid); err != nil {
+			log.Printf("Failed to delete voice session %s: %v", callSid, err)
+		}
+		twilioLang := twilio.GetTwilioLanguageCode(session.Language)
+		voice := twilio.GetVoiceForLanguage(session.Language)
+		c.String(http.StatusOK, twilio.NewTwiMLResponse().
+			Say(h.getPinLocked(session.Language), voice, twilioLang).
+			Hangup().
+			String())
+		return
+	}
+	if err := h.sessions.SaveVoiceSession(c.Request.Context(), session); err != nil {
+		log.Printf("Failed to save voice session %s: %v", callSid, err)
+	}
+	c.String(http.StatusOK, h.askPin(session, h.getPinRetry(session.Language)))
+}
+
+// maxVoicePinAttempts is how many wrong PINs end a call; phone.MaxPinFailures
+// limits them across calls
+const maxVoicePinAttempts = 3
+
+// pinLockedOut ends a call from a user whose PIN entry is locked
+func (h *VoiceHandler) pinLockedOut(language string) string {
+	twilioLang := twilio.GetTwilioLanguageCode(language)
+	voice := twilio.GetVoiceForLanguage(language)
+	return twilio.NewTwiMLResponse().
+		Say(h.getPinLockedOut(language), voice, twilioLang).
+		Hangup().
+		String()
+}
+
+// callerByPhone returns the user who verified the caller's number
+func (h *VoiceHandler) callerByPhone(ctx context.Context, from string) (*db.User, error) {
+	number, err := phone.Normalize(from)
+	if err != nil {
+		// Withheld and short-code callers can't be matched
+		return nil, db.ErrNotFound
+	}
+	return h.db.GetUserByPhone(ctx, number)
+}
+
+// greetAfterPin continues a call once the PIN checks out: with the reminder
+// or check-in on a call we placed, otherwise with the usual greeting
+func (h *VoiceHandler) greetAfterPin(ctx context.Context, session *db.VoiceSession) string {
+	if h.outbound == nil {
+		return h.greet(session)
+	}
+	call, err := h.outbound.Call(ctx, session.CallSid)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("Failed to load outbound call %s: %v", session.CallSid, err)
+		}
+		return h.greet(session)
+	}
+	if call.UserID != session.UserID {
+		return h.greet(session)
+	}
+	return h.greetOutbound(session, call)
+}
+
+// greet welcomes an identified caller and listens for the first question
+func (h *VoiceHandler) greet(session *db.VoiceSession) string {
+	twilioLang := twilio.GetTwilioLanguageCode(session.Language)
+	voice := twilio.GetVoiceForLanguage(session.Language)
+	gatherURL := fmt.Sprintf("/api/voice/gather?callSid=%s", session.CallSid)
+
+	return twilio.NewTwiMLResponse().
+		Say(h.getGreeting(session.Language), voice, twilioLang).
 		Gather(gatherURL, "speech", twilioLang, 5).
-		Say(h.getPrompt(user.Language), voice, twilioLang).
+		Say(h.getPrompt(session.Language), voice, twilioLang).
 		EndGather().
 		Say("I didn't hear anything. Please call back when you're ready.", voice, twilioLang).
 		Hangup().
 		String()
+}
+
+// askPin prompts for the voice PIN by keypad or speech
+func (h *VoiceHandler) askPin(session *db.VoiceSession, prompt string) string {
+	twilioLang := twilio.GetTwilioLanguageCode(session.Language)
+	voice := twilio.GetVoiceForLanguage(session.Language)
+	pinURL := fmt.Sprintf("/api/voice/pin?callSid=%s", session.CallSid)
+
+	return twilio.NewTwiMLResponse().
+		Gather(pinURL, "dtmf speech", twilioLang, 8).
+		Say(prompt, voice, twilioLang).
+		EndGather().
+		Say(h.getGoodbye(session.Language), voice, twilioLang).
+		Hangup().
+		String()
+}
+
+// spokenDigits keeps the digits of a spoken PIN, e.g. "4 8 2 1." becomes "4821"
+func spokenDigits(speech string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, speech)
+}
+
+// HandleOutbound starts the conversation when a user answers a call we placed
+// POST /api/voice/outbound?callId=
+func (h *VoiceHandler) HandleOutbound(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		log.Printf("Failed to parse form: %v", err)
+		c.String(http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	callParams := twilio.ParseIncomingCall(c.Request.Form)
+	callID := c.Query("callId")
+	log.Printf("Outbound call answered: CallSid=%s, CallID=%s", callParams.CallSid, callID)
+
+	hangup := twilio.NewTwiMLResponse().Hangup().String()
+	if h.outbound == nil {
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, hangup)
+		return
+	}
+
+	call, err := h.outbound.Answered(c.Request.Context(), callID)
+	if err != nil {
+		log.Printf("Failed to load outbound call %s: %v", callID, err)
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, hangup)
+		return
+	}
+
+	userPhone, err := h.db.GetUserPhone(c.Request.Context(), call.UserID)
+	if err != nil {
+		// The number may have been removed since the call was placed
+		log.Printf("Failed to load phone for user %s: %v", call.UserID, err)
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, hangup)
+		return
+	}
+	if userPhone.PinHash != nil && phone.PinLocked(userPhone, h.now()) {
+		log.Printf("Voice PIN locked for user %s", call.UserID)
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, h.pinLockedOut(call.Language))
+		return
+	}
+
+	// The rest of the call is an ordinary voice conversation; whoever answers
+	// a shared phone must enter the PIN before hearing the reminder
+	session := &db.VoiceSession{
+		UserID:     call.UserID,
+		CallSid:    callParams.CallSid,
+		Language:   call.Language,
+		From:       callParams.To,
+		Messages:   []string{},
+		PinPending: userPhone.PinHash != nil,
+		ExpiresAt:  h.now().Add(VoiceSessionTTL),
+	}
+	if call.CountryCode != nil {
+		session.CountryCode = *call.CountryCode
+	}
+	if err := h.sessions.SaveVoiceSession(c.Request.Context(), session); err != nil {
+		log.Printf("Failed to save voice session %s: %v", callParams.CallSid, err)
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, hangup)
+		return
+	}
 
 	c.Header("Content-Type", "application/xml")
-	c.String(http.StatusOK, twiml)
+	if session.PinPending {
+		c.String(http.StatusOK, h.askPin(session, h.getPinPrompt(call.Language)))
+		return
+	}
+	c.String(http.StatusOK, h.greetOutbound(session, call))
+}
+
+// greetOutbound reads the reminder or opens the check-in on a call we placed
+// and listens for the reply
+func (h *VoiceHandler) greetOutbound(session *db.VoiceSession, call *db.OutboundCall) string {
+	twilioLang := twilio.GetTwilioLanguageCode(session.Language)
+	voice := twilio.GetVoiceForLanguage(session.Language)
+
+	var intro, prompt string
+	if call.Kind == db.CallKindReminder {
+		intro = h.getReminderIntro(session.Language) + " " + call.Message
+		prompt = h.getReminderPrompt(session.Language)
+	} else {
+		name := ""
+		if call.Name != nil {
+			name = *call.Name
+		}
+		intro = h.getCheckinIntro(session.Language, name)
+		prompt = h.getCheckinPrompt(session.Language)
+	}
+
+	gatherURL := fmt.Sprintf("/api/voice/gather?callSid=%s", session.CallSid)
+	return twilio.NewTwiMLResponse().
+		Say(intro, voice, twilioLang).
+		Gather(gatherURL, "speech", twilioLang, 5).
+		Say(prompt, voice, twilioLang).
+		EndGather().
+		Say(h.getGoodbye(session.Language), voice, twilioLang).
+		Hangup().
+		String()
 }
 
 // HandleGather handles speech input from user (Gather callback)
@@ -125,8 +449,11 @@ func (h *VoiceHandler) HandleGather(c *gin.Context) {
 	log.Printf("Gather callback: CallSid=%s, Speech=%s", callSid, gatherParams.SpeechResult)
 
 	// Get session
-	sessionVal, exists := h.callSessions.Load(callSid)
-	if !exists {
+	session, err := h.sessions.GetVoiceSession(c.Request.Context(), callSid)
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			log.Printf("Failed to load voice session %s: %v", callSid, err)
+		}
 		log.Printf("Session not found for CallSid: %s", callSid)
 		twiml := twilio.NewTwiMLResponse().
 			Say("Session expired. Please call again.", "", "en-US").
@@ -136,7 +463,12 @@ func (h *VoiceHandler) HandleGather(c *gin.Context) {
 		c.String(http.StatusOK, twiml)
 		return
 	}
-	session := sessionVal.(*VoiceSession)
+	if session.PinPending {
+		// Nothing about the user is shared until the PIN checks out
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, h.askPin(session, h.getPinPrompt(session.Language)))
+		return
+	}
 
 	// Check if user said anything
 	speechResult := gatherParams.SpeechResult
@@ -154,9 +486,24 @@ func (h *VoiceHandler) HandleGather(c *gin.Context) {
 	}
 
 	// Store message in session
-	session.mu.Lock()
 	session.Messages = append(session.Messages, speechResult)
-	session.mu.Unlock()
+
+	// Reserve quota for this turn; released again if processing fails
+	reservation, err := h.subManager.Reserve(c.Request.Context(), session.UserID, "chat")
+	if err != nil {
+		if !errors.Is(err, subscription.ErrQuotaExceeded) && !errors.Is(err, subscription.ErrNoAccess) {
+			log.Printf("Failed to reserve quota for user %s: %v", session.UserID, err)
+		}
+		twilioLang := twilio.GetTwilioLanguageCode(session.Language)
+		voice := twilio.GetVoiceForLanguage(session.Language)
+		twiml := twilio.NewTwiMLResponse().
+			Say(h.getQuotaExceeded(session.Language), voice, twilioLang).
+			Hangup().
+			String()
+		c.Header("Content-Type", "application/xml")
+		c.String(http.StatusOK, twiml)
+		return
+	}
 
 	// Process message through chat engine
 	responder := NewVoiceResponder(session)
@@ -166,10 +513,14 @@ func (h *VoiceHandler) HandleGather(c *gin.Context) {
 		Message:        speechResult,
 		Language:       session.Language,
 		Responder:      responder,
+		CountryCode:    session.CountryCode,
 	}
 
 	if _, err := h.chatEngine.ProcessMessage(c.Request.Context(), req); err != nil {
 		log.Printf("Failed to process message: %v", err)
+		if err := reservation.Release(context.WithoutCancel(c.Request.Context())); err != nil {
+			log.Printf("Failed to release quota for user %s: %v", session.UserID, err)
+		}
 		twilioLang := twilio.GetTwilioLanguageCode(session.Language)
 		voice := twilio.GetVoiceForLanguage(session.Language)
 		twiml := twilio.NewTwiMLResponse().
@@ -181,6 +532,16 @@ func (h *VoiceHandler) HandleGather(c *gin.Context) {
 		return
 	}
 
+	if err := reservation.Commit(context.WithoutCancel(c.Request.Context())); err != nil {
+		log.Printf("Failed to commit quota for user %s: %v", session.UserID, err)
+	}
+
+	// Keep the turn and conversation for the next callback, which may reach another replica
+	session.ExpiresAt = h.now().Add(VoiceSessionTTL)
+	if err := h.sessions.SaveVoiceSession(context.WithoutCancel(c.Request.Context()), session); err != nil {
+		log.Printf("Failed to save voice session %s: %v", callSid, err)
+	}
+
 	// Get AI response from responder
 	aiResponse := responder.GetResponse()
 	twilioLang := twilio.GetTwilioLanguageCode(session.Language)
@@ -212,11 +573,19 @@ func (h *VoiceHandler) HandleStatus(c *gin.Context) {
 	callParams := twilio.ParseIncomingCall(c.Request.Form)
 	log.Printf("Call status: CallSid=%s, Status=%s", callParams.CallSid, callParams.CallStatus)
 
-	// Clean up session when call ends
-	if callParams.CallStatus == twilio.CallStatusCompleted ||
-		callParams.CallStatus == twilio.CallStatusFailed ||
-		callParams.CallStatus == twilio.CallStatusCanceled {
-		h.callSessions.Delete(callParams.CallSid)
+	// Clean up session when call ends; the sweeper catches calls whose callback never arrives
+	switch callParams.CallStatus {
+	case twilio.CallStatusCompleted, twilio.CallStatusFailed, twilio.CallStatusCanceled,
+		twilio.CallStatusBusy, twilio.CallStatusNoAnswer:
+		if err := h.sessions.DeleteVoiceSession(c.Request.Context(), callParams.CallSid); err != nil {
+			log.Printf("Failed to delete voice session %s: %v", callParams.CallSid, err)
+		}
+		if h.outbound != nil {
+			err := h.outbound.CallStatus(c.Request.Context(), callParams.CallSid, callParams.CallStatus, callParams.CallDuration)
+			if err != nil && !errors.Is(err, db.ErrNotFound) {
+				log.Printf("Failed to record outbound call %s: %v", callParams.CallSid, err)
+			}
+		}
 	}
 
 	c.String(http.StatusOK, "OK")
@@ -224,13 +593,13 @@ func (h *VoiceHandler) HandleStatus(c *gin.Context) {
 
 // VoiceResponder implements chat.Responder for voice calls
 type VoiceResponder struct {
-	session  *VoiceSession
+	session  *db.VoiceSession
 	response strings.Builder
 	mu       sync.Mutex
 }
 
 // NewVoiceResponder creates a new voice responder
-func NewVoiceResponder(session *VoiceSession) *VoiceResponder {
+func NewVoiceResponder(session *db.VoiceSession) *VoiceResponder {
 	return &VoiceResponder{
 		session: session,
 	}
@@ -238,8 +607,8 @@ func NewVoiceResponder(session *VoiceSession) *VoiceResponder {
 
 // SetConversationID updates the session with the conversation ID
 func (r *VoiceResponder) SetConversationID(id string) {
-	r.session.mu.Lock()
-	defer r.session.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.session.ConversationID = id
 }
 
@@ -251,6 +620,11 @@ func (r *VoiceResponder) SendMessage(content string) error {
 	return nil
 }
 
+// SendDelta accumulates streamed chunks; voice speaks the full reply at once
+func (r *VoiceResponder) SendDelta(content string) error {
+	return r.SendMessage(content)
+}
+
 // SendCalendarSuggestion handles calendar suggestions in voice
 func (r *VoiceResponder) SendCalendarSuggestion(suggestion calendar.Suggestion) error {
 	// For voice, we'll just mention it verbally
@@ -261,6 +635,12 @@ func (r *VoiceResponder) SendCalendarSuggestion(suggestion calendar.Suggestion)
 	return nil
 }
 
+// SendEmergency is a no-op for voice: the urgent-care message, including the
+// emergency number, follows via SendMessage and is spoken to the caller.
+func (r *VoiceResponder) SendEmergency(alert redflag.Alert) error {
+	return nil
+}
+
 // SendError sends error message
 func (r *VoiceResponder) SendError(message string) error {
 	r.mu.Lock()
@@ -332,34 +712,105 @@ func (h *VoiceHandler) getGoodbye(language string) string {
 	return goodbyes["en"]
 }
 
-// getUserByPhone retrieves user by phone number (assumes phone stored in users table)
-func (h *VoiceHandler) getUserByPhone(ctx context.Context, phone string) (*db.User, error) {
-	// Clean phone number (remove +1, spaces, etc.)
-	cleanPhone := strings.ReplaceAll(phone, " ", "")
-	cleanPhone = strings.ReplaceAll(cleanPhone, "-", "")
-	cleanPhone = strings.ReplaceAll(cleanPhone, "(", "")
-	cleanPhone = strings.ReplaceAll(cleanPhone, ")", "")
-
-	// Query user by phone (this assumes a phone_number column exists)
-	// For MVP, we can use email or add phone_number column
-	query := `
-		SELECT id, email, password_hash, display_name, preferred_language, 
-		       expected_delivery_date, savings_goal, is_admin, created_at, updated_at
-		FROM users
-		WHERE email = $1 OR display_name = $2
-		LIMIT 1
-	`
-
-	user := &db.User{}
-	err := h.db.QueryRowContext(ctx, query, cleanPhone, cleanPhone).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name,
-		&user.Language, &user.ExpectedDeliveryDate, &user.SavingsGoal,
-		&user.IsAdmin, &user.CreatedAt, &user.UpdatedAt,
-	)
+func (h *VoiceHandler) getCheckinIntro(language, name string) string {
+	intros := map[string]string{
+		"en": "Hello%s, this is MomLaunchpad calling for your weekly check-in.",
+		"es": "Hola%s, te llamamos de MomLaunchpad para tu seguimiento semanal.",
+	}
+	intro, ok := intros[language]
+	if !ok {
+		intro = intros["en"]
+	}
+	if name != "" {
+		name = " " + name
+	}
+	return fmt.Sprintf(intro, name)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+func (h *VoiceHandler) getCheckinPrompt(language string) string {
+	prompts := map[string]string{
+		"en": "How are you feeling this week?",
+		"es": "¿Cómo te sientes esta semana?",
+	}
+	if prompt, ok := prompts[language]; ok {
+		return prompt
 	}
+	return prompts["en"]
+}
+
+func (h *VoiceHandler) getReminderIntro(language string) string {
+	intros := map[string]string{
+		"en": "Hello, this is MomLaunchpad with a reminder.",
+		"es": "Hola, te llamamos de MomLaunchpad con un recordatorio.",
+	}
+	if intro, ok := intros[language]; ok {
+		return intro
+	}
+	return intros["en"]
+}
 
-	return user, nil
+func (h *VoiceHandler) getReminderPrompt(language string) string {
+	prompts := map[string]string{
+		"en": "Do you have any questions about it?",
+		"es": "¿Tienes alguna pregunta al respecto?",
+	}
+	if prompt, ok := prompts[language]; ok {
+		return prompt
+	}
+	return prompts["en"]
+}
+
+func (h *VoiceHandler) getPinPrompt(language string) string {
+	prompts := map[string]string{
+		"en": "Welcome to MomLaunchpad. Please key in your PIN followed by the hash key, or say it now.",
+		"es": "Bienvenida a MomLaunchpad. Marca tu PIN seguido de la tecla numeral, o dilo ahora.",
+	}
+	if prompt, ok := prompts[language]; ok {
+		return prompt
+	}
+	return prompts["en"]
+}
+
+func (h *VoiceHandler) getPinRetry(language string) string {
+	prompts := map[string]string{
+		"en": "That PIN didn't match. Please try again.",
+		"es": "Ese PIN no coincide. Inténtalo de nuevo.",
+	}
+	if prompt, ok := prompts[language]; ok {
+		return prompt
+	}
+	return prompts["en"]
+}
+
+func (h *VoiceHandler) getPinLocked(language string) string {
+	messages := map[string]string{
+		"en": "Sorry, we couldn't confirm your PIN. You can reset it in the app. Goodbye.",
+		"es": "Lo sentimos, no pudimos confirmar tu PIN. Puedes cambiarlo en la aplicación. Adiós.",
+	}
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return messages["en"]
+}
+
+func (h *VoiceHandler) getPinLockedOut(language string) string {
+	messages := map[string]string{
+		"en": "Sorry, there have been too many wrong PINs for this number. Please try again in an hour, or reset your PIN in the app.",
+		"es": "Lo sentimos, se han introducido demasiados PIN incorrectos para este número. Inténtalo de nuevo en una hora o cambia tu PIN en la aplicación.",
+	}
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return messages["en"]
+}
+
+func (h *VoiceHandler) getQuotaExceeded(language string) string {
+	messages := map[string]string{
+		"en": "You've reached your question limit for this period. Please upgrade your plan in the app or call again later.",
+		"es": "Has alcanzado tu límite de preguntas para este período. Mejora tu plan en la aplicación o vuelve a llamar más tarde.",
+	}
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return messages["en"]
 }
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// VoiceSessionTTL is how long a call's state is kept after its last turn.
+// Calls normally end with a status callback; this bounds sessions whose
+// callback never arrived.
+const VoiceSessionTTL = 2 * time.Hour
+
+// VoiceSessionStore keeps voice call state between Twilio webhooks.
+// *db.DB implements it for deployments with more than one replica;
+// MemoryVoiceSessionStore suits a single instance and tests.
+type VoiceSessionStore interface {
+	// GetVoiceSession returns db.ErrNotFound for unknown or expired calls
+	GetVoiceSession(ctx context.Context, callSid string) (*db.VoiceSession, error)
+	SaveVoiceSession(ctx context.Context, s *db.VoiceSession) error
+	DeleteVoiceSession(ctx context.Context, callSid string) error
+	DeleteExpiredVoiceSessions(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MemoryVoiceSessionStore keeps voice sessions in process
+type MemoryVoiceSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]db.VoiceSession
+	now      func() time.Time
+}
+
+// NewMemoryVoiceSessionStore creates an in-process voice session store
+func NewMemoryVoiceSessionStore() *MemoryVoiceSessionStore {
+	return &MemoryVoiceSessionStore{
+		sessions: make(map[string]db.VoiceSession),
+		now:      time.Now,
+	}
+}
+
+// GetVoiceSession returns a copy of an unexpired session
+func (m *MemoryVoiceSessionStore) GetVoiceSession(_ context.Context, callSid string) (*db.VoiceSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[callSid]
+	if !ok || !s.ExpiresAt.After(m.now()) {
+		return nil, db.ErrNotFound
+	}
+	s.Messages = append([]string(nil), s.Messages...)
+	return &s, nil
+}
+
+// SaveVoiceSession stores a copy of the session
+func (m *MemoryVoiceSessionStore) SaveVoiceSession(_ context.Context, s *db.VoiceSession) error {
+	stored := *s
+	stored.Messages = append([]string(nil), s.Messages...)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.CallSid] = stored
+	return nil
+}
+
+// DeleteVoiceSession removes a session
+func (m *MemoryVoiceSessionStore) DeleteVoiceSession(_ context.Context, callSid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, callSid)
+	return nil
+}
+
+// DeleteExpiredVoiceSessions removes sessions expired at now
+func (m *MemoryVoiceSessionStore) DeleteExpiredVoiceSessions(_ context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for sid, s := range m.sessions {
+		if !s.ExpiresAt.After(now) {
+			delete(m.sessions, sid)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SweepVoiceSessions deletes expired voice sessions every interval until ctx is done
+func SweepVoiceSessions(ctx context.Context, store VoiceSessionStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := store.DeleteExpiredVoiceSessions(ctx, time.Now())
+			if err != nil {
+				log.Printf("Voice session sweep failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Swept %d expired voice sessions", n)
+			}
+		}
+	}
+}
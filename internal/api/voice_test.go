@@ -0,0 +1,460 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/phone"
+	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func voiceForm(target string, form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestMemoryVoiceSessionStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryVoiceSessionStore()
+	store.now = func() time.Time { return now }
+
+	session := &db.VoiceSession{CallSid: "CA1", UserID: "user-1", Messages: []string{"hello"}, ExpiresAt: now.Add(time.Hour)}
+	if err := store.SaveVoiceSession(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	session.Messages[0] = "changed after save"
+
+	got, err := store.GetVoiceSession(ctx, "CA1")
+	if err != nil {
+		t.Fatalf("GetVoiceSession: %v", err)
+	}
+	if got.UserID != "user-1" || got.Messages[0] != "hello" {
+		t.Errorf("session = %+v", got)
+	}
+
+	_ = store.SaveVoiceSession(ctx, &db.VoiceSession{CallSid: "CA2", ExpiresAt: now.Add(-time.Minute)})
+	if _, err := store.GetVoiceSession(ctx, "CA2"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("expired session err = %v, want ErrNotFound", err)
+	}
+	if n, _ := store.DeleteExpiredVoiceSessions(ctx, now); n != 1 {
+		t.Errorf("swept %d sessions, want 1", n)
+	}
+	if _, err := store.GetVoiceSession(ctx, "CA1"); err != nil {
+		t.Errorf("live session swept: %v", err)
+	}
+}
+
+func TestVoiceGather_UnknownSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewVoiceHandler(nil, nil, nil, nil, NewMemoryVoiceSessionStore())
+
+	r := gin.New()
+	r.POST("/api/voice/gather", handler.HandleGather)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/gather?callSid=CA404", url.Values{"SpeechResult": {"hello"}}))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Session expired") || !strings.Contains(w.Body.String(), "<Hangup/>") {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVoiceGather_NoSpeechKeepsSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryVoiceSessionStore()
+	_ = store.SaveVoiceSession(context.Background(), &db.VoiceSession{
+		CallSid: "CA1", UserID: "user-1", Language: "es", ExpiresAt: time.Now().Add(time.Hour),
+	})
+	handler := NewVoiceHandler(nil, nil, nil, nil, store)
+
+	r := gin.New()
+	r.POST("/api/voice/gather", handler.HandleGather)
+
+	// A replica that never saw the call still finds it in the shared store
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/gather?callSid=CA1", url.Values{"CallSid": {"CA1"}}))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Polly.Lupe") || !strings.Contains(body, "<Redirect>/api/voice/gather?callSid=CA1</Redirect>") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestVoiceStatus_DeletesEndedCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	handler := NewVoiceHandler(nil, nil, database, nil, database)
+
+	r := gin.New()
+	r.POST("/api/voice/status", handler.HandleStatus)
+
+	mock.ExpectExec(`DELETE FROM voice_sessions WHERE call_sid = \$1`).
+		WithArgs("CA1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for _, status := range []string{"in-progress", "no-answer"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, voiceForm("/api/voice/status", url.Values{"CallSid": {"CA1"}, "CallStatus": {status}}))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d", status, w.Code)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeOutboundCalls struct {
+	call     *db.OutboundCall
+	answered string
+	statuses map[string]twilio.CallStatus
+}
+
+func (f *fakeOutboundCalls) Answered(_ context.Context, callID string) (*db.OutboundCall, error) {
+	if f.call == nil || f.call.ID != callID {
+		return nil, db.ErrNotFound
+	}
+	f.answered = callID
+	return f.call, nil
+}
+
+func (f *fakeOutboundCalls) Call(_ context.Context, callSid string) (*db.OutboundCall, error) {
+	if f.call == nil || f.call.CallSid == nil || *f.call.CallSid != callSid {
+		return nil, db.ErrNotFound
+	}
+	return f.call, nil
+}
+
+func (f *fakeOutboundCalls) CallStatus(_ context.Context, callSid string, status twilio.CallStatus, _ int) error {
+	if f.statuses == nil {
+		f.statuses = make(map[string]twilio.CallStatus)
+	}
+	f.statuses[callSid] = status
+	return nil
+}
+
+func TestVoiceOutbound_StartsConversation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryVoiceSessionStore()
+	name := "Ada"
+	database, mock := newMockDB(t)
+	calls := &fakeOutboundCalls{call: &db.OutboundCall{ID: "c1", UserID: "user-1", Kind: db.CallKindCheckin, Language: "en", Name: &name}}
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+	handler.EnableOutbound(calls)
+
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348000000001", time.Now(), nil, 0, nil))
+
+	r := gin.New()
+	r.POST("/api/voice/outbound", handler.HandleOutbound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/outbound?callId=c1", url.Values{"CallSid": {"CA7"}, "To": {"+2348000000001"}}))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Hello Ada, this is MomLaunchpad calling for your weekly check-in.") ||
+		!strings.Contains(body, "How are you feeling this week?") ||
+		!strings.Contains(body, `action="/api/voice/gather?callSid=CA7"`) {
+		t.Errorf("body = %s", body)
+	}
+	if calls.answered != "c1" {
+		t.Error("call not marked answered")
+	}
+	session, err := store.GetVoiceSession(context.Background(), "CA7")
+	if err != nil || session.UserID != "user-1" || session.From != "+2348000000001" || session.PinPending {
+		t.Errorf("session = %+v, err = %v", session, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoiceOutbound_AsksForPin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryVoiceSessionStore()
+	database, mock := newMockDB(t)
+	userID := "11111111-1111-1111-1111-111111111111"
+	callSid := "CA7"
+	calls := &fakeOutboundCalls{call: &db.OutboundCall{
+		ID: "c1", UserID: userID, Kind: db.CallKindReminder, Language: "en",
+		Message: "Antenatal visit at 10am", CallSid: &callSid,
+	}}
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+	handler.EnableOutbound(calls)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("4821"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPhone := func() {
+		mock.ExpectQuery(`FROM user_phones`).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+				AddRow("+2348000000001", time.Now(), string(hash), 0, nil))
+	}
+
+	r := gin.New()
+	r.POST("/api/voice/outbound", handler.HandleOutbound)
+	r.POST("/api/voice/pin", handler.HandlePin)
+
+	// Whoever picks up a shared phone hears nothing before the PIN
+	expectPhone()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/outbound?callId=c1", url.Values{"CallSid": {callSid}, "To": {"+2348000000001"}}))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `action="/api/voice/pin?callSid=CA7"`) || strings.Contains(body, "Antenatal visit") {
+		t.Errorf("body = %s", body)
+	}
+	session, err := store.GetVoiceSession(context.Background(), callSid)
+	if err != nil || !session.PinPending {
+		t.Fatalf("session = %+v, err = %v", session, err)
+	}
+
+	// The right PIN reads the reminder
+	expectPhone()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/pin?callSid=CA7", url.Values{"Digits": {"4821"}}))
+
+	body = w.Body.String()
+	if !strings.Contains(body, "Antenatal visit at 10am") || !strings.Contains(body, `action="/api/voice/gather?callSid=CA7"`) {
+		t.Errorf("pin body = %s", body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoiceOutbound_UnknownCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewVoiceHandler(nil, nil, nil, nil, NewMemoryVoiceSessionStore())
+	handler.EnableOutbound(&fakeOutboundCalls{})
+
+	r := gin.New()
+	r.POST("/api/voice/outbound", handler.HandleOutbound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/outbound?callId=missing", url.Values{"CallSid": {"CA7"}}))
+
+	if body := w.Body.String(); !strings.Contains(body, "<Hangup/>") || strings.Contains(body, "<Gather") {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestVoiceStatus_RecordsOutboundOutcome(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := &fakeOutboundCalls{}
+	handler := NewVoiceHandler(nil, nil, nil, nil, NewMemoryVoiceSessionStore())
+	handler.EnableOutbound(calls)
+
+	r := gin.New()
+	r.POST("/api/voice/status", handler.HandleStatus)
+
+	for _, status := range []string{"ringing", "no-answer"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, voiceForm("/api/voice/status", url.Values{"CallSid": {"CA7"}, "CallStatus": {status}}))
+	}
+	if calls.statuses["CA7"] != twilio.CallStatusNoAnswer || len(calls.statuses) != 1 {
+		t.Errorf("statuses = %v", calls.statuses)
+	}
+}
+
+func TestVoiceIncoming_UnverifiedNumber(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	store := NewMemoryVoiceSessionStore()
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+
+	r := gin.New()
+	r.POST("/api/voice/incoming", handler.HandleIncoming)
+
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnError(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/incoming", url.Values{"CallSid": {"CA1"}, "From": {"+2348012345678"}}))
+
+	if body := w.Body.String(); !strings.Contains(body, "verify this phone number") || !strings.Contains(body, "<Hangup/>") {
+		t.Errorf("body = %s", body)
+	}
+	if _, err := store.GetVoiceSession(context.Background(), "CA1"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("session saved for unverified caller: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoiceIncoming_AsksForPin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	store := NewMemoryVoiceSessionStore()
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	r := gin.New()
+	r.POST("/api/voice/incoming", handler.HandleIncoming)
+	r.POST("/api/voice/gather", handler.HandleGather)
+
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnRows(mockUserRows(userID, "ada@example.com"))
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348012345678", time.Now(), "$2a$04$hash", 0, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/incoming", url.Values{"CallSid": {"CA1"}, "From": {"+2348012345678"}}))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `action="/api/voice/pin?callSid=CA1" input="dtmf speech"`) || strings.Contains(body, "pregnancy support") {
+		t.Errorf("body = %s", body)
+	}
+	session, err := store.GetVoiceSession(context.Background(), "CA1")
+	if err != nil || !session.PinPending {
+		t.Fatalf("session = %+v, err = %v", session, err)
+	}
+
+	// Skipping the PIN does not reach the assistant
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/gather?callSid=CA1", url.Values{"SpeechResult": {"how many weeks am I"}}))
+	if !strings.Contains(w.Body.String(), "/api/voice/pin?callSid=CA1") {
+		t.Errorf("gather body = %s", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoiceIncoming_PinLockedOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	store := NewMemoryVoiceSessionStore()
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+	userID := "11111111-1111-1111-1111-111111111111"
+
+	r := gin.New()
+	r.POST("/api/voice/incoming", handler.HandleIncoming)
+
+	// Redialling after running out of PIN attempts doesn't allow more guesses
+	mock.ExpectQuery(`FROM user_phones WHERE phone_number = \$1`).
+		WithArgs("+2348012345678").
+		WillReturnRows(mockUserRows(userID, "ada@example.com"))
+	mock.ExpectQuery(`FROM user_phones`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+			AddRow("+2348012345678", time.Now(), "$2a$04$hash", phone.MaxPinFailures, time.Now().Add(-10*time.Minute)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, voiceForm("/api/voice/incoming", url.Values{"CallSid": {"CA1"}, "From": {"+2348012345678"}}))
+
+	if body := w.Body.String(); !strings.Contains(body, "too many wrong PINs") || strings.Contains(body, "<Gather") {
+		t.Errorf("body = %s", body)
+	}
+	if _, err := store.GetVoiceSession(context.Background(), "CA1"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("session saved for locked caller: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVoicePin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	database, mock := newMockDB(t)
+	store := NewMemoryVoiceSessionStore()
+	handler := NewVoiceHandler(nil, nil, database, nil, store)
+	userID := "11111111-1111-1111-1111-111111111111"
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("4821"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPhone := func() {
+		mock.ExpectQuery(`FROM user_phones`).
+			WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows(userPhoneColumns).
+				AddRow("+2348012345678", time.Now(), string(hash), 0, nil))
+	}
+
+	r := gin.New()
+	r.POST("/api/voice/pin", handler.HandlePin)
+	newSession := func(callSid string) {
+		_ = store.SaveVoiceSession(ctx, &db.VoiceSession{
+			CallSid: callSid, UserID: userID, Language: "en", PinPending: true, ExpiresAt: time.Now().Add(time.Hour),
+		})
+	}
+
+	t.Run("spoken PIN", func(t *testing.T) {
+		newSession("CA1")
+		expectPhone()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, voiceForm("/api/voice/pin?callSid=CA1", url.Values{"SpeechResult": {"4 8 2 1."}}))
+
+		if !strings.Contains(w.Body.String(), "pregnancy support assistant") {
+			t.Errorf("body = %s", w.Body.String())
+		}
+		if session, _ := store.GetVoiceSession(ctx, "CA1"); session == nil || session.PinPending {
+			t.Errorf("session = %+v", session)
+		}
+	})
+
+	t.Run("wrong PIN three times", func(t *testing.T) {
+		newSession("CA2")
+		for i := 1; i <= maxVoicePinAttempts; i++ {
+			expectPhone()
+			mock.ExpectQuery(`UPDATE user_phones\s+SET pin_failures`).
+				WithArgs(userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"pin_failures"}).AddRow(i))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, voiceForm("/api/voice/pin?callSid=CA2", url.Values{"Digits": {"1234"}}))
+
+			body := w.Body.String()
+			if i < maxVoicePinAttempts && !strings.Contains(body, "match. Please try again.") {
+				t.Errorf("attempt %d body = %s", i, body)
+			}
+			if i == maxVoicePinAttempts && (!strings.Contains(body, "<Hangup/>") || strings.Contains(body, "<Gather")) {
+				t.Errorf("last attempt body = %s", body)
+			}
+		}
+		if _, err := store.GetVoiceSession(ctx, "CA2"); !errors.Is(err, db.ErrNotFound) {
+			t.Errorf("session kept after failed PINs: %v", err)
+		}
+	})
+
+	t.Run("wrong PIN after failures on earlier calls", func(t *testing.T) {
+		newSession("CA3")
+		expectPhone()
+		mock.ExpectQuery(`UPDATE user_phones\s+SET pin_failures`).
+			WithArgs(userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"pin_failures"}).AddRow(phone.MaxPinFailures))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, voiceForm("/api/voice/pin?callSid=CA3", url.Values{"Digits": {"1234"}}))
+
+		if body := w.Body.String(); !strings.Contains(body, "<Hangup/>") || strings.Contains(body, "<Gather") {
+			t.Errorf("body = %s", body)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -18,12 +18,15 @@ func TestGetWelcome_ReturnsCachedMessage(t *testing.T) {
 	cacheDate := time.Now().UTC().Truncate(24 * time.Hour)
 	now := time.Now()
 
+	mock.ExpectQuery(`FROM users`).
+		WithArgs(userID).
+		WillReturnRows(mockUserRows(userID, "mom@example.com"))
 	mock.ExpectQuery(`FROM user_welcome_messages`).
 		WithArgs(userID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "cache_date", "message", "source", "created_at"}).
 			AddRow("wm-1", userID, cacheDate, "Good morning!", "gemini", now))
 
-	svc := welcome.NewService(database, nil, nil)
+	svc := welcome.NewService(database, nil)
 	handler := NewWelcomeHandler(svc)
 
 	r := ginWithUserID(userID)
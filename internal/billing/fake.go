@@ -0,0 +1,87 @@
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// FakeSignatureHeader carries the fake provider's webhook secret
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider is an in-memory payment provider for tests. Checkouts are
+// recorded and webhooks are Event JSON, accepted when FakeSignatureHeader
+// holds the secret.
+type FakeProvider struct {
+	name   string
+	secret string
+
+	mu       sync.Mutex
+	sessions []CheckoutSession
+	err      error
+}
+
+// Ensure FakeProvider implements Provider
+var _ Provider = (*FakeProvider)(nil)
+
+// NewFakeProvider creates a fake provider called name
+func NewFakeProvider(name, secret string) *FakeProvider {
+	return &FakeProvider{name: name, secret: secret}
+}
+
+// Name implements Provider.Name
+func (f *FakeProvider) Name() string { return f.name }
+
+// FailCheckouts makes CreateCheckout return err (nil to succeed again)
+func (f *FakeProvider) FailCheckouts(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// CreateCheckout implements Provider.CreateCheckout
+func (f *FakeProvider) CreateCheckout(_ context.Context, session CheckoutSession) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return "", f.err
+	}
+	f.sessions = append(f.sessions, session)
+	return fmt.Sprintf("https://checkout.example.com/%s/%s", f.name, session.Reference), nil
+}
+
+// Sessions returns the checkouts created so far
+func (f *FakeProvider) Sessions() []CheckoutSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sessions := make([]CheckoutSession, len(f.sessions))
+	copy(sessions, f.sessions)
+	return sessions
+}
+
+// ParseWebhook implements Provider.ParseWebhook
+func (f *FakeProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if f.secret == "" || header.Get(FakeSignatureHeader) != f.secret {
+		return nil, ErrInvalidSignature
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid fake event: %w", err)
+	}
+	if event.ID == "" {
+		return nil, fmt.Errorf("invalid fake event: missing id")
+	}
+	return &event, nil
+}
+
+// Webhook returns the body and header of a signed webhook for event
+func (f *FakeProvider) Webhook(event Event) ([]byte, http.Header) {
+	body, _ := json.Marshal(event)
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, f.secret)
+	return body, header
+}
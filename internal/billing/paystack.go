@@ -0,0 +1,223 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PaystackConfig holds Paystack configuration
+type PaystackConfig struct {
+	SecretKey   string // Also signs webhooks
+	CallbackURL string // Where Paystack returns the user after paying
+	BaseURL     string // Default: https://api.paystack.co
+}
+
+// Paystack sells plans through Paystack transactions. With a Paystack plan
+// code on the price the customer is subscribed and renewals are charged by
+// Paystack; without one the charge is a single payment.
+type Paystack struct {
+	config     PaystackConfig
+	httpClient *http.Client
+}
+
+// Ensure Paystack implements Provider
+var _ Provider = (*Paystack)(nil)
+
+// NewPaystack creates a Paystack provider
+func NewPaystack(config PaystackConfig) *Paystack {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.paystack.co"
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &Paystack{
+		config:     config,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implements Provider.Name
+func (p *Paystack) Name() string { return ProviderPaystack }
+
+// CreateCheckout implements Provider.CreateCheckout
+func (p *Paystack) CreateCheckout(ctx context.Context, session CheckoutSession) (string, error) {
+	if session.Email == "" {
+		return "", fmt.Errorf("paystack requires the customer's email")
+	}
+
+	request := map[string]any{
+		"email":     session.Email,
+		"amount":    session.Price.Amount,
+		"currency":  session.Price.Currency,
+		"reference": session.Reference,
+		"metadata": map[string]string{
+			"reference": session.Reference,
+			"user_id":   session.UserID,
+		},
+	}
+	if p.config.CallbackURL != "" {
+		request["callback_url"] = p.config.CallbackURL
+	}
+	if session.Price.ProviderPriceID != nil && *session.Price.ProviderPriceID != "" {
+		request["plan"] = *session.Price.ProviderPriceID
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.BaseURL+"/transaction/initialize", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.SecretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("paystack returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			AuthorizationURL string `json:"authorization_url"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !result.Status || result.Data.AuthorizationURL == "" {
+		return "", fmt.Errorf("paystack did not start the transaction: %s", result.Message)
+	}
+	return result.Data.AuthorizationURL, nil
+}
+
+// ParseWebhook implements Provider.ParseWebhook
+func (p *Paystack) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if err := p.verifySignature(header.Get("X-Paystack-Signature"), body); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Event string `json:"event"`
+		Data  struct {
+			ID               int64           `json:"id"`
+			Reference        string          `json:"reference"`
+			Amount           int64           `json:"amount"`
+			Currency         string          `json:"currency"`
+			Metadata         json.RawMessage `json:"metadata"`
+			SubscriptionCode string          `json:"subscription_code"`
+			InvoiceCode      string          `json:"invoice_code"`
+			NextPaymentDate  *time.Time      `json:"next_payment_date"`
+			Customer         struct {
+				CustomerCode string `json:"customer_code"`
+			} `json:"customer"`
+			Subscription *struct {
+				SubscriptionCode string `json:"subscription_code"`
+			} `json:"subscription"`
+			Transaction *struct {
+				Reference string `json:"reference"`
+			} `json:"transaction"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid paystack event: %w", err)
+	}
+	if payload.Event == "" {
+		return nil, fmt.Errorf("invalid paystack event: missing event")
+	}
+
+	data := payload.Data
+	event := &Event{
+		ProviderType:   payload.Event,
+		CustomerID:     data.Customer.CustomerCode,
+		SubscriptionID: data.SubscriptionCode,
+		Amount:         data.Amount,
+		Currency:       strings.ToUpper(data.Currency),
+	}
+	if data.Subscription != nil && event.SubscriptionID == "" {
+		event.SubscriptionID = data.Subscription.SubscriptionCode
+	}
+
+	// Paystack events carry no ID; key them by what they are about
+	key := data.Reference
+	switch payload.Event {
+	case "charge.success":
+		event.Type = EventPaymentSucceeded
+		event.PaymentReference = data.Reference
+		event.Reference = metadataReference(data.Metadata)
+		if event.Reference == "" {
+			event.Reference = data.Reference
+		}
+	case "invoice.payment_failed":
+		event.Type = EventPaymentFailed
+		key = data.InvoiceCode
+		event.PaymentReference = data.InvoiceCode
+		if data.Transaction != nil && data.Transaction.Reference != "" {
+			event.PaymentReference = data.Transaction.Reference
+		}
+	case "subscription.create":
+		event.Type = EventSubscriptionCreated
+		key = data.SubscriptionCode
+	case "subscription.not_renew":
+		event.Type = EventCanceled
+		key = data.SubscriptionCode
+		event.PeriodEnd = data.NextPaymentDate
+	case "subscription.disable":
+		event.Type = EventCanceled
+		key = data.SubscriptionCode
+	}
+	if key == "" {
+		key = fmt.Sprintf("%d", data.ID)
+	}
+	event.ID = payload.Event + ":" + key
+	return event, nil
+}
+
+// metadataReference reads our checkout reference from transaction metadata,
+// which Paystack may send as an object or a JSON string
+func metadataReference(raw json.RawMessage) string {
+	var metadata map[string]any
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		var encoded string
+		if json.Unmarshal(raw, &encoded) != nil || json.Unmarshal([]byte(encoded), &metadata) != nil {
+			return ""
+		}
+	}
+	reference, _ := metadata["reference"].(string)
+	return reference
+}
+
+// verifySignature checks X-Paystack-Signature, the HMAC-SHA512 of the body
+// keyed with the secret key
+func (p *Paystack) verifySignature(signature string, body []byte) error {
+	if p.config.SecretKey == "" {
+		return fmt.Errorf("%w: no secret key configured", ErrInvalidSignature)
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil || len(given) == 0 {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha512.New, []byte(p.config.SecretKey))
+	mac.Write(body)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
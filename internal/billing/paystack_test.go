@@ -0,0 +1,103 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+func paystackSignature(secret string, body []byte) string {
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestPaystack_CreateCheckout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transaction/initialize" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Email     string            `json:"email"`
+			Amount    int64             `json:"amount"`
+			Currency  string            `json:"currency"`
+			Reference string            `json:"reference"`
+			Plan      string            `json:"plan"`
+			Metadata  map[string]string `json:"metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Email != "mom@example.com" || body.Amount != 500000 || body.Currency != "NGN" ||
+			body.Reference != "mlp_ref" || body.Plan != "PLN_123" || body.Metadata["reference"] != "mlp_ref" {
+			t.Errorf("body = %+v", body)
+		}
+		_, _ = w.Write([]byte(`{"status":true,"message":"Authorization URL created","data":{"authorization_url":"https://checkout.paystack.com/abc"}}`))
+	}))
+	defer server.Close()
+
+	planCode := "PLN_123"
+	paystack := NewPaystack(PaystackConfig{SecretKey: "sk_test", BaseURL: server.URL})
+	url, err := paystack.CreateCheckout(context.Background(), CheckoutSession{
+		Reference: "mlp_ref",
+		UserID:    "user-1",
+		Email:     "mom@example.com",
+		Price:     db.PlanPrice{Currency: "NGN", Amount: 500000, ProviderPriceID: &planCode},
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckout: %v", err)
+	}
+	if url != "https://checkout.paystack.com/abc" {
+		t.Errorf("url = %q", url)
+	}
+}
+
+func TestPaystack_ParseWebhook(t *testing.T) {
+	paystack := NewPaystack(PaystackConfig{SecretKey: "sk_test"})
+
+	charge := []byte(`{"event":"charge.success","data":{"id":302961,"reference":"mlp_ref","amount":500000,
+		"currency":"NGN","metadata":{"reference":"mlp_ref","user_id":"user-1"},
+		"customer":{"customer_code":"CUS_1","email":"mom@example.com"}}}`)
+	header := http.Header{}
+	header.Set("X-Paystack-Signature", paystackSignature("sk_test", charge))
+
+	event, err := paystack.ParseWebhook(header, charge)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.ID != "charge.success:mlp_ref" || event.Type != EventPaymentSucceeded || event.Reference != "mlp_ref" ||
+		event.CustomerID != "CUS_1" || event.PaymentReference != "mlp_ref" || event.Amount != 500000 || event.Currency != "NGN" {
+		t.Errorf("event = %+v", event)
+	}
+
+	notRenew := []byte(`{"event":"subscription.not_renew","data":{"subscription_code":"SUB_1",
+		"next_payment_date":"2026-04-10T00:00:00.000Z","customer":{"customer_code":"CUS_1"}}}`)
+	header.Set("X-Paystack-Signature", paystackSignature("sk_test", notRenew))
+	event, err = paystack.ParseWebhook(header, notRenew)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventCanceled || event.SubscriptionID != "SUB_1" || event.PeriodEnd == nil || event.ID != "subscription.not_renew:SUB_1" {
+		t.Errorf("not_renew event = %+v", event)
+	}
+
+	header.Set("X-Paystack-Signature", paystackSignature("sk_other", charge))
+	if _, err := paystack.ParseWebhook(header, charge); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong key error = %v, want ErrInvalidSignature", err)
+	}
+	header.Del("X-Paystack-Signature")
+	if _, err := paystack.ParseWebhook(header, charge); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unsigned error = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Package billing sells plans through payment providers (Stripe, Paystack):
+// it starts checkouts and turns the providers' signed webhooks into
+// subscription changes, logging every event so redeliveries apply once.
+package billing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// Payment providers
+const (
+	ProviderStripe   = "stripe"
+	ProviderPaystack = "paystack"
+)
+
+// Event types, normalized across providers
+const (
+	// EventCheckoutCompleted means the user paid for a checkout
+	EventCheckoutCompleted = "checkout_completed"
+	// EventPaymentSucceeded is a paid invoice or charge, first or renewal
+	EventPaymentSucceeded = "payment_succeeded"
+	// EventPaymentFailed is a failed renewal charge
+	EventPaymentFailed = "payment_failed"
+	// EventSubscriptionCreated links a provider subscription to a customer
+	EventSubscriptionCreated = "subscription_created"
+	// EventCanceled means the subscription will not renew after PeriodEnd
+	EventCanceled = "canceled"
+)
+
+var (
+	// ErrInvalidSignature means a webhook was not signed by the provider
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownProvider means no provider with that name is configured
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrPlanUnavailable means the plan has no price with the provider
+	ErrPlanUnavailable = errors.New("plan is not available with this provider")
+)
+
+// CheckoutSession is what a provider needs to start a payment
+type CheckoutSession struct {
+	Reference string // Our checkout reference, echoed back in webhooks
+	UserID    string
+	Email     string
+	Price     db.PlanPrice
+}
+
+// Event is a provider webhook in provider-neutral form. Type is empty for
+// events billing does not act on.
+type Event struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	ProviderType     string     `json:"provider_type"` // e.g. invoice.paid
+	Reference        string     `json:"reference,omitempty"`
+	CustomerID       string     `json:"customer_id,omitempty"`
+	SubscriptionID   string     `json:"subscription_id,omitempty"`
+	PaymentReference string     `json:"payment_reference,omitempty"` // Invoice ID or transaction reference
+	Amount           int64      `json:"amount,omitempty"`
+	Currency         string     `json:"currency,omitempty"`
+	PeriodEnd        *time.Time `json:"period_end,omitempty"`
+}
+
+// Provider is a payment provider
+type Provider interface {
+	Name() string
+	// CreateCheckout starts a payment and returns the URL to send the user to
+	CreateCheckout(ctx context.Context, session CheckoutSession) (string, error)
+	// ParseWebhook verifies a webhook's signature and parses it, returning
+	// an error wrapping ErrInvalidSignature for unsigned requests
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
@@ -0,0 +1,252 @@
+package billing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// Store is the persistence billing needs (implemented by *db.DB)
+type Store interface {
+	GetPlanPrice(ctx context.Context, planCode, provider string) (*db.PlanPrice, error)
+	CreateBillingCheckout(ctx context.Context, c *db.BillingCheckout) error
+	FindBillingCheckout(ctx context.Context, provider, reference, subscriptionID, customerID string) (*db.BillingCheckout, error)
+	CompleteBillingCheckout(ctx context.Context, id, customerID, subscriptionID string) error
+	RecordBillingEvent(ctx context.Context, e *db.BillingEvent) (bool, error)
+	FinishBillingEvent(ctx context.Context, id, status string, userID *string, processErr error) error
+	CreateBillingPayment(ctx context.Context, p *db.BillingPayment) error
+}
+
+// Subscriptions changes users' plans (implemented by *subscription.Manager)
+type Subscriptions interface {
+	ActivatePlan(ctx context.Context, userID, planCode string, endsAt time.Time) error
+	CancelPlanAt(ctx context.Context, userID, planCode string, endsAt time.Time) error
+}
+
+// Service starts checkouts and applies provider webhooks
+type Service struct {
+	store         Store
+	subscriptions Subscriptions
+	providers     map[string]Provider
+	now           func() time.Time
+}
+
+// NewService creates a billing service for the given providers
+func NewService(store Store, subscriptions Subscriptions, providers ...Provider) *Service {
+	s := &Service{
+		store:         store,
+		subscriptions: subscriptions,
+		providers:     make(map[string]Provider, len(providers)),
+		now:           time.Now,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
+}
+
+// Providers returns the names of the configured providers
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckoutRequest is a user's request to buy a plan
+type CheckoutRequest struct {
+	UserID   string
+	Email    string
+	PlanCode string
+	Provider string
+}
+
+// Checkout starts a plan purchase and returns the stored checkout and the
+// provider URL to send the user to. It returns ErrUnknownProvider or
+// ErrPlanUnavailable for plans that cannot be bought that way.
+func (s *Service) Checkout(ctx context.Context, req CheckoutRequest) (*db.BillingCheckout, string, error) {
+	provider, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, "", ErrUnknownProvider
+	}
+	price, err := s.store.GetPlanPrice(ctx, req.PlanCode, req.Provider)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, "", ErrPlanUnavailable
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	reference, err := newReference()
+	if err != nil {
+		return nil, "", err
+	}
+	checkout := &db.BillingCheckout{
+		Reference: reference,
+		UserID:    req.UserID,
+		PlanCode:  price.PlanCode,
+		Provider:  req.Provider,
+		Currency:  price.Currency,
+		Amount:    price.Amount,
+		Interval:  price.Interval,
+	}
+	// Stored first so the webhook always finds it
+	if err := s.store.CreateBillingCheckout(ctx, checkout); err != nil {
+		return nil, "", err
+	}
+
+	url, err := provider.CreateCheckout(ctx, CheckoutSession{
+		Reference: reference,
+		UserID:    req.UserID,
+		Email:     req.Email,
+		Price:     *price,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start %s checkout: %w", req.Provider, err)
+	}
+	return checkout, url, nil
+}
+
+// HandleWebhook verifies and applies a provider webhook. Events already
+// applied are acknowledged without being applied again. It returns
+// ErrUnknownProvider, an error wrapping ErrInvalidSignature, or the error
+// that stopped the event being applied (so the provider retries it).
+func (s *Service) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) error {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return ErrUnknownProvider
+	}
+	event, err := provider.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	logged := &db.BillingEvent{
+		Provider: providerName,
+		EventID:  event.ID,
+		Type:     event.ProviderType,
+		Payload:  body,
+	}
+	if logged.Type == "" {
+		logged.Type = event.Type
+	}
+	pending, err := s.store.RecordBillingEvent(ctx, logged)
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return nil
+	}
+
+	userID, status, applyErr := s.apply(ctx, providerName, event)
+	if err := s.store.FinishBillingEvent(ctx, logged.ID, status, userID, applyErr); err != nil {
+		log.Printf("Failed to record outcome of %s event %s: %v", providerName, event.ID, err)
+	}
+	if status == db.BillingEventFailed {
+		return applyErr
+	}
+	return nil
+}
+
+// apply makes the subscription changes for an event, returning the user it
+// concerned and the event status to log
+func (s *Service) apply(ctx context.Context, providerName string, event *Event) (*string, string, error) {
+	if event.Type == "" {
+		return nil, db.BillingEventIgnored, nil
+	}
+
+	checkout, err := s.store.FindBillingCheckout(ctx, providerName, event.Reference, event.SubscriptionID, event.CustomerID)
+	if errors.Is(err, db.ErrNotFound) {
+		// Not started here (or not ours); retrying will not help
+		return nil, db.BillingEventIgnored, errors.New("no checkout matches the event")
+	}
+	if err != nil {
+		return nil, db.BillingEventFailed, err
+	}
+	userID := &checkout.UserID
+
+	if event.Type != EventCanceled {
+		if err := s.store.CompleteBillingCheckout(ctx, checkout.ID, event.CustomerID, event.SubscriptionID); err != nil {
+			return userID, db.BillingEventFailed, err
+		}
+	}
+
+	switch event.Type {
+	case EventCheckoutCompleted, EventPaymentSucceeded:
+		endsAt := s.periodEnd(event, checkout)
+		if err := s.subscriptions.ActivatePlan(ctx, checkout.UserID, checkout.PlanCode, endsAt); err != nil {
+			return userID, db.BillingEventFailed, err
+		}
+		if event.Type == EventPaymentSucceeded {
+			if err := s.recordPayment(ctx, providerName, event, checkout, db.PaymentSucceeded, &endsAt); err != nil {
+				return userID, db.BillingEventFailed, err
+			}
+		}
+	case EventPaymentFailed:
+		if err := s.recordPayment(ctx, providerName, event, checkout, db.PaymentFailed, nil); err != nil {
+			return userID, db.BillingEventFailed, err
+		}
+	case EventCanceled:
+		endsAt := s.now()
+		if event.PeriodEnd != nil {
+			endsAt = *event.PeriodEnd
+		}
+		if err := s.subscriptions.CancelPlanAt(ctx, checkout.UserID, checkout.PlanCode, endsAt); err != nil {
+			return userID, db.BillingEventFailed, err
+		}
+	}
+	return userID, db.BillingEventProcessed, nil
+}
+
+// periodEnd is when a payment's access ends: the provider's period end, or
+// one billing interval from now
+func (s *Service) periodEnd(event *Event, checkout *db.BillingCheckout) time.Time {
+	if event.PeriodEnd != nil {
+		return *event.PeriodEnd
+	}
+	if checkout.Interval == "year" {
+		return s.now().AddDate(1, 0, 0)
+	}
+	return s.now().AddDate(0, 1, 0)
+}
+
+func (s *Service) recordPayment(ctx context.Context, providerName string, event *Event, checkout *db.BillingCheckout, status string, periodEnd *time.Time) error {
+	reference := event.PaymentReference
+	if reference == "" {
+		reference = event.ID
+	}
+	currency := event.Currency
+	if currency == "" {
+		currency = checkout.Currency
+	}
+	return s.store.CreateBillingPayment(ctx, &db.BillingPayment{
+		UserID:            checkout.UserID,
+		CheckoutID:        &checkout.ID,
+		Provider:          providerName,
+		ProviderReference: reference,
+		PlanCode:          checkout.PlanCode,
+		Currency:          currency,
+		Amount:            event.Amount,
+		Status:            status,
+		PeriodEnd:         periodEnd,
+	})
+}
+
+// newReference returns a random checkout reference
+func newReference() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reference: %w", err)
+	}
+	return "mlp_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,263 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+type fakeStore struct {
+	prices    map[string]db.PlanPrice // provider/plan -> price
+	checkouts []*db.BillingCheckout
+	events    map[string]string // provider/event ID -> status
+	eventIDs  map[string]string // row ID -> provider/event ID
+	payments  []db.BillingPayment
+}
+
+func newFakeStore() *fakeStore {
+	priceID := "price_123"
+	return &fakeStore{
+		prices: map[string]db.PlanPrice{
+			"fake/premium": {PlanID: 2, PlanCode: "premium", Provider: "fake", Currency: "NGN", Amount: 500000, Interval: "month", ProviderPriceID: &priceID},
+		},
+		events:   make(map[string]string),
+		eventIDs: make(map[string]string),
+	}
+}
+
+func (f *fakeStore) GetPlanPrice(_ context.Context, planCode, provider string) (*db.PlanPrice, error) {
+	price, ok := f.prices[provider+"/"+planCode]
+	if !ok {
+		return nil, db.ErrNotFound
+	}
+	return &price, nil
+}
+
+func (f *fakeStore) CreateBillingCheckout(_ context.Context, c *db.BillingCheckout) error {
+	c.ID = "checkout-" + c.Reference
+	c.Status = "pending"
+	f.checkouts = append(f.checkouts, c)
+	return nil
+}
+
+func (f *fakeStore) FindBillingCheckout(_ context.Context, provider, reference, subscriptionID, customerID string) (*db.BillingCheckout, error) {
+	for _, c := range f.checkouts {
+		if c.Provider != provider {
+			continue
+		}
+		if (reference != "" && c.Reference == reference) ||
+			(subscriptionID != "" && c.ProviderSubscriptionID != nil && *c.ProviderSubscriptionID == subscriptionID) ||
+			(customerID != "" && c.ProviderCustomerID != nil && *c.ProviderCustomerID == customerID) {
+			return c, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeStore) CompleteBillingCheckout(_ context.Context, id, customerID, subscriptionID string) error {
+	for _, c := range f.checkouts {
+		if c.ID != id {
+			continue
+		}
+		c.Status = "completed"
+		if customerID != "" {
+			c.ProviderCustomerID = &customerID
+		}
+		if subscriptionID != "" {
+			c.ProviderSubscriptionID = &subscriptionID
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) RecordBillingEvent(_ context.Context, e *db.BillingEvent) (bool, error) {
+	key := e.Provider + "/" + e.EventID
+	e.ID = "event-" + key
+	f.eventIDs[e.ID] = key
+	status, seen := f.events[key]
+	if seen && status != db.BillingEventReceived && status != db.BillingEventFailed {
+		return false, nil
+	}
+	f.events[key] = db.BillingEventProcessing
+	return true, nil
+}
+
+func (f *fakeStore) FinishBillingEvent(_ context.Context, id, status string, _ *string, _ error) error {
+	f.events[f.eventIDs[id]] = status
+	return nil
+}
+
+func (f *fakeStore) CreateBillingPayment(_ context.Context, p *db.BillingPayment) error {
+	f.payments = append(f.payments, *p)
+	return nil
+}
+
+type planChange struct {
+	userID, planCode string
+	endsAt           time.Time
+	canceled         bool
+}
+
+type fakeSubscriptions struct {
+	changes []planChange
+	err     error
+}
+
+func (f *fakeSubscriptions) ActivatePlan(_ context.Context, userID, planCode string, endsAt time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.changes = append(f.changes, planChange{userID: userID, planCode: planCode, endsAt: endsAt})
+	return nil
+}
+
+func (f *fakeSubscriptions) CancelPlanAt(_ context.Context, userID, planCode string, endsAt time.Time) error {
+	f.changes = append(f.changes, planChange{userID: userID, planCode: planCode, endsAt: endsAt, canceled: true})
+	return nil
+}
+
+func newTestService() (*Service, *fakeStore, *fakeSubscriptions, *FakeProvider) {
+	store := newFakeStore()
+	subs := &fakeSubscriptions{}
+	provider := NewFakeProvider("fake", "secret")
+	svc := NewService(store, subs, provider)
+	svc.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+	return svc, store, subs, provider
+}
+
+func TestService_Checkout(t *testing.T) {
+	svc, store, _, provider := newTestService()
+
+	checkout, url, err := svc.Checkout(context.Background(), CheckoutRequest{
+		UserID: "user-1", Email: "mom@example.com", PlanCode: "premium", Provider: "fake",
+	})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if checkout.PlanCode != "premium" || checkout.Amount != 500000 || checkout.Currency != "NGN" || checkout.Reference == "" {
+		t.Errorf("checkout = %+v", checkout)
+	}
+	if url != "https://checkout.example.com/fake/"+checkout.Reference {
+		t.Errorf("url = %q", url)
+	}
+	if len(store.checkouts) != 1 {
+		t.Errorf("stored %d checkouts, want 1", len(store.checkouts))
+	}
+	sessions := provider.Sessions()
+	if len(sessions) != 1 || sessions[0].Email != "mom@example.com" || sessions[0].Reference != checkout.Reference {
+		t.Errorf("sessions = %+v", sessions)
+	}
+
+	if _, _, err := svc.Checkout(context.Background(), CheckoutRequest{UserID: "user-1", PlanCode: "free", Provider: "fake"}); !errors.Is(err, ErrPlanUnavailable) {
+		t.Errorf("free plan error = %v, want ErrPlanUnavailable", err)
+	}
+	if _, _, err := svc.Checkout(context.Background(), CheckoutRequest{UserID: "user-1", PlanCode: "premium", Provider: "paypal"}); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("unknown provider error = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestService_HandleWebhook_Lifecycle(t *testing.T) {
+	svc, store, subs, provider := newTestService()
+	ctx := context.Background()
+
+	checkout, _, err := svc.Checkout(ctx, CheckoutRequest{UserID: "user-1", Email: "mom@example.com", PlanCode: "premium", Provider: "fake"})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	// First payment activates for one interval and links the customer
+	body, header := provider.Webhook(Event{
+		ID: "evt-1", Type: EventPaymentSucceeded, Reference: checkout.Reference,
+		CustomerID: "CUS_1", PaymentReference: "txn-1", Amount: 500000, Currency: "NGN",
+	})
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	firstEnd := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	if len(subs.changes) != 1 || subs.changes[0] != (planChange{userID: "user-1", planCode: "premium", endsAt: firstEnd}) {
+		t.Fatalf("changes = %+v", subs.changes)
+	}
+	if len(store.payments) != 1 || store.payments[0].Status != db.PaymentSucceeded || store.payments[0].ProviderReference != "txn-1" {
+		t.Errorf("payments = %+v", store.payments)
+	}
+
+	// Redelivery is acknowledged but not applied again
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if len(subs.changes) != 1 || len(store.payments) != 1 {
+		t.Errorf("redelivery applied again: %d changes, %d payments", len(subs.changes), len(store.payments))
+	}
+
+	// A renewal is matched by customer and extends to the provider's period end
+	renewedEnd := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	body, header = provider.Webhook(Event{
+		ID: "evt-2", Type: EventPaymentSucceeded, CustomerID: "CUS_1",
+		PaymentReference: "txn-2", Amount: 500000, PeriodEnd: &renewedEnd,
+	})
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Fatalf("renewal: %v", err)
+	}
+	if len(subs.changes) != 2 || !subs.changes[1].endsAt.Equal(renewedEnd) {
+		t.Errorf("renewal changes = %+v", subs.changes)
+	}
+
+	// Cancellation ends the plan at the period end
+	body, header = provider.Webhook(Event{ID: "evt-3", Type: EventCanceled, CustomerID: "CUS_1", PeriodEnd: &renewedEnd})
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if last := subs.changes[len(subs.changes)-1]; !last.canceled || !last.endsAt.Equal(renewedEnd) {
+		t.Errorf("cancel change = %+v", last)
+	}
+}
+
+func TestService_HandleWebhook_Rejections(t *testing.T) {
+	svc, store, subs, provider := newTestService()
+	ctx := context.Background()
+
+	body, header := provider.Webhook(Event{ID: "evt-1", Type: EventPaymentSucceeded, Reference: "unknown"})
+	header.Set(FakeSignatureHeader, "forged")
+	if err := svc.HandleWebhook(ctx, "fake", header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("forged signature error = %v, want ErrInvalidSignature", err)
+	}
+	if err := svc.HandleWebhook(ctx, "paypal", header, body); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("unknown provider error = %v, want ErrUnknownProvider", err)
+	}
+
+	// Events for checkouts started elsewhere are logged and ignored
+	body, header = provider.Webhook(Event{ID: "evt-2", Type: EventPaymentSucceeded, Reference: "unknown"})
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Errorf("unmatched event error = %v, want nil", err)
+	}
+	if store.events["fake/evt-2"] != db.BillingEventIgnored || len(subs.changes) != 0 {
+		t.Errorf("unmatched event status = %q, changes = %+v", store.events["fake/evt-2"], subs.changes)
+	}
+}
+
+func TestService_HandleWebhook_RetriesFailures(t *testing.T) {
+	svc, store, subs, provider := newTestService()
+	ctx := context.Background()
+
+	checkout, _, _ := svc.Checkout(ctx, CheckoutRequest{UserID: "user-1", PlanCode: "premium", Provider: "fake"})
+	body, header := provider.Webhook(Event{ID: "evt-1", Type: EventCheckoutCompleted, Reference: checkout.Reference})
+
+	subs.err = errors.New("database down")
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err == nil {
+		t.Fatal("expected the failure to be returned so the provider retries")
+	}
+	if store.events["fake/evt-1"] != db.BillingEventFailed {
+		t.Errorf("status = %q, want failed", store.events["fake/evt-1"])
+	}
+
+	subs.err = nil
+	if err := svc.HandleWebhook(ctx, "fake", header, body); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(subs.changes) != 1 || store.events["fake/evt-1"] != db.BillingEventProcessed {
+		t.Errorf("retry not applied: changes = %+v, status = %q", subs.changes, store.events["fake/evt-1"])
+	}
+}
@@ -0,0 +1,262 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stripeSignatureTolerance is how old a signed webhook may be
+const stripeSignatureTolerance = 5 * time.Minute
+
+// StripeConfig holds Stripe configuration
+type StripeConfig struct {
+	SecretKey     string
+	WebhookSecret string // whsec_... signing secret of the webhook endpoint
+	SuccessURL    string // Where Checkout returns the user after paying
+	CancelURL     string
+	BaseURL       string // Default: https://api.stripe.com
+}
+
+// Stripe sells plans as Stripe Checkout subscriptions. Plan prices need a
+// Stripe price ID.
+type Stripe struct {
+	config     StripeConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// Ensure Stripe implements Provider
+var _ Provider = (*Stripe)(nil)
+
+// NewStripe creates a Stripe provider
+func NewStripe(config StripeConfig) *Stripe {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.stripe.com"
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &Stripe{
+		config:     config,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Name implements Provider.Name
+func (s *Stripe) Name() string { return ProviderStripe }
+
+// CreateCheckout implements Provider.CreateCheckout
+func (s *Stripe) CreateCheckout(ctx context.Context, session CheckoutSession) (string, error) {
+	if session.Price.ProviderPriceID == nil || *session.Price.ProviderPriceID == "" {
+		return "", fmt.Errorf("%w: no Stripe price ID for %s", ErrPlanUnavailable, session.Price.PlanCode)
+	}
+
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", *session.Price.ProviderPriceID)
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("client_reference_id", session.Reference)
+	form.Set("metadata[reference]", session.Reference)
+	form.Set("subscription_data[metadata][reference]", session.Reference)
+	form.Set("success_url", s.config.SuccessURL)
+	form.Set("cancel_url", s.config.CancelURL)
+	if session.Email != "" {
+		form.Set("customer_email", session.Email)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.config.BaseURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", session.Reference)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("stripe returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.URL == "" {
+		return "", fmt.Errorf("stripe returned no checkout URL")
+	}
+	return result.URL, nil
+}
+
+// stripeObject holds the fields billing reads from the checkout sessions,
+// invoices and subscriptions in Stripe events
+type stripeObject struct {
+	ID                string            `json:"id"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Customer          string            `json:"customer"`
+	Subscription      string            `json:"subscription"`
+	Metadata          map[string]string `json:"metadata"`
+	Currency          string            `json:"currency"`
+	AmountPaid        int64             `json:"amount_paid"`
+	AmountDue         int64             `json:"amount_due"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	CancelAt          int64             `json:"cancel_at"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	EndedAt           int64             `json:"ended_at"`
+	Lines             struct {
+		Data []struct {
+			Period struct {
+				End int64 `json:"end"`
+			} `json:"period"`
+		} `json:"data"`
+	} `json:"lines"`
+	SubscriptionDetails *struct {
+		Metadata map[string]string `json:"metadata"`
+	} `json:"subscription_details"`
+	Parent *struct {
+		SubscriptionDetails *struct {
+			Subscription string            `json:"subscription"`
+			Metadata     map[string]string `json:"metadata"`
+		} `json:"subscription_details"`
+	} `json:"parent"`
+}
+
+// ParseWebhook implements Provider.ParseWebhook
+func (s *Stripe) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if err := s.verifySignature(header.Get("Stripe-Signature"), body); err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripeObject `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+	if payload.ID == "" {
+		return nil, fmt.Errorf("invalid stripe event: missing id")
+	}
+
+	obj := payload.Data.Object
+	event := &Event{
+		ID:           payload.ID,
+		ProviderType: payload.Type,
+		CustomerID:   obj.Customer,
+		Currency:     strings.ToUpper(obj.Currency),
+	}
+
+	switch payload.Type {
+	case "checkout.session.completed":
+		event.Type = EventCheckoutCompleted
+		event.Reference = obj.ClientReferenceID
+		event.SubscriptionID = obj.Subscription
+	case "invoice.paid", "invoice.payment_failed":
+		event.Type = EventPaymentSucceeded
+		event.Amount = obj.AmountPaid
+		if payload.Type == "invoice.payment_failed" {
+			event.Type = EventPaymentFailed
+			event.Amount = obj.AmountDue
+		}
+		event.PaymentReference = obj.ID
+		event.SubscriptionID = obj.Subscription
+		if obj.SubscriptionDetails != nil {
+			event.Reference = obj.SubscriptionDetails.Metadata["reference"]
+		}
+		if obj.Parent != nil && obj.Parent.SubscriptionDetails != nil {
+			if event.SubscriptionID == "" {
+				event.SubscriptionID = obj.Parent.SubscriptionDetails.Subscription
+			}
+			if event.Reference == "" {
+				event.Reference = obj.Parent.SubscriptionDetails.Metadata["reference"]
+			}
+		}
+		var end int64
+		for _, line := range obj.Lines.Data {
+			if line.Period.End > end {
+				end = line.Period.End
+			}
+		}
+		event.PeriodEnd = unixTime(end)
+	case "customer.subscription.updated", "customer.subscription.deleted":
+		event.SubscriptionID = obj.ID
+		event.Reference = obj.Metadata["reference"]
+		switch {
+		case payload.Type == "customer.subscription.deleted":
+			event.Type = EventCanceled
+			event.PeriodEnd = unixTime(obj.EndedAt)
+		case obj.CancelAtPeriodEnd:
+			event.Type = EventCanceled
+			event.PeriodEnd = unixTime(obj.CurrentPeriodEnd)
+		case obj.CancelAt > 0:
+			event.Type = EventCanceled
+			event.PeriodEnd = unixTime(obj.CancelAt)
+		}
+	}
+	return event, nil
+}
+
+// verifySignature checks a Stripe-Signature header ("t=...,v1=...")
+func (s *Stripe) verifySignature(header string, body []byte) error {
+	if s.config.WebhookSecret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrInvalidSignature)
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed Stripe-Signature", ErrInvalidSignature)
+	}
+	if age := s.now().Sub(time.Unix(seconds, 0)); age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.config.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if given, err := hex.DecodeString(signature); err == nil && hmac.Equal(given, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// unixTime converts a Stripe timestamp, returning nil for zero
+func unixTime(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
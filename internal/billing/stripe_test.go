@@ -0,0 +1,113 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+func stripeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestStripe_CreateCheckout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/checkout/sessions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("mode") != "subscription" || r.PostForm.Get("line_items[0][price]") != "price_123" ||
+			r.PostForm.Get("client_reference_id") != "mlp_ref" ||
+			r.PostForm.Get("subscription_data[metadata][reference]") != "mlp_ref" ||
+			r.PostForm.Get("customer_email") != "mom@example.com" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		_, _ = w.Write([]byte(`{"id":"cs_1","url":"https://checkout.stripe.com/c/pay/cs_1"}`))
+	}))
+	defer server.Close()
+
+	priceID := "price_123"
+	stripe := NewStripe(StripeConfig{SecretKey: "sk_test", BaseURL: server.URL})
+	url, err := stripe.CreateCheckout(context.Background(), CheckoutSession{
+		Reference: "mlp_ref",
+		Email:     "mom@example.com",
+		Price:     db.PlanPrice{PlanCode: "premium", ProviderPriceID: &priceID},
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckout: %v", err)
+	}
+	if url != "https://checkout.stripe.com/c/pay/cs_1" {
+		t.Errorf("url = %q", url)
+	}
+
+	if _, err := stripe.CreateCheckout(context.Background(), CheckoutSession{Price: db.PlanPrice{PlanCode: "premium"}}); !errors.Is(err, ErrPlanUnavailable) {
+		t.Errorf("missing price ID error = %v, want ErrPlanUnavailable", err)
+	}
+}
+
+func TestStripe_ParseWebhook(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	stripe := NewStripe(StripeConfig{WebhookSecret: "whsec_test"})
+	stripe.now = func() time.Time { return now }
+
+	invoice := []byte(`{"id":"evt_1","type":"invoice.paid","data":{"object":{
+		"id":"in_1","customer":"cus_1","currency":"usd","amount_paid":999,
+		"parent":{"subscription_details":{"subscription":"sub_1","metadata":{"reference":"mlp_ref"}}},
+		"lines":{"data":[{"period":{"end":1775822400}}]}}}}`)
+	header := http.Header{}
+	header.Set("Stripe-Signature", stripeSignature("whsec_test", now.Unix(), invoice))
+
+	event, err := stripe.ParseWebhook(header, invoice)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.ID != "evt_1" || event.Type != EventPaymentSucceeded || event.Reference != "mlp_ref" ||
+		event.SubscriptionID != "sub_1" || event.CustomerID != "cus_1" || event.PaymentReference != "in_1" ||
+		event.Amount != 999 || event.Currency != "USD" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.PeriodEnd == nil || !event.PeriodEnd.Equal(time.Unix(1775822400, 0)) {
+		t.Errorf("period end = %v", event.PeriodEnd)
+	}
+
+	canceled := []byte(`{"id":"evt_2","type":"customer.subscription.updated","data":{"object":{
+		"id":"sub_1","customer":"cus_1","cancel_at_period_end":true,"current_period_end":1775822400}}}`)
+	header.Set("Stripe-Signature", stripeSignature("whsec_test", now.Unix(), canceled))
+	event, err = stripe.ParseWebhook(header, canceled)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventCanceled || event.SubscriptionID != "sub_1" || event.PeriodEnd == nil {
+		t.Errorf("cancel event = %+v", event)
+	}
+
+	cases := map[string]string{
+		"wrong secret": stripeSignature("whsec_other", now.Unix(), invoice),
+		"stale":        stripeSignature("whsec_test", now.Add(-10*time.Minute).Unix(), invoice),
+		"malformed":    "v1=abc",
+		"missing":      "",
+	}
+	for name, signature := range cases {
+		header.Set("Stripe-Signature", signature)
+		if _, err := stripe.ParseWebhook(header, invoice); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: error = %v, want ErrInvalidSignature", name, err)
+		}
+	}
+}
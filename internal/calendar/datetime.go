@@ -0,0 +1,329 @@
+package calendar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTime is a date and/or time of day found in a message
+type DateTime struct {
+	Time    time.Time // In the location it was resolved in; midnight if !HasTime
+	HasDate bool
+	HasTime bool
+}
+
+// Default hours for parts of the day mentioned without a time
+const (
+	morningHour   = 9
+	afternoonHour = 15
+	eveningHour   = 19
+)
+
+// ExtractDateTime finds a date and/or time of day in an English or Spanish
+// message, such as "next Tuesday at 3pm", "in two weeks" or "mañana a las 9",
+// resolved relative to now in loc. ok is false if the message has neither.
+//
+// A weekday means its next occurrence after today ("this" weekday allows
+// today), and a time of day alone means the next time it comes round.
+func ExtractDateTime(message string, now time.Time, loc *time.Location) (dt DateTime, ok bool) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	text := normalizeText(message)
+
+	// "in 2 hours" is an exact time
+	if m := relativeDurationPattern.FindStringSubmatch(text); m != nil {
+		if n, ok := parseNumber(m[1]); ok {
+			d := time.Duration(n) * time.Hour
+			if strings.HasPrefix(m[2], "min") {
+				d = time.Duration(n) * time.Minute
+			}
+			return DateTime{Time: now.Add(d).Truncate(time.Minute), HasDate: true, HasTime: true}, true
+		}
+	}
+
+	date, hasDate, text := extractDate(text, now)
+	// "at 20 weeks" is a gestational age, not 8pm
+	text = lengthPattern.ReplaceAllString(text, " ")
+	hour, minute, hasTime := extractTime(text)
+
+	if !hasDate && !hasTime {
+		return DateTime{}, false
+	}
+	if !hasDate {
+		y, m, d := now.Date()
+		t := time.Date(y, m, d, hour, minute, 0, 0, loc)
+		if !t.After(now) {
+			t = time.Date(y, m, d+1, hour, minute, 0, 0, loc)
+		}
+		return DateTime{Time: t, HasTime: true}, true
+	}
+
+	y, m, d := date.Date()
+	return DateTime{Time: time.Date(y, m, d, hour, minute, 0, 0, loc), HasDate: true, HasTime: hasTime}, true
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"¿", " ", "?", " ", "¡", " ", "!", " ", ",", " ", ";", " ",
+)
+
+// normalizeText lowercases and strips accents and punctuation, keeping the
+// characters used in times and dates (":", ".", "/", "-")
+func normalizeText(s string) string {
+	s = accentReplacer.Replace(strings.ToLower(s))
+	return " " + strings.Join(strings.Fields(s), " ") + " "
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"un": 1, "una": 1, "uno": 1, "dos": 2, "tres": 3, "cuatro": 4, "cinco": 5, "seis": 6,
+	"siete": 7, "ocho": 8, "nueve": 9, "diez": 10, "once": 11, "doce": 12,
+}
+
+const numberPattern = `(\d{1,3}|a|an|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|un|una|uno|dos|tres|cuatro|cinco|seis|siete|ocho|nueve|diez|once|doce)`
+
+func parseNumber(s string) (int, bool) {
+	if n, ok := numberWords[s]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n > 0
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"domingo": time.Sunday, "lunes": time.Monday, "martes": time.Tuesday, "miercoles": time.Wednesday,
+	"jueves": time.Thursday, "viernes": time.Friday, "sabado": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September, "sept": time.September, "oct": time.October,
+	"nov": time.November, "dec": time.December,
+	"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+	"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+	"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+	"noviembre": time.November, "diciembre": time.December,
+}
+
+const (
+	weekdayPattern = `(sunday|monday|tuesday|wednesday|thursday|friday|saturday|domingo|lunes|martes|miercoles|jueves|viernes|sabado)`
+	monthPattern   = `(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec|enero|febrero|marzo|abril|mayo|junio|julio|agosto|septiembre|setiembre|octubre|noviembre|diciembre)`
+)
+
+var (
+	relativeDurationPattern = regexp.MustCompile(` (?:in|en|dentro de) ` + numberPattern + ` (hours?|horas?|minutes?|mins?|minutos?) `)
+	relativeDatePattern     = regexp.MustCompile(` (?:in|en|dentro de) ` + numberPattern + ` (days?|dias?|weeks?|semanas?|months?|mes|meses) `)
+
+	lengthPattern = regexp.MustCompile(` \d{1,3} (?:days?|dias?|weeks?|semanas?|months?|mes|meses) `)
+
+	dayAfterTomorrowPattern = regexp.MustCompile(` (?:the )?day after tomorrow | pasado manana `)
+	tomorrowPattern         = regexp.MustCompile(` tomorrow `)
+	// "mañana" is tomorrow unless it is "la mañana" (the morning)
+	mananaPattern      = regexp.MustCompile(`( la)? manana `)
+	todayPattern       = regexp.MustCompile(` today | hoy `)
+	tonightPattern     = regexp.MustCompile(` tonight | esta noche `)
+	nextWeekPattern    = regexp.MustCompile(` next week | (?:la )?semana que viene | (?:la )?proxima semana | (?:la )?semana proxima `)
+	nextMonthPattern   = regexp.MustCompile(` next month | (?:el )?mes que viene | (?:el )?proximo mes `)
+	weekdayDatePattern = regexp.MustCompile(`(?: (this|next|este|proximo|el proximo))? ` + weekdayPattern + `( after next| que viene| proximo)? `)
+	dayMonthPattern    = regexp.MustCompile(` (\d{1,2})(?:st|nd|rd|th)? (?:of |de )?` + monthPattern + `(?: (?:de )?(\d{4}))? `)
+	monthDayPattern    = regexp.MustCompile(` ` + monthPattern + ` (\d{1,2})(?:st|nd|rd|th)?(?: (\d{4}))? `)
+	ordinalDayPattern  = regexp.MustCompile(` (?:on )?the (\d{1,2})(?:st|nd|rd|th) | el dia (\d{1,2}) `)
+	isoDatePattern     = regexp.MustCompile(` (\d{4})-(\d{2})-(\d{2}) `)
+)
+
+// extractDate returns the date mentioned in text (normalized), if any, and
+// text with a Spanish "mañana" meaning tomorrow removed so it is not taken
+// for the morning
+func extractDate(text string, now time.Time) (time.Time, bool, string) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	if match := isoDatePattern.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		if t, ok := validDate(year, time.Month(month), day, now.Location()); ok {
+			return t, true, text
+		}
+	}
+	if match := dayMonthPattern.FindStringSubmatch(text); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		if t, ok := resolveMonthDay(today, months[match[2]], day, match[3]); ok {
+			return t, true, text
+		}
+	}
+	if match := monthDayPattern.FindStringSubmatch(text); match != nil {
+		day, _ := strconv.Atoi(match[2])
+		if t, ok := resolveMonthDay(today, months[match[1]], day, match[3]); ok {
+			return t, true, text
+		}
+	}
+	if match := relativeDatePattern.FindStringSubmatch(text); match != nil {
+		if n, ok := parseNumber(match[1]); ok {
+			switch unit := match[2]; {
+			case strings.HasPrefix(unit, "day") || strings.HasPrefix(unit, "dia"):
+				return today.AddDate(0, 0, n), true, text
+			case strings.HasPrefix(unit, "week") || strings.HasPrefix(unit, "semana"):
+				return today.AddDate(0, 0, 7*n), true, text
+			default:
+				return today.AddDate(0, n, 0), true, text
+			}
+		}
+	}
+	if dayAfterTomorrowPattern.MatchString(text) {
+		return today.AddDate(0, 0, 2), true, text
+	}
+	if tomorrowPattern.MatchString(text) {
+		return today.AddDate(0, 0, 1), true, text
+	}
+	for _, match := range mananaPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[2] < 0 { // Not "la mañana"
+			return today.AddDate(0, 0, 1), true, text[:match[0]] + " " + text[match[1]:]
+		}
+	}
+	if match := weekdayDatePattern.FindStringSubmatch(text); match != nil {
+		days := (int(weekdays[match[2]]) - int(today.Weekday()) + 7) % 7
+		if days == 0 && match[1] != "this" && match[1] != "este" {
+			days = 7
+		}
+		if match[3] == " after next" {
+			days += 7
+		}
+		return today.AddDate(0, 0, days), true, text
+	}
+	if match := ordinalDayPattern.FindStringSubmatch(text); match != nil {
+		day, _ := strconv.Atoi(match[1] + match[2])
+		t, ok := validDate(today.Year(), today.Month(), day, now.Location())
+		if ok && t.Before(today) {
+			t, ok = validDate(today.Year(), today.Month()+1, day, now.Location())
+		}
+		if ok {
+			return t, true, text
+		}
+	}
+	if nextWeekPattern.MatchString(text) {
+		return today.AddDate(0, 0, 7), true, text
+	}
+	if nextMonthPattern.MatchString(text) {
+		return today.AddDate(0, 1, 0), true, text
+	}
+	if todayPattern.MatchString(text) || tonightPattern.MatchString(text) {
+		return today, true, text
+	}
+	return time.Time{}, false, text
+}
+
+// resolveMonthDay returns day/month in the given year, or the next one on or
+// after today if no year was given
+func resolveMonthDay(today time.Time, month time.Month, day int, year string) (time.Time, bool) {
+	if year != "" {
+		y, _ := strconv.Atoi(year)
+		return validDate(y, month, day, today.Location())
+	}
+	t, ok := validDate(today.Year(), month, day, today.Location())
+	if ok && t.Before(today) {
+		t, ok = validDate(today.Year()+1, month, day, today.Location())
+	}
+	return t, ok
+}
+
+// validDate rejects days that do not exist in the month (e.g. 31 April)
+func validDate(year int, month time.Month, day int, loc *time.Location) (time.Time, bool) {
+	if month > time.December {
+		year, month = year+1, month-12
+	}
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return t, day >= 1 && t.Month() == month
+}
+
+var (
+	meridiemPattern   = regexp.MustCompile(` (?:at |a las |a la )?(\d{1,2})(?:[:.](\d{2}))? ?(am|pm|a\.m\.|p\.m\.) `)
+	spanishDayPattern = regexp.MustCompile(` (?:a las |a la )?(\d{1,2})(?:[:.](\d{2}))? (?:de la|en la|por la) (manana|tarde|noche) `)
+	clockPattern      = regexp.MustCompile(` (?:at |a las |a la )?(\d{1,2})[:h](\d{2})(?: ?h| hrs| horas)? `)
+	atHourPattern     = regexp.MustCompile(` (?:at|a las|a la) (\d{1,2})(?: o'?clock)?(?: (in the morning|in the afternoon|in the evening|at night))? `)
+	noonPattern       = regexp.MustCompile(` noon | midday | mediodia `)
+	midnightPattern   = regexp.MustCompile(` midnight | medianoche `)
+	morningPattern    = regexp.MustCompile(` (?:in the )?morning | (?:por|en|de) la manana `)
+	afternoonPattern  = regexp.MustCompile(` (?:in the )?afternoon | (?:por|en|de) la tarde `)
+	eveningPattern    = regexp.MustCompile(` (?:in the )?evening | tonight | (?:at )?night | (?:por|en|de) la noche | esta noche `)
+)
+
+// extractTime returns the time of day mentioned in text (normalized), if any
+func extractTime(text string) (hour, minute int, ok bool) {
+	if m := meridiemPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+		if hour >= 1 && hour <= 12 && minute < 60 {
+			hour %= 12
+			if strings.HasPrefix(m[3], "p") {
+				hour += 12
+			}
+			return hour, minute, true
+		}
+	}
+	if m := spanishDayPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+		if hour <= 12 && minute < 60 {
+			return withPartOfDay(hour, m[3] != "manana"), minute, true
+		}
+	}
+	if m := clockPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+		if hour < 24 && minute < 60 {
+			return hour, minute, true
+		}
+	}
+	if m := atHourPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		if hour <= 23 {
+			switch {
+			case m[2] != "":
+				return withPartOfDay(hour, m[2] != "in the morning"), 0, true
+			case hour >= 1 && hour <= 7:
+				// "at 3" means the afternoon, not 3am
+				return hour + 12, 0, true
+			default:
+				return hour, 0, true
+			}
+		}
+	}
+	switch {
+	case noonPattern.MatchString(text):
+		return 12, 0, true
+	case midnightPattern.MatchString(text):
+		return 0, 0, true
+	case morningPattern.MatchString(text):
+		return morningHour, 0, true
+	case afternoonPattern.MatchString(text):
+		return afternoonHour, 0, true
+	case eveningPattern.MatchString(text):
+		return eveningHour, 0, true
+	}
+	return 0, 0, false
+}
+
+// withPartOfDay converts a 12-hour clock hour to 24 hours
+func withPartOfDay(hour int, pm bool) int {
+	if hour == 12 {
+		if pm {
+			return 12
+		}
+		return 0
+	}
+	if pm && hour < 12 {
+		return hour + 12
+	}
+	return hour
+}
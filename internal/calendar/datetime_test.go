@@ -0,0 +1,96 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractDateTime(t *testing.T) {
+	loc, err := time.LoadLocation("Africa/Lagos")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, loc) // Wednesday
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		message  string
+		want     time.Time
+		wantDate bool
+		wantTime bool
+	}{
+		// English
+		{"I have a scan next Tuesday at 3pm", at(3, 10, 15, 0), true, true},
+		{"doctor appointment tomorrow", at(3, 5, 0, 0), true, false},
+		{"checkup the day after tomorrow at 10:30", at(3, 6, 10, 30), true, true},
+		{"follow-up visit in two weeks", at(3, 18, 0, 0), true, false},
+		{"next appointment in 3 days", at(3, 7, 0, 0), true, false},
+		{"call the midwife in 2 hours", at(3, 4, 12, 0), true, true},
+		{"glucose test on Friday morning", at(3, 6, 9, 0), true, true},
+		{"see the doctor on Wednesday", at(3, 11, 0, 0), true, false},
+		{"scan the Thursday after next", at(3, 12, 0, 0), true, false},
+		{"anomaly scan this Wednesday at 4 pm", at(3, 4, 16, 0), true, true},
+		{"my appointment is on March 20th at 2:15pm", at(3, 20, 14, 15), true, true},
+		{"booked for the 5th of February", time.Date(2027, 2, 5, 0, 0, 0, 0, loc), true, false},
+		{"clinic visit on 2026-04-01 at 08:00", at(4, 1, 8, 0), true, true},
+		{"appointment at 9", at(3, 5, 9, 0), false, true},
+		{"appointment at 3", at(3, 4, 15, 0), false, true},
+		{"come back tonight", at(3, 4, 19, 0), true, true},
+		{"see you at noon", at(3, 4, 12, 0), false, true},
+		{"scan next week", at(3, 11, 0, 0), true, false},
+		// Spanish
+		{"mañana a las 9", at(3, 5, 9, 0), true, true},
+		{"Tengo cita el martes a las 3 de la tarde", at(3, 10, 15, 0), true, true},
+		{"ecografía pasado mañana por la mañana", at(3, 6, 9, 0), true, true},
+		{"control prenatal dentro de dos semanas", at(3, 18, 0, 0), true, false},
+		{"la cita es en 3 días", at(3, 7, 0, 0), true, false},
+		{"el viernes que viene a las 10:30", at(3, 6, 10, 30), true, true},
+		{"vacuna el 20 de marzo", at(3, 20, 0, 0), true, false},
+		{"análisis de sangre el miércoles", at(3, 11, 0, 0), true, false},
+		{"la próxima semana", at(3, 11, 0, 0), true, false},
+		{"hoy a las 8 de la noche", at(3, 4, 20, 0), true, true},
+		{"a mediodía", at(3, 4, 12, 0), false, true},
+		{"por la tarde", at(3, 4, 15, 0), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got, ok := ExtractDateTime(tt.message, now, loc)
+			if !ok {
+				t.Fatal("expected a date or time")
+			}
+			if !got.Time.Equal(tt.want) {
+				t.Errorf("Time = %v, want %v", got.Time, tt.want)
+			}
+			if got.HasDate != tt.wantDate || got.HasTime != tt.wantTime {
+				t.Errorf("HasDate, HasTime = %v, %v, want %v, %v", got.HasDate, got.HasTime, tt.wantDate, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestExtractDateTime_NoDate(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	for _, message := range []string{
+		"I have a doctor appointment",
+		"I'm 36 weeks pregnant",
+		"what happens at 20 weeks?",
+		"tengo una cita con la matrona",
+		"is it normal to feel tired in the third trimester?",
+		"on February 30th",
+	} {
+		if got, ok := ExtractDateTime(message, now, time.UTC); ok {
+			t.Errorf("%q: got %+v, want nothing", message, got)
+		}
+	}
+}
+
+func TestExtractDateTime_PastTimeRollsOver(t *testing.T) {
+	now := time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)
+	got, ok := ExtractDateTime("appointment at 9am", now, time.UTC)
+	if !ok || !got.Time.Equal(time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v, want 9am tomorrow", got)
+	}
+}
@@ -0,0 +1,118 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+// ErrInvalidEdit is returned when the changes to a suggestion being accepted
+// are invalid
+var ErrInvalidEdit = errors.New("invalid suggestion edit")
+
+// maxTitleLength matches calendar_suggestions.title and reminders.title
+const maxTitleLength = 255
+
+// SuggestionStore is the persistence the resolver needs (implemented by *db.DB)
+type SuggestionStore interface {
+	GetCalendarSuggestion(ctx context.Context, userID, id string) (*db.CalendarSuggestion, error)
+	AcceptCalendarSuggestion(ctx context.Context, userID, id string, reminder *db.Reminder, edited bool) error
+	DismissCalendarSuggestion(ctx context.Context, userID, id string) error
+}
+
+// Edit holds the changes a user makes to a suggestion before accepting it;
+// nil fields keep the suggested value
+type Edit struct {
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	ReminderTime *time.Time `json:"reminder_time"`
+	Timezone     *string    `json:"timezone"`
+}
+
+// Resolver accepts and dismisses stored suggestions for REST and WebSocket
+// clients alike
+type Resolver struct {
+	store SuggestionStore
+}
+
+// NewResolver creates a suggestion resolver
+func NewResolver(store SuggestionStore) *Resolver {
+	return &Resolver{store: store}
+}
+
+// Accept turns the user's pending suggestion into a reminder, with edit
+// applied. It returns db.ErrNotFound, db.ErrSuggestionResolved or an error
+// wrapping ErrInvalidEdit.
+func (r *Resolver) Accept(ctx context.Context, userID, id string, edit Edit) (*db.Reminder, error) {
+	suggestion, err := r.store.GetCalendarSuggestion(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != db.SuggestionPending {
+		return nil, db.ErrSuggestionResolved
+	}
+
+	reminder := &db.Reminder{
+		UserID:       userID,
+		Title:        suggestion.Title,
+		Description:  suggestion.Description,
+		ReminderTime: suggestion.SuggestedTime.UTC(),
+		Timezone:     suggestion.Timezone,
+		Source:       db.ReminderSourceUser,
+	}
+	edited, err := applyEdit(reminder, edit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.store.AcceptCalendarSuggestion(ctx, userID, id, reminder, edited); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// Dismiss marks the user's pending suggestion dismissed
+func (r *Resolver) Dismiss(ctx context.Context, userID, id string) error {
+	return r.store.DismissCalendarSuggestion(ctx, userID, id)
+}
+
+// applyEdit applies edit to reminder and reports whether anything changed
+func applyEdit(reminder *db.Reminder, edit Edit) (bool, error) {
+	edited := false
+	if edit.Title != nil {
+		title := strings.TrimSpace(*edit.Title)
+		if title == "" || len(title) > maxTitleLength {
+			return false, fmt.Errorf("%w: title must be 1-%d characters", ErrInvalidEdit, maxTitleLength)
+		}
+		edited = edited || title != reminder.Title
+		reminder.Title = title
+	}
+	if edit.Description != nil {
+		edited = edited || reminder.Description == nil || *edit.Description != *reminder.Description
+		description := *edit.Description
+		reminder.Description = &description
+	}
+	if edit.ReminderTime != nil {
+		if edit.ReminderTime.IsZero() {
+			return false, fmt.Errorf("%w: reminder_time is required", ErrInvalidEdit)
+		}
+		at := edit.ReminderTime.UTC()
+		edited = edited || !at.Equal(reminder.ReminderTime)
+		reminder.ReminderTime = at
+	}
+	if edit.Timezone != nil {
+		if *edit.Timezone == "" {
+			return false, fmt.Errorf("%w: timezone is required", ErrInvalidEdit)
+		}
+		if _, err := time.LoadLocation(*edit.Timezone); err != nil {
+			return false, fmt.Errorf("%w: unknown timezone %q", ErrInvalidEdit, *edit.Timezone)
+		}
+		edited = edited || *edit.Timezone != reminder.Timezone
+		reminder.Timezone = *edit.Timezone
+	}
+	return edited, nil
+}
@@ -1,10 +1,15 @@
 package calendar
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/classifier"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
 // SuggestionResult represents the decision on whether to suggest a reminder
@@ -13,17 +18,42 @@ type SuggestionResult struct {
 	Priority      string // "urgent", "high", "medium", "low"
 }
 
+// Where a suggestion's time came from
+const (
+	TimeSourceMessage = "message" // Found by the rules
+	TimeSourceLLM     = "llm"     // Found by the LLM
+	TimeSourceDefault = "default" // Nothing found; a default offset
+)
+
 // Suggestion represents a calendar reminder suggestion
 type Suggestion struct {
+	ID            string    `json:"id,omitempty"` // Set once stored; used to accept or dismiss it
 	Type          string    `json:"type"`
 	Title         string    `json:"title"`
 	Description   string    `json:"description"`
 	SuggestedTime time.Time `json:"suggested_time"`
+	Timezone      string    `json:"timezone"`
+	VisitType     string    `json:"visit_type,omitempty"`
+	Location      string    `json:"location,omitempty"`
+	Provider      string    `json:"provider,omitempty"`
+	TimeSource    string    `json:"time_source"`
 }
 
+// Locale is the user's language and IANA time zone; suggestion times are
+// resolved in the time zone (UTC if empty or unknown)
+type Locale struct {
+	Language string
+	Timezone string
+}
+
+// defaultHour is the time of day used when a message gives a date but no time
+const defaultHour = 9
+
 // Suggester handles calendar reminder suggestions
 type Suggester struct {
 	urgentKeywords []string
+	llmClient      llm.Client // Optional; resolves dates the rules miss
+	now            func() time.Time
 }
 
 // NewSuggester creates a new calendar suggester
@@ -33,9 +63,17 @@ func NewSuggester() *Suggester {
 			"severe", "bleeding", "emergency", "urgent",
 			"intense pain", "can't breathe", "contractions",
 		},
+		now: time.Now,
 	}
 }
 
+// WithLLM lets the suggester ask client for the date and time of scheduling
+// messages the rules cannot read
+func (s *Suggester) WithLLM(client llm.Client) *Suggester {
+	s.llmClient = client
+	return s
+}
+
 // ShouldSuggest determines if a calendar reminder should be suggested
 func (s *Suggester) ShouldSuggest(intent classifier.Intent, message string) SuggestionResult {
 	// Only suggest for symptoms and scheduling
@@ -64,26 +102,254 @@ func (s *Suggester) ShouldSuggest(intent classifier.Intent, message string) Sugg
 	}
 }
 
-// BuildSuggestion creates a calendar suggestion based on intent and message
-func (s *Suggester) BuildSuggestion(intent classifier.Intent, message string) Suggestion {
-	now := time.Now()
+// BuildSuggestion creates a calendar suggestion based on intent and message,
+// at the date and time the message mentions, if any, in the user's time zone
+func (s *Suggester) BuildSuggestion(ctx context.Context, intent classifier.Intent, message string, locale Locale) Suggestion {
+	loc, err := time.LoadLocation(locale.Timezone)
+	if err != nil || locale.Timezone == "" {
+		loc = time.UTC
+	}
+	lang := "en"
+	if strings.HasPrefix(strings.ToLower(locale.Language), "es") {
+		lang = "es"
+	}
+	now := s.now().In(loc)
 
 	switch intent {
 	case classifier.IntentSymptom:
-		return Suggestion{
+		suggestion := Suggestion{
 			Type:          "symptom_followup",
-			Title:         "Follow up on symptom",
-			Description:   "Check if the symptom persists or improves",
+			Title:         text(lang, "Follow up on symptom", "Seguimiento del síntoma"),
+			Description:   text(lang, "Check if the symptom persists or improves", "Comprueba si el síntoma continúa o mejora"),
 			SuggestedTime: now.Add(24 * time.Hour), // Tomorrow
+			Timezone:      loc.String(),
+			TimeSource:    TimeSourceDefault,
 		}
+		if at, ok := ExtractDateTime(message, now, loc); ok {
+			suggestion.SuggestedTime = withDefaultHour(at)
+			suggestion.TimeSource = TimeSourceMessage
+		}
+		return suggestion
 	case classifier.IntentScheduling:
-		return Suggestion{
+		visit := detectVisitType(message)
+		suggestion := Suggestion{
 			Type:          "appointment",
-			Title:         "Appointment reminder",
-			Description:   message,
+			Title:         visit.title[lang],
+			VisitType:     visit.key,
+			Location:      extractPlace(message),
+			Provider:      extractProvider(message),
 			SuggestedTime: now.Add(1 * time.Hour), // Default to 1 hour from now
+			Timezone:      loc.String(),
+			TimeSource:    TimeSourceDefault,
+		}
+		if at, ok := ExtractDateTime(message, now, loc); ok {
+			suggestion.SuggestedTime = withDefaultHour(at)
+			suggestion.TimeSource = TimeSourceMessage
+		} else if s.llmClient != nil {
+			if at, err := s.extractLLM(ctx, message, now); err == nil {
+				suggestion.SuggestedTime = withDefaultHour(at)
+				suggestion.TimeSource = TimeSourceLLM
+			}
 		}
+		suggestion.Title, suggestion.Description = describeVisit(lang, suggestion.Title, suggestion.Provider, suggestion.Location)
+		return suggestion
 	default:
 		return Suggestion{}
 	}
 }
+
+// withDefaultHour sets a date mentioned without a time to defaultHour
+func withDefaultHour(at DateTime) time.Time {
+	if at.HasTime {
+		return at.Time
+	}
+	return at.Time.Add(defaultHour * time.Hour)
+}
+
+func text(lang, en, es string) string {
+	if lang == "es" {
+		return es
+	}
+	return en
+}
+
+// describeVisit returns the suggestion title ("Ultrasound scan at City
+// Clinic") and a description naming who and where
+func describeVisit(lang, title, provider, place string) (string, string) {
+	var parts []string
+	if provider != "" {
+		parts = append(parts, text(lang, "With ", "Con ")+provider)
+	}
+	if place != "" {
+		title += text(lang, " at ", " en ") + place
+		parts = append(parts, text(lang, "At ", "En ")+place)
+	} else if provider != "" {
+		title += text(lang, " with ", " con ") + provider
+	}
+	return title, strings.Join(parts, ". ")
+}
+
+type visitType struct {
+	key      string
+	keywords []string
+	title    map[string]string
+}
+
+// visitTypes is checked in order; the first matching visit type wins
+var visitTypes = []visitType{
+	{"ultrasound", []string{"ultrasound", "scan", "sonogram", "anomaly scan", "ecografia", "ultrasonido", "eco"},
+		map[string]string{"en": "Ultrasound scan", "es": "Ecografía"}},
+	{"glucose_test", []string{"glucose", "gtt", "gestational diabetes", "glucosa", "curva de glucosa"},
+		map[string]string{"en": "Glucose test", "es": "Prueba de glucosa"}},
+	{"blood_test", []string{"blood test", "blood work", "bloodwork", "labs", "lab test", "analisis de sangre", "analitica", "laboratorio"},
+		map[string]string{"en": "Blood test", "es": "Análisis de sangre"}},
+	{"vaccination", []string{"vaccine", "vaccination", "immunization", "shot", "jab", "tdap", "vacuna", "vacunacion"},
+		map[string]string{"en": "Vaccination", "es": "Vacunación"}},
+	{"prenatal_checkup", []string{"checkup", "check-up", "check up", "prenatal", "antenatal", "anc", "control prenatal", "consulta prenatal", "revision", "chequeo"},
+		map[string]string{"en": "Prenatal checkup", "es": "Control prenatal"}},
+	{"postnatal_checkup", []string{"postnatal", "postpartum", "posparto", "postparto", "cuarentena"},
+		map[string]string{"en": "Postnatal checkup", "es": "Control posparto"}},
+	{"doctor_visit", []string{"doctor", "dr", "obgyn", "ob-gyn", "gynecologist", "midwife", "medico", "medica", "doctora", "dra", "ginecologo", "ginecologa", "matrona", "partera"},
+		map[string]string{"en": "Doctor's appointment", "es": "Cita médica"}},
+}
+
+var defaultVisit = visitType{"appointment", nil, map[string]string{"en": "Appointment", "es": "Cita"}}
+
+func detectVisitType(message string) visitType {
+	text := normalizeText(strings.ReplaceAll(message, ".", " "))
+	for _, v := range visitTypes {
+		for _, keyword := range v.keywords {
+			if strings.Contains(text, " "+keyword+" ") {
+				return v
+			}
+		}
+	}
+	return defaultVisit
+}
+
+var (
+	// A capitalised name around a facility word: "City Clinic", "Clínica San José"
+	placePattern = regexp.MustCompile(`((?:\p{Lu}[\p{L}'’.&-]*\s+){0,4})` +
+		`(?i:(clinic|hospital|cl[ií]nica|health cent(?:er|re)|medical cent(?:er|re)|centro de salud|centro m[eé]dico|maternity|maternidad|sanatorio))` +
+		`((?:\s+(?:de\s+|del\s+|la\s+)?\p{Lu}[\p{L}'’.&-]*){0,4})`)
+	providerPattern = regexp.MustCompile(`(?i:\b(dr|dra|doctor|doctora|midwife|matrona)\b\.?)\s+(\p{Lu}[\p{L}'’-]+(?:\s+\p{Lu}[\p{L}'’-]+)?)`)
+)
+
+// placeStopWords are capitalised words before a facility name that are not
+// part of it ("At City Clinic", "My Hospital")
+var placeStopWords = map[string]bool{
+	"at": true, "the": true, "to": true, "in": true, "my": true, "a": true,
+	"en": true, "la": true, "el": true, "al": true, "mi": true,
+}
+
+// extractPlace returns the clinic or hospital named in message, if any
+func extractPlace(message string) string {
+	m := placePattern.FindStringSubmatch(message)
+	if m == nil {
+		return ""
+	}
+	before := strings.Fields(m[1])
+	for len(before) > 0 && placeStopWords[strings.ToLower(before[0])] {
+		before = before[1:]
+	}
+	after := strings.TrimSpace(m[3])
+	if len(before) == 0 && after == "" {
+		return "" // An unnamed "the clinic"
+	}
+	return strings.Join(append(before, m[2]), " ") + strings.TrimRight(" "+after, " ")
+}
+
+// extractProvider returns the doctor or midwife named in message, if any
+func extractProvider(message string) string {
+	m := providerPattern.FindStringSubmatch(message)
+	if m == nil {
+		return ""
+	}
+	title := map[string]string{"dr": "Dr.", "doctor": "Dr.", "dra": "Dra.", "doctora": "Dra.", "midwife": "Midwife", "matrona": "Matrona"}[strings.ToLower(m[1])]
+	return title + " " + m[2]
+}
+
+// extractLLM asks the LLM for the date and time a scheduling message refers to
+func (s *Suggester) extractLLM(ctx context.Context, message string, now time.Time) (DateTime, error) {
+	ctx, cancel := context.WithTimeout(llm.WithTask(ctx, llm.TaskSchedule), 5*time.Second)
+	defer cancel()
+
+	resp, err := s.llmClient.ChatCompletion(ctx, llm.ChatRequest{
+		Messages: []llm.ChatMessage{
+			{Role: "user", Content: buildSchedulePrompt(message, now)},
+		},
+		MaxTokens:   60,
+		Temperature: 0,
+	})
+	if err != nil {
+		return DateTime{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return DateTime{}, fmt.Errorf("empty schedule extraction response")
+	}
+
+	content := strings.TrimSpace(resp.Choices[0].Message.Content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var parsed struct {
+		Date string `json:"date"`
+		Time string `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &parsed); err != nil {
+		return DateTime{}, fmt.Errorf("invalid schedule extraction JSON: %w", err)
+	}
+	return parseLLMDateTime(parsed.Date, parsed.Time, now)
+}
+
+// maxLLMHorizon bounds how far ahead an LLM-read date may be
+const maxLLMHorizon = 366 * 24 * time.Hour
+
+// parseLLMDateTime validates the LLM's date ("YYYY-MM-DD") and time ("HH:MM"),
+// either of which may be empty, as a time after now in now's location
+func parseLLMDateTime(date, clock string, now time.Time) (DateTime, error) {
+	loc := now.Location()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	dt := DateTime{Time: today}
+	if date != "" {
+		t, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return DateTime{}, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		dt.Time, dt.HasDate = t, true
+	}
+	if clock != "" {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return DateTime{}, fmt.Errorf("invalid time %q: %w", clock, err)
+		}
+		y, m, d := dt.Time.Date()
+		dt.Time, dt.HasTime = time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc), true
+		if !dt.HasDate && !dt.Time.After(now) {
+			dt.Time = dt.Time.AddDate(0, 0, 1)
+		}
+	}
+	switch {
+	case !dt.HasDate && !dt.HasTime:
+		return DateTime{}, fmt.Errorf("no date or time found")
+	case dt.Time.Before(today):
+		return DateTime{}, fmt.Errorf("date %s is in the past", date)
+	case dt.Time.After(now.Add(maxLLMHorizon)):
+		return DateTime{}, fmt.Errorf("date %s is too far ahead", date)
+	}
+	return dt, nil
+}
+
+func buildSchedulePrompt(message string, now time.Time) string {
+	return fmt.Sprintf(`Find the date and time of the appointment or event in this message (English or Spanish).
+
+Now: %s (%s, time zone %s)
+Message: %q
+
+Resolve relative dates ("next Tuesday", "in two weeks", "pasado mañana") against now.
+Respond ONLY with JSON: {"date": "YYYY-MM-DD", "time": "HH:MM"}
+Use "" for a date or time the message does not give.`,
+		now.Format("2006-01-02 15:04"), now.Weekday(), now.Location(), message)
+}
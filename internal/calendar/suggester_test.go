@@ -1,9 +1,14 @@
 package calendar
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/classifier"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
 func TestSuggester_ShouldSuggest(t *testing.T) {
@@ -95,7 +100,7 @@ func TestSuggester_BuildSuggestion(t *testing.T) {
 	suggester := NewSuggester()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			suggestion := suggester.BuildSuggestion(tt.intent, tt.message)
+			suggestion := suggester.BuildSuggestion(context.Background(), tt.intent, tt.message, Locale{})
 			if suggestion.Type != tt.wantType {
 				t.Errorf("Type = %v, want %v", suggestion.Type, tt.wantType)
 			}
@@ -108,3 +113,177 @@ func TestSuggester_BuildSuggestion(t *testing.T) {
 		})
 	}
 }
+
+type fakeLLM struct {
+	content string
+	calls   int
+	task    llm.Task
+}
+
+func (f *fakeLLM) StreamChatCompletion(context.Context, llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeLLM) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	f.calls++
+	f.task = llm.TaskFromContext(ctx)
+	body, _ := json.Marshal(map[string]any{
+		"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": f.content}}},
+	})
+	var resp llm.ChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func newTestSuggester(now time.Time) *Suggester {
+	s := NewSuggester()
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestSuggester_BuildSuggestion_ResolvesMessage(t *testing.T) {
+	lagos, err := time.LoadLocation("Africa/Lagos")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	mexico, err := time.LoadLocation("America/Mexico_City")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC) // Wednesday
+	s := newTestSuggester(now)
+
+	tests := []struct {
+		name         string
+		message      string
+		locale       Locale
+		wantTitle    string
+		wantVisit    string
+		wantLocation string
+		wantProvider string
+		wantTime     time.Time
+		wantTimezone string
+		wantSource   string
+	}{
+		{
+			name:         "english scan with clinic",
+			message:      "I have an ultrasound at City Clinic next Tuesday at 3pm",
+			locale:       Locale{Language: "en", Timezone: "Africa/Lagos"},
+			wantTitle:    "Ultrasound scan at City Clinic",
+			wantVisit:    "ultrasound",
+			wantLocation: "City Clinic",
+			wantTime:     time.Date(2026, 3, 10, 15, 0, 0, 0, lagos),
+			wantTimezone: "Africa/Lagos",
+			wantSource:   TimeSourceMessage,
+		},
+		{
+			name:         "english doctor with date only",
+			message:      "Appointment with Dr. Okafor in two weeks",
+			locale:       Locale{Language: "en"},
+			wantTitle:    "Doctor's appointment with Dr. Okafor",
+			wantVisit:    "doctor_visit",
+			wantProvider: "Dr. Okafor",
+			wantTime:     time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC),
+			wantTimezone: "UTC",
+			wantSource:   TimeSourceMessage,
+		},
+		{
+			name:         "spanish checkup at hospital",
+			message:      "Tengo control prenatal mañana a las 9 en el Hospital San José",
+			locale:       Locale{Language: "es", Timezone: "America/Mexico_City"},
+			wantTitle:    "Control prenatal en Hospital San José",
+			wantVisit:    "prenatal_checkup",
+			wantLocation: "Hospital San José",
+			wantTime:     time.Date(2026, 3, 5, 9, 0, 0, 0, mexico), // 03:00 on the 4th in Mexico City
+			wantTimezone: "America/Mexico_City",
+			wantSource:   TimeSourceMessage,
+		},
+		{
+			name:         "spanish vaccine with doctor",
+			message:      "La vacuna con la doctora Pérez es el viernes por la tarde",
+			locale:       Locale{Language: "es-MX", Timezone: "America/Mexico_City"},
+			wantTitle:    "Vacunación con Dra. Pérez",
+			wantVisit:    "vaccination",
+			wantProvider: "Dra. Pérez",
+			wantTime:     time.Date(2026, 3, 6, 15, 0, 0, 0, mexico),
+			wantTimezone: "America/Mexico_City",
+			wantSource:   TimeSourceMessage,
+		},
+		{
+			name:         "no date falls back to default",
+			message:      "I need to book a blood test",
+			locale:       Locale{Language: "en", Timezone: "Not/AZone"},
+			wantTitle:    "Blood test",
+			wantVisit:    "blood_test",
+			wantTime:     now.Add(time.Hour),
+			wantTimezone: "UTC",
+			wantSource:   TimeSourceDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.BuildSuggestion(context.Background(), classifier.IntentScheduling, tt.message, tt.locale)
+			if got.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if got.VisitType != tt.wantVisit || got.Location != tt.wantLocation || got.Provider != tt.wantProvider {
+				t.Errorf("visit, location, provider = %q, %q, %q", got.VisitType, got.Location, got.Provider)
+			}
+			if !got.SuggestedTime.Equal(tt.wantTime) {
+				t.Errorf("SuggestedTime = %v, want %v", got.SuggestedTime, tt.wantTime)
+			}
+			if got.Timezone != tt.wantTimezone || got.TimeSource != tt.wantSource {
+				t.Errorf("Timezone, TimeSource = %q, %q", got.Timezone, got.TimeSource)
+			}
+			if got.Description == tt.message {
+				t.Error("description should not copy the raw message")
+			}
+		})
+	}
+}
+
+func TestSuggester_BuildSuggestion_SymptomFollowUp(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	s := newTestSuggester(now)
+
+	got := s.BuildSuggestion(context.Background(), classifier.IntentSymptom, "me duele la cabeza, recuérdame esta noche", Locale{Language: "es"})
+	if got.Title != "Seguimiento del síntoma" {
+		t.Errorf("Title = %q", got.Title)
+	}
+	if want := time.Date(2026, 3, 4, 19, 0, 0, 0, time.UTC); !got.SuggestedTime.Equal(want) {
+		t.Errorf("SuggestedTime = %v, want %v", got.SuggestedTime, want)
+	}
+}
+
+func TestSuggester_BuildSuggestion_LLMAssist(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	client := &fakeLLM{content: "```json\n{\"date\": \"2026-03-12\", \"time\": \"14:30\"}\n```"}
+	s := newTestSuggester(now).WithLLM(client)
+	got := s.BuildSuggestion(context.Background(), classifier.IntentScheduling, "my scan is at the end of the month", Locale{})
+	if got.TimeSource != TimeSourceLLM || !got.SuggestedTime.Equal(time.Date(2026, 3, 12, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("got %v from %s", got.SuggestedTime, got.TimeSource)
+	}
+	if client.task != llm.TaskSchedule {
+		t.Errorf("task = %q, want %q", client.task, llm.TaskSchedule)
+	}
+
+	// The rules answer first; the LLM is not asked
+	client.calls = 0
+	got = s.BuildSuggestion(context.Background(), classifier.IntentScheduling, "scan tomorrow at 10am", Locale{})
+	if client.calls != 0 || got.TimeSource != TimeSourceMessage {
+		t.Errorf("LLM calls = %d, source = %s", client.calls, got.TimeSource)
+	}
+
+	// Past or malformed answers are ignored
+	for _, content := range []string{`{"date": "2025-01-01", "time": ""}`, `{"date": "", "time": ""}`, "not json"} {
+		client.content = content
+		got = s.BuildSuggestion(context.Background(), classifier.IntentScheduling, "my scan is soon", Locale{})
+		if got.TimeSource != TimeSourceDefault {
+			t.Errorf("%q: source = %s, want default", content, got.TimeSource)
+		}
+	}
+}
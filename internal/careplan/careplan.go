@@ -0,0 +1,152 @@
+// Package careplan generates the standard care schedule for a user's journey
+// stage (antenatal contacts, screening tests, vaccines, postnatal checks) as
+// reminders, from configurable WHO and country templates.
+package careplan
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/profile"
+)
+
+// reminderHour is the time of day, in the user's timezone, care plan
+// reminders are set for
+const reminderHour = 9
+
+// gestationDays is the length of a pregnancy from LMP to the due date
+const gestationDays = 280
+
+// Store is the persistence the generator needs (implemented by *db.DB)
+type Store interface {
+	SyncCarePlanReminders(ctx context.Context, userID string, reminders []db.Reminder, now time.Time) error
+}
+
+// Generator creates care plan reminders
+type Generator struct {
+	store     Store
+	templates []Template
+	now       func() time.Time
+}
+
+// NewGenerator creates a generator using templates (see DefaultTemplates)
+func NewGenerator(store Store, templates []Template) *Generator {
+	return &Generator{
+		store:     store,
+		templates: templates,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Generate replaces the user's upcoming care plan reminders with the plan
+// for their current stage. It is idempotent: reminders are keyed by template
+// item, so running it again updates them in place. Completed and past care
+// plan reminders are kept.
+func (g *Generator) Generate(ctx context.Context, user *db.User) error {
+	now := g.now()
+	return g.store.SyncCarePlanReminders(ctx, user.ID, g.Plan(user, now), now)
+}
+
+// Plan returns the care plan reminders after now for the user's stage, or
+// none if the stage has no template or its anchor date is unknown
+func (g *Generator) Plan(user *db.User, now time.Time) []db.Reminder {
+	if user.JourneyStage == nil {
+		return nil
+	}
+	stage := *user.JourneyStage
+	loc := user.Location()
+	anchor, ok := anchorDate(user, stage, now.In(loc))
+	if !ok {
+		return nil
+	}
+	country := ""
+	if user.CountryCode != nil {
+		country = strings.ToUpper(*user.CountryCode)
+	}
+	template, ok := g.selectTemplate(stage, country)
+	if !ok {
+		return nil
+	}
+
+	reminders := make([]db.Reminder, 0, len(template.Items))
+	for _, item := range template.Items {
+		at := time.Date(anchor.Year(), anchor.Month(), anchor.Day()+item.Week*7+item.Day, reminderHour, 0, 0, 0, loc).UTC()
+		if !at.After(now) {
+			continue
+		}
+		key := stage + "." + item.Key
+		description := localized(item.Description, user.Language)
+		reminders = append(reminders, db.Reminder{
+			UserID:       user.ID,
+			Title:        localized(item.Title, user.Language),
+			Description:  &description,
+			ReminderTime: at,
+			Timezone:     loc.String(),
+			Source:       db.ReminderSourceCarePlan,
+			CarePlanItem: &key,
+		})
+	}
+	return reminders
+}
+
+// selectTemplate returns the stage's template for country, falling back to
+// the default
+func (g *Generator) selectTemplate(stage, country string) (Template, bool) {
+	var fallback *Template
+	for i := range g.templates {
+		t := &g.templates[i]
+		if t.Stage != stage {
+			continue
+		}
+		if country != "" && strings.EqualFold(t.Country, country) {
+			return *t, true
+		}
+		if t.Country == "" && fallback == nil {
+			fallback = t
+		}
+	}
+	if fallback == nil {
+		return Template{}, false
+	}
+	return *fallback, true
+}
+
+// anchorDate returns the date the stage's schedule counts from, as midnight
+// UTC of that calendar date
+func anchorDate(user *db.User, stage string, now time.Time) (time.Time, bool) {
+	var anchor time.Time
+	switch stage {
+	case profile.StagePregnant:
+		switch {
+		case user.ExpectedDeliveryDate != nil:
+			anchor = user.ExpectedDeliveryDate.AddDate(0, 0, -gestationDays)
+		case user.PregnancyStartDate != nil:
+			anchor = *user.PregnancyStartDate
+		default:
+			return time.Time{}, false
+		}
+	case profile.StagePostpartum:
+		if user.BabyBirthDate == nil {
+			return time.Time{}, false
+		}
+		anchor = *user.BabyBirthDate
+	case profile.StageTTC:
+		anchor = now
+		if user.JourneyStageSince != nil {
+			anchor = *user.JourneyStageSince
+		}
+	default:
+		return time.Time{}, false
+	}
+	y, m, d := anchor.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+}
+
+func localized(text map[string]string, language string) string {
+	if s, ok := text[language]; ok && s != "" {
+		return s
+	}
+	return text["en"]
+}
@@ -0,0 +1,202 @@
+package careplan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/profile"
+)
+
+type fakeStore struct {
+	userID    string
+	reminders []db.Reminder
+}
+
+func (f *fakeStore) SyncCarePlanReminders(_ context.Context, userID string, reminders []db.Reminder, _ time.Time) error {
+	f.userID = userID
+	f.reminders = reminders
+	return nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func planKeys(reminders []db.Reminder) map[string]time.Time {
+	keys := make(map[string]time.Time, len(reminders))
+	for _, r := range reminders {
+		keys[*r.CarePlanItem] = r.ReminderTime
+	}
+	return keys
+}
+
+func TestPlan_Pregnant(t *testing.T) {
+	g := NewGenerator(nil, DefaultTemplates())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	edd := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) // LMP 2025-12-25: week 9 now
+	user := &db.User{ID: "user-1", Language: "en", JourneyStage: strPtr(profile.StagePregnant), ExpectedDeliveryDate: &edd}
+
+	plan := g.Plan(user, now)
+	keys := planKeys(plan)
+
+	lmp := edd.AddDate(0, 0, -gestationDays)
+	want := map[string]int{
+		"pregnant.booking_visit":  12,
+		"pregnant.anomaly_scan":   20,
+		"pregnant.glucose_test":   26,
+		"pregnant.tdap_vaccine":   28,
+		"pregnant.gbs_swab":       36,
+		"pregnant.anc_contact_40": 40,
+	}
+	for key, week := range want {
+		at, ok := keys[key]
+		if !ok {
+			t.Errorf("plan is missing %s", key)
+			continue
+		}
+		if expected := lmp.AddDate(0, 0, week*7).Add(9 * time.Hour); !at.Equal(expected) {
+			t.Errorf("%s at %v, want %v", key, at, expected)
+		}
+	}
+	if len(plan) != 12 {
+		t.Errorf("len(plan) = %d, want the 12 WHO items", len(plan))
+	}
+	for _, r := range plan {
+		if r.Source != db.ReminderSourceCarePlan || r.UserID != "user-1" || r.Title == "" {
+			t.Errorf("reminder = %+v", r)
+		}
+	}
+}
+
+func TestPlan_UsesUserTimezone(t *testing.T) {
+	g := NewGenerator(nil, DefaultTemplates())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	edd := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	user := &db.User{ID: "user-1", Language: "en", Timezone: "Africa/Lagos", JourneyStage: strPtr(profile.StagePregnant), ExpectedDeliveryDate: &edd}
+
+	plan := g.Plan(user, now)
+	if len(plan) == 0 {
+		t.Fatal("empty plan")
+	}
+	// 09:00 in Lagos (UTC+1) on the booking visit date
+	lmp := edd.AddDate(0, 0, -gestationDays)
+	want := lmp.AddDate(0, 0, 12*7).Add(8 * time.Hour)
+	if at := planKeys(plan)["pregnant.booking_visit"]; !at.Equal(want) {
+		t.Errorf("booking visit at %v, want %v", at, want)
+	}
+	for _, r := range plan {
+		if r.Timezone != "Africa/Lagos" {
+			t.Errorf("%s timezone = %q, want Africa/Lagos", *r.CarePlanItem, r.Timezone)
+		}
+	}
+}
+
+func TestPlan_SkipsPastItemsAndUsesCountryAndLanguage(t *testing.T) {
+	g := NewGenerator(nil, DefaultTemplates())
+	edd := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := edd.AddDate(0, 0, -10*7) // Week 30
+	user := &db.User{
+		ID: "user-1", Language: "es", CountryCode: strPtr("us"),
+		JourneyStage: strPtr(profile.StagePregnant), ExpectedDeliveryDate: &edd,
+	}
+
+	plan := g.Plan(user, now)
+	keys := planKeys(plan)
+
+	if _, ok := keys["pregnant.anomaly_scan"]; ok {
+		t.Error("past anomaly scan should be skipped")
+	}
+	if _, ok := keys["pregnant.anc_contact_39"]; !ok {
+		t.Error("US plan should include the week 39 visit")
+	}
+	for _, r := range plan {
+		if *r.CarePlanItem == "pregnant.gbs_swab" && r.Title != "Prueba de estreptococo del grupo B (36–37 semanas)" {
+			t.Errorf("title = %q, want Spanish", r.Title)
+		}
+	}
+}
+
+func TestPlan_PostpartumAndUnknownStages(t *testing.T) {
+	g := NewGenerator(nil, DefaultTemplates())
+	birth := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	now := birth.AddDate(0, 0, 5)
+
+	keys := planKeys(g.Plan(&db.User{ID: "u", JourneyStage: strPtr(profile.StagePostpartum), BabyBirthDate: &birth}, now))
+	if _, ok := keys["postpartum.postnatal_check_day3"]; ok {
+		t.Error("day 3 check is already past")
+	}
+	if at := keys["postpartum.postnatal_check_week6"]; !at.Equal(birth.AddDate(0, 0, 42).Add(9 * time.Hour)) {
+		t.Errorf("six-week check at %v", at)
+	}
+
+	for _, user := range []*db.User{
+		{ID: "u"},
+		{ID: "u", JourneyStage: strPtr(profile.StageMiscarriage)},
+		{ID: "u", JourneyStage: strPtr(profile.StagePregnant)},
+		{ID: "u", JourneyStage: strPtr(profile.StagePostpartum)},
+	} {
+		if plan := g.Plan(user, now); len(plan) != 0 {
+			t.Errorf("stage %v: plan = %d reminders, want none", user.JourneyStage, len(plan))
+		}
+	}
+}
+
+func TestGenerate_SyncsPlan(t *testing.T) {
+	store := &fakeStore{}
+	g := NewGenerator(store, DefaultTemplates())
+	g.now = func() time.Time { return time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC) }
+
+	user := &db.User{ID: "user-1", JourneyStage: strPtr(profile.StageTTC), JourneyStageSince: timePtr(time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC))}
+	if err := g.Generate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	if store.userID != "user-1" || len(store.reminders) != 3 {
+		t.Fatalf("synced %d reminders for %q", len(store.reminders), store.userID)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "templates.json")
+	custom := `[{"stage": "Pregnant", "country": "NG", "source": "FMoH", "items": [
+		{"key": "booking_visit", "week": 10, "title": {"en": "Booking visit"}}
+	]}]`
+	if err := os.WriteFile(path, []byte(custom), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadTemplates(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := Merge(DefaultTemplates(), templates)
+	if len(merged) != len(DefaultTemplates())+1 {
+		t.Fatalf("len(merged) = %d", len(merged))
+	}
+
+	g := NewGenerator(nil, merged)
+	edd := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	plan := g.Plan(&db.User{ID: "u", CountryCode: strPtr("NG"), JourneyStage: strPtr("pregnant"), ExpectedDeliveryDate: &edd}, edd.AddDate(0, -9, 0))
+	if len(plan) != 1 || plan[0].Title != "Booking visit" {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	invalid := map[string]string{
+		"stage":     `[{"stage": "newborn", "items": []}]`,
+		"title":     `[{"stage": "ttc", "items": [{"key": "x", "title": {"es": "x"}}]}]`,
+		"duplicate": `[{"stage": "ttc", "items": [{"key": "x", "title": {"en": "x"}}, {"key": "x", "title": {"en": "y"}}]}]`,
+		"json":      `{"stage": "ttc"}`,
+	}
+	for name, content := range invalid {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTemplates(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package careplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/profile"
+)
+
+// Template is the standard care schedule for a journey stage, optionally
+// specific to a country
+type Template struct {
+	Stage   string `json:"stage"`   // profile.Stage*
+	Country string `json:"country"` // ISO 3166-1 alpha-2; "" for the default (WHO)
+	Source  string `json:"source"`  // Guideline the schedule follows
+	Items   []Item `json:"items"`
+}
+
+// Item is one scheduled contact, test or vaccine. It falls Week weeks and Day
+// days after the stage's anchor date: the start of pregnancy (LMP) for
+// gestational weeks, the baby's birth date postpartum, and the day the user
+// started trying to conceive.
+type Item struct {
+	Key         string            `json:"key"`
+	Week        int               `json:"week"`
+	Day         int               `json:"day"`
+	Title       map[string]string `json:"title"`       // By language code; "en" is required
+	Description map[string]string `json:"description"` // By language code
+}
+
+// LoadTemplates reads templates from a JSON file holding an array of Template
+func LoadTemplates(path string) ([]Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read care plan templates: %w", err)
+	}
+
+	var templates []Template
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse care plan templates: %w", err)
+	}
+	for i := range templates {
+		if err := templates[i].validate(); err != nil {
+			return nil, fmt.Errorf("care plan template %d: %w", i, err)
+		}
+	}
+	return templates, nil
+}
+
+// Merge returns base with each template in overrides replacing the one for
+// the same stage and country, or added if there is none
+func Merge(base, overrides []Template) []Template {
+	merged := append([]Template(nil), base...)
+	for _, o := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Stage == o.Stage && merged[i].Country == o.Country {
+				merged[i] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+func (t *Template) validate() error {
+	stage, err := profile.NormalizeStage(t.Stage)
+	if err != nil {
+		return err
+	}
+	t.Stage = stage
+
+	seen := make(map[string]bool, len(t.Items))
+	for _, item := range t.Items {
+		if item.Key == "" || len(t.Stage)+1+len(item.Key) > 64 {
+			return fmt.Errorf("item key %q must be set and at most %d characters", item.Key, 63-len(t.Stage))
+		}
+		if seen[item.Key] {
+			return fmt.Errorf("duplicate item key %q", item.Key)
+		}
+		seen[item.Key] = true
+		if item.Title["en"] == "" {
+			return fmt.Errorf("item %q has no English title", item.Key)
+		}
+		if item.Week < 0 || item.Day < 0 {
+			return fmt.Errorf("item %q is scheduled before its anchor date", item.Key)
+		}
+	}
+	return nil
+}
+
+// DefaultTemplates returns the built-in schedules: the WHO 2016 antenatal
+// care model (eight contacts) with the common screening tests and vaccines,
+// a US (ACOG) antenatal schedule, WHO postnatal care, and preconception care.
+func DefaultTemplates() []Template {
+	return []Template{
+		{
+			Stage:  profile.StagePregnant,
+			Source: "WHO antenatal care model (2016)",
+			Items: []Item{
+				bookingVisit(12),
+				ancContact(20),
+				anomalyScan,
+				ancContact(26),
+				glucoseTest,
+				tdapVaccine,
+				ancContact(30),
+				ancContact(34),
+				ancContact(36),
+				gbsSwab,
+				ancContact(38),
+				ancContact(40),
+			},
+		},
+		{
+			Stage:   profile.StagePregnant,
+			Country: "US",
+			Source:  "ACOG prenatal care schedule",
+			Items: []Item{
+				bookingVisit(8),
+				ancContact(12),
+				ancContact(16),
+				anomalyScan,
+				ancContact(24),
+				glucoseTest,
+				tdapVaccine,
+				ancContact(30),
+				ancContact(32),
+				ancContact(34),
+				gbsSwab,
+				ancContact(37),
+				ancContact(38),
+				ancContact(39),
+				ancContact(40),
+			},
+		},
+		{
+			Stage:  profile.StagePostpartum,
+			Source: "WHO postnatal care recommendations (2022)",
+			Items: []Item{
+				{
+					Key: "postnatal_check_day3", Day: 3,
+					Title:       map[string]string{"en": "Postnatal check (day 3)", "es": "Control posparto (día 3)"},
+					Description: map[string]string{"en": "Check-up for you and your baby: feeding, jaundice, bleeding and how you are feeling.", "es": "Control para ti y tu bebé: alimentación, ictericia, sangrado y cómo te sientes."},
+				},
+				{
+					Key: "postnatal_check_week2", Day: 10,
+					Title:       map[string]string{"en": "Postnatal check (1–2 weeks)", "es": "Control posparto (1–2 semanas)"},
+					Description: map[string]string{"en": "Follow-up for you and your baby, including your baby's weight and your mood.", "es": "Seguimiento para ti y tu bebé, incluido el peso del bebé y tu estado de ánimo."},
+				},
+				{
+					Key: "postnatal_check_week6", Week: 6,
+					Title:       map[string]string{"en": "Six-week postnatal check", "es": "Control posparto de las seis semanas"},
+					Description: map[string]string{"en": "Your recovery, contraception and emotional wellbeing; your baby's growth and development.", "es": "Tu recuperación, anticoncepción y bienestar emocional; el crecimiento y desarrollo de tu bebé."},
+				},
+				babyVaccines(6),
+				babyVaccines(10),
+				babyVaccines(14),
+			},
+		},
+		{
+			Stage:  profile.StageTTC,
+			Source: "WHO preconception care",
+			Items: []Item{
+				{
+					Key: "folic_acid", Day: 1,
+					Title:       map[string]string{"en": "Start taking folic acid", "es": "Empieza a tomar ácido fólico"},
+					Description: map[string]string{"en": "400 micrograms daily from before conception until 12 weeks of pregnancy helps prevent neural tube defects.", "es": "400 microgramos al día desde antes de la concepción hasta las 12 semanas de embarazo ayudan a prevenir defectos del tubo neural."},
+				},
+				{
+					Key: "preconception_visit", Week: 2,
+					Title:       map[string]string{"en": "Preconception check-up", "es": "Consulta preconcepcional"},
+					Description: map[string]string{"en": "Review your health, medicines and vaccinations (such as rubella) with a doctor before pregnancy.", "es": "Revisa tu salud, medicamentos y vacunas (como la rubéola) con un médico antes del embarazo."},
+				},
+				{
+					Key: "fertility_review", Week: 52,
+					Title:       map[string]string{"en": "Fertility review", "es": "Revisión de fertilidad"},
+					Description: map[string]string{"en": "If you have been trying for a year (six months if you are over 35), talk to a doctor about a fertility check.", "es": "Si llevas un año intentándolo (seis meses si tienes más de 35), habla con un médico sobre un estudio de fertilidad."},
+				},
+			},
+		},
+	}
+}
+
+func bookingVisit(week int) Item {
+	return Item{
+		Key: "booking_visit", Week: week,
+		Title:       map[string]string{"en": "First antenatal (booking) visit", "es": "Primera consulta prenatal"},
+		Description: map[string]string{"en": "Confirm your due date, blood tests, blood pressure and a plan for your pregnancy care.", "es": "Confirma tu fecha probable de parto, análisis de sangre, presión arterial y el plan de tu control prenatal."},
+	}
+}
+
+func ancContact(week int) Item {
+	return Item{
+		Key: fmt.Sprintf("anc_contact_%d", week), Week: week,
+		Title:       map[string]string{"en": fmt.Sprintf("Antenatal check-up (week %d)", week), "es": fmt.Sprintf("Control prenatal (semana %d)", week)},
+		Description: map[string]string{"en": "Blood pressure, your baby's growth and heartbeat, and any questions you have.", "es": "Presión arterial, crecimiento y latido del bebé, y cualquier pregunta que tengas."},
+	}
+}
+
+func babyVaccines(week int) Item {
+	return Item{
+		Key: fmt.Sprintf("baby_vaccines_%dw", week), Week: week,
+		Title:       map[string]string{"en": fmt.Sprintf("Baby's %d-week vaccinations", week), "es": fmt.Sprintf("Vacunas del bebé de las %d semanas", week)},
+		Description: map[string]string{"en": "Routine immunisations at the clinic. Bring your baby's health card.", "es": "Vacunas de rutina en la clínica. Lleva la cartilla de salud del bebé."},
+	}
+}
+
+var anomalyScan = Item{
+	Key: "anomaly_scan", Week: 20,
+	Title:       map[string]string{"en": "Anomaly scan (18–20 weeks)", "es": "Ecografía morfológica (18–20 semanas)"},
+	Description: map[string]string{"en": "Detailed ultrasound to check your baby's development and the placenta.", "es": "Ecografía detallada para revisar el desarrollo del bebé y la placenta."},
+}
+
+var glucoseTest = Item{
+	Key: "glucose_test", Week: 26,
+	Title:       map[string]string{"en": "Glucose test (24–28 weeks)", "es": "Prueba de glucosa (24–28 semanas)"},
+	Description: map[string]string{"en": "Screening for gestational diabetes. Ask whether you need to fast beforehand.", "es": "Detección de diabetes gestacional. Pregunta si necesitas ir en ayunas."},
+}
+
+var tdapVaccine = Item{
+	Key: "tdap_vaccine", Week: 28,
+	Title:       map[string]string{"en": "Tdap (whooping cough) vaccine", "es": "Vacuna Tdap (tos ferina)"},
+	Description: map[string]string{"en": "Best given between 27 and 36 weeks to protect your baby in the first months.", "es": "Se recomienda entre las semanas 27 y 36 para proteger al bebé en sus primeros meses."},
+}
+
+var gbsSwab = Item{
+	Key: "gbs_swab", Week: 36,
+	Title:       map[string]string{"en": "Group B strep swab (36–37 weeks)", "es": "Prueba de estreptococo del grupo B (36–37 semanas)"},
+	Description: map[string]string{"en": "A quick swab to check for GBS, so you can be offered antibiotics in labour if needed.", "es": "Un hisopado rápido para detectar GBS y, si hace falta, recibir antibióticos durante el parto."},
+}
@@ -14,11 +14,13 @@ import (
 	"github.com/themobileprof/momlaunchpad-be/internal/classifier"
 	"github.com/themobileprof/momlaunchpad-be/internal/conversation"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/facts"
 	"github.com/themobileprof/momlaunchpad-be/internal/fallback"
 	"github.com/themobileprof/momlaunchpad-be/internal/language"
 	"github.com/themobileprof/momlaunchpad-be/internal/memory"
 	"github.com/themobileprof/momlaunchpad-be/internal/privacy"
 	"github.com/themobileprof/momlaunchpad-be/internal/prompt"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
 	"github.com/themobileprof/momlaunchpad-be/internal/symptoms"
 	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
@@ -26,7 +28,9 @@ import (
 // Responder defines the interface for sending responses to any transport
 type Responder interface {
 	SendMessage(content string) error
+	SendDelta(content string) error
 	SendCalendarSuggestion(suggestion calendar.Suggestion) error
+	SendEmergency(alert redflag.Alert) error
 	SendError(message string) error
 	SendDone() error
 	SendTitleUpdated(title string) error
@@ -40,6 +44,9 @@ type ProcessRequest struct {
 	Message        string
 	Language       string
 	Responder      Responder
+	Stream         bool   // Forward LLM output token-by-token via SendDelta
+	CountryCode    string // ISO country for emergency hotlines (optional)
+	Timezone       string // IANA time zone for calendar suggestions (optional, UTC)
 }
 
 // Engine handles core conversation logic independent of transport
@@ -54,8 +61,11 @@ type Engine struct {
 	convManager       *conversation.Manager
 	symptomTracker    *symptoms.Tracker
 	symptomSummarizer *symptoms.Summarizer
+	redFlags          *redflag.Detector
 	circuitBreaker    *circuitbreaker.CircuitBreaker
 	aiTimeout         time.Duration
+	recall            RecallInterface // Optional long-term semantic memory
+	factExtractor     *facts.Extractor
 }
 
 // Interfaces for dependencies
@@ -68,13 +78,19 @@ type MemoryInterface interface {
 	GetShortTermMemory(userID string) []memory.Message
 }
 
+// RecallInterface indexes messages into long-term memory and recalls related ones
+type RecallInterface interface {
+	Index(ctx context.Context, userID, source, sourceID, content string) error
+	Recall(ctx context.Context, userID, query string, k int) ([]memory.Snippet, error)
+}
+
 type PromptInterface interface {
 	BuildPrompt(req prompt.PromptRequest) []llm.ChatMessage
 }
 
 type CalendarInterface interface {
 	ShouldSuggest(intent classifier.Intent, message string) calendar.SuggestionResult
-	BuildSuggestion(intent classifier.Intent, message string) calendar.Suggestion
+	BuildSuggestion(ctx context.Context, intent classifier.Intent, message string, locale calendar.Locale) calendar.Suggestion
 }
 
 type LanguageInterface interface {
@@ -87,12 +103,14 @@ type DBInterface interface {
 	GetUserFacts(ctx context.Context, userID string) ([]db.UserFact, error)
 	SaveSymptom(ctx context.Context, input db.SymptomInsert) (string, error)
 	GetRecentSymptoms(ctx context.Context, userID string, limit int) ([]map[string]interface{}, error)
-	SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64) (*db.UserFact, error)
+	SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64, source string) (*db.UserFact, error)
 	GetSystemSetting(ctx context.Context, key string) (*db.SystemSetting, error)
 	GetMostRecentConversation(ctx context.Context, userID string) (*db.Conversation, error)
 	GetConversation(ctx context.Context, id string) (*db.Conversation, error)
 	UpdateConversation(ctx context.Context, id string, title *string, isStarred *bool) (*db.Conversation, error)
 	CountMessagesByConversation(ctx context.Context, conversationID string) (int, error)
+	CreateEscalation(ctx context.Context, e *db.Escalation) error
+	CreateCalendarSuggestion(ctx context.Context, s *db.CalendarSuggestion) error
 }
 
 // NewEngine creates a new transport-agnostic chat engine
@@ -117,14 +135,27 @@ func NewEngine(
 		convManager:       conversation.NewManager(),
 		symptomTracker:    symptoms.NewTracker(),
 		symptomSummarizer: symptomSummarizer,
+		redFlags:          redflag.NewDetector(),
 		circuitBreaker:    circuitbreaker.NewCircuitBreaker(5, 5*time.Minute),
 		aiTimeout:         30 * time.Second,
+		factExtractor:     facts.NewExtractor(client),
 	}
 }
 
+// recallLimit is how many long-term memories are added to a prompt
+const recallLimit = 3
+
+// WithRecall enables long-term semantic memory: user messages are indexed and
+// related past messages and visit notes are recalled into the prompt.
+func (e *Engine) WithRecall(recall RecallInterface) *Engine {
+	e.recall = recall
+	return e
+}
+
 // ProcessMessage processes a chat message and sends responses via the provided responder
 func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string, error) {
 	log.Printf("Processing message: userID=%s, length=%d", req.UserID, len(req.Message))
+	ctx = llm.WithUser(ctx, req.UserID)
 
 	// Ensure conversation ID exists
 	conversationID := req.ConversationID
@@ -179,6 +210,15 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		Content: req.Message,
 	})
 
+	if result.Intent != classifier.IntentSmallTalk {
+		e.remember(ctx, req.UserID, userMsg.ID, req.Message)
+	}
+
+	// Deterministic red-flag check runs before any LLM work and short-circuits it
+	if detection, found := e.redFlags.Detect(req.Message); found {
+		return conversationID, e.escalate(ctx, req, conversationID, userMsg.ID, detection)
+	}
+
 	msgCount, countErr := e.db.CountMessagesByConversation(ctx, conversationID)
 	if countErr != nil {
 		log.Printf("Warning: failed to count messages: %v", countErr)
@@ -263,7 +303,11 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 	}
 
 	if shouldSuggest := e.calSuggester.ShouldSuggest(result.Intent, req.Message); shouldSuggest.ShouldSuggest {
-		suggestion := e.calSuggester.BuildSuggestion(result.Intent, req.Message)
+		suggestion := e.calSuggester.BuildSuggestion(ctx, result.Intent, req.Message, calendar.Locale{
+			Language: req.Language,
+			Timezone: req.Timezone,
+		})
+		suggestion.ID = e.saveSuggestion(ctx, req.UserID, conversationID, userMsg.ID, shouldSuggest.Priority, suggestion)
 		if err := req.Responder.SendCalendarSuggestion(suggestion); err != nil {
 			return conversationID, err
 		}
@@ -276,15 +320,16 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		return conversationID, req.Responder.SendDone()
 	}
 
-	// Fetch facts, symptoms, AI name, and short-term memory concurrently for speed
+	// Fetch facts, symptoms, AI name, short-term and long-term memory concurrently for speed
 	var (
 		facts          []db.UserFact
 		recentSymptoms []map[string]interface{}
 		shortTermMsgs  []memory.Message
+		recalled       []memory.Snippet
 		aiName         string
 		wg             sync.WaitGroup
 	)
-	wg.Add(4)
+	wg.Add(5)
 	go func() {
 		defer wg.Done()
 		facts, _ = e.db.GetUserFacts(ctx, req.UserID)
@@ -297,6 +342,10 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		defer wg.Done()
 		shortTermMsgs = e.memoryManager.GetShortTermMemory(req.UserID)
 	}()
+	go func() {
+		defer wg.Done()
+		recalled = e.recallMemories(ctx, req.UserID, req.Message)
+	}()
 	go func() {
 		defer wg.Done()
 		// Fetch AI name from system settings
@@ -308,6 +357,7 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		}
 	}()
 	wg.Wait()
+	recalled = excludeShortTerm(recalled, shortTermMsgs, req.Message)
 
 	sanitizedContent := privacy.SanitizeForAPI(req.Message)
 
@@ -322,6 +372,7 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		ShortTermMemory:     shortTermMsgs,
 		Facts:               convertDBFactsToMemoryFacts(facts),
 		RecentSymptoms:      recentSymptoms,
+		RelevantMemories:    recalled,
 		ConversationState:   convState,
 		AIName:              aiName, // Pass AI name to prompt builder
 	}
@@ -336,13 +387,22 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		Model:       "", // Let client use default or we can inject it
 		Messages:    messages,
 		Temperature: 0.7,
-		MaxTokens:   200,   // Limit to ~150 words for voice-friendly, concise responses
-		Stream:      false, // Non-streaming for short responses
+		MaxTokens:   200, // Limit to ~150 words for voice-friendly, concise responses
+		Stream:      req.Stream,
 	}
 
-	log.Printf("Calling LLM API for user=%s", req.UserID)
-	var assistantMsg string
+	log.Printf("Calling LLM API for user=%s, stream=%t", req.UserID, req.Stream)
+	var (
+		assistantMsg string
+		sendErr      error
+	)
 	err = e.circuitBreaker.Call(func() error {
+		if req.Stream {
+			var streamErr error
+			assistantMsg, sendErr, streamErr = e.streamCompletion(ctxWithTimeout, chatReq, req.Responder)
+			return streamErr
+		}
+
 		response, err := e.llmClient.ChatCompletion(ctxWithTimeout, chatReq)
 		if err != nil {
 			log.Printf("LLM API error: %v", err)
@@ -358,6 +418,10 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		return nil
 	})
 
+	if sendErr != nil {
+		return conversationID, fmt.Errorf("failed to send message: %w", sendErr)
+	}
+
 	if err != nil {
 		log.Printf("AI call failed: %v", err)
 
@@ -368,13 +432,16 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 			fbResp = fallback.GetFallbackResponse(result.Intent, req.Language)
 		}
 
+		// A full message after deltas replaces any partially streamed draft
 		_ = req.Responder.SendMessage(fbResp.Content)
 		return conversationID, req.Responder.SendDone()
 	}
 
-	// Send the complete response at once
-	if err := req.Responder.SendMessage(assistantMsg); err != nil {
-		return conversationID, fmt.Errorf("failed to send message: %w", err)
+	// Non-streaming: send the complete response at once
+	if !req.Stream {
+		if err := req.Responder.SendMessage(assistantMsg); err != nil {
+			return conversationID, fmt.Errorf("failed to send message: %w", err)
+		}
 	}
 
 	// Save assistant message to DB and memory
@@ -386,13 +453,111 @@ func (e *Engine) ProcessMessage(ctx context.Context, req ProcessRequest) (string
 		Content: assistantMsg,
 	})
 
-	e.extractAndSaveFacts(ctx, req.UserID, req.Message, assistantMsg)
+	e.extractAndSaveFacts(ctx, req.UserID, sanitizedContent, previousAssistantReply(shortTermMsgs), facts)
 
 	e.maybeGenerateConversationTitle(ctx, conversationID, req.Message, assistantMsg, req.Responder)
 
 	return conversationID, req.Responder.SendDone()
 }
 
+// errStreamInterrupted marks a stream that closed before the provider reported a finish reason.
+var errStreamInterrupted = errors.New("LLM stream interrupted")
+
+// streamCompletion forwards streamed LLM content to the responder as deltas and
+// returns the assembled text. sendErr reports a transport failure (client gone),
+// which is kept apart from err so it does not trip the circuit breaker.
+func (e *Engine) streamCompletion(ctx context.Context, chatReq llm.ChatRequest, responder Responder) (text string, sendErr error, err error) {
+	chunks, err := e.llmClient.StreamChatCompletion(ctx, chatReq)
+	if err != nil {
+		log.Printf("LLM stream error: %v", err)
+		return "", nil, err
+	}
+
+	var full strings.Builder
+	finished := false
+	for chunk := range chunks {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if content := choice.Delta.Content; content != "" {
+			full.WriteString(content)
+			if err := responder.SendDelta(content); err != nil {
+				return full.String(), err, nil
+			}
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			finished = true
+		}
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		log.Printf("LLM stream aborted after %d bytes: %v", full.Len(), ctxErr)
+		return full.String(), nil, ctxErr
+	}
+	if !finished {
+		log.Printf("LLM stream ended without finish reason after %d bytes", full.Len())
+		return full.String(), nil, errStreamInterrupted
+	}
+	if full.Len() == 0 {
+		return "", nil, fmt.Errorf("no response from LLM")
+	}
+
+	log.Printf("AI response streamed: %d bytes", full.Len())
+	return full.String(), nil, nil
+}
+
+// remember indexes a user message into long-term memory in the background;
+// embedding may call an external API and must not delay the reply.
+func (e *Engine) remember(ctx context.Context, userID, messageID, content string) {
+	if e.recall == nil {
+		return
+	}
+	go func() {
+		bgCtx, cancel := context.WithTimeout(llm.WithUser(context.Background(), llm.UserFromContext(ctx)), 10*time.Second)
+		defer cancel()
+
+		if err := e.recall.Index(bgCtx, userID, memory.SourceMessage, messageID, content); err != nil {
+			log.Printf("Failed to index message into long-term memory: %v", err)
+		}
+	}()
+}
+
+// recallMemories returns long-term memories related to the message, with a
+// little headroom for the ones excludeShortTerm drops.
+func (e *Engine) recallMemories(ctx context.Context, userID, message string) []memory.Snippet {
+	if e.recall == nil {
+		return nil
+	}
+	snippets, err := e.recall.Recall(ctx, userID, message, recallLimit+2)
+	if err != nil {
+		log.Printf("Warning: long-term memory recall failed: %v", err)
+		return nil
+	}
+	return snippets
+}
+
+// excludeShortTerm drops recalled snippets that are already in the prompt
+// (the current message or recent history) and caps the result at recallLimit.
+func excludeShortTerm(snippets []memory.Snippet, shortTerm []memory.Message, current string) []memory.Snippet {
+	seen := map[string]bool{strings.TrimSpace(current): true}
+	for _, msg := range shortTerm {
+		seen[strings.TrimSpace(msg.Content)] = true
+	}
+
+	kept := make([]memory.Snippet, 0, recallLimit)
+	for _, s := range snippets {
+		if seen[strings.TrimSpace(s.Content)] {
+			continue
+		}
+		kept = append(kept, s)
+		if len(kept) == recallLimit {
+			break
+		}
+	}
+	return kept
+}
+
 func getSmallTalkResponse(language string) string {
 	responses := map[string]string{
 		"en": "I'm here with you. How can I help today?",
@@ -496,7 +661,7 @@ func (e *Engine) maybeGenerateConversationTitle(
 	}
 
 	go func() {
-		bgCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		bgCtx, cancel := context.WithTimeout(llm.WithUser(context.Background(), llm.UserFromContext(ctx)), 10*time.Second)
 		defer cancel()
 
 		title, err := conversation.GenerateTitle(bgCtx, e.llmClient, userMessage, assistantMessage)
@@ -515,3 +680,35 @@ func (e *Engine) maybeGenerateConversationTitle(
 		}
 	}()
 }
+
+// saveSuggestion stores a calendar suggestion so the client can accept or
+// dismiss it, returning its ID ("" if it could not be stored)
+func (e *Engine) saveSuggestion(ctx context.Context, userID, conversationID, messageID, priority string, s calendar.Suggestion) string {
+	record := &db.CalendarSuggestion{
+		UserID:         userID,
+		ConversationID: &conversationID,
+		MessageID:      &messageID,
+		Type:           s.Type,
+		VisitType:      optionalString(s.VisitType),
+		Priority:       optionalString(priority),
+		Title:          s.Title,
+		Description:    optionalString(s.Description),
+		SuggestedTime:  s.SuggestedTime,
+		Timezone:       s.Timezone,
+		Location:       optionalString(s.Location),
+		Provider:       optionalString(s.Provider),
+		TimeSource:     s.TimeSource,
+	}
+	if err := e.db.CreateCalendarSuggestion(ctx, record); err != nil {
+		log.Printf("Warning: failed to save calendar suggestion: %v", err)
+		return ""
+	}
+	return record.ID
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
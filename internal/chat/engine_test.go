@@ -2,7 +2,9 @@ package chat
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
 	"github.com/themobileprof/momlaunchpad-be/internal/classifier"
@@ -10,6 +12,7 @@ import (
 	"github.com/themobileprof/momlaunchpad-be/internal/language"
 	"github.com/themobileprof/momlaunchpad-be/internal/memory"
 	"github.com/themobileprof/momlaunchpad-be/internal/prompt"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
 	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
@@ -65,7 +68,9 @@ func (m *mockLLMClient) StreamChatCompletion(ctx context.Context, req llm.ChatRe
 			Delta        llm.Delta `json:"delta"`
 			FinishReason *string   `json:"finish_reason"`
 		}, 1)
+		finish := "stop"
 		chunk.Choices[0].Delta.Content = "Test response"
+		chunk.Choices[0].FinishReason = &finish
 		ch <- chunk
 	}()
 	return ch, nil
@@ -93,12 +98,12 @@ func (m *mockLLMClient) ChatCompletion(ctx context.Context, req llm.ChatRequest)
 	}, nil
 }
 
-type mockCalSuggester struct{}
+type mockCalSuggester struct{ suggest bool }
 
 func (m *mockCalSuggester) ShouldSuggest(intent classifier.Intent, message string) calendar.SuggestionResult {
-	return calendar.SuggestionResult{ShouldSuggest: false}
+	return calendar.SuggestionResult{ShouldSuggest: m.suggest, Priority: "high"}
 }
-func (m *mockCalSuggester) BuildSuggestion(intent classifier.Intent, message string) calendar.Suggestion {
+func (m *mockCalSuggester) BuildSuggestion(ctx context.Context, intent classifier.Intent, message string, locale calendar.Locale) calendar.Suggestion {
 	return calendar.Suggestion{Type: "appointment", Title: "Test", Description: "Test"}
 }
 
@@ -109,8 +114,10 @@ func (m *mockLangManager) Validate(code string) language.ValidationResult {
 }
 
 type mockDB struct {
-	messages []string
-	facts    []string
+	messages    []string
+	facts       []string
+	escalations []db.Escalation
+	suggestions []db.CalendarSuggestion
 }
 
 func (m *mockDB) SaveMessage(ctx context.Context, userID, conversationID, role, content string) (*db.Message, error) {
@@ -123,7 +130,7 @@ func (m *mockDB) CreateConversation(ctx context.Context, userID string, title *s
 func (m *mockDB) GetUserFacts(ctx context.Context, userID string) ([]db.UserFact, error) {
 	return []db.UserFact{}, nil
 }
-func (m *mockDB) SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64) (*db.UserFact, error) {
+func (m *mockDB) SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64, source string) (*db.UserFact, error) {
 	m.facts = append(m.facts, key+":"+value)
 	return &db.UserFact{}, nil
 }
@@ -156,22 +163,45 @@ func (m *mockDB) UpdateConversation(ctx context.Context, id string, title *strin
 func (m *mockDB) CountMessagesByConversation(ctx context.Context, conversationID string) (int, error) {
 	return len(m.messages), nil
 }
+func (m *mockDB) CreateEscalation(ctx context.Context, e *db.Escalation) error {
+	m.escalations = append(m.escalations, *e)
+	return nil
+}
+func (m *mockDB) CreateCalendarSuggestion(ctx context.Context, s *db.CalendarSuggestion) error {
+	s.ID = "suggestion-1"
+	m.suggestions = append(m.suggestions, *s)
+	return nil
+}
 
 type mockResponder struct {
-	messages []string
-	done     bool
-	convID   string
+	messages    []string
+	deltas      []string
+	alerts      []redflag.Alert
+	suggestions []calendar.Suggestion
+	done        bool
+	convID      string
 }
 
 func (m *mockResponder) SendMessage(content string) error {
 	m.messages = append(m.messages, content)
 	return nil
 }
-func (m *mockResponder) SendCalendarSuggestion(suggestion calendar.Suggestion) error { return nil }
-func (m *mockResponder) SendError(message string) error                              { return nil }
-func (m *mockResponder) SendDone() error                                             { m.done = true; return nil }
-func (m *mockResponder) SendTitleUpdated(title string) error                         { return nil }
-func (m *mockResponder) SetConversationID(id string)                                 { m.convID = id }
+func (m *mockResponder) SendDelta(content string) error {
+	m.deltas = append(m.deltas, content)
+	return nil
+}
+func (m *mockResponder) SendCalendarSuggestion(suggestion calendar.Suggestion) error {
+	m.suggestions = append(m.suggestions, suggestion)
+	return nil
+}
+func (m *mockResponder) SendEmergency(alert redflag.Alert) error {
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+func (m *mockResponder) SendError(message string) error      { return nil }
+func (m *mockResponder) SendDone() error                     { m.done = true; return nil }
+func (m *mockResponder) SendTitleUpdated(title string) error { return nil }
+func (m *mockResponder) SetConversationID(id string)         { m.convID = id }
 
 type trackingPromptBuilder struct {
 	lastReq prompt.PromptRequest
@@ -249,3 +279,300 @@ func TestEngine_FollowUpSmallTalkUsesCannedResponse(t *testing.T) {
 		t.Errorf("Expected canned small-talk response, got %v", responder.messages)
 	}
 }
+
+// chunkedLLMClient streams the given parts; finish controls whether the
+// stream ends with a finish reason or is cut off.
+type chunkedLLMClient struct {
+	mockLLMClient
+	parts  []string
+	finish bool
+}
+
+func (m *chunkedLLMClient) StreamChatCompletion(ctx context.Context, req llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	ch := make(chan llm.ChatChunk, len(m.parts)+1)
+	for _, part := range m.parts {
+		chunk := llm.ChatChunk{}
+		chunk.Choices = make([]struct {
+			Index        int       `json:"index"`
+			Delta        llm.Delta `json:"delta"`
+			FinishReason *string   `json:"finish_reason"`
+		}, 1)
+		chunk.Choices[0].Delta.Content = part
+		ch <- chunk
+	}
+	if m.finish {
+		reason := "stop"
+		chunk := llm.ChatChunk{}
+		chunk.Choices = make([]struct {
+			Index        int       `json:"index"`
+			Delta        llm.Delta `json:"delta"`
+			FinishReason *string   `json:"finish_reason"`
+		}, 1)
+		chunk.Choices[0].FinishReason = &reason
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestEngine_StreamingSendsDeltasAndPersistsFullText(t *testing.T) {
+	database := &mockDB{}
+	engine := NewEngine(
+		&mockClassifier{},
+		&mockMemoryManager{},
+		&mockPromptBuilder{},
+		&chunkedLLMClient{parts: []string{"Rest ", "when ", "you can."}, finish: true},
+		&mockCalSuggester{},
+		&mockLangManager{},
+		database,
+		nil,
+	)
+	responder := &mockResponder{}
+
+	_, err := engine.ProcessMessage(context.Background(), ProcessRequest{
+		UserID:         "user1",
+		ConversationID: "conv1",
+		Message:        "Hello",
+		Language:       "en",
+		Responder:      responder,
+		Stream:         true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(responder.deltas) != 3 {
+		t.Fatalf("Expected 3 deltas, got %v", responder.deltas)
+	}
+	if len(responder.messages) != 0 {
+		t.Errorf("Expected no full message when streaming, got %v", responder.messages)
+	}
+	if !responder.done {
+		t.Error("Expected done to be sent")
+	}
+
+	want := "user1:conv1:assistant:Rest when you can."
+	if got := database.messages[len(database.messages)-1]; got != want {
+		t.Errorf("Expected assembled reply to be saved, got %q", got)
+	}
+}
+
+func TestEngine_InterruptedStreamFallsBack(t *testing.T) {
+	database := &mockDB{}
+	engine := NewEngine(
+		&mockClassifier{},
+		&mockMemoryManager{},
+		&mockPromptBuilder{},
+		&chunkedLLMClient{parts: []string{"Partial "}, finish: false},
+		&mockCalSuggester{},
+		&mockLangManager{},
+		database,
+		nil,
+	)
+	responder := &mockResponder{}
+
+	_, err := engine.ProcessMessage(context.Background(), ProcessRequest{
+		UserID:         "user1",
+		ConversationID: "conv1",
+		Message:        "Hello",
+		Language:       "en",
+		Responder:      responder,
+		Stream:         true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(responder.deltas) != 1 {
+		t.Errorf("Expected the partial delta to be forwarded, got %v", responder.deltas)
+	}
+	if len(responder.messages) != 1 || responder.messages[0] == "" {
+		t.Fatalf("Expected a fallback message replacing the draft, got %v", responder.messages)
+	}
+	for _, m := range database.messages {
+		if m == "user1:conv1:assistant:Partial " {
+			t.Error("Partial stream must not be persisted")
+		}
+	}
+}
+
+// countingLLMClient records whether the LLM was called at all.
+type countingLLMClient struct {
+	mockLLMClient
+	calls int
+}
+
+func (m *countingLLMClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	m.calls++
+	return m.mockLLMClient.ChatCompletion(ctx, req)
+}
+
+func (m *countingLLMClient) StreamChatCompletion(ctx context.Context, req llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	m.calls++
+	return m.mockLLMClient.StreamChatCompletion(ctx, req)
+}
+
+func TestEngine_RedFlagShortCircuitsLLM(t *testing.T) {
+	database := &mockDB{}
+	client := &countingLLMClient{}
+	engine := NewEngine(
+		&mockClassifier{},
+		&mockMemoryManager{},
+		&mockPromptBuilder{},
+		client,
+		&mockCalSuggester{},
+		&mockLangManager{},
+		database,
+		nil,
+	)
+	responder := &mockResponder{}
+
+	_, err := engine.ProcessMessage(context.Background(), ProcessRequest{
+		UserID:         "user1",
+		ConversationID: "conv1",
+		Message:        "My baby stopped moving since last night",
+		Language:       "en",
+		Responder:      responder,
+		CountryCode:    "NG",
+		Stream:         true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if client.calls != 0 {
+		t.Errorf("Expected LLM to be skipped, got %d calls", client.calls)
+	}
+	if len(responder.alerts) != 1 || responder.alerts[0].Category != redflag.CategoryReducedFetalMovement {
+		t.Fatalf("Expected one reduced-movement alert, got %+v", responder.alerts)
+	}
+	if len(responder.alerts[0].Hotlines) == 0 || responder.alerts[0].Hotlines[0].Number != "112" {
+		t.Errorf("Expected Nigerian emergency number, got %+v", responder.alerts[0].Hotlines)
+	}
+	if len(responder.messages) != 1 || responder.messages[0] != responder.alerts[0].Message {
+		t.Errorf("Expected urgent-care message, got %v", responder.messages)
+	}
+	if !responder.done {
+		t.Error("Expected done to be sent")
+	}
+
+	if len(database.escalations) != 1 {
+		t.Fatalf("Expected escalation to be recorded, got %d", len(database.escalations))
+	}
+	esc := database.escalations[0]
+	if esc.MessageID == nil || *esc.MessageID != "mock-message-id" || esc.CountryCode == nil || *esc.CountryCode != "NG" {
+		t.Errorf("Unexpected escalation record: %+v", esc)
+	}
+}
+
+// questionClassifier classifies every message as a pregnancy question.
+type questionClassifier struct{}
+
+func (questionClassifier) Classify(text, language string) classifier.ClassifierResult {
+	return classifier.ClassifierResult{Intent: classifier.IntentPregnancyQ, Confidence: 0.9}
+}
+
+type fakeRecall struct {
+	mu       sync.Mutex
+	snippets []memory.Snippet
+	indexed  []string
+	done     chan struct{}
+}
+
+func (f *fakeRecall) Index(ctx context.Context, userID, source, sourceID, content string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.indexed = append(f.indexed, source+":"+sourceID+":"+content)
+	close(f.done)
+	return nil
+}
+
+func (f *fakeRecall) Recall(ctx context.Context, userID, query string, k int) ([]memory.Snippet, error) {
+	return f.snippets, nil
+}
+
+func TestEngine_RecallsLongTermMemory(t *testing.T) {
+	const message = "Can I eat mangoes with my blood sugar?"
+	recall := &fakeRecall{
+		done: make(chan struct{}),
+		snippets: []memory.Snippet{
+			{Source: memory.SourceMessage, Content: message}, // The message being indexed right now
+			{Source: memory.SourceMessage, Content: "I was diagnosed with gestational diabetes"},
+			{Source: memory.SourceMessage, Content: "Recent history line"},
+		},
+	}
+	pb := &trackingPromptBuilder{}
+	engine := NewEngine(
+		questionClassifier{},
+		&mockMemoryManager{messages: []memory.Message{{Role: "user", Content: "Recent history line"}}},
+		pb,
+		&mockLLMClient{},
+		&mockCalSuggester{},
+		&mockLangManager{},
+		&mockDB{},
+		nil,
+	).WithRecall(recall)
+
+	_, err := engine.ProcessMessage(context.Background(), ProcessRequest{
+		UserID:         "user1",
+		ConversationID: "conv1",
+		Message:        message,
+		Language:       "en",
+		Responder:      &mockResponder{},
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	got := pb.lastReq.RelevantMemories
+	if len(got) != 1 || got[0].Content != "I was diagnosed with gestational diabetes" {
+		t.Errorf("RelevantMemories = %+v, want only the diabetes memory", got)
+	}
+
+	select {
+	case <-recall.done:
+	case <-time.After(time.Second):
+		t.Fatal("message was not indexed")
+	}
+	recall.mu.Lock()
+	defer recall.mu.Unlock()
+	if recall.indexed[0] != "message:mock-message-id:"+message {
+		t.Errorf("indexed = %v", recall.indexed)
+	}
+}
+
+func TestEngine_StoresCalendarSuggestion(t *testing.T) {
+	database := &mockDB{}
+	engine := NewEngine(
+		&mockClassifier{},
+		&mockMemoryManager{},
+		&mockPromptBuilder{},
+		&mockLLMClient{},
+		&mockCalSuggester{suggest: true},
+		&mockLangManager{},
+		database,
+		nil,
+	)
+	responder := &mockResponder{}
+
+	if _, err := engine.ProcessMessage(context.Background(), ProcessRequest{
+		UserID:         "user1",
+		ConversationID: "conv1",
+		Message:        "Scan next Tuesday",
+		Language:       "en",
+		Responder:      responder,
+	}); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	if len(database.suggestions) != 1 {
+		t.Fatalf("stored %d suggestions, want 1", len(database.suggestions))
+	}
+	stored := database.suggestions[0]
+	if *stored.ConversationID != "conv1" || *stored.MessageID != "mock-message-id" || *stored.Priority != "high" {
+		t.Errorf("stored suggestion = %+v", stored)
+	}
+	if len(responder.suggestions) != 1 || responder.suggestions[0].ID != "suggestion-1" {
+		t.Errorf("sent suggestions = %+v, want the stored ID", responder.suggestions)
+	}
+}
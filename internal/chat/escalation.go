@@ -0,0 +1,52 @@
+package chat
+
+import (
+	"context"
+	"log"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/memory"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
+)
+
+// escalate answers a red-flag message with a fixed urgent-care response,
+// sends the emergency event and records an escalation for admin audit.
+// The LLM is never consulted so this works when providers are down.
+func (e *Engine) escalate(ctx context.Context, req ProcessRequest, conversationID, messageID string, detection redflag.Detection) error {
+	log.Printf("Red flag detected: user=%s, category=%s", req.UserID, detection.Category)
+
+	alert := redflag.NewAlert(detection, req.Language, req.CountryCode)
+
+	if err := req.Responder.SendEmergency(alert); err != nil {
+		log.Printf("Failed to send emergency event: %v", err)
+	}
+	if err := req.Responder.SendMessage(alert.Message); err != nil {
+		return err
+	}
+
+	if _, err := e.db.SaveMessage(ctx, req.UserID, conversationID, "assistant", alert.Message); err != nil {
+		log.Printf("Failed to save assistant message: %v", err)
+	}
+	e.memoryManager.AddMessage(req.UserID, memory.Message{
+		Role:    "assistant",
+		Content: alert.Message,
+	})
+
+	escalation := &db.Escalation{
+		UserID:         req.UserID,
+		ConversationID: &conversationID,
+		MessageID:      &messageID,
+		Category:       string(detection.Category),
+		MatchedPhrase:  detection.Matched,
+		Language:       req.Language,
+		Response:       alert.Message,
+	}
+	if alert.CountryCode != "" {
+		escalation.CountryCode = &alert.CountryCode
+	}
+	if err := e.db.CreateEscalation(ctx, escalation); err != nil {
+		log.Printf("Failed to record escalation: user=%s, category=%s: %v", req.UserID, detection.Category, err)
+	}
+
+	return req.Responder.SendDone()
+}
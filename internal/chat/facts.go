@@ -2,120 +2,56 @@ package chat
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/facts"
+	"github.com/themobileprof/momlaunchpad-be/internal/memory"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
-const chatFactConfidence = 0.85
-
-func (e *Engine) extractAndSaveFacts(ctx context.Context, userID, userMsg, aiMsg string) {
-	normalized := strings.ToLower(userMsg)
-
-	if week := extractPregnancyWeek(normalized); week > 0 {
-		_, _ = e.db.SaveOrUpdateFact(ctx, userID, "pregnancy_week", fmt.Sprintf("%d", week), chatFactConfidence)
-	}
-
-	if value, ok := extractFirstPregnancy(normalized); ok {
-		_, _ = e.db.SaveOrUpdateFact(ctx, userID, "is_first_pregnancy", value, chatFactConfidence)
-	}
-
-	if diet := extractDietPreference(normalized); diet != "" {
-		_, _ = e.db.SaveOrUpdateFact(ctx, userID, "diet", diet, chatFactConfidence)
-	}
-
-	if concern := extractPrimaryConcernFact(normalized); concern != "" {
-		_, _ = e.db.SaveOrUpdateFact(ctx, userID, "primary_concern", concern, chatFactConfidence)
-	}
-}
-
-func extractPregnancyWeek(normalized string) int {
-	if !strings.Contains(normalized, "week") {
-		return 0
-	}
-
-	for i := 1; i <= 42; i++ {
-		weekStr := fmt.Sprintf("%d week", i)
-		if strings.Contains(normalized, weekStr) {
-			return i
+// extractAndSaveFacts extracts facts from the user's message in the
+// background so the LLM round-trip never delays the reply.
+func (e *Engine) extractAndSaveFacts(ctx context.Context, userID, userMsg, previousReply string, known []db.UserFact) {
+	go func() {
+		bgCtx, cancel := context.WithTimeout(llm.WithUser(context.Background(), llm.UserFromContext(ctx)), 15*time.Second)
+		defer cancel()
+
+		extracted := e.factExtractor.Extract(bgCtx, facts.Request{
+			Message:       userMsg,
+			PreviousReply: previousReply,
+			Known:         knownFacts(known),
+		})
+
+		for _, f := range extracted {
+			// SaveOrUpdateFact keeps a stored fact with higher confidence
+			if _, err := e.db.SaveOrUpdateFact(bgCtx, userID, f.Key, f.Value, f.Confidence, db.FactSourceChat); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				log.Printf("Failed to save fact %s: %v", f.Key, err)
+			}
 		}
-	}
-
-	return 0
+	}()
 }
 
-func extractFirstPregnancy(normalized string) (string, bool) {
-	firstPatterns := []string{
-		"first pregnancy",
-		"first baby",
-		"first time mom",
-		"first-time mom",
-		"first child",
-		"never been pregnant",
-	}
-	notFirstPatterns := []string{
-		"second pregnancy",
-		"third pregnancy",
-		"not my first",
-		"another baby",
-		"second baby",
-		"third baby",
-	}
-
-	for _, pattern := range notFirstPatterns {
-		if strings.Contains(normalized, pattern) {
-			return "no", true
-		}
-	}
-
-	for _, pattern := range firstPatterns {
-		if strings.Contains(normalized, pattern) {
-			return "yes", true
-		}
-	}
-
-	return "", false
-}
-
-func extractDietPreference(normalized string) string {
-	dietPatterns := map[string]string{
-		"vegetarian":  "vegetarian",
-		"vegan":       "vegan",
-		"pescatarian": "pescatarian",
-		"gluten free": "gluten-free",
-		"gluten-free": "gluten-free",
-		"halal":       "halal",
-		"kosher":      "kosher",
-	}
-
-	for pattern, value := range dietPatterns {
-		if strings.Contains(normalized, pattern) {
-			return value
+func knownFacts(stored []db.UserFact) []facts.Fact {
+	known := make([]facts.Fact, 0, len(stored))
+	for _, f := range stored {
+		if facts.IsKnownKey(f.Key) {
+			known = append(known, facts.Fact{Key: f.Key, Value: f.Value, Confidence: f.Confidence})
 		}
 	}
-
-	return ""
+	return known
 }
 
-func extractPrimaryConcernFact(normalized string) string {
-	concernKeywords := map[string]string{
-		"morning sickness": "morning sickness",
-		"nausea":           "nausea",
-		"headache":         "headaches",
-		"back pain":        "back pain",
-		"cramp":            "cramping",
-		"heartburn":        "heartburn",
-		"swelling":         "swelling",
-		"insomnia":         "sleep issues",
-		"anxiety":          "anxiety",
-		"nutrition":        "nutrition",
-		"diet":             "nutrition",
-	}
-
-	for keyword, concern := range concernKeywords {
-		if strings.Contains(normalized, keyword) {
-			return concern
+// previousAssistantReply returns the last assistant message in short-term
+// memory, which gives context to short answers like "22 weeks".
+func previousAssistantReply(history []memory.Message) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "assistant" {
+			return history[i].Content
 		}
 	}
-
 	return ""
 }
@@ -0,0 +1,315 @@
+// Package codes redeems promo codes, sponsor seat codes and referral codes.
+// Promo and sponsor codes grant a plan or a feature for a number of days;
+// a referral code credits both the new user and the user who invited them.
+package codes
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
+)
+
+// Redemption kinds
+const (
+	KindPromo    = "promo"
+	KindSponsor  = "sponsor"
+	KindReferral = "referral"
+)
+
+var (
+	// ErrCodeNotFound means no promo or referral code matches
+	ErrCodeNotFound = errors.New("code not found")
+	// ErrCodeUnavailable means the code is inactive, expired or used up
+	ErrCodeUnavailable = errors.New("code is no longer available")
+	// ErrAlreadyRedeemed means the user already redeemed the code
+	ErrAlreadyRedeemed = errors.New("code already redeemed")
+	// ErrSelfReferral means a user entered their own referral code
+	ErrSelfReferral = errors.New("cannot use your own referral code")
+	// ErrAlreadyReferred means the user already joined through a referral
+	ErrAlreadyReferred = errors.New("already referred")
+	// ErrReferralNotEligible means the account is too old for a referral credit
+	ErrReferralNotEligible = errors.New("referral codes are for new accounts")
+)
+
+// Store is the persistence codes need (implemented by *db.DB)
+type Store interface {
+	GetUserByID(ctx context.Context, id string) (*db.User, error)
+	GetPromoCodeByCode(ctx context.Context, code string) (*db.PromoCode, error)
+	CreatePromoCode(ctx context.Context, c *db.PromoCode) error
+	RedeemPromoCode(ctx context.Context, codeID, userID string) error
+	DeleteCodeRedemption(ctx context.Context, codeID, userID string) error
+	IssueSponsorCodes(ctx context.Context, sponsorID string, codes []string) ([]db.PromoCode, error)
+	GetReferralCode(ctx context.Context, userID string) (string, error)
+	CreateReferralCode(ctx context.Context, userID, code string) (string, error)
+	GetReferralCodeOwner(ctx context.Context, code string) (string, error)
+	CreateReferral(ctx context.Context, referrerID, referredID string) error
+	DeleteReferral(ctx context.Context, referredID string) error
+	CountReferrals(ctx context.Context, referrerID string) (int, error)
+}
+
+// Granter applies grants (implemented by *subscription.Manager)
+type Granter interface {
+	GrantPlan(ctx context.Context, userID, planCode string, days int) (*subscription.Subscription, error)
+	GrantFeatureUntil(ctx context.Context, userID, featureKey, source string, expiresAt *time.Time) error
+}
+
+// Config tunes referral credits
+type Config struct {
+	ReferralPlan    string        // Plan both users get (default premium)
+	ReferralDays    int           // Days of the plan (default 7)
+	ReferralWindow  time.Duration // How new an account must be to use a referral code (default 14 days)
+	ReferralBaseURL string        // Share link base; the code is added as ?ref= (optional)
+}
+
+// Redemption is what a redeemed code granted
+type Redemption struct {
+	Kind        string     `json:"kind"` // promo, sponsor, referral
+	Code        string     `json:"code"`
+	GrantType   string     `json:"grant_type"`   // plan or feature
+	GrantTarget string     `json:"grant_target"` // Plan code or feature key
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Referral is a user's own referral code and how often it was used
+type Referral struct {
+	Code      string `json:"code"`
+	Link      string `json:"link,omitempty"`
+	Referrals int    `json:"referrals"`
+	Plan      string `json:"plan"`
+	Days      int    `json:"days"`
+}
+
+// Service redeems and issues codes
+type Service struct {
+	store   Store
+	granter Granter
+	cfg     Config
+	now     func() time.Time
+}
+
+// NewService creates a codes service
+func NewService(store Store, granter Granter, cfg Config) *Service {
+	if cfg.ReferralPlan == "" {
+		cfg.ReferralPlan = "premium"
+	}
+	if cfg.ReferralDays <= 0 {
+		cfg.ReferralDays = 7
+	}
+	if cfg.ReferralWindow <= 0 {
+		cfg.ReferralWindow = 14 * 24 * time.Hour
+	}
+	return &Service{store: store, granter: granter, cfg: cfg, now: time.Now}
+}
+
+// Normalize returns a code as stored: trimmed and upper-case
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// alphabet leaves out look-alikes (0/O, 1/I) so codes survive being read aloud or copied by hand
+const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Generate returns a random code of n characters
+func Generate(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generate code: %w", err)
+		}
+		b[i] = alphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// maxAttempts bounds retries when a generated code is already taken
+const maxAttempts = 3
+
+// Redeem applies a promo, sponsor or referral code for a user
+func (s *Service) Redeem(ctx context.Context, userID, code string) (*Redemption, error) {
+	code = Normalize(code)
+	if code == "" {
+		return nil, ErrCodeNotFound
+	}
+
+	promo, err := s.store.GetPromoCodeByCode(ctx, code)
+	if errors.Is(err, db.ErrNotFound) {
+		return s.redeemReferral(ctx, userID, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.redeemPromo(ctx, userID, promo)
+}
+
+func (s *Service) redeemPromo(ctx context.Context, userID string, c *db.PromoCode) (*Redemption, error) {
+	now := s.now()
+	if !c.Active || (c.ExpiresAt != nil && !c.ExpiresAt.After(now)) ||
+		(c.MaxRedemptions != nil && c.RedemptionCount >= *c.MaxRedemptions) {
+		return nil, ErrCodeUnavailable
+	}
+
+	switch err := s.store.RedeemPromoCode(ctx, c.ID, userID); {
+	case errors.Is(err, db.ErrAlreadyExists):
+		return nil, ErrAlreadyRedeemed
+	case errors.Is(err, db.ErrNotFound):
+		return nil, ErrCodeUnavailable
+	case err != nil:
+		return nil, err
+	}
+
+	r := &Redemption{Kind: KindPromo, Code: c.Code, GrantType: c.GrantType, GrantTarget: c.GrantTarget}
+	source := subscription.GrantPromo
+	if c.SponsorID != nil {
+		r.Kind = KindSponsor
+		source = subscription.GrantSponsor
+	}
+
+	var grantErr error
+	switch c.GrantType {
+	case db.GrantTypePlan:
+		var sub *subscription.Subscription
+		if sub, grantErr = s.granter.GrantPlan(ctx, userID, c.GrantTarget, c.DurationDays); grantErr == nil {
+			r.ExpiresAt = sub.EndsAt
+		}
+	case db.GrantTypeFeature:
+		until := now.AddDate(0, 0, c.DurationDays)
+		r.ExpiresAt = &until
+		grantErr = s.granter.GrantFeatureUntil(ctx, userID, c.GrantTarget, source, &until)
+	default:
+		grantErr = fmt.Errorf("unknown grant type %q", c.GrantType)
+	}
+
+	if grantErr != nil {
+		// Give the use back so the user can try again once the grant can apply
+		if err := s.store.DeleteCodeRedemption(ctx, c.ID, userID); err != nil {
+			log.Printf("codes: failed to undo redemption of %s by %s: %v", c.Code, userID, err)
+		}
+		return nil, grantErr
+	}
+	return r, nil
+}
+
+func (s *Service) redeemReferral(ctx context.Context, userID, code string) (*Redemption, error) {
+	referrerID, err := s.store.GetReferralCodeOwner(ctx, code)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, ErrCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if referrerID == userID {
+		return nil, ErrSelfReferral
+	}
+
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.now().Sub(user.CreatedAt) > s.cfg.ReferralWindow {
+		return nil, ErrReferralNotEligible
+	}
+
+	if err := s.store.CreateReferral(ctx, referrerID, userID); errors.Is(err, db.ErrAlreadyExists) {
+		return nil, ErrAlreadyReferred
+	} else if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.granter.GrantPlan(ctx, userID, s.cfg.ReferralPlan, s.cfg.ReferralDays)
+	if err != nil {
+		if err := s.store.DeleteReferral(ctx, userID); err != nil {
+			log.Printf("codes: failed to undo referral of %s: %v", userID, err)
+		}
+		return nil, err
+	}
+
+	// The new user's credit stands even if the referrer's cannot apply (e.g. they are on another paid plan)
+	if _, err := s.granter.GrantPlan(ctx, referrerID, s.cfg.ReferralPlan, s.cfg.ReferralDays); err != nil {
+		log.Printf("codes: referral credit for %s not applied: %v", referrerID, err)
+	}
+
+	return &Redemption{
+		Kind:        KindReferral,
+		Code:        code,
+		GrantType:   db.GrantTypePlan,
+		GrantTarget: s.cfg.ReferralPlan,
+		ExpiresAt:   sub.EndsAt,
+	}, nil
+}
+
+// ReferralCode returns the user's referral code, creating it on first use
+func (s *Service) ReferralCode(ctx context.Context, userID string) (*Referral, error) {
+	code, err := s.store.GetReferralCode(ctx, userID)
+	if errors.Is(err, db.ErrNotFound) {
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			if code, err = Generate(8); err != nil {
+				return nil, err
+			}
+			if code, err = s.store.CreateReferralCode(ctx, userID, code); !errors.Is(err, db.ErrAlreadyExists) {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.CountReferrals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Referral{Code: code, Referrals: count, Plan: s.cfg.ReferralPlan, Days: s.cfg.ReferralDays}
+	if s.cfg.ReferralBaseURL != "" {
+		r.Link = s.cfg.ReferralBaseURL + "?ref=" + url.QueryEscape(code)
+	}
+	return r, nil
+}
+
+// CreatePromoCode saves a promo code, generating one when c.Code is empty
+func (s *Service) CreatePromoCode(ctx context.Context, c *db.PromoCode) error {
+	c.Code = Normalize(c.Code)
+	if c.Code != "" {
+		return s.store.CreatePromoCode(ctx, c)
+	}
+
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if c.Code, err = Generate(10); err != nil {
+			return err
+		}
+		if err = s.store.CreatePromoCode(ctx, c); !errors.Is(err, db.ErrAlreadyExists) {
+			return err
+		}
+	}
+	return err
+}
+
+// IssueSponsorCodes creates count single-use seat codes for a sponsor
+func (s *Service) IssueSponsorCodes(ctx context.Context, sponsorID string, count int) ([]db.PromoCode, error) {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		batch := make([]string, count)
+		for i := range batch {
+			if batch[i], err = Generate(10); err != nil {
+				return nil, err
+			}
+		}
+		var issued []db.PromoCode
+		if issued, err = s.store.IssueSponsorCodes(ctx, sponsorID, batch); !errors.Is(err, db.ErrAlreadyExists) {
+			return issued, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,310 @@
+package codes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
+)
+
+type fakeStore struct {
+	users       map[string]*db.User
+	promos      map[string]*db.PromoCode
+	redemptions map[string]bool // codeID/userID
+	referral    map[string]string
+	referrals   map[string]string // referred -> referrer
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:       make(map[string]*db.User),
+		promos:      make(map[string]*db.PromoCode),
+		redemptions: make(map[string]bool),
+		referral:    make(map[string]string),
+		referrals:   make(map[string]string),
+	}
+}
+
+func (f *fakeStore) GetUserByID(_ context.Context, id string) (*db.User, error) {
+	if u, ok := f.users[id]; ok {
+		return u, nil
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeStore) GetPromoCodeByCode(_ context.Context, code string) (*db.PromoCode, error) {
+	for _, c := range f.promos {
+		if c.Code == code {
+			return c, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeStore) CreatePromoCode(_ context.Context, c *db.PromoCode) error {
+	if _, err := f.GetPromoCodeByCode(context.Background(), c.Code); err == nil {
+		return db.ErrAlreadyExists
+	}
+	c.ID = "promo-" + c.Code
+	c.Active = true
+	f.promos[c.ID] = c
+	return nil
+}
+
+func (f *fakeStore) RedeemPromoCode(_ context.Context, codeID, userID string) error {
+	if f.redemptions[codeID+"/"+userID] {
+		return db.ErrAlreadyExists
+	}
+	c := f.promos[codeID]
+	if c.MaxRedemptions != nil && c.RedemptionCount >= *c.MaxRedemptions {
+		return db.ErrNotFound
+	}
+	f.redemptions[codeID+"/"+userID] = true
+	c.RedemptionCount++
+	return nil
+}
+
+func (f *fakeStore) DeleteCodeRedemption(_ context.Context, codeID, userID string) error {
+	if f.redemptions[codeID+"/"+userID] {
+		delete(f.redemptions, codeID+"/"+userID)
+		f.promos[codeID].RedemptionCount--
+	}
+	return nil
+}
+
+func (f *fakeStore) IssueSponsorCodes(_ context.Context, sponsorID string, codes []string) ([]db.PromoCode, error) {
+	issued := make([]db.PromoCode, 0, len(codes))
+	for _, code := range codes {
+		c := &db.PromoCode{Code: code, GrantType: db.GrantTypePlan, GrantTarget: "premium", DurationDays: 90, SponsorID: &sponsorID}
+		if err := f.CreatePromoCode(context.Background(), c); err != nil {
+			return nil, err
+		}
+		issued = append(issued, *c)
+	}
+	return issued, nil
+}
+
+func (f *fakeStore) GetReferralCode(_ context.Context, userID string) (string, error) {
+	if code, ok := f.referral[userID]; ok {
+		return code, nil
+	}
+	return "", db.ErrNotFound
+}
+
+func (f *fakeStore) CreateReferralCode(_ context.Context, userID, code string) (string, error) {
+	f.referral[userID] = code
+	return code, nil
+}
+
+func (f *fakeStore) GetReferralCodeOwner(_ context.Context, code string) (string, error) {
+	for userID, c := range f.referral {
+		if c == code {
+			return userID, nil
+		}
+	}
+	return "", db.ErrNotFound
+}
+
+func (f *fakeStore) CreateReferral(_ context.Context, referrerID, referredID string) error {
+	if _, ok := f.referrals[referredID]; ok {
+		return db.ErrAlreadyExists
+	}
+	f.referrals[referredID] = referrerID
+	return nil
+}
+
+func (f *fakeStore) DeleteReferral(_ context.Context, referredID string) error {
+	delete(f.referrals, referredID)
+	return nil
+}
+
+func (f *fakeStore) CountReferrals(_ context.Context, referrerID string) (int, error) {
+	n := 0
+	for _, r := range f.referrals {
+		if r == referrerID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type fakeGranter struct {
+	plans    map[string]int // userID/plan -> days
+	features map[string]string
+	conflict map[string]bool // GrantPlan fails for these users
+}
+
+func newFakeGranter() *fakeGranter {
+	return &fakeGranter{plans: make(map[string]int), features: make(map[string]string), conflict: make(map[string]bool)}
+}
+
+func (g *fakeGranter) GrantPlan(_ context.Context, userID, planCode string, days int) (*subscription.Subscription, error) {
+	if g.conflict[userID] {
+		return nil, subscription.ErrPlanConflict
+	}
+	g.plans[userID+"/"+planCode] += days
+	endsAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+	return &subscription.Subscription{PlanCode: planCode, EndsAt: &endsAt}, nil
+}
+
+func (g *fakeGranter) GrantFeatureUntil(_ context.Context, userID, featureKey, source string, _ *time.Time) error {
+	g.features[userID+"/"+featureKey] = source
+	return nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestService_RedeemPromo(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	store := newFakeStore()
+	store.promos["p1"] = &db.PromoCode{ID: "p1", Code: "MOMSDAY", GrantType: db.GrantTypePlan, GrantTarget: "premium", DurationDays: 30, Active: true}
+	store.promos["p2"] = &db.PromoCode{ID: "p2", Code: "ONCE", GrantType: db.GrantTypeFeature, GrantTarget: "voice_calls", DurationDays: 7, MaxRedemptions: intPtr(1), Active: true}
+	store.promos["p3"] = &db.PromoCode{ID: "p3", Code: "OLD", GrantType: db.GrantTypePlan, GrantTarget: "premium", DurationDays: 30, ExpiresAt: &past, Active: true}
+	granter := newFakeGranter()
+
+	s := NewService(store, granter, Config{})
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	r, err := s.Redeem(ctx, "user-1", " momsday ")
+	if err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if r.Kind != KindPromo || r.GrantTarget != "premium" || r.ExpiresAt == nil || granter.plans["user-1/premium"] != 30 {
+		t.Errorf("redemption = %+v, plans = %v", r, granter.plans)
+	}
+	if _, err := s.Redeem(ctx, "user-1", "MOMSDAY"); !errors.Is(err, ErrAlreadyRedeemed) {
+		t.Errorf("second redemption err = %v, want ErrAlreadyRedeemed", err)
+	}
+
+	r, err = s.Redeem(ctx, "user-1", "once")
+	if err != nil {
+		t.Fatalf("Redeem feature: %v", err)
+	}
+	if want := now.AddDate(0, 0, 7); r.ExpiresAt == nil || !r.ExpiresAt.Equal(want) || granter.features["user-1/voice_calls"] != subscription.GrantPromo {
+		t.Errorf("redemption = %+v, features = %v", r, granter.features)
+	}
+	if _, err := s.Redeem(ctx, "user-2", "ONCE"); !errors.Is(err, ErrCodeUnavailable) {
+		t.Errorf("used-up code err = %v, want ErrCodeUnavailable", err)
+	}
+	if _, err := s.Redeem(ctx, "user-2", "OLD"); !errors.Is(err, ErrCodeUnavailable) {
+		t.Errorf("expired code err = %v, want ErrCodeUnavailable", err)
+	}
+	if _, err := s.Redeem(ctx, "user-2", "NOPE"); !errors.Is(err, ErrCodeNotFound) {
+		t.Errorf("unknown code err = %v, want ErrCodeNotFound", err)
+	}
+}
+
+func TestService_RedeemPromo_UndoesFailedGrant(t *testing.T) {
+	store := newFakeStore()
+	store.promos["p1"] = &db.PromoCode{ID: "p1", Code: "SEAT", GrantType: db.GrantTypePlan, GrantTarget: "premium", DurationDays: 30, MaxRedemptions: intPtr(1), Active: true}
+	granter := newFakeGranter()
+	granter.conflict["paid"] = true
+
+	s := NewService(store, granter, Config{})
+	if _, err := s.Redeem(context.Background(), "paid", "SEAT"); !errors.Is(err, subscription.ErrPlanConflict) {
+		t.Fatalf("err = %v, want ErrPlanConflict", err)
+	}
+	if store.promos["p1"].RedemptionCount != 0 || len(store.redemptions) != 0 {
+		t.Errorf("redemption kept after failed grant: %+v", store.promos["p1"])
+	}
+	if _, err := s.Redeem(context.Background(), "user-2", "SEAT"); err != nil {
+		t.Errorf("seat not released: %v", err)
+	}
+}
+
+func TestService_RedeemReferral(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := newFakeStore()
+	store.users["new"] = &db.User{ID: "new", CreatedAt: now.Add(-24 * time.Hour)}
+	store.users["old"] = &db.User{ID: "old", CreatedAt: now.AddDate(0, -3, 0)}
+	store.users["other"] = &db.User{ID: "other", CreatedAt: now}
+	granter := newFakeGranter()
+
+	s := NewService(store, granter, Config{ReferralBaseURL: "https://app.example.com/join"})
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	ref, err := s.ReferralCode(ctx, "old")
+	if err != nil {
+		t.Fatalf("ReferralCode: %v", err)
+	}
+	if len(ref.Code) != 8 || ref.Link != "https://app.example.com/join?ref="+ref.Code || ref.Plan != "premium" || ref.Days != 7 {
+		t.Errorf("referral = %+v", ref)
+	}
+
+	r, err := s.Redeem(ctx, "new", strings.ToLower(ref.Code))
+	if err != nil {
+		t.Fatalf("Redeem referral: %v", err)
+	}
+	if r.Kind != KindReferral || granter.plans["new/premium"] != 7 || granter.plans["old/premium"] != 7 {
+		t.Errorf("redemption = %+v, plans = %v", r, granter.plans)
+	}
+	if _, err := s.Redeem(ctx, "new", ref.Code); !errors.Is(err, ErrAlreadyReferred) {
+		t.Errorf("second referral err = %v, want ErrAlreadyReferred", err)
+	}
+	if _, err := s.Redeem(ctx, "old", ref.Code); !errors.Is(err, ErrSelfReferral) {
+		t.Errorf("self referral err = %v, want ErrSelfReferral", err)
+	}
+
+	newRef, _ := s.ReferralCode(ctx, "new")
+	if _, err := s.Redeem(ctx, "old", newRef.Code); !errors.Is(err, ErrReferralNotEligible) {
+		t.Errorf("old account err = %v, want ErrReferralNotEligible", err)
+	}
+
+	// The referrer's credit is skipped, not the new user's
+	granter.conflict["old"] = true
+	if _, err := s.Redeem(ctx, "other", ref.Code); err != nil {
+		t.Fatalf("Redeem with referrer on another plan: %v", err)
+	}
+	if granter.plans["other/premium"] != 7 {
+		t.Errorf("plans = %v", granter.plans)
+	}
+
+	ref, _ = s.ReferralCode(ctx, "old")
+	if ref.Referrals != 2 {
+		t.Errorf("referrals = %d, want 2", ref.Referrals)
+	}
+}
+
+func TestService_IssueSponsorCodes(t *testing.T) {
+	store := newFakeStore()
+	granter := newFakeGranter()
+	s := NewService(store, granter, Config{})
+
+	issued, err := s.IssueSponsorCodes(context.Background(), "sponsor-1", 3)
+	if err != nil {
+		t.Fatalf("IssueSponsorCodes: %v", err)
+	}
+	if len(issued) != 3 {
+		t.Fatalf("issued %d codes, want 3", len(issued))
+	}
+
+	r, err := s.Redeem(context.Background(), "user-1", issued[0].Code)
+	if err != nil {
+		t.Fatalf("Redeem sponsor code: %v", err)
+	}
+	if r.Kind != KindSponsor || granter.plans["user-1/premium"] != 90 {
+		t.Errorf("redemption = %+v, plans = %v", r, granter.plans)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate(10)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(code) != 10 {
+		t.Errorf("len = %d, want 10", len(code))
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(alphabet, r) {
+			t.Errorf("code %q has %q outside the alphabet", code, r)
+		}
+	}
+}
@@ -21,13 +21,12 @@ type PostAnalysis struct {
 
 // Processor classifies posts using LLM with rule-based fallback.
 type Processor struct {
-	gemini   llm.Client
-	deepseek llm.Client
+	llmClient llm.Client
 }
 
-// NewProcessor creates a post analysis processor.
-func NewProcessor(gemini, deepseek llm.Client) *Processor {
-	return &Processor{gemini: gemini, deepseek: deepseek}
+// NewProcessor creates a post analysis processor. The LLM client may be nil.
+func NewProcessor(client llm.Client) *Processor {
+	return &Processor{llmClient: client}
 }
 
 // AnalyzePost returns classification metadata for a post body.
@@ -38,15 +37,11 @@ func (p *Processor) AnalyzePost(ctx context.Context, body string, validCategorie
 
 	prompt := buildAnalysisPrompt(validCategories)
 
-	client := p.gemini
-	if client == nil {
-		client = p.deepseek
-	}
-	if client == nil {
+	if p.llmClient == nil {
 		return fallback
 	}
 
-	resp, err := client.ChatCompletion(ctx, llm.ChatRequest{
+	resp, err := p.llmClient.ChatCompletion(llm.WithTask(ctx, llm.TaskModeration), llm.ChatRequest{
 		Messages: []llm.ChatMessage{
 			{Role: "user", Content: prompt + body},
 		},
@@ -39,7 +39,7 @@ func FallbackTitle(userMessage string) string {
 
 // GenerateTitle asks the LLM for a concise conversation title.
 func GenerateTitle(ctx context.Context, client llm.Client, userMessage, assistantMessage string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 8*time.Second)
+	ctx, cancel := context.WithTimeout(llm.WithTask(ctx, llm.TaskTitle), 8*time.Second)
 	defer cancel()
 
 	prompt := fmt.Sprintf(
@@ -18,6 +18,7 @@ type Plan struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Active      bool      `json:"active"`
+	TrialDays   int       `json:"trial_days"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -44,16 +45,17 @@ func (db *DB) CreatePlan(ctx context.Context, code, name, description string) (*
 }
 
 // UpdatePlan updates an existing plan
-func (db *DB) UpdatePlan(ctx context.Context, planID int, name, description string, active *bool) error {
+func (db *DB) UpdatePlan(ctx context.Context, planID int, name, description string, active *bool, trialDays *int) error {
 	query := `
 		UPDATE plans 
 		SET name = COALESCE(NULLIF($2, ''), name),
 		    description = COALESCE(NULLIF($3, ''), description),
-		    active = COALESCE($4, active)
+		    active = COALESCE($4, active),
+		    trial_days = COALESCE($5, trial_days)
 		WHERE id = $1
 	`
 
-	result, err := db.ExecContext(ctx, query, planID, name, description, active)
+	result, err := db.ExecContext(ctx, query, planID, name, description, active, trialDays)
 	if err != nil {
 		return fmt.Errorf("failed to update plan: %w", err)
 	}
@@ -86,7 +88,7 @@ func (db *DB) DeactivatePlan(ctx context.Context, planID int) error {
 // GetAllPlans returns all plans
 func (db *DB) GetAllPlans(ctx context.Context) ([]Plan, error) {
 	query := `
-		SELECT id, code, name, description, active, created_at
+		SELECT id, code, name, description, active, trial_days, created_at
 		FROM plans
 		ORDER BY created_at
 	`
@@ -100,7 +102,7 @@ func (db *DB) GetAllPlans(ctx context.Context) ([]Plan, error) {
 	var plans []Plan
 	for rows.Next() {
 		var p Plan
-		if err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.Description, &p.Active, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.Description, &p.Active, &p.TrialDays, &p.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan plan: %w", err)
 		}
 		plans = append(plans, p)
@@ -527,23 +529,74 @@ func (db *DB) GetUserStats(ctx context.Context) (*UserStats, error) {
 	return stats, nil
 }
 
-// VoiceCall represents a voice call record
+// VoiceCall represents an outbound call and its outcome
 type VoiceCall struct {
-	CallSID     string    `json:"call_sid"`
-	UserID      string    `json:"user_id"`
-	UserEmail   string    `json:"user_email"`
-	PhoneNumber string    `json:"phone_number"`
-	Duration    int       `json:"duration_seconds"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID           string    `json:"id"`
+	CallSID      string    `json:"call_sid"`
+	UserID       string    `json:"user_id"`
+	UserEmail    string    `json:"user_email"`
+	PhoneNumber  string    `json:"phone_number"`
+	Kind         string    `json:"kind"` // checkin or reminder
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Attempts     int       `json:"attempts"`
+	Duration     int       `json:"duration_seconds"`
+	Status       string    `json:"status"`
+	LastError    *string   `json:"last_error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
-// GetVoiceCallHistory returns voice call history (placeholder - requires voice_calls table)
+// GetVoiceCallHistory returns outbound calls created since, newest first
 func (db *DB) GetVoiceCallHistory(ctx context.Context, since time.Time, limit int) ([]VoiceCall, error) {
-	// Note: This requires a voice_calls table to be added to the schema
-	// For now, return empty slice
-	// TODO: Add voice_calls table migration and implement this query
-	return []VoiceCall{}, nil
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.id, COALESCE(c.call_sid, ''), c.user_id, u.email, COALESCE(up.phone_number, ''),
+		       c.kind, c.scheduled_for, c.attempts, COALESCE(c.duration_seconds, 0), c.status, c.last_error, c.created_at
+		FROM outbound_calls c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN user_phones up ON up.user_id = c.user_id
+		WHERE c.created_at >= $1
+		ORDER BY c.created_at DESC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voice call history: %w", err)
+	}
+	defer rows.Close()
+
+	calls := make([]VoiceCall, 0)
+	for rows.Next() {
+		var call VoiceCall
+		if err := rows.Scan(&call.ID, &call.CallSID, &call.UserID, &call.UserEmail, &call.PhoneNumber,
+			&call.Kind, &call.ScheduledFor, &call.Attempts, &call.Duration, &call.Status, &call.LastError, &call.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan voice call: %w", err)
+		}
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
+
+// GetVoiceCallOutcomes counts outbound calls created since by status
+func (db *DB) GetVoiceCallOutcomes(ctx context.Context, since time.Time) (map[string]int, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT status, COUNT(*)
+		FROM outbound_calls
+		WHERE created_at >= $1
+		GROUP BY status
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voice call outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	outcomes := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan voice call outcome: %w", err)
+		}
+		outcomes[status] = count
+	}
+	return outcomes, rows.Err()
 }
 
 // isDuplicateKeyError checks if an error is a duplicate key violation
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Billing event statuses
+const (
+	BillingEventReceived   = "received"
+	BillingEventProcessing = "processing"
+	BillingEventProcessed  = "processed"
+	BillingEventIgnored    = "ignored"
+	BillingEventFailed     = "failed"
+)
+
+// Billing payment statuses
+const (
+	PaymentSucceeded = "succeeded"
+	PaymentFailed    = "failed"
+)
+
+// PlanPrice is what a plan costs with one payment provider
+type PlanPrice struct {
+	ID              int       `json:"id"`
+	PlanID          int       `json:"plan_id"`
+	PlanCode        string    `json:"plan_code"`
+	PlanName        string    `json:"plan_name"`
+	Provider        string    `json:"provider"`
+	Currency        string    `json:"currency"`
+	Amount          int64     `json:"amount"`   // Minor units (cents, kobo)
+	Interval        string    `json:"interval"` // month or year
+	ProviderPriceID *string   `json:"provider_price_id,omitempty"`
+	Active          bool      `json:"active"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// BillingCheckout is a plan purchase started by a user
+type BillingCheckout struct {
+	ID                     string     `json:"id"`
+	Reference              string     `json:"reference"`
+	UserID                 string     `json:"user_id"`
+	PlanCode               string     `json:"plan_code"`
+	Provider               string     `json:"provider"`
+	Currency               string     `json:"currency"`
+	Amount                 int64      `json:"amount"`
+	Interval               string     `json:"interval"`
+	Status                 string     `json:"status"`
+	ProviderCustomerID     *string    `json:"provider_customer_id,omitempty"`
+	ProviderSubscriptionID *string    `json:"provider_subscription_id,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	CompletedAt            *time.Time `json:"completed_at,omitempty"`
+}
+
+// BillingEvent is a webhook received from a payment provider
+type BillingEvent struct {
+	ID       string
+	Provider string
+	EventID  string
+	Type     string
+	Payload  []byte
+}
+
+// BillingPayment is a payment in the user's billing history
+type BillingPayment struct {
+	ID                string     `json:"id"`
+	UserID            string     `json:"-"`
+	CheckoutID        *string    `json:"-"`
+	Provider          string     `json:"provider"`
+	ProviderReference string     `json:"reference"`
+	PlanCode          string     `json:"plan_code"`
+	Currency          string     `json:"currency"`
+	Amount            int64      `json:"amount"`
+	Status            string     `json:"status"`
+	PeriodEnd         *time.Time `json:"period_end,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+const planPriceSelectSQL = `
+	SELECT pp.id, pp.plan_id, p.code, p.name, pp.provider, pp.currency, pp.amount,
+	       pp.billing_interval, pp.provider_price_id, pp.active, pp.updated_at
+	FROM plan_prices pp
+	JOIN plans p ON p.id = pp.plan_id`
+
+func scanPlanPrice(scanner interface {
+	Scan(dest ...any) error
+}) (*PlanPrice, error) {
+	var p PlanPrice
+	if err := scanner.Scan(
+		&p.ID, &p.PlanID, &p.PlanCode, &p.PlanName, &p.Provider, &p.Currency, &p.Amount,
+		&p.Interval, &p.ProviderPriceID, &p.Active, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPlanPrices returns the active prices of active plans
+func (db *DB) ListPlanPrices(ctx context.Context) ([]PlanPrice, error) {
+	rows, err := db.QueryContext(ctx, planPriceSelectSQL+`
+		WHERE pp.active = TRUE AND p.active = TRUE
+		ORDER BY p.id, pp.provider
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make([]PlanPrice, 0)
+	for rows.Next() {
+		p, err := scanPlanPrice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plan price: %w", err)
+		}
+		prices = append(prices, *p)
+	}
+	return prices, rows.Err()
+}
+
+// GetPlanPrice returns the active price of an active plan with a provider
+func (db *DB) GetPlanPrice(ctx context.Context, planCode, provider string) (*PlanPrice, error) {
+	p, err := scanPlanPrice(db.QueryRowContext(ctx, planPriceSelectSQL+`
+		WHERE p.code = $1 AND pp.provider = $2 AND pp.active = TRUE AND p.active = TRUE
+	`, planCode, provider))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan price: %w", err)
+	}
+	return p, nil
+}
+
+// UpsertPlanPrice sets a plan's price with a provider
+func (db *DB) UpsertPlanPrice(ctx context.Context, p *PlanPrice) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO plan_prices (plan_id, provider, currency, amount, billing_interval, provider_price_id, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (plan_id, provider) DO UPDATE
+		SET currency = $3, amount = $4, billing_interval = $5, provider_price_id = $6, active = $7,
+		    updated_at = NOW()
+		RETURNING id, updated_at
+	`, p.PlanID, p.Provider, p.Currency, p.Amount, p.Interval, p.ProviderPriceID, p.Active,
+	).Scan(&p.ID, &p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save plan price: %w", err)
+	}
+	return nil
+}
+
+// CreateBillingCheckout stores a pending checkout
+func (db *DB) CreateBillingCheckout(ctx context.Context, c *BillingCheckout) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO billing_checkouts (reference, user_id, plan_code, provider, currency, amount, billing_interval)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`, c.Reference, c.UserID, c.PlanCode, c.Provider, c.Currency, c.Amount, c.Interval,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create checkout: %w", err)
+	}
+	return nil
+}
+
+// FindBillingCheckout returns the provider's checkout with reference or,
+// failing that, the latest one linked to the subscription or customer ID.
+// Empty keys are skipped.
+func (db *DB) FindBillingCheckout(ctx context.Context, provider, reference, subscriptionID, customerID string) (*BillingCheckout, error) {
+	var c BillingCheckout
+	err := db.QueryRowContext(ctx, `
+		SELECT id, reference, user_id, plan_code, provider, currency, amount, billing_interval, status,
+		       provider_customer_id, provider_subscription_id, created_at, completed_at
+		FROM billing_checkouts
+		WHERE provider = $1
+		  AND ((reference = $2 AND $2 <> '')
+		    OR (provider_subscription_id = $3 AND $3 <> '')
+		    OR (provider_customer_id = $4 AND $4 <> ''))
+		ORDER BY reference = $2 DESC, COALESCE(provider_subscription_id = $3, FALSE) DESC, created_at DESC
+		LIMIT 1
+	`, provider, reference, subscriptionID, customerID).Scan(
+		&c.ID, &c.Reference, &c.UserID, &c.PlanCode, &c.Provider, &c.Currency, &c.Amount, &c.Interval, &c.Status,
+		&c.ProviderCustomerID, &c.ProviderSubscriptionID, &c.CreatedAt, &c.CompletedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checkout: %w", err)
+	}
+	return &c, nil
+}
+
+// CompleteBillingCheckout marks a checkout completed and links the
+// provider's customer and subscription IDs (empty IDs are left unchanged)
+func (db *DB) CompleteBillingCheckout(ctx context.Context, id, customerID, subscriptionID string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE billing_checkouts
+		SET status = 'completed',
+		    completed_at = COALESCE(completed_at, NOW()),
+		    provider_customer_id = COALESCE(NULLIF($2, ''), provider_customer_id),
+		    provider_subscription_id = COALESCE(NULLIF($3, ''), provider_subscription_id)
+		WHERE id = $1
+	`, id, customerID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to complete checkout: %w", err)
+	}
+	return nil
+}
+
+// BillingEventClaimTimeout is how long a claimed event may stay processing
+// before another delivery of it takes over, e.g. after a crash
+const BillingEventClaimTimeout = 10 * time.Minute
+
+// RecordBillingEvent logs a webhook and claims the event for processing. It
+// returns false when the event was already processed or ignored, or another
+// delivery of it is being processed; only the caller that gets true may
+// apply it, then call FinishBillingEvent.
+func (db *DB) RecordBillingEvent(ctx context.Context, e *BillingEvent) (bool, error) {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO billing_events (provider, event_id, type, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, event_id) DO UPDATE
+		SET deliveries = billing_events.deliveries + 1
+		RETURNING id
+	`, e.Provider, e.EventID, e.Type, e.Payload).Scan(&e.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record billing event: %w", err)
+	}
+
+	var id string
+	err = db.QueryRowContext(ctx, `
+		UPDATE billing_events
+		SET status = 'processing', claimed_at = NOW()
+		WHERE id = $1
+		  AND (status IN ('received', 'failed')
+		       OR (status = 'processing' AND claimed_at < $2))
+		RETURNING id
+	`, e.ID, time.Now().Add(-BillingEventClaimTimeout)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim billing event: %w", err)
+	}
+	return true, nil
+}
+
+// FinishBillingEvent records the outcome of processing an event
+func (db *DB) FinishBillingEvent(ctx context.Context, id, status string, userID *string, processErr error) error {
+	var message *string
+	if processErr != nil {
+		text := processErr.Error()
+		message = &text
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE billing_events
+		SET status = $2, user_id = COALESCE($3, user_id), error = $4, processed_at = NOW()
+		WHERE id = $1
+	`, id, status, userID, message)
+	if err != nil {
+		return fmt.Errorf("failed to update billing event: %w", err)
+	}
+	return nil
+}
+
+// CreateBillingPayment adds a payment to the user's history; a payment
+// already recorded with the same reference and status is skipped
+func (db *DB) CreateBillingPayment(ctx context.Context, p *BillingPayment) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO billing_payments
+			(user_id, checkout_id, provider, provider_reference, plan_code, currency, amount, status, period_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (provider, provider_reference, status) DO NOTHING
+	`, p.UserID, p.CheckoutID, p.Provider, p.ProviderReference, p.PlanCode, p.Currency, p.Amount, p.Status, p.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("failed to record payment: %w", err)
+	}
+	return nil
+}
+
+// ListBillingPayments returns the user's payments, newest first
+func (db *DB) ListBillingPayments(ctx context.Context, userID string, limit int) ([]BillingPayment, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, provider, provider_reference, plan_code, currency, amount, status, period_end, created_at
+		FROM billing_payments
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make([]BillingPayment, 0)
+	for rows.Next() {
+		p := BillingPayment{UserID: userID}
+		if err := rows.Scan(&p.ID, &p.Provider, &p.ProviderReference, &p.PlanCode, &p.Currency,
+			&p.Amount, &p.Status, &p.PeriodEnd, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, rows.Err()
+}
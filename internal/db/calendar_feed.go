@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CalendarFeed is a user's iCalendar subscription. Only a hash of the token
+// in the feed URL is stored.
+type CalendarFeed struct {
+	UserID         string     `json:"user_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// SaveCalendarFeedToken creates the user's feed, or rotates its token so the
+// previous URL stops working
+func (db *DB) SaveCalendarFeedToken(ctx context.Context, userID, tokenHash string) (*CalendarFeed, error) {
+	query := `
+		INSERT INTO calendar_feeds (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash,
+		    created_at = CURRENT_TIMESTAMP,
+		    last_accessed_at = NULL
+		RETURNING created_at
+	`
+
+	feed := &CalendarFeed{UserID: userID}
+	if err := db.QueryRowContext(ctx, query, userID, tokenHash).Scan(&feed.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+	return feed, nil
+}
+
+// GetCalendarFeed returns the user's feed, or ErrNotFound if it is disabled
+func (db *DB) GetCalendarFeed(ctx context.Context, userID string) (*CalendarFeed, error) {
+	query := `
+		SELECT user_id, created_at, last_accessed_at
+		FROM calendar_feeds
+		WHERE user_id = $1
+	`
+
+	feed := &CalendarFeed{}
+	err := db.QueryRowContext(ctx, query, userID).Scan(&feed.UserID, &feed.CreatedAt, &feed.LastAccessedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return feed, nil
+}
+
+// DeleteCalendarFeed disables the user's feed
+func (db *DB) DeleteCalendarFeed(ctx context.Context, userID string) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetUserIDByCalendarFeedToken resolves a feed token hash to its owner and
+// records the access
+func (db *DB) GetUserIDByCalendarFeedToken(ctx context.Context, tokenHash string) (string, error) {
+	query := `
+		UPDATE calendar_feeds
+		SET last_accessed_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		RETURNING user_id
+	`
+
+	var userID string
+	err := db.QueryRowContext(ctx, query, tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up calendar feed: %w", err)
+	}
+	return userID, nil
+}
+
+// GetUpcomingAppointments returns the user's doctor visits with a next
+// appointment at or after since, soonest first
+func (db *DB) GetUpcomingAppointments(ctx context.Context, userID string, since time.Time) ([]DoctorVisit, error) {
+	query := `
+		SELECT ` + doctorVisitSelectColumns + `
+		FROM doctor_visits
+		WHERE user_id = $1 AND next_appointment_at >= $2
+		ORDER BY next_appointment_at ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointments: %w", err)
+	}
+	defer rows.Close()
+
+	visits := make([]DoctorVisit, 0)
+	for rows.Next() {
+		var visit DoctorVisit
+		if err := scanDoctorVisit(rows, &visit); err != nil {
+			return nil, fmt.Errorf("failed to scan doctor visit: %w", err)
+		}
+		visits = append(visits, visit)
+	}
+	return visits, rows.Err()
+}
+
+// GetInterestedCommunityEvents returns the community events the user marked
+// as interested that start at or after since
+func (db *DB) GetInterestedCommunityEvents(ctx context.Context, userID string, since time.Time) ([]CommunityEvent, error) {
+	query := `
+		SELECT e.id, e.post_id, e.event_type, e.title, e.description, e.venue, e.starts_at, e.ends_at,
+		       e.country, e.state_province, e.city, e.interested_count, e.created_at
+		FROM community_events e
+		JOIN community_event_interests ei ON ei.event_id = e.id
+		WHERE ei.user_id = $1 AND e.starts_at >= $2
+		ORDER BY e.starts_at ASC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interested events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]CommunityEvent, 0)
+	for rows.Next() {
+		event := CommunityEvent{InterestedByMe: true}
+		if err := rows.Scan(
+			&event.ID, &event.PostID, &event.EventType, &event.Title, &event.Description, &event.Venue,
+			&event.StartsAt, &event.EndsAt, &event.Country, &event.StateProvince, &event.City,
+			&event.InterestedCount, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan community event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// UpsertImportedReminder creates a reminder imported from an ICS file, or
+// updates the one imported earlier with the same ICalUID. inserted reports
+// which happened.
+func (db *DB) UpsertImportedReminder(ctx context.Context, reminder *Reminder) (inserted bool, err error) {
+	if reminder.Timezone == "" {
+		reminder.Timezone = "UTC"
+	}
+	reminder.Source = ReminderSourceUser
+	query := `
+		INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, rrule, timezone, ical_uid, next_notify_at)
+		VALUES ($1, $2, $3, $4, FALSE, $5, $6, $7, $4)
+		ON CONFLICT (user_id, ical_uid) WHERE ical_uid IS NOT NULL DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    reminder_time = EXCLUDED.reminder_time,
+		    rrule = EXCLUDED.rrule,
+		    timezone = EXCLUDED.timezone,
+		    next_notify_at = CASE
+		        WHEN reminders.is_completed THEN reminders.next_notify_at
+		        WHEN reminders.reminder_time IS DISTINCT FROM EXCLUDED.reminder_time
+		          OR reminders.rrule IS DISTINCT FROM EXCLUDED.rrule
+		          OR reminders.timezone IS DISTINCT FROM EXCLUDED.timezone
+		        THEN EXCLUDED.next_notify_at
+		        ELSE reminders.next_notify_at
+		    END,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at, (xmax = 0)
+	`
+
+	err = db.QueryRowContext(ctx, query,
+		reminder.UserID, reminder.Title, reminder.Description, reminder.ReminderTime,
+		reminder.RRule, reminder.Timezone, reminder.ICalUID,
+	).Scan(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt, &inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to import reminder: %w", err)
+	}
+	return inserted, nil
+}
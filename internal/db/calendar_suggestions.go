@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSuggestionResolved is returned when accepting or dismissing a calendar
+// suggestion that was already accepted or dismissed
+var ErrSuggestionResolved = errors.New("suggestion already resolved")
+
+// Calendar suggestion statuses
+const (
+	SuggestionPending   = "pending"
+	SuggestionAccepted  = "accepted"
+	SuggestionDismissed = "dismissed"
+)
+
+// CalendarSuggestion is a reminder suggestion offered in chat
+type CalendarSuggestion struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	ConversationID *string    `json:"conversation_id,omitempty"`
+	MessageID      *string    `json:"message_id,omitempty"`
+	Type           string     `json:"type"`
+	VisitType      *string    `json:"visit_type,omitempty"`
+	Priority       *string    `json:"priority,omitempty"`
+	Title          string     `json:"title"`
+	Description    *string    `json:"description,omitempty"`
+	SuggestedTime  time.Time  `json:"suggested_time"`
+	Timezone       string     `json:"timezone"`
+	Location       *string    `json:"location,omitempty"`
+	Provider       *string    `json:"provider,omitempty"`
+	TimeSource     string     `json:"time_source"`
+	Status         string     `json:"status"`
+	Edited         bool       `json:"edited"`
+	ReminderID     *string    `json:"reminder_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// CalendarSuggestionStats is the acceptance of one suggestion type
+type CalendarSuggestionStats struct {
+	Type           string  `json:"type"`
+	Total          int     `json:"total"`
+	Accepted       int     `json:"accepted"`
+	Edited         int     `json:"edited"` // Accepted after changes
+	Dismissed      int     `json:"dismissed"`
+	Pending        int     `json:"pending"`
+	AcceptanceRate float64 `json:"acceptance_rate"` // Accepted / resolved
+}
+
+// CreateCalendarSuggestion stores a suggestion as pending
+func (db *DB) CreateCalendarSuggestion(ctx context.Context, s *CalendarSuggestion) error {
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	query := `
+		INSERT INTO calendar_suggestions
+			(user_id, conversation_id, message_id, type, visit_type, priority, title, description,
+			 suggested_time, timezone, location, provider, time_source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, status, created_at
+	`
+	err := db.QueryRowContext(ctx, query,
+		s.UserID, s.ConversationID, s.MessageID, s.Type, s.VisitType, s.Priority, s.Title, s.Description,
+		s.SuggestedTime.UTC(), s.Timezone, s.Location, s.Provider, s.TimeSource,
+	).Scan(&s.ID, &s.Status, &s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create calendar suggestion: %w", err)
+	}
+	return nil
+}
+
+const calendarSuggestionSelectColumns = `
+	id, user_id, conversation_id, message_id, type, visit_type, priority, title, description,
+	suggested_time, timezone, location, provider, time_source, status, edited, reminder_id,
+	created_at, resolved_at
+`
+
+func scanCalendarSuggestion(scanner interface {
+	Scan(dest ...any) error
+}) (*CalendarSuggestion, error) {
+	var s CalendarSuggestion
+	if err := scanner.Scan(
+		&s.ID, &s.UserID, &s.ConversationID, &s.MessageID, &s.Type, &s.VisitType, &s.Priority,
+		&s.Title, &s.Description, &s.SuggestedTime, &s.Timezone, &s.Location, &s.Provider,
+		&s.TimeSource, &s.Status, &s.Edited, &s.ReminderID, &s.CreatedAt, &s.ResolvedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetCalendarSuggestion returns one of the user's suggestions
+func (db *DB) GetCalendarSuggestion(ctx context.Context, userID, id string) (*CalendarSuggestion, error) {
+	s, err := scanCalendarSuggestion(db.QueryRowContext(ctx,
+		`SELECT `+calendarSuggestionSelectColumns+` FROM calendar_suggestions WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar suggestion: %w", err)
+	}
+	return s, nil
+}
+
+// ListPendingCalendarSuggestions returns the user's unresolved suggestions,
+// newest first
+func (db *DB) ListPendingCalendarSuggestions(ctx context.Context, userID string, limit int) ([]CalendarSuggestion, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+calendarSuggestionSelectColumns+`
+		FROM calendar_suggestions
+		WHERE user_id = $1 AND status = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, userID, SuggestionPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]CalendarSuggestion, 0)
+	for rows.Next() {
+		s, err := scanCalendarSuggestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar suggestion: %w", err)
+		}
+		suggestions = append(suggestions, *s)
+	}
+	return suggestions, rows.Err()
+}
+
+// AcceptCalendarSuggestion creates reminder and marks the pending suggestion
+// accepted and linked to it, in one transaction. edited records whether the
+// user changed the suggestion first.
+func (db *DB) AcceptCalendarSuggestion(ctx context.Context, userID, id string, reminder *Reminder, edited bool) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockPendingSuggestion(ctx, tx, userID, id); err != nil {
+		return err
+	}
+
+	if reminder.Timezone == "" {
+		reminder.Timezone = "UTC"
+	}
+	if reminder.Source == "" {
+		reminder.Source = ReminderSourceUser
+	}
+	if err := tx.QueryRowContext(ctx, reminderInsertQuery,
+		reminder.UserID, reminder.Title, reminder.Description,
+		reminder.ReminderTime, reminder.IsCompleted, reminder.CommunityEventID,
+		reminder.RRule, reminder.Timezone, reminder.ICalUID,
+		reminder.Source, reminder.CarePlanItem,
+	).Scan(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE calendar_suggestions
+		SET status = $1, edited = $2, reminder_id = $3, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, SuggestionAccepted, edited, reminder.ID, id); err != nil {
+		return fmt.Errorf("failed to accept calendar suggestion: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DismissCalendarSuggestion marks the pending suggestion dismissed
+func (db *DB) DismissCalendarSuggestion(ctx context.Context, userID, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockPendingSuggestion(ctx, tx, userID, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE calendar_suggestions SET status = $1, resolved_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, SuggestionDismissed, id); err != nil {
+		return fmt.Errorf("failed to dismiss calendar suggestion: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// lockPendingSuggestion locks the user's suggestion for update, returning
+// ErrNotFound or ErrSuggestionResolved if it cannot be resolved
+func lockPendingSuggestion(ctx context.Context, tx *sql.Tx, userID, id string) error {
+	var status string
+	err := tx.QueryRowContext(ctx,
+		`SELECT status FROM calendar_suggestions WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		id, userID,
+	).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get calendar suggestion: %w", err)
+	}
+	if status != SuggestionPending {
+		return ErrSuggestionResolved
+	}
+	return nil
+}
+
+// GetCalendarSuggestionStats returns suggestion acceptance per type for
+// suggestions made since the given time
+func (db *DB) GetCalendarSuggestionStats(ctx context.Context, since time.Time) ([]CalendarSuggestionStats, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT type, COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'accepted'),
+		       COUNT(*) FILTER (WHERE status = 'accepted' AND edited),
+		       COUNT(*) FILTER (WHERE status = 'dismissed'),
+		       COUNT(*) FILTER (WHERE status = 'pending')
+		FROM calendar_suggestions
+		WHERE created_at >= $1
+		GROUP BY type
+		ORDER BY COUNT(*) DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar suggestion stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]CalendarSuggestionStats, 0)
+	for rows.Next() {
+		var s CalendarSuggestionStats
+		if err := rows.Scan(&s.Type, &s.Total, &s.Accepted, &s.Edited, &s.Dismissed, &s.Pending); err != nil {
+			return nil, fmt.Errorf("failed to scan calendar suggestion stats: %w", err)
+		}
+		if resolved := s.Accepted + s.Dismissed; resolved > 0 {
+			s.AcceptanceRate = float64(s.Accepted) / float64(resolved)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SyncCarePlanReminders makes the user's upcoming care plan reminders match
+// reminders (each with a CarePlanItem). Existing reminders for the same item
+// are updated and re-armed if their time moved; upcoming ones no longer in
+// the plan are deleted. Completed and past reminders are left alone.
+func (db *DB) SyncCarePlanReminders(ctx context.Context, userID string, reminders []Reminder, now time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	upsert := `
+		INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, timezone, source, care_plan_item, next_notify_at)
+		VALUES ($1, $2, $3, $4, FALSE, $5, $6, $7, $4)
+		ON CONFLICT (user_id, care_plan_item) WHERE care_plan_item IS NOT NULL DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    reminder_time = EXCLUDED.reminder_time,
+		    timezone = EXCLUDED.timezone,
+		    next_notify_at = CASE
+		        WHEN reminders.reminder_time = EXCLUDED.reminder_time THEN reminders.next_notify_at
+		        ELSE EXCLUDED.next_notify_at
+		    END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE NOT reminders.is_completed
+	`
+
+	items := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		if r.CarePlanItem == nil {
+			continue
+		}
+		items = append(items, *r.CarePlanItem)
+		if _, err := tx.ExecContext(ctx, upsert,
+			userID, r.Title, r.Description, r.ReminderTime, r.Timezone, ReminderSourceCarePlan, *r.CarePlanItem,
+		); err != nil {
+			return fmt.Errorf("failed to save care plan reminder: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM reminders
+		WHERE user_id = $1 AND source = $2 AND NOT is_completed
+		  AND reminder_time > $3 AND NOT (care_plan_item = ANY($4))
+	`, userID, ReminderSourceCarePlan, now, pq.Array(items)); err != nil {
+		return fmt.Errorf("failed to remove outdated care plan reminders: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
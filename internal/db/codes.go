@@ -0,0 +1,681 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoSeats means a sponsor has no seats left for new codes
+var ErrNoSeats = errors.New("not enough sponsor seats")
+
+// Promo code grant types
+const (
+	GrantTypePlan    = "plan"
+	GrantTypeFeature = "feature"
+)
+
+// PromoCode is a redeemable code granting a plan or a feature for a number of days
+type PromoCode struct {
+	ID              string     `json:"id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	GrantType       string     `json:"grant_type"`   // plan or feature
+	GrantTarget     string     `json:"grant_target"` // Plan code or feature key
+	DurationDays    int        `json:"duration_days"`
+	MaxRedemptions  *int       `json:"max_redemptions,omitempty"` // 1 = single-use, nil = unlimited
+	RedemptionCount int        `json:"redemption_count"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Active          bool       `json:"active"`
+	SponsorID       *string    `json:"sponsor_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PromoCodeUpdate holds the promo code fields an admin may change; nil fields are unchanged
+type PromoCodeUpdate struct {
+	Description    *string
+	MaxRedemptions *int
+	ExpiresAt      *time.Time
+	Active         *bool
+}
+
+// CodeRedemption is one user's use of a promo code
+type CodeRedemption struct {
+	Code       string    `json:"code"`
+	UserID     string    `json:"user_id"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+	Active     bool      `json:"active"` // Still on the granted plan (sponsor reports)
+}
+
+// Sponsor is an organization paying for mothers' access through seat codes
+type Sponsor struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Kind         string    `json:"kind"` // ngo, employer, other
+	ContactEmail *string   `json:"contact_email,omitempty"`
+	Seats        int       `json:"seats"`
+	PlanCode     string    `json:"plan_code"`
+	DurationDays int       `json:"duration_days"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SponsorUpdate holds the sponsor fields an admin may change; nil fields are unchanged
+type SponsorUpdate struct {
+	Name         *string
+	ContactEmail *string
+	Seats        *int
+	PlanCode     *string
+	DurationDays *int
+	Active       *bool
+}
+
+// SponsorReport is a sponsor's seat usage
+type SponsorReport struct {
+	SponsorID     string           `json:"sponsor_id"`
+	Name          string           `json:"name"`
+	Seats         int              `json:"seats"`
+	CodesIssued   int              `json:"codes_issued"`
+	CodesRedeemed int              `json:"codes_redeemed"`
+	ActiveMembers int              `json:"active_members"`
+	Redemptions   []CodeRedemption `json:"redemptions"`
+}
+
+// RedemptionStats summarizes code redemptions and referrals since a date
+type RedemptionStats struct {
+	Since              time.Time          `json:"since"`
+	PromoRedemptions   int                `json:"promo_redemptions"`
+	SponsorRedemptions int                `json:"sponsor_redemptions"`
+	Referrals          int                `json:"referrals"`
+	TopCodes           []CodeStat         `json:"top_codes"`
+	Daily              []DailyRedemptions `json:"daily"`
+}
+
+// CodeStat is one promo code's redemptions in a period
+type CodeStat struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	GrantType   string `json:"grant_type"`
+	GrantTarget string `json:"grant_target"`
+	Redemptions int    `json:"redemptions"`
+}
+
+// DailyRedemptions counts redemptions on one day
+type DailyRedemptions struct {
+	Date     string `json:"date"` // YYYY-MM-DD (UTC)
+	Promo    int    `json:"promo"`
+	Sponsor  int    `json:"sponsor"`
+	Referral int    `json:"referral"`
+}
+
+const promoCodeColumns = `id, code, description, grant_type, grant_target, duration_days,
+	max_redemptions, redemption_count, expires_at, active, sponsor_id, created_at, updated_at`
+
+func scanPromoCode(row interface{ Scan(...any) error }) (*PromoCode, error) {
+	var c PromoCode
+	err := row.Scan(&c.ID, &c.Code, &c.Description, &c.GrantType, &c.GrantTarget, &c.DurationDays,
+		&c.MaxRedemptions, &c.RedemptionCount, &c.ExpiresAt, &c.Active, &c.SponsorID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (db *DB) queryPromoCodes(ctx context.Context, query string, args ...any) ([]PromoCode, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []PromoCode{}
+	for rows.Next() {
+		c, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promo code: %w", err)
+		}
+		codes = append(codes, *c)
+	}
+	return codes, rows.Err()
+}
+
+// ListPromoCodes returns promo codes not issued to sponsors, newest first
+func (db *DB) ListPromoCodes(ctx context.Context) ([]PromoCode, error) {
+	return db.queryPromoCodes(ctx, `
+		SELECT `+promoCodeColumns+`
+		FROM promo_codes
+		WHERE sponsor_id IS NULL
+		ORDER BY created_at DESC
+	`)
+}
+
+// ListSponsorCodes returns the seat codes issued to a sponsor
+func (db *DB) ListSponsorCodes(ctx context.Context, sponsorID string) ([]PromoCode, error) {
+	return db.queryPromoCodes(ctx, `
+		SELECT `+promoCodeColumns+`
+		FROM promo_codes
+		WHERE sponsor_id = $1
+		ORDER BY created_at, code
+	`, sponsorID)
+}
+
+// GetPromoCode returns a promo code by ID
+func (db *DB) GetPromoCode(ctx context.Context, id string) (*PromoCode, error) {
+	c, err := scanPromoCode(db.QueryRowContext(ctx, `SELECT `+promoCodeColumns+` FROM promo_codes WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return c, nil
+}
+
+// GetPromoCodeByCode returns a promo code by its (upper-case) code
+func (db *DB) GetPromoCodeByCode(ctx context.Context, code string) (*PromoCode, error) {
+	c, err := scanPromoCode(db.QueryRowContext(ctx, `SELECT `+promoCodeColumns+` FROM promo_codes WHERE code = $1`, code))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return c, nil
+}
+
+// CreatePromoCode saves a new promo code. It returns ErrNotFound if the plan
+// or feature it grants does not exist, and ErrAlreadyExists if the code is taken.
+func (db *DB) CreatePromoCode(ctx context.Context, c *PromoCode) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO promo_codes (code, description, grant_type, grant_target, duration_days, max_redemptions, expires_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE ($3 = 'plan' AND EXISTS (SELECT 1 FROM plans WHERE code = $4 AND active = TRUE))
+		   OR ($3 = 'feature' AND EXISTS (SELECT 1 FROM features WHERE feature_key = $4))
+		RETURNING id, redemption_count, active, created_at, updated_at
+	`, c.Code, c.Description, c.GrantType, c.GrantTarget, c.DurationDays, c.MaxRedemptions, c.ExpiresAt).
+		Scan(&c.ID, &c.RedemptionCount, &c.Active, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if isDuplicateKeyError(err) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return nil
+}
+
+// UpdatePromoCode changes a promo code's description, limits or status
+func (db *DB) UpdatePromoCode(ctx context.Context, id string, u PromoCodeUpdate) (*PromoCode, error) {
+	c, err := scanPromoCode(db.QueryRowContext(ctx, `
+		UPDATE promo_codes
+		SET description = COALESCE($2, description),
+		    max_redemptions = COALESCE($3, max_redemptions),
+		    expires_at = COALESCE($4, expires_at),
+		    active = COALESCE($5, active),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+promoCodeColumns,
+		id, u.Description, u.MaxRedemptions, u.ExpiresAt, u.Active))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update promo code: %w", err)
+	}
+	return c, nil
+}
+
+// RedeemPromoCode records a user's redemption and takes one use of the
+// code. It returns ErrAlreadyExists if the user already redeemed it, and
+// ErrNotFound if the code is inactive, expired or used up.
+func (db *DB) RedeemPromoCode(ctx context.Context, codeID, userID string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var redemptionID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO code_redemptions (code_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (code_id, user_id) DO NOTHING
+		RETURNING id
+	`, codeID, userID).Scan(&redemptionID)
+	if err == sql.ErrNoRows {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record redemption: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE promo_codes
+		SET redemption_count = redemption_count + 1, updated_at = NOW()
+		WHERE id = $1 AND active = TRUE
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_redemptions IS NULL OR redemption_count < max_redemptions)
+	`, codeID)
+	if err != nil {
+		return fmt.Errorf("failed to redeem promo code: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit redemption: %w", err)
+	}
+	return nil
+}
+
+// DeleteCodeRedemption undoes a redemption whose grant could not be applied
+func (db *DB) DeleteCodeRedemption(ctx context.Context, codeID, userID string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM code_redemptions WHERE code_id = $1 AND user_id = $2`, codeID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete redemption: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE promo_codes
+			SET redemption_count = redemption_count - 1, updated_at = NOW()
+			WHERE id = $1 AND redemption_count > 0
+		`, codeID); err != nil {
+			return fmt.Errorf("failed to release promo code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// ListCodeRedemptions returns who redeemed a promo code, newest first
+func (db *DB) ListCodeRedemptions(ctx context.Context, codeID string, limit int) ([]CodeRedemption, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.code, r.user_id, r.redeemed_at
+		FROM code_redemptions r
+		JOIN promo_codes c ON c.id = r.code_id
+		WHERE r.code_id = $1
+		ORDER BY r.redeemed_at DESC
+		LIMIT $2
+	`, codeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	redemptions := []CodeRedemption{}
+	for rows.Next() {
+		var r CodeRedemption
+		if err := rows.Scan(&r.Code, &r.UserID, &r.RedeemedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan redemption: %w", err)
+		}
+		redemptions = append(redemptions, r)
+	}
+	return redemptions, rows.Err()
+}
+
+const sponsorColumns = `id, name, kind, contact_email, seats, plan_code, duration_days, active, created_at, updated_at`
+
+func scanSponsor(row interface{ Scan(...any) error }) (*Sponsor, error) {
+	var s Sponsor
+	err := row.Scan(&s.ID, &s.Name, &s.Kind, &s.ContactEmail, &s.Seats, &s.PlanCode, &s.DurationDays,
+		&s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSponsors returns all sponsors by name
+func (db *DB) ListSponsors(ctx context.Context) ([]Sponsor, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+sponsorColumns+` FROM sponsors ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sponsors: %w", err)
+	}
+	defer rows.Close()
+
+	sponsors := []Sponsor{}
+	for rows.Next() {
+		s, err := scanSponsor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sponsor: %w", err)
+		}
+		sponsors = append(sponsors, *s)
+	}
+	return sponsors, rows.Err()
+}
+
+// GetSponsor returns a sponsor by ID
+func (db *DB) GetSponsor(ctx context.Context, id string) (*Sponsor, error) {
+	s, err := scanSponsor(db.QueryRowContext(ctx, `SELECT `+sponsorColumns+` FROM sponsors WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sponsor: %w", err)
+	}
+	return s, nil
+}
+
+// CreateSponsor saves a new sponsor. It returns ErrNotFound if the plan does not exist.
+func (db *DB) CreateSponsor(ctx context.Context, s *Sponsor) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO sponsors (name, kind, contact_email, seats, plan_code, duration_days)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE EXISTS (SELECT 1 FROM plans WHERE code = $5 AND active = TRUE)
+		RETURNING id, active, created_at, updated_at
+	`, s.Name, s.Kind, s.ContactEmail, s.Seats, s.PlanCode, s.DurationDays).
+		Scan(&s.ID, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create sponsor: %w", err)
+	}
+	return nil
+}
+
+// UpdateSponsor changes a sponsor. Deactivating a sponsor deactivates its
+// unused codes; codes already redeemed keep their grant.
+func (db *DB) UpdateSponsor(ctx context.Context, id string, u SponsorUpdate) (*Sponsor, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	s, err := scanSponsor(tx.QueryRowContext(ctx, `
+		UPDATE sponsors
+		SET name = COALESCE($2, name),
+		    contact_email = COALESCE($3, contact_email),
+		    seats = COALESCE($4, seats),
+		    plan_code = COALESCE($5, plan_code),
+		    duration_days = COALESCE($6, duration_days),
+		    active = COALESCE($7, active),
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+sponsorColumns,
+		id, u.Name, u.ContactEmail, u.Seats, u.PlanCode, u.DurationDays, u.Active))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sponsor: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promo_codes
+		SET active = $2, updated_at = NOW()
+		WHERE sponsor_id = $1 AND active <> $2
+	`, id, s.Active); err != nil {
+		return nil, fmt.Errorf("failed to update sponsor codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sponsor: %w", err)
+	}
+	return s, nil
+}
+
+// IssueSponsorCodes creates single-use codes for a sponsor's plan, one per
+// seat. It returns ErrNoSeats if the sponsor is inactive or the codes would
+// exceed its seats, and ErrAlreadyExists if a code is taken.
+func (db *DB) IssueSponsorCodes(ctx context.Context, sponsorID string, codes []string) ([]PromoCode, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	sponsor, err := scanSponsor(tx.QueryRowContext(ctx, `SELECT `+sponsorColumns+` FROM sponsors WHERE id = $1 FOR UPDATE`, sponsorID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sponsor: %w", err)
+	}
+
+	var issued int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM promo_codes WHERE sponsor_id = $1`, sponsorID).Scan(&issued); err != nil {
+		return nil, fmt.Errorf("failed to count sponsor codes: %w", err)
+	}
+	if !sponsor.Active || issued+len(codes) > sponsor.Seats {
+		return nil, ErrNoSeats
+	}
+
+	created := make([]PromoCode, 0, len(codes))
+	for _, code := range codes {
+		c, err := scanPromoCode(tx.QueryRowContext(ctx, `
+			INSERT INTO promo_codes (code, description, grant_type, grant_target, duration_days, max_redemptions, sponsor_id)
+			VALUES ($1, $2, 'plan', $3, $4, 1, $5)
+			RETURNING `+promoCodeColumns,
+			code, "Sponsored by "+sponsor.Name, sponsor.PlanCode, sponsor.DurationDays, sponsorID))
+		if isDuplicateKeyError(err) {
+			return nil, ErrAlreadyExists
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sponsor code: %w", err)
+		}
+		created = append(created, *c)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit sponsor codes: %w", err)
+	}
+	return created, nil
+}
+
+// GetSponsorReport returns how a sponsor's seats are used
+func (db *DB) GetSponsorReport(ctx context.Context, sponsorID string) (*SponsorReport, error) {
+	report := &SponsorReport{SponsorID: sponsorID, Redemptions: []CodeRedemption{}}
+	err := db.QueryRowContext(ctx, `
+		SELECT s.name, s.seats,
+		       (SELECT COUNT(*) FROM promo_codes WHERE sponsor_id = s.id),
+		       (SELECT COUNT(*) FROM promo_codes WHERE sponsor_id = s.id AND redemption_count > 0)
+		FROM sponsors s
+		WHERE s.id = $1
+	`, sponsorID).Scan(&report.Name, &report.Seats, &report.CodesIssued, &report.CodesRedeemed)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sponsor report: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.code, r.user_id, r.redeemed_at,
+		       EXISTS (
+		           SELECT 1
+		           FROM subscriptions sub
+		           JOIN plans p ON p.id = sub.plan_id
+		           WHERE sub.user_id = r.user_id AND p.code = c.grant_target
+		             AND sub.status = 'active' AND (sub.ends_at IS NULL OR sub.ends_at > NOW())
+		       )
+		FROM code_redemptions r
+		JOIN promo_codes c ON c.id = r.code_id
+		WHERE c.sponsor_id = $1
+		ORDER BY r.redeemed_at DESC
+	`, sponsorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sponsor redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r CodeRedemption
+		if err := rows.Scan(&r.Code, &r.UserID, &r.RedeemedAt, &r.Active); err != nil {
+			return nil, fmt.Errorf("failed to scan sponsor redemption: %w", err)
+		}
+		if r.Active {
+			report.ActiveMembers++
+		}
+		report.Redemptions = append(report.Redemptions, r)
+	}
+	return report, rows.Err()
+}
+
+// GetReferralCode returns a user's referral code
+func (db *DB) GetReferralCode(ctx context.Context, userID string) (string, error) {
+	var code string
+	err := db.QueryRowContext(ctx, `SELECT code FROM referral_codes WHERE user_id = $1`, userID).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get referral code: %w", err)
+	}
+	return code, nil
+}
+
+// CreateReferralCode gives a user a referral code, returning the existing
+// one if they already have it. It returns ErrAlreadyExists if another user
+// has the code.
+func (db *DB) CreateReferralCode(ctx context.Context, userID, code string) (string, error) {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO referral_codes (user_id, code)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING code
+	`, userID, code).Scan(&code)
+	if isDuplicateKeyError(err) {
+		return "", ErrAlreadyExists
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create referral code: %w", err)
+	}
+	return code, nil
+}
+
+// GetReferralCodeOwner returns the user a referral code belongs to
+func (db *DB) GetReferralCodeOwner(ctx context.Context, code string) (string, error) {
+	var userID string
+	err := db.QueryRowContext(ctx, `SELECT user_id FROM referral_codes WHERE code = $1`, code).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get referral code: %w", err)
+	}
+	return userID, nil
+}
+
+// CreateReferral records that referredID joined through referrerID. It
+// returns ErrAlreadyExists if referredID was already referred.
+func (db *DB) CreateReferral(ctx context.Context, referrerID, referredID string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO referrals (referrer_id, referred_id)
+		VALUES ($1, $2)
+	`, referrerID, referredID)
+	if isDuplicateKeyError(err) {
+		return ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create referral: %w", err)
+	}
+	return nil
+}
+
+// DeleteReferral undoes a referral whose credit could not be applied
+func (db *DB) DeleteReferral(ctx context.Context, referredID string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM referrals WHERE referred_id = $1`, referredID); err != nil {
+		return fmt.Errorf("failed to delete referral: %w", err)
+	}
+	return nil
+}
+
+// CountReferrals returns how many users a user has referred
+func (db *DB) CountReferrals(ctx context.Context, referrerID string) (int, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM referrals WHERE referrer_id = $1`, referrerID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count referrals: %w", err)
+	}
+	return n, nil
+}
+
+// GetRedemptionStats summarizes promo, sponsor and referral redemptions since a date
+func (db *DB) GetRedemptionStats(ctx context.Context, since time.Time) (*RedemptionStats, error) {
+	stats := &RedemptionStats{Since: since, TopCodes: []CodeStat{}, Daily: []DailyRedemptions{}}
+
+	err := db.QueryRowContext(ctx, `
+		SELECT
+		    (SELECT COUNT(*) FROM code_redemptions r JOIN promo_codes c ON c.id = r.code_id
+		     WHERE r.redeemed_at >= $1 AND c.sponsor_id IS NULL),
+		    (SELECT COUNT(*) FROM code_redemptions r JOIN promo_codes c ON c.id = r.code_id
+		     WHERE r.redeemed_at >= $1 AND c.sponsor_id IS NOT NULL),
+		    (SELECT COUNT(*) FROM referrals WHERE created_at >= $1)
+	`, since).Scan(&stats.PromoRedemptions, &stats.SponsorRedemptions, &stats.Referrals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count redemptions: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.code, c.description, c.grant_type, c.grant_target, COUNT(*) AS redemptions
+		FROM code_redemptions r
+		JOIN promo_codes c ON c.id = r.code_id
+		WHERE r.redeemed_at >= $1 AND c.sponsor_id IS NULL
+		GROUP BY c.id
+		ORDER BY redemptions DESC, c.code
+		LIMIT 20
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top codes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s CodeStat
+		if err := rows.Scan(&s.Code, &s.Description, &s.GrantType, &s.GrantTarget, &s.Redemptions); err != nil {
+			return nil, fmt.Errorf("failed to scan code stat: %w", err)
+		}
+		stats.TopCodes = append(stats.TopCodes, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	daily, err := db.QueryContext(ctx, `
+		SELECT TO_CHAR(day, 'YYYY-MM-DD'),
+		       COUNT(*) FILTER (WHERE kind = 'promo'),
+		       COUNT(*) FILTER (WHERE kind = 'sponsor'),
+		       COUNT(*) FILTER (WHERE kind = 'referral')
+		FROM (
+		    SELECT DATE_TRUNC('day', r.redeemed_at AT TIME ZONE 'UTC') AS day,
+		           CASE WHEN c.sponsor_id IS NULL THEN 'promo' ELSE 'sponsor' END AS kind
+		    FROM code_redemptions r
+		    JOIN promo_codes c ON c.id = r.code_id
+		    WHERE r.redeemed_at >= $1
+		    UNION ALL
+		    SELECT DATE_TRUNC('day', created_at AT TIME ZONE 'UTC'), 'referral'
+		    FROM referrals
+		    WHERE created_at >= $1
+		) events
+		GROUP BY day
+		ORDER BY day
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily redemptions: %w", err)
+	}
+	defer daily.Close()
+	for daily.Next() {
+		var d DailyRedemptions
+		if err := daily.Scan(&d.Date, &d.Promo, &d.Sponsor, &d.Referral); err != nil {
+			return nil, fmt.Errorf("failed to scan daily redemptions: %w", err)
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	return stats, daily.Err()
+}
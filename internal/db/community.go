@@ -680,8 +680,8 @@ func syncCommunityEventReminderTx(ctx context.Context, tx *sql.Tx, userID, event
 	}
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, community_event_id)
-		VALUES ($1, $2, $3, $4, FALSE, $5)
+		INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, community_event_id, next_notify_at)
+		VALUES ($1, $2, $3, $4, FALSE, $5, $4)
 	`, userID, title, reminderDescription, startsAt, eventID)
 	return err
 }
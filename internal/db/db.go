@@ -109,6 +109,7 @@ type User struct {
 	CountryCode           *string    `json:"country_code"`
 	StateProvince         *string    `json:"state_province"`
 	City                  *string    `json:"city"`
+	Timezone              string     `json:"timezone"` // IANA zone, default UTC
 	CommunityOnboardingAt *time.Time `json:"community_onboarding_completed_at,omitempty"`
 	SavingsGoal           *float64   `json:"savings_goal"`
 	IsAdmin               bool       `json:"is_admin"`
@@ -117,6 +118,19 @@ type User struct {
 	UpdatedAt             time.Time  `json:"updated_at"`
 }
 
+// Location returns the user's timezone, falling back to UTC when it is unset
+// or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // Conversation represents a chat session
 type Conversation struct {
 	ID        string    `json:"id"`
@@ -144,23 +158,49 @@ type UserFact struct {
 	Key        string    `json:"key"`
 	Value      string    `json:"value"`
 	Confidence float64   `json:"confidence"`
+	Source     string    `json:"source"` // FactSourceChat, FactSourceProfile or FactSourceUser
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// Where a user fact came from
+const (
+	FactSourceChat    = "chat"    // Extracted from chat messages
+	FactSourceProfile = "profile" // Synced from the user's profile
+	FactSourceUser    = "user"    // Entered or corrected by the user
+)
+
 // Reminder represents a calendar reminder
 type Reminder struct {
 	ID               string    `json:"id"`
 	UserID           string    `json:"user_id"`
 	Title            string    `json:"title"`
 	Description      *string   `json:"description"`
-	ReminderTime     time.Time `json:"reminder_time"`
-	IsCompleted      bool      `json:"is_completed"`
+	ReminderTime     time.Time `json:"reminder_time"` // First occurrence of a recurring reminder
+	IsCompleted      bool      `json:"is_completed"`  // Ends the whole series of a recurring reminder
 	CommunityEventID *string   `json:"community_event_id,omitempty"`
+	RRule            *string   `json:"rrule,omitempty"`    // RFC 5545 RRULE, e.g. FREQ=DAILY;COUNT=30
+	Timezone         string    `json:"timezone"`           // IANA zone occurrences are expanded in
+	ICalUID          *string   `json:"ical_uid,omitempty"` // Set on reminders imported from an ICS file
+	Source           string    `json:"source"`             // ReminderSourceUser or ReminderSourceCarePlan
+	CarePlanItem     *string   `json:"care_plan_item,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
 
+// Reminder sources
+const (
+	ReminderSourceUser     = "user"
+	ReminderSourceCarePlan = "care_plan"
+)
+
+// ReminderOccurrence is a completed occurrence of a recurring reminder
+type ReminderOccurrence struct {
+	ReminderID     string    `json:"reminder_id"`
+	OccurrenceTime time.Time `json:"occurrence_time"`
+	CompletedAt    time.Time `json:"completed_at"`
+}
+
 // DoctorVisit represents a prenatal/medical visit record (micro EMR).
 type DoctorVisit struct {
 	ID                     string
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Escalation is an audit record of a red flag raised during chat.
+type Escalation struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	ConversationID *string    `json:"conversation_id,omitempty"`
+	MessageID      *string    `json:"message_id,omitempty"`
+	Category       string     `json:"category"`
+	MatchedPhrase  string     `json:"matched_phrase"`
+	Language       string     `json:"language"`
+	CountryCode    *string    `json:"country_code,omitempty"`
+	Response       string     `json:"response"`
+	Status         string     `json:"status"`
+	ReviewedBy     *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNotes    *string    `json:"review_notes,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreateEscalation stores a red-flag escalation.
+func (db *DB) CreateEscalation(ctx context.Context, e *Escalation) error {
+	query := `
+		INSERT INTO red_flag_escalations
+			(user_id, conversation_id, message_id, category, matched_phrase, language, country_code, response)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`
+	err := db.QueryRowContext(ctx, query,
+		e.UserID, e.ConversationID, e.MessageID, e.Category, e.MatchedPhrase,
+		e.Language, e.CountryCode, e.Response,
+	).Scan(&e.ID, &e.Status, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create escalation: %w", err)
+	}
+	return nil
+}
+
+// ListEscalations lists escalations for admin review (optionally filtered by status and category).
+func (db *DB) ListEscalations(ctx context.Context, status, category string, limit int) ([]Escalation, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `
+		SELECT id, user_id, conversation_id, message_id, category, matched_phrase, language,
+		       country_code, response, status, reviewed_by, reviewed_at, review_notes, created_at
+		FROM red_flag_escalations
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR category = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	rows, err := db.QueryContext(ctx, query, status, category, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escalations: %w", err)
+	}
+	defer rows.Close()
+
+	escalations := make([]Escalation, 0)
+	for rows.Next() {
+		var e Escalation
+		var conversationID, messageID, countryCode, reviewedBy, reviewNotes sql.NullString
+		var reviewedAt sql.NullTime
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &conversationID, &messageID, &e.Category, &e.MatchedPhrase,
+			&e.Language, &countryCode, &e.Response, &e.Status, &reviewedBy, &reviewedAt,
+			&reviewNotes, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan escalation: %w", err)
+		}
+		e.ConversationID = nullStringPtr(conversationID)
+		e.MessageID = nullStringPtr(messageID)
+		e.CountryCode = nullStringPtr(countryCode)
+		e.ReviewedBy = nullStringPtr(reviewedBy)
+		e.ReviewNotes = nullStringPtr(reviewNotes)
+		if reviewedAt.Valid {
+			e.ReviewedAt = &reviewedAt.Time
+		}
+		escalations = append(escalations, e)
+	}
+	return escalations, rows.Err()
+}
+
+// UpdateEscalationStatus records an admin review of an escalation.
+func (db *DB) UpdateEscalationStatus(ctx context.Context, id, status, reviewerID string, notes *string) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE red_flag_escalations
+		SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP,
+		    review_notes = COALESCE($3, review_notes)
+		WHERE id = $4
+	`, status, reviewerID, notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update escalation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func nullStringPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	s := ns.String
+	return &s
+}
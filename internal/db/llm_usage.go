@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LLMUsage is one entry in the LLM usage ledger.
+type LLMUsage struct {
+	ID               int64     `json:"id"`
+	UserID           *string   `json:"user_id,omitempty"`
+	PlanCode         *string   `json:"plan_code,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Task             string    `json:"task"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TokensEstimated  bool      `json:"tokens_estimated"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// LLMCostTotals are aggregated usage figures for a group of ledger entries.
+type LLMCostTotals struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// LLMUserCost is LLM spend for one user.
+type LLMUserCost struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	LLMCostTotals
+}
+
+// LLMPlanCost is LLM spend for one plan ("none" = no active subscription).
+type LLMPlanCost struct {
+	PlanCode string `json:"plan_code"`
+	Users    int    `json:"users"`
+	LLMCostTotals
+	CostPerUserUSD     float64 `json:"cost_per_user_usd"`
+	AvgChatCallCostUSD float64 `json:"avg_chat_call_cost_usd"`
+}
+
+// LLMDailyCost is LLM spend for one UTC day.
+type LLMDailyCost struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	LLMCostTotals
+}
+
+// RecordLLMUsage writes a ledger entry, stamping the user's current plan.
+func (db *DB) RecordLLMUsage(ctx context.Context, u *LLMUsage) error {
+	query := `
+		INSERT INTO llm_usage
+			(user_id, plan_code, provider, model, task, prompt_tokens, completion_tokens, tokens_estimated, cost_usd)
+		VALUES ($1, (
+			SELECT p.code FROM subscriptions s
+			JOIN plans p ON p.id = s.plan_id
+			WHERE s.user_id = $1
+			  AND s.status = 'active'
+			  AND (s.ends_at IS NULL OR s.ends_at > NOW())
+			ORDER BY s.starts_at DESC
+			LIMIT 1
+		), $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, plan_code, created_at
+	`
+	err := db.QueryRowContext(ctx, query,
+		u.UserID, u.Provider, u.Model, u.Task, u.PromptTokens, u.CompletionTokens,
+		u.TokensEstimated, u.CostUSD,
+	).Scan(&u.ID, &u.PlanCode, &u.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record llm usage: %w", err)
+	}
+	return nil
+}
+
+// GetLLMCostByUser returns the highest-spending users since the given time.
+func (db *DB) GetLLMCostByUser(ctx context.Context, since time.Time, limit int) ([]LLMUserCost, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT lu.user_id, COALESCE(u.email, ''), COUNT(*),
+		       COALESCE(SUM(lu.prompt_tokens), 0), COALESCE(SUM(lu.completion_tokens), 0),
+		       COALESCE(SUM(lu.cost_usd), 0)
+		FROM llm_usage lu
+		LEFT JOIN users u ON u.id = lu.user_id
+		WHERE lu.created_at >= $1 AND lu.user_id IS NOT NULL
+		GROUP BY lu.user_id, u.email
+		ORDER BY SUM(lu.cost_usd) DESC
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query llm cost by user: %w", err)
+	}
+	defer rows.Close()
+
+	costs := make([]LLMUserCost, 0)
+	for rows.Next() {
+		var c LLMUserCost
+		if err := rows.Scan(&c.UserID, &c.Email, &c.Calls, &c.PromptTokens, &c.CompletionTokens, &c.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan llm user cost: %w", err)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
+
+// GetLLMCostByPlan returns LLM spend per plan since the given time.
+func (db *DB) GetLLMCostByPlan(ctx context.Context, since time.Time) ([]LLMPlanCost, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT COALESCE(plan_code, 'none'), COUNT(DISTINCT user_id), COUNT(*),
+		       COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+		       COALESCE(SUM(cost_usd), 0),
+		       COALESCE(AVG(cost_usd) FILTER (WHERE task = 'chat'), 0)
+		FROM llm_usage
+		WHERE created_at >= $1
+		GROUP BY COALESCE(plan_code, 'none')
+		ORDER BY SUM(cost_usd) DESC
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query llm cost by plan: %w", err)
+	}
+	defer rows.Close()
+
+	costs := make([]LLMPlanCost, 0)
+	for rows.Next() {
+		var c LLMPlanCost
+		if err := rows.Scan(&c.PlanCode, &c.Users, &c.Calls, &c.PromptTokens, &c.CompletionTokens,
+			&c.CostUSD, &c.AvgChatCallCostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan llm plan cost: %w", err)
+		}
+		if c.Users > 0 {
+			c.CostPerUserUSD = c.CostUSD / float64(c.Users)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
+
+// GetLLMCostByDay returns LLM spend per UTC day since the given time.
+func (db *DB) GetLLMCostByDay(ctx context.Context, since time.Time) ([]LLMDailyCost, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*),
+		       COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+		       COALESCE(SUM(cost_usd), 0)
+		FROM llm_usage
+		WHERE created_at >= $1
+		GROUP BY day
+		ORDER BY day
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query llm cost by day: %w", err)
+	}
+	defer rows.Close()
+
+	costs := make([]LLMDailyCost, 0)
+	for rows.Next() {
+		var c LLMDailyCost
+		if err := rows.Scan(&c.Day, &c.Calls, &c.PromptTokens, &c.CompletionTokens, &c.CostUSD); err != nil {
+			return nil, fmt.Errorf("failed to scan llm daily cost: %w", err)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/memory"
 )
@@ -36,3 +38,47 @@ func (a *MemoryAdapter) GetRecentMessages(userID string, limit int) ([]memory.Me
 
 	return messages, nil
 }
+
+// SaveEmbedding implements memory.EmbeddingStore
+func (a *MemoryAdapter) SaveEmbedding(ctx context.Context, userID, model string, e memory.StoredEmbedding) error {
+	row := &MemoryEmbedding{
+		UserID:    userID,
+		Content:   e.Content,
+		Model:     model,
+		Embedding: e.Vector,
+	}
+	sourceID := e.SourceID
+	switch e.Source {
+	case memory.SourceMessage:
+		row.MessageID = &sourceID
+	case memory.SourceDoctorVisit:
+		row.DoctorVisitID = &sourceID
+	default:
+		return fmt.Errorf("unknown memory source %q", e.Source)
+	}
+	return a.db.SaveMemoryEmbedding(ctx, row)
+}
+
+// LoadEmbeddings implements memory.EmbeddingStore
+func (a *MemoryAdapter) LoadEmbeddings(ctx context.Context, userID, model string, since time.Time, limit int) ([]memory.StoredEmbedding, error) {
+	rows, err := a.db.GetMemoryEmbeddings(ctx, userID, model, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([]memory.StoredEmbedding, len(rows))
+	for i, row := range rows {
+		e := memory.StoredEmbedding{
+			Content:   row.Content,
+			Vector:    row.Embedding,
+			CreatedAt: row.CreatedAt,
+		}
+		if row.DoctorVisitID != nil {
+			e.Source, e.SourceID = memory.SourceDoctorVisit, *row.DoctorVisitID
+		} else if row.MessageID != nil {
+			e.Source, e.SourceID = memory.SourceMessage, *row.MessageID
+		}
+		embeddings[i] = e
+	}
+	return embeddings, nil
+}
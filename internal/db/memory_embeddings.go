@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MemoryEmbedding is an embedded chat message or doctor visit note.
+// Exactly one of MessageID and DoctorVisitID is set.
+type MemoryEmbedding struct {
+	ID            int64
+	UserID        string
+	MessageID     *string
+	DoctorVisitID *string
+	Content       string
+	Model         string
+	Embedding     []float32
+	CreatedAt     time.Time
+}
+
+// SaveMemoryEmbedding stores an embedding. Re-embedding a doctor visit
+// (after an edit or a model change) replaces the previous vector.
+func (db *DB) SaveMemoryEmbedding(ctx context.Context, e *MemoryEmbedding) error {
+	conflict := `ON CONFLICT (message_id) DO NOTHING`
+	if e.DoctorVisitID != nil {
+		conflict = `ON CONFLICT (doctor_visit_id) DO UPDATE SET
+			content = EXCLUDED.content, model = EXCLUDED.model,
+			embedding = EXCLUDED.embedding, created_at = EXCLUDED.created_at`
+	}
+
+	query := `
+		INSERT INTO memory_embeddings (user_id, message_id, doctor_visit_id, content, model, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		` + conflict
+
+	_, err := db.ExecContext(ctx, query,
+		e.UserID, e.MessageID, e.DoctorVisitID, e.Content, e.Model, pq.Array(e.Embedding),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save memory embedding: %w", err)
+	}
+	return nil
+}
+
+// GetMemoryEmbeddings returns up to limit of a user's most recent embeddings
+// for a model created since the given time. The (user_id, model, created_at)
+// index serves it without reading older rows.
+func (db *DB) GetMemoryEmbeddings(ctx context.Context, userID, model string, since time.Time, limit int) ([]MemoryEmbedding, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, user_id, message_id, doctor_visit_id, content, model, embedding, created_at
+		FROM memory_embeddings
+		WHERE user_id = $1 AND model = $2 AND created_at >= $3
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, userID, model, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memory embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	embeddings := make([]MemoryEmbedding, 0)
+	for rows.Next() {
+		var e MemoryEmbedding
+		var vector pq.Float32Array
+		if err := rows.Scan(&e.ID, &e.UserID, &e.MessageID, &e.DoctorVisitID, &e.Content, &e.Model,
+			&vector, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan memory embedding: %w", err)
+		}
+		e.Embedding = vector
+		embeddings = append(embeddings, e)
+	}
+	return embeddings, rows.Err()
+}
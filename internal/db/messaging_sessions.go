@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Messaging channels
+const (
+	MessagingSMS      = "sms"
+	MessagingWhatsApp = "whatsapp"
+)
+
+// MessagingSession links a phone number on a messaging channel to the user's
+// current chat conversation
+type MessagingSession struct {
+	Channel             string    `json:"channel"`
+	Phone               string    `json:"phone"`
+	UserID              string    `json:"user_id"`
+	ConversationID      string    `json:"conversation_id"`       // Empty until the first reply creates one
+	PendingSuggestionID string    `json:"pending_suggestion_id"` // Calendar suggestion a YES reply accepts
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// GetMessagingSession returns the session for a phone number on a channel
+func (db *DB) GetMessagingSession(ctx context.Context, channel, phone string) (*MessagingSession, error) {
+	s := &MessagingSession{Channel: channel, Phone: phone}
+	var conversationID, suggestionID sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT user_id, conversation_id, pending_suggestion_id, updated_at
+		FROM messaging_sessions
+		WHERE channel = $1 AND phone = $2
+	`, channel, phone).Scan(&s.UserID, &conversationID, &suggestionID, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messaging session: %w", err)
+	}
+	s.ConversationID = conversationID.String
+	s.PendingSuggestionID = suggestionID.String
+	return s, nil
+}
+
+// SaveMessagingSession creates or replaces the session for a phone number on a channel
+func (db *DB) SaveMessagingSession(ctx context.Context, s *MessagingSession) error {
+	var conversationID, suggestionID *string
+	if s.ConversationID != "" {
+		conversationID = &s.ConversationID
+	}
+	if s.PendingSuggestionID != "" {
+		suggestionID = &s.PendingSuggestionID
+	}
+
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO messaging_sessions (channel, phone, user_id, conversation_id, pending_suggestion_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (channel, phone) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+		    conversation_id = EXCLUDED.conversation_id,
+		    pending_suggestion_id = EXCLUDED.pending_suggestion_id,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, s.Channel, s.Phone, s.UserID, conversationID, suggestionID).Scan(&s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save messaging session: %w", err)
+	}
+	return nil
+}
+
+// ClaimMessagingWelcome records that an unregistered number is being sent the
+// welcome message. It returns false if the number was already sent one within
+// interval, so concurrent messages from the same number claim it only once.
+func (db *DB) ClaimMessagingWelcome(ctx context.Context, channel, phone string, now time.Time, interval time.Duration) (bool, error) {
+	var sentAt time.Time
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO messaging_welcomes (channel, phone, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (channel, phone) DO UPDATE
+		SET sent_at = EXCLUDED.sent_at
+		WHERE messaging_welcomes.sent_at <= $4
+		RETURNING sent_at
+	`, channel, phone, now, now.Add(-interval)).Scan(&sentAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim messaging welcome: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,328 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NotificationPreferences are a user's reminder delivery channel settings
+type NotificationPreferences struct {
+	UserID       string    `json:"user_id"`
+	PushEnabled  bool      `json:"push_enabled"`
+	EmailEnabled bool      `json:"email_enabled"`
+	SMSEnabled   bool      `json:"sms_enabled"` // Texts go to the verified number in user_phones
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PushDevice is a push notification target registered by a mobile app
+type PushDevice struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Provider  string    `json:"provider"` // fcm or apns
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Reminder delivery statuses
+const (
+	DeliveryPending = "pending"
+	DeliverySending = "sending"
+	DeliveryRetry   = "retry"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliverySkipped = "skipped"
+)
+
+// ReminderDelivery is one claimed attempt to deliver a reminder over a channel,
+// joined with what is needed to send it.
+type ReminderDelivery struct {
+	ID           string
+	ReminderID   string
+	Channel      string
+	Attempts     int // Including the current attempt
+	ScheduledFor time.Time
+	UserID       string
+	Title        string
+	Description  *string
+	Email        string
+	Language     string
+	Phone        *string // The verified number, for SMS and voice
+}
+
+// GetNotificationPreferences returns a user's channel settings, or the
+// defaults if they never changed them
+func (db *DB) GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	prefs := &NotificationPreferences{UserID: userID, PushEnabled: true, EmailEnabled: true}
+	err := db.QueryRowContext(ctx, `
+		SELECT push_enabled, email_enabled, sms_enabled, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&prefs.PushEnabled, &prefs.EmailEnabled, &prefs.SMSEnabled, &prefs.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return prefs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// SaveNotificationPreferences creates or replaces a user's channel settings
+func (db *DB) SaveNotificationPreferences(ctx context.Context, prefs *NotificationPreferences) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO notification_preferences (user_id, push_enabled, email_enabled, sms_enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			push_enabled = EXCLUDED.push_enabled,
+			email_enabled = EXCLUDED.email_enabled,
+			sms_enabled = EXCLUDED.sms_enabled,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, prefs.UserID, prefs.PushEnabled, prefs.EmailEnabled, prefs.SMSEnabled).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// SavePushDevice registers a push token. A token moves to the latest user
+// who registers it (e.g. after signing in to another account on the device).
+func (db *DB) SavePushDevice(ctx context.Context, device *PushDevice) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO push_devices (user_id, provider, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			provider = EXCLUDED.provider,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at
+	`, device.UserID, device.Provider, device.Token).Scan(&device.ID, &device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save push device: %w", err)
+	}
+	return nil
+}
+
+// DeletePushDevice unregisters one of a user's push tokens
+func (db *DB) DeletePushDevice(ctx context.Context, userID, token string) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM push_devices WHERE user_id = $1 AND token = $2`, userID, token)
+	if err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeletePushToken removes a token the push provider no longer accepts
+func (db *DB) DeletePushToken(ctx context.Context, token string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM push_devices WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("failed to delete push token: %w", err)
+	}
+	return nil
+}
+
+// GetPushDevices returns a user's registered push devices
+func (db *DB) GetPushDevices(ctx context.Context, userID string) ([]PushDevice, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, user_id, provider, token, created_at, updated_at
+		FROM push_devices
+		WHERE user_id = $1
+		ORDER BY updated_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := make([]PushDevice, 0)
+	for rows.Next() {
+		var d PushDevice
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Provider, &d.Token, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push device: %w", err)
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// RecurringReminder is a recurring reminder whose occurrence at ScheduledFor
+// was queued. It stays armed for that occurrence until AdvanceReminder moves
+// it to the next one.
+type RecurringReminder struct {
+	ID           string
+	RRule        string
+	Timezone     string
+	ReminderTime time.Time
+	ScheduledFor time.Time
+}
+
+// QueueDueReminders creates a delivery row per enabled channel for reminders
+// due at or before now (but not more than maxLateness ago) and disarms
+// one-off reminders. Recurring reminders stay armed until AdvanceReminder
+// succeeds, so a crash or error before then queues the same occurrence again
+// (a no-op) rather than ending the series. Reminders are locked with SKIP
+// LOCKED so concurrent replicas never queue the same reminder twice. It
+// returns the number of deliveries queued and the recurring reminders due.
+func (db *DB) QueueDueReminders(ctx context.Context, now time.Time, maxLateness time.Duration, channels []string, limit int) (int, []RecurringReminder, error) {
+	query := `
+		WITH due AS (
+			SELECT id, user_id, next_notify_at, rrule, timezone, reminder_time
+			FROM reminders
+			WHERE next_notify_at <= $1 AND is_completed = FALSE
+			ORDER BY next_notify_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		), disarmed AS (
+			UPDATE reminders r
+			SET next_notify_at = NULL
+			FROM due
+			WHERE r.id = due.id AND due.rrule IS NULL
+		), queued AS (
+			INSERT INTO reminder_deliveries (reminder_id, scheduled_for, channel, next_attempt_at)
+			SELECT d.id, d.next_notify_at, ch.channel, $1
+			FROM due d
+			JOIN users u ON u.id = d.user_id
+			LEFT JOIN notification_preferences np ON np.user_id = d.user_id
+			LEFT JOIN voice_call_preferences vp ON vp.user_id = d.user_id
+			LEFT JOIN user_phones up ON up.user_id = d.user_id
+			CROSS JOIN LATERAL (VALUES
+				('push', COALESCE(np.push_enabled, TRUE)
+					AND EXISTS (SELECT 1 FROM push_devices pd WHERE pd.user_id = d.user_id)),
+				('email', COALESCE(np.email_enabled, TRUE) AND u.email <> ''),
+				('sms', COALESCE(np.sms_enabled, FALSE) AND up.phone_number IS NOT NULL),
+				('voice', COALESCE(vp.reminder_calls_enabled, FALSE) AND up.phone_number IS NOT NULL)
+			) AS ch(channel, enabled)
+			WHERE ch.enabled
+			  AND ch.channel = ANY($3)
+			  AND d.next_notify_at > $2
+			  -- An occurrence the user already ticked off needs no reminder
+			  AND NOT EXISTS (
+				SELECT 1 FROM reminder_occurrences o
+				WHERE o.reminder_id = d.id AND o.occurrence_time = d.next_notify_at
+			  )
+			ON CONFLICT (reminder_id, scheduled_for, channel) DO NOTHING
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM queued), d.id, d.rrule, d.timezone, d.reminder_time, d.next_notify_at
+		FROM due d
+	`
+
+	// Reminders missed by more than maxLateness (e.g. during an outage) are
+	// not sent: a day-old "take your vitamins" is noise.
+	rows, err := db.QueryContext(ctx, query, now, now.Add(-maxLateness), pq.Array(channels), limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to queue due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		queued    int
+		recurring []RecurringReminder
+	)
+	for rows.Next() {
+		var r RecurringReminder
+		var rrule sql.NullString
+		if err := rows.Scan(&queued, &r.ID, &rrule, &r.Timezone, &r.ReminderTime, &r.ScheduledFor); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan queued reminder: %w", err)
+		}
+		if rrule.Valid {
+			r.RRule = rrule.String
+			recurring = append(recurring, r)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to queue due reminders: %w", err)
+	}
+	return queued, recurring, nil
+}
+
+// ClaimReminderDeliveries leases up to limit deliveries that are due for an
+// attempt. A claimed delivery is hidden from other workers until lease
+// expires, so a worker that dies mid-send is retried by another.
+func (db *DB) ClaimReminderDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]ReminderDelivery, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE reminder_deliveries
+			SET status = 'sending', attempts = attempts + 1, next_attempt_at = $2
+			WHERE id IN (
+				SELECT id
+				FROM reminder_deliveries
+				WHERE status IN ('pending', 'sending', 'retry') AND next_attempt_at <= $1
+				ORDER BY next_attempt_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, reminder_id, channel, attempts, scheduled_for
+		)
+		SELECT c.id, c.reminder_id, c.channel, c.attempts, c.scheduled_for,
+		       r.user_id, r.title, r.description, u.email, COALESCE(u.preferred_language, 'en'),
+		       up.phone_number
+		FROM claimed c
+		JOIN reminders r ON r.id = c.reminder_id
+		JOIN users u ON u.id = r.user_id
+		LEFT JOIN user_phones up ON up.user_id = r.user_id -- Texts and calls only go to verified numbers
+	`
+
+	rows, err := db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim reminder deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]ReminderDelivery, 0)
+	for rows.Next() {
+		var d ReminderDelivery
+		if err := rows.Scan(
+			&d.ID, &d.ReminderID, &d.Channel, &d.Attempts, &d.ScheduledFor,
+			&d.UserID, &d.Title, &d.Description, &d.Email, &d.Language, &d.Phone,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// MarkReminderDeliverySent records a successful delivery
+func (db *DB) MarkReminderDeliverySent(ctx context.Context, id string, sentAt time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE reminder_deliveries
+		SET status = 'sent', sent_at = $2, last_error = NULL
+		WHERE id = $1
+	`, id, sentAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark delivery sent: %w", err)
+	}
+	return nil
+}
+
+// MarkReminderDeliveryFailed records a failed attempt. With a retryAt the
+// delivery is retried then; otherwise it ends with the given final status
+// (DeliveryFailed or DeliverySkipped).
+func (db *DB) MarkReminderDeliveryFailed(ctx context.Context, id, errMsg string, retryAt *time.Time, finalStatus string) error {
+	status := finalStatus
+	if retryAt != nil {
+		status = DeliveryRetry
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE reminder_deliveries
+		SET status = $2, last_error = $3, next_attempt_at = COALESCE($4, next_attempt_at)
+		WHERE id = $1
+	`, id, status, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark delivery failed: %w", err)
+	}
+	return nil
+}
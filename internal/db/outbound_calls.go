@@ -0,0 +1,281 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Outbound call kinds
+const (
+	CallKindCheckin  = "checkin"
+	CallKindReminder = "reminder"
+)
+
+// Outbound call statuses
+const (
+	CallPending    = "pending"
+	CallDialing    = "dialing"
+	CallInProgress = "in_progress"
+	CallRetry      = "retry"
+	CallCompleted  = "completed"
+	CallNoAnswer   = "no_answer"
+	CallBusy       = "busy"
+	CallFailed     = "failed"
+	CallSkipped    = "skipped"
+)
+
+// VoiceCallPreferences are a user's outbound call settings. Times are
+// HH:MM in the user's timezone.
+type VoiceCallPreferences struct {
+	UserID               string    `json:"user_id"`
+	CheckinEnabled       bool      `json:"checkin_enabled"`
+	CheckinWeekday       int       `json:"checkin_weekday"` // ISO: 1 = Monday ... 7 = Sunday
+	CheckinTime          string    `json:"checkin_time"`
+	ReminderCallsEnabled bool      `json:"reminder_calls_enabled"`
+	QuietStart           string    `json:"quiet_start"`
+	QuietEnd             string    `json:"quiet_end"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// OutboundCall is a claimed call to place, joined with what is needed to place it
+type OutboundCall struct {
+	ID           string
+	UserID       string
+	Kind         string
+	ReminderID   *string
+	ScheduledFor time.Time
+	Message      string
+	Status       string
+	Attempts     int // Including the current attempt
+	CallSid      *string
+	Phone        *string
+	Name         *string
+	Language     string
+	CountryCode  *string
+	Timezone     string
+	QuietStart   string
+	QuietEnd     string
+}
+
+// GetVoiceCallPreferences returns a user's outbound call settings, or the
+// defaults (no calls) if they never changed them
+func (db *DB) GetVoiceCallPreferences(ctx context.Context, userID string) (*VoiceCallPreferences, error) {
+	prefs := &VoiceCallPreferences{UserID: userID, CheckinWeekday: 1, CheckinTime: "10:00", QuietStart: "21:00", QuietEnd: "08:00"}
+	err := db.QueryRowContext(ctx, `
+		SELECT checkin_enabled, checkin_weekday, TO_CHAR(checkin_time, 'HH24:MI'), reminder_calls_enabled,
+		       TO_CHAR(quiet_start, 'HH24:MI'), TO_CHAR(quiet_end, 'HH24:MI'), updated_at
+		FROM voice_call_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&prefs.CheckinEnabled, &prefs.CheckinWeekday, &prefs.CheckinTime, &prefs.ReminderCallsEnabled,
+		&prefs.QuietStart, &prefs.QuietEnd, &prefs.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return prefs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voice call preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// SaveVoiceCallPreferences creates or replaces a user's outbound call settings
+func (db *DB) SaveVoiceCallPreferences(ctx context.Context, prefs *VoiceCallPreferences) error {
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO voice_call_preferences (user_id, checkin_enabled, checkin_weekday, checkin_time,
+			reminder_calls_enabled, quiet_start, quiet_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			checkin_enabled = EXCLUDED.checkin_enabled,
+			checkin_weekday = EXCLUDED.checkin_weekday,
+			checkin_time = EXCLUDED.checkin_time,
+			reminder_calls_enabled = EXCLUDED.reminder_calls_enabled,
+			quiet_start = EXCLUDED.quiet_start,
+			quiet_end = EXCLUDED.quiet_end,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, prefs.UserID, prefs.CheckinEnabled, prefs.CheckinWeekday, prefs.CheckinTime,
+		prefs.ReminderCallsEnabled, prefs.QuietStart, prefs.QuietEnd).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save voice call preferences: %w", err)
+	}
+	return nil
+}
+
+// QueueCheckinCalls queues this week's check-in call for users whose
+// check-in slot (weekday and time in their timezone) has passed, unless the
+// slot is more than maxLateness ago. It returns the number of calls queued.
+func (db *DB) QueueCheckinCalls(ctx context.Context, now time.Time, maxLateness time.Duration, limit int) (int, error) {
+	result, err := db.ExecContext(ctx, `
+		WITH slots AS (
+			SELECT p.user_id,
+			       (DATE_TRUNC('week', $1::timestamptz AT TIME ZONE z.tz)
+			        + (p.checkin_weekday - 1) * INTERVAL '1 day' + p.checkin_time) AT TIME ZONE z.tz AS slot
+			FROM voice_call_preferences p
+			JOIN users u ON u.id = p.user_id
+			CROSS JOIN LATERAL (SELECT COALESCE(NULLIF(u.timezone, ''), 'UTC') AS tz) z
+			WHERE p.checkin_enabled = TRUE
+		)
+		INSERT INTO outbound_calls (user_id, kind, scheduled_for, next_attempt_at)
+		SELECT user_id, 'checkin', slot, $1
+		FROM slots
+		WHERE slot <= $1 AND slot > $2
+		ORDER BY slot
+		LIMIT $3
+		ON CONFLICT (user_id, scheduled_for) WHERE kind = 'checkin' DO NOTHING
+	`, now, now.Add(-maxLateness), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue check-in calls: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// QueueReminderCall queues a call for a reminder occurrence; queuing the
+// same occurrence twice is a no-op
+func (db *DB) QueueReminderCall(ctx context.Context, userID, reminderID string, scheduledFor time.Time, message string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO outbound_calls (user_id, kind, reminder_id, scheduled_for, message, next_attempt_at)
+		VALUES ($1, 'reminder', $2, $3, $4, $5)
+		ON CONFLICT (reminder_id, scheduled_for) WHERE kind = 'reminder' DO NOTHING
+	`, userID, reminderID, scheduledFor, message, now)
+	if err != nil {
+		return fmt.Errorf("failed to queue reminder call: %w", err)
+	}
+	return nil
+}
+
+const outboundCallJoin = `
+		SELECT c.id, c.user_id, c.kind, c.reminder_id, c.scheduled_for, c.message, c.status, c.attempts, c.call_sid,
+		       up.phone_number, u.display_name, COALESCE(u.preferred_language, 'en'), u.country_code,
+		       COALESCE(NULLIF(u.timezone, ''), 'UTC'),
+		       TO_CHAR(COALESCE(vp.quiet_start, '21:00'), 'HH24:MI'), TO_CHAR(COALESCE(vp.quiet_end, '08:00'), 'HH24:MI')
+		FROM %s c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN user_phones up ON up.user_id = c.user_id
+		LEFT JOIN voice_call_preferences vp ON vp.user_id = c.user_id`
+
+func scanOutboundCall(row interface{ Scan(...any) error }) (*OutboundCall, error) {
+	var call OutboundCall
+	err := row.Scan(&call.ID, &call.UserID, &call.Kind, &call.ReminderID, &call.ScheduledFor, &call.Message,
+		&call.Status, &call.Attempts, &call.CallSid, &call.Phone, &call.Name, &call.Language, &call.CountryCode,
+		&call.Timezone, &call.QuietStart, &call.QuietEnd)
+	if err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// ClaimOutboundCalls leases up to limit calls that are due to be placed. A
+// call whose worker died before dialing is claimed again after lease; one
+// already dialed waits for Twilio's status callback.
+func (db *DB) ClaimOutboundCalls(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboundCall, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbound_calls
+			SET status = 'dialing', attempts = attempts + 1, next_attempt_at = $2, updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM outbound_calls
+				WHERE next_attempt_at <= $1
+				  AND (status IN ('pending', 'retry') OR (status = 'dialing' AND call_sid IS NULL))
+				ORDER BY next_attempt_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)` + fmt.Sprintf(outboundCallJoin, "claimed")
+
+	rows, err := db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbound calls: %w", err)
+	}
+	defer rows.Close()
+
+	calls := make([]OutboundCall, 0)
+	for rows.Next() {
+		call, err := scanOutboundCall(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbound call: %w", err)
+		}
+		calls = append(calls, *call)
+	}
+	return calls, rows.Err()
+}
+
+// GetOutboundCall returns an outbound call by ID
+func (db *DB) GetOutboundCall(ctx context.Context, id string) (*OutboundCall, error) {
+	call, err := scanOutboundCall(db.QueryRowContext(ctx, fmt.Sprintf(outboundCallJoin, "outbound_calls")+`
+		WHERE c.id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbound call: %w", err)
+	}
+	return call, nil
+}
+
+// GetOutboundCallBySid returns the outbound call Twilio knows by callSid
+func (db *DB) GetOutboundCallBySid(ctx context.Context, callSid string) (*OutboundCall, error) {
+	call, err := scanOutboundCall(db.QueryRowContext(ctx, fmt.Sprintf(outboundCallJoin, "outbound_calls")+`
+		WHERE c.call_sid = $1
+	`, callSid))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbound call: %w", err)
+	}
+	return call, nil
+}
+
+// DeferOutboundCall puts a claimed call back until a later time without
+// counting the attempt (e.g. to wait out the user's quiet hours)
+func (db *DB) DeferOutboundCall(ctx context.Context, id string, until time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE outbound_calls
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), next_attempt_at = $2, updated_at = NOW()
+		WHERE id = $1
+	`, id, until)
+	if err != nil {
+		return fmt.Errorf("failed to defer outbound call: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboundCallDialed records the CallSid of a placed call
+func (db *DB) MarkOutboundCallDialed(ctx context.Context, id, callSid string) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE outbound_calls
+		SET call_sid = $2, last_error = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, id, callSid)
+	if err != nil {
+		return fmt.Errorf("failed to record dialed call: %w", err)
+	}
+	return nil
+}
+
+// UpdateOutboundCall records a call's status. With a retryAt the call is
+// placed again then, as a new Twilio call.
+func (db *DB) UpdateOutboundCall(ctx context.Context, id, status string, duration *int, errMsg *string, retryAt *time.Time) error {
+	if retryAt != nil {
+		status = CallRetry
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE outbound_calls
+		SET status = $2,
+		    duration_seconds = COALESCE($3, duration_seconds),
+		    last_error = COALESCE($4, last_error),
+		    next_attempt_at = COALESCE($5, next_attempt_at),
+		    call_sid = CASE WHEN $5::timestamptz IS NULL THEN call_sid ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, status, duration, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to update outbound call: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// UserPhone is a user's verified phone number
+type UserPhone struct {
+	UserID      string    `json:"-"`
+	PhoneNumber string    `json:"phone_number"` // E.164
+	VerifiedAt  time.Time `json:"verified_at"`
+	PinHash     *string   `json:"-"` // bcrypt hash of the voice PIN, if set
+
+	PinFailures    int        `json:"-"` // Wrong PINs since PinFailedSince
+	PinFailedSince *time.Time `json:"-"`
+}
+
+// PhoneVerification is a one-time code sent to a number the user is verifying
+type PhoneVerification struct {
+	UserID      string    `json:"-"`
+	PhoneNumber string    `json:"phone_number"`
+	CodeHash    string    `json:"-"`
+	Attempts    int       `json:"-"`
+	SentAt      time.Time `json:"sent_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// GetUserByPhone returns the user who verified phone (E.164). Unverified
+// numbers never match, so caller ID alone cannot claim an account.
+func (db *DB) GetUserByPhone(ctx context.Context, phone string) (*User, error) {
+	query := userSelectSQL + `
+		WHERE id = (SELECT user_id FROM user_phones WHERE phone_number = $1)
+	`
+
+	user, err := scanUser(db.QueryRowContext(ctx, query, phone))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by phone: %w", err)
+	}
+	return user, nil
+}
+
+// GetUserPhone returns a user's verified phone number
+func (db *DB) GetUserPhone(ctx context.Context, userID string) (*UserPhone, error) {
+	p := &UserPhone{UserID: userID}
+	err := db.QueryRowContext(ctx, `
+		SELECT phone_number, verified_at, voice_pin_hash, pin_failures, pin_failed_since
+		FROM user_phones
+		WHERE user_id = $1
+	`, userID).Scan(&p.PhoneNumber, &p.VerifiedAt, &p.PinHash, &p.PinFailures, &p.PinFailedSince)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user phone: %w", err)
+	}
+	return p, nil
+}
+
+// GetPhoneOwner returns the ID of the user who verified phone
+func (db *DB) GetPhoneOwner(ctx context.Context, phone string) (string, error) {
+	var userID string
+	err := db.QueryRowContext(ctx, `SELECT user_id FROM user_phones WHERE phone_number = $1`, phone).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get phone owner: %w", err)
+	}
+	return userID, nil
+}
+
+// DeleteUserPhone unlinks a user's verified phone number
+func (db *DB) DeleteUserPhone(ctx context.Context, userID string) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM user_phones WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user phone: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetVoicePin stores the hash of a user's voice PIN; nil removes it. Either
+// way it clears any PIN lockout.
+func (db *DB) SetVoicePin(ctx context.Context, userID string, pinHash *string) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE user_phones
+		SET voice_pin_hash = $2, pin_failures = 0, pin_failed_since = NULL, updated_at = NOW()
+		WHERE user_id = $1
+	`, userID, pinHash)
+	if err != nil {
+		return fmt.Errorf("failed to set voice PIN: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordVoicePinFailure counts a wrong voice PIN and returns the wrong PINs
+// entered within window of the first, including this one. A failure after the
+// window has passed starts a new one.
+func (db *DB) RecordVoicePinFailure(ctx context.Context, userID string, now time.Time, window time.Duration) (int, error) {
+	var failures int
+	err := db.QueryRowContext(ctx, `
+		UPDATE user_phones
+		SET pin_failures = CASE WHEN pin_failed_since > $2 THEN pin_failures + 1 ELSE 1 END,
+		    pin_failed_since = CASE WHEN pin_failed_since > $2 THEN pin_failed_since ELSE $3 END
+		WHERE user_id = $1
+		RETURNING pin_failures
+	`, userID, now.Add(-window), now).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record voice PIN failure: %w", err)
+	}
+	return failures, nil
+}
+
+// ResetVoicePinFailures clears wrong PINs after a correct one
+func (db *DB) ResetVoicePinFailures(ctx context.Context, userID string) error {
+	if _, err := db.ExecContext(ctx, `
+		UPDATE user_phones SET pin_failures = 0, pin_failed_since = NULL WHERE user_id = $1 AND pin_failures > 0
+	`, userID); err != nil {
+		return fmt.Errorf("failed to reset voice PIN failures: %w", err)
+	}
+	return nil
+}
+
+// SavePhoneVerification replaces the user's pending verification
+func (db *DB) SavePhoneVerification(ctx context.Context, v *PhoneVerification) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO phone_verifications (user_id, phone_number, code_hash, attempts, sent_at, expires_at)
+		VALUES ($1, $2, $3, 0, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			phone_number = EXCLUDED.phone_number,
+			code_hash = EXCLUDED.code_hash,
+			attempts = 0,
+			sent_at = EXCLUDED.sent_at,
+			expires_at = EXCLUDED.expires_at
+	`, v.UserID, v.PhoneNumber, v.CodeHash, v.SentAt, v.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save phone verification: %w", err)
+	}
+	return nil
+}
+
+// GetPhoneVerification returns the user's pending verification
+func (db *DB) GetPhoneVerification(ctx context.Context, userID string) (*PhoneVerification, error) {
+	v := &PhoneVerification{UserID: userID}
+	err := db.QueryRowContext(ctx, `
+		SELECT phone_number, code_hash, attempts, sent_at, expires_at
+		FROM phone_verifications
+		WHERE user_id = $1
+	`, userID).Scan(&v.PhoneNumber, &v.CodeHash, &v.Attempts, &v.SentAt, &v.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phone verification: %w", err)
+	}
+	return v, nil
+}
+
+// CountPhoneVerificationAttempt records a guess at the code and returns the
+// attempts made so far, including this one
+func (db *DB) CountPhoneVerificationAttempt(ctx context.Context, userID string) (int, error) {
+	var attempts int
+	err := db.QueryRowContext(ctx, `
+		UPDATE phone_verifications SET attempts = attempts + 1 WHERE user_id = $1 RETURNING attempts
+	`, userID).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count verification attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+// DeletePhoneVerification removes the user's pending verification
+func (db *DB) DeletePhoneVerification(ctx context.Context, userID string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM phone_verifications WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete phone verification: %w", err)
+	}
+	return nil
+}
+
+// ConfirmPhone makes phone the user's verified number and clears the pending
+// verification, in one transaction. Changing number clears the voice PIN.
+// It returns ErrAlreadyExists if another user verified the number first.
+func (db *DB) ConfirmPhone(ctx context.Context, userID, phone string, verifiedAt time.Time) (*UserPhone, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	p := &UserPhone{UserID: userID, PhoneNumber: phone, VerifiedAt: verifiedAt}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_phones (user_id, phone_number, verified_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			phone_number = EXCLUDED.phone_number,
+			verified_at = EXCLUDED.verified_at,
+			voice_pin_hash = CASE WHEN user_phones.phone_number = EXCLUDED.phone_number
+			                      THEN user_phones.voice_pin_hash END,
+			pin_failures = CASE WHEN user_phones.phone_number = EXCLUDED.phone_number
+			                    THEN user_phones.pin_failures ELSE 0 END,
+			pin_failed_since = CASE WHEN user_phones.phone_number = EXCLUDED.phone_number
+			                        THEN user_phones.pin_failed_since END,
+			updated_at = NOW()
+		RETURNING voice_pin_hash
+	`, userID, phone, verifiedAt).Scan(&p.PinHash)
+	if isDuplicateKeyError(err) {
+		return nil, ErrAlreadyExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm phone: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM phone_verifications WHERE user_id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete phone verification: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return p, nil
+}
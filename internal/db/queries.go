@@ -21,7 +21,7 @@ const userSelectSQL = `
 	       is_first_pregnancy, primary_concern, diet_preference,
 	       journey_stage, journey_stage_since, baby_birth_date, loss_date,
 	       profile_photo_url, country, country_code, state_province, city,
-	       community_onboarding_completed_at,
+	       timezone, community_onboarding_completed_at,
 	       savings_goal, is_admin, onboarding_completed_at, created_at, updated_at
 	FROM users`
 
@@ -36,7 +36,7 @@ func scanUser(scanner interface {
 		&user.DietPreference, &user.JourneyStage, &user.JourneyStageSince,
 		&user.BabyBirthDate, &user.LossDate,
 		&user.ProfilePhotoURL, &user.Country, &user.CountryCode, &user.StateProvince, &user.City,
-		&user.CommunityOnboardingAt,
+		&user.Timezone, &user.CommunityOnboardingAt,
 		&user.SavingsGoal, &user.IsAdmin, &user.OnboardingCompletedAt,
 		&user.CreatedAt, &user.UpdatedAt,
 	)
@@ -65,6 +65,7 @@ type UserProfileUpdate struct {
 	CountryCode          *string
 	StateProvince        *string
 	City                 *string
+	Timezone             *string
 }
 
 // CreateUser creates a new user
@@ -127,6 +128,19 @@ func (db *DB) GetUserByID(ctx context.Context, id string) (*User, error) {
 	return user, nil
 }
 
+// GetUserTimezone returns the user's IANA timezone
+func (db *DB) GetUserTimezone(ctx context.Context, userID string) (string, error) {
+	var tz string
+	err := db.QueryRowContext(ctx, `SELECT timezone FROM users WHERE id = $1`, userID).Scan(&tz)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	return tz, nil
+}
+
 // SaveMessage saves a chat message
 func (db *DB) SaveMessage(ctx context.Context, userID, conversationID, role, content string) (*Message, error) {
 	query := `
@@ -146,13 +160,16 @@ func (db *DB) SaveMessage(ctx context.Context, userID, conversationID, role, con
 	return msg, nil
 }
 
-// GetRecentMessages retrieves the N most recent messages for a user
+// GetRecentMessages retrieves the N most recent messages for a user,
+// ignoring anything before the user last wiped the assistant's memory
 func (db *DB) GetRecentMessages(ctx context.Context, userID string, limit int) ([]Message, error) {
 	query := `
-		SELECT id, user_id, role, content, created_at
-		FROM messages
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT m.id, m.user_id, m.role, m.content, m.created_at
+		FROM messages m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.user_id = $1
+		  AND (u.memory_reset_at IS NULL OR m.created_at > u.memory_reset_at)
+		ORDER BY m.created_at DESC
 		LIMIT $2
 	`
 
@@ -180,20 +197,20 @@ func (db *DB) GetRecentMessages(ctx context.Context, userID string, limit int) (
 }
 
 // SaveOrUpdateFact saves or updates a user fact
-func (db *DB) SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64) (*UserFact, error) {
+func (db *DB) SaveOrUpdateFact(ctx context.Context, userID, key, value string, confidence float64, source string) (*UserFact, error) {
 	query := `
-		INSERT INTO user_facts (user_id, key, value, confidence)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO user_facts (user_id, key, value, confidence, source)
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (user_id, key) 
-		DO UPDATE SET value = $3, confidence = $4, updated_at = CURRENT_TIMESTAMP
+		DO UPDATE SET value = $3, confidence = $4, source = $5, updated_at = CURRENT_TIMESTAMP
 		WHERE user_facts.confidence <= $4
-		RETURNING id, user_id, key, value, confidence, created_at, updated_at
+		RETURNING id, user_id, key, value, confidence, source, created_at, updated_at
 	`
 
 	fact := &UserFact{}
-	err := db.QueryRowContext(ctx, query, userID, key, value, confidence).Scan(
+	err := db.QueryRowContext(ctx, query, userID, key, value, confidence, source).Scan(
 		&fact.ID, &fact.UserID, &fact.Key, &fact.Value,
-		&fact.Confidence, &fact.CreatedAt, &fact.UpdatedAt,
+		&fact.Confidence, &fact.Source, &fact.CreatedAt, &fact.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save fact: %w", err)
@@ -421,7 +438,7 @@ func (db *DB) MarkSymptomResolved(ctx context.Context, symptomID, userID string)
 // GetUserFacts retrieves all facts for a user
 func (db *DB) GetUserFacts(ctx context.Context, userID string) ([]UserFact, error) {
 	query := `
-		SELECT id, user_id, key, value, confidence, created_at, updated_at
+		SELECT id, user_id, key, value, confidence, source, created_at, updated_at
 		FROM user_facts
 		WHERE user_id = $1
 		ORDER BY updated_at DESC
@@ -437,7 +454,7 @@ func (db *DB) GetUserFacts(ctx context.Context, userID string) ([]UserFact, erro
 	for rows.Next() {
 		var fact UserFact
 		if err := rows.Scan(&fact.ID, &fact.UserID, &fact.Key, &fact.Value,
-			&fact.Confidence, &fact.CreatedAt, &fact.UpdatedAt); err != nil {
+			&fact.Confidence, &fact.Source, &fact.CreatedAt, &fact.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan fact: %w", err)
 		}
 		facts = append(facts, fact)
@@ -446,6 +463,50 @@ func (db *DB) GetUserFacts(ctx context.Context, userID string) ([]UserFact, erro
 	return facts, nil
 }
 
+// DeleteUserFact removes a single fact
+func (db *DB) DeleteUserFact(ctx context.Context, userID, key string) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM user_facts WHERE user_id = $1 AND key = $2`, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete fact: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check fact delete result: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// WipeUserMemory forgets everything the assistant learned about a user:
+// facts, long-term memory embeddings and, via memory_reset_at, the recent
+// messages used as short-term context. Conversation history is kept.
+func (db *DB) WipeUserMemory(ctx context.Context, userID string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_facts WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete facts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_embeddings WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete memory embeddings: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET memory_reset_at = CURRENT_TIMESTAMP WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to reset memory: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit memory wipe: %w", err)
+	}
+	return nil
+}
+
 // GetSystemSetting retrieves a system setting by key
 func (db *DB) GetSystemSetting(ctx context.Context, key string) (*SystemSetting, error) {
 	query := `
@@ -530,17 +591,27 @@ func (db *DB) GetAllSystemSettings(ctx context.Context) ([]SystemSetting, error)
 	return settings, nil
 }
 
+// reminderInsertQuery inserts a reminder armed to notify at its first
+// occurrence unless it is already completed
+const reminderInsertQuery = `
+	INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, community_event_id, rrule, timezone, ical_uid, source, care_plan_item, next_notify_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, CASE WHEN $5 THEN NULL ELSE $4 END)
+	RETURNING id, created_at, updated_at
+`
+
 // CreateReminder creates a new reminder
 func (db *DB) CreateReminder(ctx context.Context, reminder *Reminder) error {
-	query := `
-		INSERT INTO reminders (user_id, title, description, reminder_time, is_completed, community_event_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
-	`
-
-	return db.QueryRowContext(ctx, query,
+	if reminder.Timezone == "" {
+		reminder.Timezone = "UTC"
+	}
+	if reminder.Source == "" {
+		reminder.Source = ReminderSourceUser
+	}
+	return db.QueryRowContext(ctx, reminderInsertQuery,
 		reminder.UserID, reminder.Title, reminder.Description,
 		reminder.ReminderTime, reminder.IsCompleted, reminder.CommunityEventID,
+		reminder.RRule, reminder.Timezone, reminder.ICalUID,
+		reminder.Source, reminder.CarePlanItem,
 	).Scan(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt)
 }
 
@@ -548,10 +619,11 @@ func scanReminder(scanner interface {
 	Scan(dest ...any) error
 }) (Reminder, error) {
 	var reminder Reminder
-	var communityEventID sql.NullString
+	var communityEventID, rrule sql.NullString
 	if err := scanner.Scan(
 		&reminder.ID, &reminder.UserID, &reminder.Title, &reminder.Description,
 		&reminder.ReminderTime, &reminder.IsCompleted, &communityEventID,
+		&rrule, &reminder.Timezone, &reminder.ICalUID, &reminder.Source, &reminder.CarePlanItem,
 		&reminder.CreatedAt, &reminder.UpdatedAt,
 	); err != nil {
 		return Reminder{}, err
@@ -560,13 +632,17 @@ func scanReminder(scanner interface {
 		id := communityEventID.String
 		reminder.CommunityEventID = &id
 	}
+	if rrule.Valid {
+		rule := rrule.String
+		reminder.RRule = &rule
+	}
 	return reminder, nil
 }
 
 // GetUserReminders retrieves all reminders for a user
 func (db *DB) GetUserReminders(ctx context.Context, userID string) ([]Reminder, error) {
 	query := `
-		SELECT id, user_id, title, description, reminder_time, is_completed, community_event_id, created_at, updated_at
+		SELECT id, user_id, title, description, reminder_time, is_completed, community_event_id, rrule, timezone, ical_uid, source, care_plan_item, created_at, updated_at
 		FROM reminders
 		WHERE user_id = $1
 		ORDER BY reminder_time ASC
@@ -621,7 +697,7 @@ func (db *DB) GetEnabledLanguages(ctx context.Context) ([]Language, error) {
 // GetReminderByID retrieves a reminder by ID
 func (db *DB) GetReminderByID(ctx context.Context, id string) (*Reminder, error) {
 	query := `
-		SELECT id, user_id, title, description, reminder_time, is_completed, community_event_id, created_at, updated_at
+		SELECT id, user_id, title, description, reminder_time, is_completed, community_event_id, rrule, timezone, ical_uid, source, care_plan_item, created_at, updated_at
 		FROM reminders
 		WHERE id = $1
 	`
@@ -641,13 +717,21 @@ func (db *DB) GetReminderByID(ctx context.Context, id string) (*Reminder, error)
 func (db *DB) UpdateReminder(ctx context.Context, reminder *Reminder) error {
 	query := `
 		UPDATE reminders
-		SET title = $1, description = $2, reminder_time = $3, is_completed = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
+		SET title = $1, description = $2, reminder_time = $3, is_completed = $4, rrule = $5, timezone = $6,
+		    updated_at = CURRENT_TIMESTAMP,
+		    -- Rescheduling a reminder re-arms its notification; the scheduler
+		    -- moves a recurring one on to its next occurrence
+		    next_notify_at = CASE
+		        WHEN $4 THEN NULL
+		        WHEN reminder_time <> $3 OR rrule IS DISTINCT FROM $5 OR timezone <> $6 OR is_completed THEN $3
+		        ELSE next_notify_at
+		    END
+		WHERE id = $7
 	`
 
 	result, err := db.ExecContext(ctx, query,
 		reminder.Title, reminder.Description, reminder.ReminderTime,
-		reminder.IsCompleted, reminder.ID,
+		reminder.IsCompleted, reminder.RRule, reminder.Timezone, reminder.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update reminder: %w", err)
@@ -664,6 +748,123 @@ func (db *DB) UpdateReminder(ctx context.Context, reminder *Reminder) error {
 	return nil
 }
 
+// SplitReminder ends a recurring reminder's series before splitAt, with the
+// already-truncated rule in head.RRule, and creates next to carry on from
+// there ("this and following" edits). Completions from splitAt on move to next.
+func (db *DB) SplitReminder(ctx context.Context, head, next *Reminder, splitAt time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE reminders
+		SET rrule = $1, updated_at = CURRENT_TIMESTAMP,
+		    next_notify_at = CASE WHEN next_notify_at >= $2 THEN NULL ELSE next_notify_at END
+		WHERE id = $3
+	`, head.RRule, splitAt, head.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update reminder: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	if next.Timezone == "" {
+		next.Timezone = "UTC"
+	}
+	if next.Source == "" {
+		next.Source = ReminderSourceUser
+	}
+	if err := tx.QueryRowContext(ctx, reminderInsertQuery,
+		next.UserID, next.Title, next.Description,
+		next.ReminderTime, next.IsCompleted, next.CommunityEventID,
+		next.RRule, next.Timezone, next.ICalUID,
+		next.Source, next.CarePlanItem,
+	).Scan(&next.ID, &next.CreatedAt, &next.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reminder_occurrences
+		SET reminder_id = $1
+		WHERE reminder_id = $2 AND occurrence_time >= $3
+	`, next.ID, head.ID, splitAt); err != nil {
+		return fmt.Errorf("failed to move reminder occurrences: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetReminderOccurrences returns the completed occurrences of the given
+// reminders within [from, to)
+func (db *DB) GetReminderOccurrences(ctx context.Context, reminderIDs []string, from, to time.Time) ([]ReminderOccurrence, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT reminder_id, occurrence_time, completed_at
+		FROM reminder_occurrences
+		WHERE reminder_id = ANY($1) AND occurrence_time >= $2 AND occurrence_time < $3
+		ORDER BY occurrence_time
+	`, pq.Array(reminderIDs), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder occurrences: %w", err)
+	}
+	defer rows.Close()
+
+	occurrences := make([]ReminderOccurrence, 0)
+	for rows.Next() {
+		var o ReminderOccurrence
+		if err := rows.Scan(&o.ReminderID, &o.OccurrenceTime, &o.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder occurrence: %w", err)
+		}
+		occurrences = append(occurrences, o)
+	}
+	return occurrences, nil
+}
+
+// SetReminderOccurrenceCompleted marks one occurrence of a recurring reminder
+// as completed or not
+func (db *DB) SetReminderOccurrenceCompleted(ctx context.Context, reminderID string, occurrence time.Time, completed bool) error {
+	var err error
+	if completed {
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO reminder_occurrences (reminder_id, occurrence_time)
+			VALUES ($1, $2)
+			ON CONFLICT (reminder_id, occurrence_time) DO NOTHING
+		`, reminderID, occurrence)
+	} else {
+		_, err = db.ExecContext(ctx, `
+			DELETE FROM reminder_occurrences WHERE reminder_id = $1 AND occurrence_time = $2
+		`, reminderID, occurrence)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update reminder occurrence: %w", err)
+	}
+	return nil
+}
+
+// AdvanceReminder moves a recurring reminder from the occurrence that was
+// queued to the next one; nil next disarms it when the series is over. It
+// does nothing if the reminder was rescheduled or advanced meanwhile.
+func (db *DB) AdvanceReminder(ctx context.Context, reminderID string, from time.Time, next *time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE reminders
+		SET next_notify_at = $3
+		WHERE id = $1 AND next_notify_at = $2 AND is_completed = FALSE
+	`, reminderID, from, next)
+	if err != nil {
+		return fmt.Errorf("failed to advance reminder: %w", err)
+	}
+	return nil
+}
+
 // DeleteReminder deletes a reminder
 func (db *DB) DeleteReminder(ctx context.Context, id string) error {
 	query := `DELETE FROM reminders WHERE id = $1`
@@ -737,8 +938,9 @@ func (db *DB) UpdateUserProfileDetails(ctx context.Context, userID string, updat
 		    country_code = COALESCE($15, country_code),
 		    state_province = COALESCE($16, state_province),
 		    city = COALESCE($17, city),
+		    timezone = COALESCE($18, timezone),
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $18
+		WHERE id = $19
 	`
 
 	result, err := db.ExecContext(ctx, query,
@@ -759,6 +961,7 @@ func (db *DB) UpdateUserProfileDetails(ctx context.Context, userID string, updat
 		update.CountryCode,
 		update.StateProvince,
 		update.City,
+		update.Timezone,
 		userID,
 	)
 	if err != nil {
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// VoiceSession is the state of an active voice call, keyed by Twilio CallSid
+type VoiceSession struct {
+	CallSid        string    `json:"call_sid"`
+	UserID         string    `json:"user_id"`
+	Language       string    `json:"language"`
+	CountryCode    string    `json:"country_code"`
+	From           string    `json:"from"`
+	ConversationID string    `json:"conversation_id"` // Empty until the first turn creates one
+	Messages       []string  `json:"messages"`        // What the caller said, in order
+	PinPending     bool      `json:"pin_pending"`     // Caller must enter the voice PIN before talking
+	PinAttempts    int       `json:"pin_attempts"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// GetVoiceSession returns an unexpired voice session
+func (db *DB) GetVoiceSession(ctx context.Context, callSid string) (*VoiceSession, error) {
+	s := &VoiceSession{CallSid: callSid}
+	var conversationID sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT user_id, language, country_code, from_number, conversation_id, messages,
+		       pin_pending, pin_attempts, expires_at
+		FROM voice_sessions
+		WHERE call_sid = $1 AND expires_at > NOW()
+	`, callSid).Scan(&s.UserID, &s.Language, &s.CountryCode, &s.From, &conversationID,
+		pq.Array(&s.Messages), &s.PinPending, &s.PinAttempts, &s.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voice session: %w", err)
+	}
+	s.ConversationID = conversationID.String
+	return s, nil
+}
+
+// SaveVoiceSession creates or replaces a voice session
+func (db *DB) SaveVoiceSession(ctx context.Context, s *VoiceSession) error {
+	var conversationID *string
+	if s.ConversationID != "" {
+		conversationID = &s.ConversationID
+	}
+	messages := s.Messages
+	if messages == nil {
+		messages = []string{}
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO voice_sessions (call_sid, user_id, language, country_code, from_number, conversation_id, messages,
+		                            pin_pending, pin_attempts, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (call_sid) DO UPDATE
+		SET language = EXCLUDED.language,
+		    country_code = EXCLUDED.country_code,
+		    conversation_id = EXCLUDED.conversation_id,
+		    messages = EXCLUDED.messages,
+		    pin_pending = EXCLUDED.pin_pending,
+		    pin_attempts = EXCLUDED.pin_attempts,
+		    expires_at = EXCLUDED.expires_at,
+		    updated_at = NOW()
+	`, s.CallSid, s.UserID, s.Language, s.CountryCode, s.From, conversationID, pq.Array(messages),
+		s.PinPending, s.PinAttempts, s.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save voice session: %w", err)
+	}
+	return nil
+}
+
+// DeleteVoiceSession removes a voice session when its call ends
+func (db *DB) DeleteVoiceSession(ctx context.Context, callSid string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM voice_sessions WHERE call_sid = $1`, callSid); err != nil {
+		return fmt.Errorf("failed to delete voice session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredVoiceSessions removes sessions whose calls ended without a status callback
+func (db *DB) DeleteExpiredVoiceSessions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, `DELETE FROM voice_sessions WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired voice sessions: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n, nil
+}
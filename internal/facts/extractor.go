@@ -0,0 +1,185 @@
+package facts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+// Request is one user turn to extract facts from
+type Request struct {
+	Message       string
+	PreviousReply string // Assistant message the user is answering (optional)
+	Known         []Fact // Facts already stored, so lists can be updated in full
+}
+
+// Extractor asks the LLM for structured facts with per-fact confidence and
+// falls back to keyword rules when the LLM is unavailable.
+type Extractor struct {
+	llmClient llm.Client
+	now       func() time.Time
+}
+
+// NewExtractor creates a fact extractor. The LLM client may be nil, in which
+// case only the keyword rules are used.
+func NewExtractor(client llm.Client) *Extractor {
+	return &Extractor{llmClient: client, now: time.Now}
+}
+
+// Extract returns the validated facts stated in the user's message
+func (x *Extractor) Extract(ctx context.Context, req Request) []Fact {
+	if strings.TrimSpace(req.Message) == "" {
+		return nil
+	}
+	if x.llmClient == nil {
+		return Rules(req.Message)
+	}
+
+	extracted, err := x.extractLLM(ctx, req)
+	if err != nil {
+		log.Printf("LLM fact extraction failed, using keyword rules: %v", err)
+		return Rules(req.Message)
+	}
+	return extracted
+}
+
+type llmFact struct {
+	Key        string          `json:"key"`
+	Value      json.RawMessage `json:"value"`
+	Confidence float64         `json:"confidence"`
+}
+
+func (x *Extractor) extractLLM(ctx context.Context, req Request) ([]Fact, error) {
+	ctx, cancel := context.WithTimeout(llm.WithTask(ctx, llm.TaskFacts), 10*time.Second)
+	defer cancel()
+
+	now := x.now()
+	resp, err := x.llmClient.ChatCompletion(ctx, llm.ChatRequest{
+		Messages: []llm.ChatMessage{
+			{Role: "user", Content: buildExtractionPrompt(req, now)},
+		},
+		MaxTokens:   300,
+		Temperature: 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty fact extraction response")
+	}
+
+	content := strings.TrimSpace(resp.Choices[0].Message.Content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var parsed struct {
+		Facts []llmFact `json:"facts"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid fact extraction JSON: %w", err)
+	}
+
+	return validate(parsed.Facts, now), nil
+}
+
+// validate drops unknown keys, malformed values and low-confidence facts,
+// caps confidence and keeps the most confident fact per key.
+func validate(raw []llmFact, now time.Time) []Fact {
+	best := make(map[string]Fact)
+	for _, f := range raw {
+		if f.Confidence < MinConfidence {
+			continue
+		}
+		value, err := Normalize(f.Key, rawValue(f.Value), now)
+		if err != nil {
+			continue
+		}
+		confidence := f.Confidence
+		if confidence > MaxConfidence {
+			confidence = MaxConfidence
+		}
+		if prev, ok := best[f.Key]; ok && prev.Confidence >= confidence {
+			continue
+		}
+		best[f.Key] = Fact{Key: f.Key, Value: value, Confidence: confidence}
+	}
+
+	valid := make([]Fact, 0, len(best))
+	for _, f := range best {
+		valid = append(valid, f)
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].Key < valid[j].Key })
+	return valid
+}
+
+// rawValue flattens a JSON string, number, boolean or string list into text
+func rawValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	var b bool
+	if json.Unmarshal(raw, &b) == nil {
+		return strconv.FormatBool(b)
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, ", ")
+	}
+	return ""
+}
+
+func buildExtractionPrompt(req Request, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(`Extract durable facts the user states about themselves in a pregnancy support chat.
+Respond with JSON only (no markdown):
+{"facts": [{"key": "<key>", "value": <value>, "confidence": 0.0 to 1.0}]}
+
+Keys (omit any the user does not clearly state):
+- due_date: expected delivery date as YYYY-MM-DD (today is ` + now.Format(dateLayout) + `)
+- pregnancy_week: current week of pregnancy, 1-42
+- is_first_pregnancy: "yes" or "no"
+- prior_pregnancies: number of previous pregnancies
+- allergies: list of allergies, or ["none"]
+- medications: list of medicines or supplements currently taken
+- conditions: list of medical conditions (e.g. gestational diabetes, asthma)
+- diet: dietary preference (e.g. vegetarian, halal)
+- support_network: who supports the user (e.g. partner, mother, nobody)
+- work_situation: employment situation (e.g. works full time, on leave, student)
+- primary_concern: the main pregnancy concern the user raises
+
+For list keys, return the complete updated list: the known value plus anything new, minus anything the user says has stopped.
+Never guess; use a confidence below 0.5 for anything uncertain. Return {"facts": []} if there is nothing to extract.
+`)
+
+	if len(req.Known) > 0 {
+		b.WriteString("\nKnown facts:\n")
+		for _, f := range req.Known {
+			fmt.Fprintf(&b, "- %s: %s\n", f.Key, f.Value)
+		}
+	}
+	if reply := strings.TrimSpace(req.PreviousReply); reply != "" {
+		fmt.Fprintf(&b, "\nAssistant's previous message: %s\n", truncate(reply, 500))
+	}
+	fmt.Fprintf(&b, "\nUser message: %s\n", truncate(req.Message, 1000))
+	return b.String()
+}
+
+func truncate(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
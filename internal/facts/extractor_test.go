@@ -0,0 +1,156 @@
+package facts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+type fakeLLM struct {
+	content string
+	err     error
+	lastReq llm.ChatRequest
+	task    llm.Task
+}
+
+func (f *fakeLLM) StreamChatCompletion(context.Context, llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeLLM) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	f.lastReq = req
+	f.task = llm.TaskFromContext(ctx)
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, _ := json.Marshal(map[string]any{
+		"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": f.content}}},
+	})
+	var resp llm.ChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func newTestExtractor(client llm.Client) *Extractor {
+	x := NewExtractor(client)
+	x.now = func() time.Time { return time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) }
+	return x
+}
+
+func TestExtractor_ValidatesLLMFacts(t *testing.T) {
+	client := &fakeLLM{content: "```json\n" + `{"facts": [
+		{"key": "due_date", "value": "2026-07-20", "confidence": 0.9},
+		{"key": "allergies", "value": ["Penicillin", "peanuts", "penicillin"], "confidence": 1.0},
+		{"key": "prior_pregnancies", "value": 2, "confidence": 0.8},
+		{"key": "medications", "value": "folic acid", "confidence": 0.3},
+		{"key": "pregnancy_week", "value": 58, "confidence": 0.9},
+		{"key": "favourite_colour", "value": "blue", "confidence": 0.9},
+		{"key": "is_first_pregnancy", "value": false, "confidence": 0.7}
+	]}` + "\n```"}
+
+	got := newTestExtractor(client).Extract(context.Background(), Request{
+		Message: "I'm due July 20th, this is my third pregnancy and I'm allergic to penicillin and peanuts",
+		Known:   []Fact{{Key: KeyAllergies, Value: "penicillin"}},
+	})
+
+	want := []Fact{
+		{Key: KeyAllergies, Value: "Penicillin, peanuts", Confidence: MaxConfidence},
+		{Key: KeyDueDate, Value: "2026-07-20", Confidence: 0.9},
+		{Key: KeyIsFirstPregnancy, Value: "no", Confidence: 0.7},
+		{Key: KeyPriorPregnancies, Value: "2", Confidence: 0.8},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("fact %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if client.task != llm.TaskFacts {
+		t.Errorf("task = %q, want %q", client.task, llm.TaskFacts)
+	}
+	prompt := client.lastReq.Messages[0].Content
+	if !strings.Contains(prompt, "- allergies: penicillin") || !strings.Contains(prompt, "today is 2026-03-01") {
+		t.Errorf("prompt missing known facts or date:\n%s", prompt)
+	}
+}
+
+func TestExtractor_FallsBackToRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		client llm.Client
+	}{
+		{"no client", nil},
+		{"LLM error", &fakeLLM{err: errors.New("provider down")}},
+		{"invalid JSON", &fakeLLM{content: "Sure! Here are the facts."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTestExtractor(tt.client).Extract(context.Background(), Request{
+				Message: "I'm 12 weeks along with my first baby and I'm vegetarian",
+			})
+			values := make(map[string]string)
+			for _, f := range got {
+				values[f.Key] = f.Value
+			}
+			if values[KeyPregnancyWeek] != "12" || values[KeyIsFirstPregnancy] != "yes" || values[KeyDiet] != "vegetarian" {
+				t.Errorf("rule facts = %+v", got)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		key, value, want string
+		wantErr          bool
+	}{
+		{KeyDueDate, "2026-09-30", "2026-09-30", false},
+		{KeyDueDate, "2027-06-01", "", true},
+		{KeyDueDate, "next spring", "", true},
+		{KeyPregnancyWeek, "0", "", true},
+		{KeyIsFirstPregnancy, "YES", "yes", false},
+		{KeyConditions, " asthma ,, gestational diabetes, Asthma", "asthma, gestational diabetes", false},
+		{KeyWorkSituation, "  nurse,   night   shifts ", "nurse, night shifts", false},
+		{KeySupportNetwork, strings.Repeat("x", 201), "", true},
+		{"journey_stage", "pregnant", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.key, tt.value, now)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Normalize(%s, %q) = %q, %v; want %q, err %v", tt.key, tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRules_PregnancyWeek(t *testing.T) {
+	tests := map[string]string{
+		"I'm 22 weeks today":   "22",
+		"just hit 8-week mark": "8",
+		"in 60 weeks":          "",
+		"we have been trying":  "",
+	}
+	for msg, want := range tests {
+		got := ""
+		for _, f := range Rules(msg) {
+			if f.Key == KeyPregnancyWeek {
+				got = f.Value
+			}
+		}
+		if got != want {
+			t.Errorf("Rules(%q) week = %q, want %q", msg, got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package facts extracts durable facts about a user (due date, allergies,
+// medications, ...) from chat messages.
+package facts
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the facts the extractor understands
+const (
+	KeyDueDate          = "due_date"
+	KeyPregnancyWeek    = "pregnancy_week"
+	KeyIsFirstPregnancy = "is_first_pregnancy"
+	KeyPriorPregnancies = "prior_pregnancies"
+	KeyAllergies        = "allergies"
+	KeyMedications      = "medications"
+	KeyConditions       = "conditions"
+	KeyDiet             = "diet"
+	KeySupportNetwork   = "support_network"
+	KeyWorkSituation    = "work_situation"
+	KeyPrimaryConcern   = "primary_concern"
+)
+
+const (
+	// MinConfidence is the lowest confidence at which an extracted fact is kept
+	MinConfidence = 0.5
+	// MaxConfidence caps extracted facts so they never outrank what the
+	// user entered themselves (profile and corrections are stored at 1.0)
+	MaxConfidence = 0.95
+
+	maxValueLength = 200
+	dateLayout     = "2006-01-02"
+)
+
+// ErrUnknownKey is returned for keys outside the fact schema
+var ErrUnknownKey = errors.New("unknown fact key")
+
+// Fact is a single validated fact about the user
+type Fact struct {
+	Key        string  `json:"key"`
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+}
+
+type kind int
+
+const (
+	kindText kind = iota
+	kindList
+	kindDate
+	kindInt
+	kindYesNo
+)
+
+var schema = map[string]kind{
+	KeyDueDate:          kindDate,
+	KeyPregnancyWeek:    kindInt,
+	KeyIsFirstPregnancy: kindYesNo,
+	KeyPriorPregnancies: kindInt,
+	KeyAllergies:        kindList,
+	KeyMedications:      kindList,
+	KeyConditions:       kindList,
+	KeyDiet:             kindText,
+	KeySupportNetwork:   kindText,
+	KeyWorkSituation:    kindText,
+	KeyPrimaryConcern:   kindText,
+}
+
+// IsKnownKey reports whether key is part of the fact schema
+func IsKnownKey(key string) bool {
+	_, ok := schema[key]
+	return ok
+}
+
+// Normalize validates value for key and returns it in canonical form:
+// dates as YYYY-MM-DD, yes/no flags lowercased, lists comma-separated
+// and de-duplicated.
+func Normalize(key, value string, now time.Time) (string, error) {
+	k, ok := schema[key]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", key)
+	}
+
+	switch k {
+	case kindDate:
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a YYYY-MM-DD date", key)
+		}
+		// A due date is at most ~10 months away and not long past
+		if date.Before(now.AddDate(0, 0, -30)) || date.After(now.AddDate(0, 0, 300)) {
+			return "", fmt.Errorf("%s is out of range", key)
+		}
+		return date.Format(dateLayout), nil
+
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a whole number", key)
+		}
+		lo, hi := 0, 20
+		if key == KeyPregnancyWeek {
+			lo, hi = 1, 42
+		}
+		if n < lo || n > hi {
+			return "", fmt.Errorf("%s must be between %d and %d", key, lo, hi)
+		}
+		return strconv.Itoa(n), nil
+
+	case kindYesNo:
+		switch strings.ToLower(value) {
+		case "yes", "true":
+			return "yes", nil
+		case "no", "false":
+			return "no", nil
+		}
+		return "", fmt.Errorf("%s must be yes or no", key)
+
+	case kindList:
+		seen := make(map[string]bool)
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" || seen[strings.ToLower(item)] {
+				continue
+			}
+			seen[strings.ToLower(item)] = true
+			items = append(items, item)
+		}
+		value = strings.Join(items, ", ")
+	}
+
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", key)
+	}
+	if len([]rune(value)) > maxValueLength {
+		return "", fmt.Errorf("%s is too long", key)
+	}
+	return value, nil
+}
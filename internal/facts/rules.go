@@ -0,0 +1,126 @@
+package facts
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RuleConfidence is the confidence of facts found by keyword rules
+const RuleConfidence = 0.85
+
+var pregnancyWeekPattern = regexp.MustCompile(`\b(\d{1,2})\s*(?:-\s*)?weeks?\b`)
+
+// Rules extracts facts with keyword heuristics. It is the fallback used when
+// the LLM is unavailable or returns something unusable.
+func Rules(message string) []Fact {
+	normalized := strings.ToLower(message)
+	var found []Fact
+
+	if week := extractPregnancyWeek(normalized); week != "" {
+		found = append(found, Fact{Key: KeyPregnancyWeek, Value: week, Confidence: RuleConfidence})
+	}
+
+	if value, ok := extractFirstPregnancy(normalized); ok {
+		found = append(found, Fact{Key: KeyIsFirstPregnancy, Value: value, Confidence: RuleConfidence})
+	}
+
+	if diet := extractDietPreference(normalized); diet != "" {
+		found = append(found, Fact{Key: KeyDiet, Value: diet, Confidence: RuleConfidence})
+	}
+
+	if concern := extractPrimaryConcern(normalized); concern != "" {
+		found = append(found, Fact{Key: KeyPrimaryConcern, Value: concern, Confidence: RuleConfidence})
+	}
+
+	return found
+}
+
+func extractPregnancyWeek(normalized string) string {
+	match := pregnancyWeekPattern.FindStringSubmatch(normalized)
+	if match == nil {
+		return ""
+	}
+	week, _ := strconv.Atoi(match[1])
+	if week < 1 || week > 42 {
+		return ""
+	}
+	return strconv.Itoa(week)
+}
+
+func extractFirstPregnancy(normalized string) (string, bool) {
+	firstPatterns := []string{
+		"first pregnancy",
+		"first baby",
+		"first time mom",
+		"first-time mom",
+		"first child",
+		"never been pregnant",
+	}
+	notFirstPatterns := []string{
+		"second pregnancy",
+		"third pregnancy",
+		"not my first",
+		"another baby",
+		"second baby",
+		"third baby",
+	}
+
+	for _, pattern := range notFirstPatterns {
+		if strings.Contains(normalized, pattern) {
+			return "no", true
+		}
+	}
+
+	for _, pattern := range firstPatterns {
+		if strings.Contains(normalized, pattern) {
+			return "yes", true
+		}
+	}
+
+	return "", false
+}
+
+func extractDietPreference(normalized string) string {
+	dietPatterns := map[string]string{
+		"vegetarian":  "vegetarian",
+		"vegan":       "vegan",
+		"pescatarian": "pescatarian",
+		"gluten free": "gluten-free",
+		"gluten-free": "gluten-free",
+		"halal":       "halal",
+		"kosher":      "kosher",
+	}
+
+	for pattern, value := range dietPatterns {
+		if strings.Contains(normalized, pattern) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func extractPrimaryConcern(normalized string) string {
+	concernKeywords := map[string]string{
+		"morning sickness": "morning sickness",
+		"nausea":           "nausea",
+		"headache":         "headaches",
+		"back pain":        "back pain",
+		"cramp":            "cramping",
+		"heartburn":        "heartburn",
+		"swelling":         "swelling",
+		"insomnia":         "sleep issues",
+		"anxiety":          "anxiety",
+		"nutrition":        "nutrition",
+		"diet":             "nutrition",
+	}
+
+	for keyword, concern := range concernKeywords {
+		if strings.Contains(normalized, keyword) {
+			return concern
+		}
+	}
+
+	return ""
+}
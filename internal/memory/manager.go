@@ -241,3 +241,12 @@ func (m *MemoryManager) RemoveFact(userID, key string) {
 
 	delete(userMem.Facts, key)
 }
+
+// Forget drops everything cached for a user. The next GetShortTermMemory
+// reloads from the database.
+func (m *MemoryManager) Forget(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.users, userID)
+}
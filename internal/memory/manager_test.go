@@ -190,6 +190,40 @@ func TestMemoryManager_ClearShortTermMemory(t *testing.T) {
 	}
 }
 
+type stubMessageDB struct {
+	messages []Message
+	loads    int
+}
+
+func (s *stubMessageDB) GetRecentMessages(userID string, limit int) ([]Message, error) {
+	s.loads++
+	return s.messages, nil
+}
+
+func TestMemoryManager_ForgetReloadsFromDB(t *testing.T) {
+	store := &stubMessageDB{messages: []Message{{Role: "user", Content: "I'm allergic to peanuts", Timestamp: time.Now()}}}
+	manager := NewMemoryManager(5, store)
+
+	if history := manager.GetShortTermMemory("user123"); len(history) != 1 {
+		t.Fatalf("Expected 1 loaded message, got %d", len(history))
+	}
+	manager.AddFact("user123", UserFact{Key: "allergies", Value: "peanuts", Confidence: 0.9})
+
+	// The user wiped their memory: the database no longer returns old messages
+	store.messages = nil
+	manager.Forget("user123")
+
+	if history := manager.GetShortTermMemory("user123"); len(history) != 0 {
+		t.Errorf("Expected forgotten history, got %+v", history)
+	}
+	if _, ok := manager.GetFactByKey("user123", "allergies"); ok {
+		t.Error("Expected cached fact to be forgotten")
+	}
+	if store.loads != 2 {
+		t.Errorf("Expected history to be reloaded after Forget, loads = %d", store.loads)
+	}
+}
+
 func TestMemoryManager_MultipleUsers(t *testing.T) {
 	manager := NewMemoryManager(5, nil)
 
@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/embedding"
+)
+
+// Sources of long-term semantic memories
+const (
+	SourceMessage     = "message"
+	SourceDoctorVisit = "doctor_visit"
+)
+
+// StoredEmbedding is an embedded memory as persisted by an EmbeddingStore
+type StoredEmbedding struct {
+	Source    string // SourceMessage or SourceDoctorVisit
+	SourceID  string
+	Content   string
+	Vector    []float32
+	CreatedAt time.Time
+}
+
+// Snippet is a past memory recalled because it relates to the current message
+type Snippet struct {
+	Source    string    `json:"source"`
+	Content   string    `json:"content"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmbeddingStore persists embedded memories
+type EmbeddingStore interface {
+	SaveEmbedding(ctx context.Context, userID, model string, e StoredEmbedding) error
+	// LoadEmbeddings returns up to limit of the user's most recent embeddings
+	// for a model created since the given time
+	LoadEmbeddings(ctx context.Context, userID, model string, since time.Time, limit int) ([]StoredEmbedding, error)
+}
+
+// SemanticConfig tunes recall. Every chat message loads and scores up to
+// MaxCandidates vectors, so it bounds the cost of a recall; older memories
+// are left out by the query rather than scored.
+type SemanticConfig struct {
+	MaxCandidates int           // Most recent memories scored per recall. Default: 500
+	MaxAge        time.Duration // Older memories are not recalled. Default: 1 year
+	MinScore      float64       // Minimum cosine similarity to recall. Default: 0.15
+	MinLength     int           // Shorter texts are not worth remembering. Default: 20
+	MaxLength     int           // Texts are truncated before embedding. Default: 2000
+}
+
+// SemanticMemory remembers a user's full history (chat messages, doctor
+// visit notes) as embeddings and recalls the snippets most related to
+// what the user is asking now.
+type SemanticMemory struct {
+	store    EmbeddingStore
+	embedder embedding.Embedder
+	config   SemanticConfig
+	now      func() time.Time
+}
+
+// NewSemanticMemory creates a semantic memory over the given store and embedder
+func NewSemanticMemory(store EmbeddingStore, embedder embedding.Embedder, config SemanticConfig) *SemanticMemory {
+	if config.MaxCandidates <= 0 {
+		config.MaxCandidates = 500
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = 365 * 24 * time.Hour
+	}
+	if config.MinScore == 0 {
+		config.MinScore = 0.15
+	}
+	if config.MinLength <= 0 {
+		config.MinLength = 20
+	}
+	if config.MaxLength <= 0 {
+		config.MaxLength = 2000
+	}
+	return &SemanticMemory{store: store, embedder: embedder, config: config, now: time.Now}
+}
+
+// Index embeds and stores a memory. Very short texts are skipped.
+func (s *SemanticMemory) Index(ctx context.Context, userID, source, sourceID, content string) error {
+	content = strings.TrimSpace(content)
+	if len([]rune(content)) < s.config.MinLength {
+		return nil
+	}
+	if runes := []rune(content); len(runes) > s.config.MaxLength {
+		content = string(runes[:s.config.MaxLength])
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{content})
+	if err != nil {
+		return fmt.Errorf("embed memory: %w", err)
+	}
+
+	return s.store.SaveEmbedding(ctx, userID, s.embedder.Model(), StoredEmbedding{
+		Source:   source,
+		SourceID: sourceID,
+		Content:  content,
+		Vector:   vectors[0],
+	})
+}
+
+// Recall returns up to k memories most similar to the query, best first.
+// Only the MaxCandidates most recent memories within MaxAge are considered.
+func (s *SemanticMemory) Recall(ctx context.Context, userID, query string, k int) ([]Snippet, error) {
+	if k <= 0 || strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+
+	since := s.now().Add(-s.config.MaxAge)
+	stored, err := s.store.LoadEmbeddings(ctx, userID, s.embedder.Model(), since, s.config.MaxCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("load memories: %w", err)
+	}
+
+	snippets := make([]Snippet, 0, k)
+	for _, e := range stored {
+		score := embedding.Cosine(vectors[0], e.Vector)
+		if score < s.config.MinScore {
+			continue
+		}
+		snippets = append(snippets, Snippet{
+			Source:    e.Source,
+			Content:   e.Content,
+			Score:     score,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(snippets, func(i, j int) bool { return snippets[i].Score > snippets[j].Score })
+	if len(snippets) > k {
+		snippets = snippets[:k]
+	}
+	return snippets, nil
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/embedding"
+)
+
+type fakeEmbeddingStore struct {
+	saved map[string][]StoredEmbedding // userID/model -> embeddings
+}
+
+func (f *fakeEmbeddingStore) SaveEmbedding(_ context.Context, userID, model string, e StoredEmbedding) error {
+	if f.saved == nil {
+		f.saved = make(map[string][]StoredEmbedding)
+	}
+	e.CreatedAt = time.Now()
+	f.saved[userID+"/"+model] = append(f.saved[userID+"/"+model], e)
+	return nil
+}
+
+func (f *fakeEmbeddingStore) LoadEmbeddings(_ context.Context, userID, model string, since time.Time, limit int) ([]StoredEmbedding, error) {
+	var all []StoredEmbedding
+	for _, e := range f.saved[userID+"/"+model] {
+		if !e.CreatedAt.Before(since) {
+			all = append(all, e)
+		}
+	}
+	if len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return all, nil
+}
+
+func TestSemanticMemory_RecallsRelevantHistory(t *testing.T) {
+	store := &fakeEmbeddingStore{}
+	sm := NewSemanticMemory(store, embedding.NewHashEmbedder(512), SemanticConfig{})
+	ctx := context.Background()
+
+	history := []string{
+		"We are painting the nursery yellow this weekend",
+		"My doctor said I have gestational diabetes and need to watch my sugar",
+		"My husband keeps snoring and I can't sleep well",
+		"ok",
+	}
+	for i, msg := range history {
+		if err := sm.Index(ctx, "user-1", SourceMessage, string(rune('a'+i)), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Another user's memories must never be recalled
+	_ = sm.Index(ctx, "user-2", SourceMessage, "x", "I also have gestational diabetes problems")
+
+	if n := len(store.saved["user-1/hash-512"]); n != 3 {
+		t.Fatalf("expected short message to be skipped, stored %d", n)
+	}
+
+	snippets, err := sm.Recall(ctx, "user-1", "Is it safe to eat fruit with diabetes?", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) == 0 || snippets[0].Content != history[1] {
+		t.Fatalf("expected diabetes memory first, got %+v", snippets)
+	}
+	for _, s := range snippets {
+		if s.Content == "I also have gestational diabetes problems" {
+			t.Error("recalled another user's memory")
+		}
+	}
+}
+
+func TestSemanticMemory_NoMatchBelowThreshold(t *testing.T) {
+	store := &fakeEmbeddingStore{}
+	sm := NewSemanticMemory(store, embedding.NewHashEmbedder(512), SemanticConfig{MinScore: 0.5})
+	ctx := context.Background()
+
+	_ = sm.Index(ctx, "user-1", SourceMessage, "a", "We are painting the nursery yellow this weekend")
+
+	snippets, err := sm.Recall(ctx, "user-1", "Is it safe to eat fruit with diabetes?", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 0 {
+		t.Errorf("expected no snippets, got %+v", snippets)
+	}
+}
+
+func TestSemanticMemory_RecallScoresOnlyRecentCandidates(t *testing.T) {
+	store := &fakeEmbeddingStore{}
+	sm := NewSemanticMemory(store, embedding.NewHashEmbedder(512), SemanticConfig{MaxCandidates: 3})
+	ctx := context.Background()
+
+	if defaults := NewSemanticMemory(store, nil, SemanticConfig{}).config; defaults.MaxCandidates != 500 || defaults.MaxAge != 365*24*time.Hour {
+		t.Fatalf("defaults = %+v, want 500 candidates from the last year", defaults)
+	}
+
+	// The match is the oldest memory, beyond the three most recent
+	_ = sm.Index(ctx, "user-1", SourceMessage, "old", "My doctor said I have gestational diabetes and need to watch my sugar")
+	for _, id := range []string{"a", "b", "c"} {
+		_ = sm.Index(ctx, "user-1", SourceMessage, id, "We are painting the nursery yellow this weekend "+id)
+	}
+	snippets, err := sm.Recall(ctx, "user-1", "Is it safe to eat fruit with diabetes?", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range snippets {
+		if s.Content == store.saved["user-1/hash-512"][0].Content {
+			t.Errorf("recalled a memory beyond MaxCandidates: %+v", s)
+		}
+	}
+
+	// Memories older than MaxAge are not loaded at all
+	sm = NewSemanticMemory(store, embedding.NewHashEmbedder(512), SemanticConfig{MaxAge: time.Hour})
+	sm.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	snippets, err = sm.Recall(ctx, "user-1", "painting the nursery", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 0 {
+		t.Errorf("recalled memories older than MaxAge: %+v", snippets)
+	}
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, errors.New("embedding API down")
+}
+func (failingEmbedder) Model() string { return "broken" }
+
+func TestSemanticMemory_EmbedderError(t *testing.T) {
+	sm := NewSemanticMemory(&fakeEmbeddingStore{}, failingEmbedder{}, SemanticConfig{})
+	if err := sm.Index(context.Background(), "u", SourceMessage, "a", "a long enough message to embed"); err == nil {
+		t.Error("expected index error")
+	}
+	if _, err := sm.Recall(context.Background(), "u", "query", 3); err == nil {
+		t.Error("expected recall error")
+	}
+}
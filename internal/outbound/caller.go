@@ -0,0 +1,300 @@
+// Package outbound places scheduled check-in and reminder calls.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
+)
+
+// FeatureKey is the subscription feature a user needs to receive calls
+const FeatureKey = "voice_calls"
+
+// Store is the persistence the caller needs (implemented by *db.DB)
+type Store interface {
+	QueueCheckinCalls(ctx context.Context, now time.Time, maxLateness time.Duration, limit int) (int, error)
+	QueueReminderCall(ctx context.Context, userID, reminderID string, scheduledFor time.Time, message string, now time.Time) error
+	ClaimOutboundCalls(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.OutboundCall, error)
+	DeferOutboundCall(ctx context.Context, id string, until time.Time) error
+	MarkOutboundCallDialed(ctx context.Context, id, callSid string) error
+	UpdateOutboundCall(ctx context.Context, id, status string, duration *int, errMsg *string, retryAt *time.Time) error
+	GetOutboundCall(ctx context.Context, id string) (*db.OutboundCall, error)
+	GetOutboundCallBySid(ctx context.Context, callSid string) (*db.OutboundCall, error)
+}
+
+// Dialer places calls (implemented by *twilio.VoiceClient)
+type Dialer interface {
+	CreateCall(ctx context.Context, to, answerURL, statusURL string) (string, error)
+}
+
+// Features reports whether a user's plan includes a feature
+// (implemented by *subscription.Manager)
+type Features interface {
+	HasFeature(ctx context.Context, userID, featureKey string) (bool, error)
+}
+
+// Config tunes the caller
+type Config struct {
+	BaseURL      string          // Public URL Twilio reaches our webhooks on. Required.
+	PollInterval time.Duration   // Default: 30s
+	BatchSize    int             // Calls queued and claimed per poll. Default: 20
+	MaxLateness  time.Duration   // Check-ins missed by longer are skipped. Default: 6h
+	Lease        time.Duration   // How long a claimed call is hidden from other workers. Default: 2m
+	RetryDelays  []time.Duration // Wait before calling again after no answer; attempts = len + 1. Default: 15m, 1h
+}
+
+// Caller queues and places outbound calls. Several replicas can run it at
+// once: calls are claimed with SKIP LOCKED. It is also the notifier for the
+// voice reminder channel, turning due reminders into queued calls.
+type Caller struct {
+	store    Store
+	dialer   Dialer
+	features Features
+	config   Config
+	now      func() time.Time
+}
+
+// NewCaller creates a caller
+func NewCaller(store Store, dialer Dialer, features Features, config Config) *Caller {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.MaxLateness <= 0 {
+		config.MaxLateness = 6 * time.Hour
+	}
+	if config.Lease <= 0 {
+		config.Lease = 2 * time.Minute
+	}
+	if config.RetryDelays == nil {
+		config.RetryDelays = []time.Duration{15 * time.Minute, time.Hour}
+	}
+
+	return &Caller{
+		store:    store,
+		dialer:   dialer,
+		features: features,
+		config:   config,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Channel implements notify.Notifier
+func (c *Caller) Channel() string {
+	return notify.ChannelVoice
+}
+
+// Send implements notify.Notifier by queuing a reminder call; the call
+// itself is placed on a later tick, outside the user's quiet hours
+func (c *Caller) Send(ctx context.Context, msg notify.Message) error {
+	if msg.Phone == "" {
+		return notify.ErrNoRecipient
+	}
+	reminderID := msg.Data["reminder_id"]
+	scheduledFor, err := time.Parse(time.RFC3339, msg.Data["scheduled_for"])
+	if reminderID == "" || err != nil {
+		return errors.New("reminder call needs reminder_id and scheduled_for")
+	}
+	return c.store.QueueReminderCall(ctx, msg.UserID, reminderID, scheduledFor, msg.Body, c.now())
+}
+
+// Run polls until ctx is cancelled
+func (c *Caller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Outbound caller: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick queues check-ins whose slot has come and places the calls that are due
+func (c *Caller) Tick(ctx context.Context) error {
+	now := c.now()
+
+	queued, err := c.store.QueueCheckinCalls(ctx, now, c.config.MaxLateness, c.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		log.Printf("Outbound caller: queued %d check-in calls", queued)
+	}
+
+	calls, err := c.store.ClaimOutboundCalls(ctx, now, c.config.Lease, c.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, call := range calls {
+		c.place(ctx, call, now)
+	}
+	return nil
+}
+
+func (c *Caller) place(ctx context.Context, call db.OutboundCall, now time.Time) {
+	if call.Phone == nil || *call.Phone == "" {
+		c.record(ctx, call, db.CallSkipped, nil, "no phone number")
+		return
+	}
+	if c.features != nil {
+		ok, err := c.features.HasFeature(ctx, call.UserID, FeatureKey)
+		if err != nil {
+			c.record(ctx, call, db.CallFailed, nil, err.Error())
+			return
+		}
+		if !ok {
+			c.record(ctx, call, db.CallSkipped, nil, "plan does not include voice calls")
+			return
+		}
+	}
+	if until, quiet := quietUntil(now, call.Timezone, call.QuietStart, call.QuietEnd); quiet {
+		if err := c.store.DeferOutboundCall(ctx, call.ID, until); err != nil {
+			log.Printf("Outbound caller: failed to defer call %s: %v", call.ID, err)
+		}
+		return
+	}
+
+	answerURL := c.config.BaseURL + "/api/voice/outbound?callId=" + url.QueryEscape(call.ID)
+	statusURL := c.config.BaseURL + "/api/voice/status"
+
+	dialCtx, cancel := context.WithTimeout(ctx, c.config.Lease/2)
+	sid, err := c.dialer.CreateCall(dialCtx, *call.Phone, answerURL, statusURL)
+	cancel()
+	if err != nil {
+		c.record(ctx, call, db.CallFailed, nil, err.Error())
+		return
+	}
+	if err := c.store.MarkOutboundCallDialed(ctx, call.ID, sid); err != nil {
+		log.Printf("Outbound caller: call %s placed as %s but not recorded: %v", call.ID, sid, err)
+	}
+}
+
+// record stores a call's outcome. Unanswered and failed calls are retried
+// while attempts remain.
+func (c *Caller) record(ctx context.Context, call db.OutboundCall, status string, duration *int, reason string) {
+	var retryAt *time.Time
+	switch status {
+	case db.CallNoAnswer, db.CallBusy, db.CallFailed:
+		if call.Attempts > 0 && call.Attempts <= len(c.config.RetryDelays) {
+			at := c.now().Add(c.config.RetryDelays[call.Attempts-1])
+			retryAt = &at
+		}
+		log.Printf("Outbound caller: %s call %s (attempt %d) %s", call.Kind, call.ID, call.Attempts, status)
+	}
+
+	var errMsg *string
+	if reason != "" {
+		errMsg = &reason
+	}
+	if err := c.store.UpdateOutboundCall(ctx, call.ID, status, duration, errMsg, retryAt); err != nil {
+		log.Printf("Outbound caller: failed to record call %s as %s: %v", call.ID, status, err)
+	}
+}
+
+// Answered returns the call Twilio has just connected and marks it in progress
+func (c *Caller) Answered(ctx context.Context, callID string) (*db.OutboundCall, error) {
+	call, err := c.store.GetOutboundCall(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.UpdateOutboundCall(ctx, call.ID, db.CallInProgress, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// Call returns the call we placed with callSid, or db.ErrNotFound for calls
+// we didn't place
+func (c *Caller) Call(ctx context.Context, callSid string) (*db.OutboundCall, error) {
+	return c.store.GetOutboundCallBySid(ctx, callSid)
+}
+
+// CallStatus records a status callback for a call we placed. It returns
+// db.ErrNotFound for calls that were not outbound calls.
+func (c *Caller) CallStatus(ctx context.Context, callSid string, status twilio.CallStatus, duration int) error {
+	call, err := c.store.GetOutboundCallBySid(ctx, callSid)
+	if err != nil {
+		return err
+	}
+
+	var outcome string
+	switch status {
+	case twilio.CallStatusCompleted:
+		outcome = db.CallCompleted
+	case twilio.CallStatusNoAnswer:
+		outcome = db.CallNoAnswer
+	case twilio.CallStatusBusy:
+		outcome = db.CallBusy
+	case twilio.CallStatusFailed, twilio.CallStatusCanceled:
+		outcome = db.CallFailed
+	default:
+		return nil // Still ringing or talking
+	}
+
+	c.record(ctx, *call, outcome, &duration, "")
+	return nil
+}
+
+// quietUntil reports whether now falls in the quiet hours [start, end) in
+// the user's timezone and, if so, when they end. Quiet hours may wrap past
+// midnight; equal start and end mean none.
+func quietUntil(now time.Time, timezone, start, end string) (time.Time, bool) {
+	startMin, ok1 := clockMinutes(start)
+	endMin, ok2 := clockMinutes(end)
+	if !ok1 || !ok2 || startMin == endMin {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), endMin/60, endMin%60, 0, 0, loc)
+
+	if startMin < endMin {
+		if minute >= startMin && minute < endMin {
+			return endToday, true
+		}
+		return time.Time{}, false
+	}
+	switch {
+	case minute < endMin:
+		return endToday, true
+	case minute >= startMin:
+		return endToday.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// clockMinutes parses HH:MM into minutes after midnight
+func clockMinutes(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// ValidClock reports whether s is a HH:MM time of day
+func ValidClock(s string) bool {
+	_, ok := clockMinutes(s)
+	return ok
+}
@@ -0,0 +1,278 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+	"github.com/themobileprof/momlaunchpad-be/pkg/twilio"
+)
+
+type update struct {
+	status  string
+	errMsg  *string
+	retryAt *time.Time
+}
+
+type fakeStore struct {
+	claimed  []db.OutboundCall
+	bySid    map[string]db.OutboundCall
+	queued   []string
+	deferred map[string]time.Time
+	dialed   map[string]string
+	updates  map[string]update
+}
+
+func (f *fakeStore) QueueCheckinCalls(context.Context, time.Time, time.Duration, int) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeStore) QueueReminderCall(_ context.Context, userID, reminderID string, scheduledFor time.Time, message string, _ time.Time) error {
+	f.queued = append(f.queued, userID+"|"+reminderID+"|"+scheduledFor.Format(time.RFC3339)+"|"+message)
+	return nil
+}
+
+func (f *fakeStore) ClaimOutboundCalls(context.Context, time.Time, time.Duration, int) ([]db.OutboundCall, error) {
+	claimed := f.claimed
+	f.claimed = nil
+	return claimed, nil
+}
+
+func (f *fakeStore) DeferOutboundCall(_ context.Context, id string, until time.Time) error {
+	if f.deferred == nil {
+		f.deferred = make(map[string]time.Time)
+	}
+	f.deferred[id] = until
+	return nil
+}
+
+func (f *fakeStore) MarkOutboundCallDialed(_ context.Context, id, callSid string) error {
+	if f.dialed == nil {
+		f.dialed = make(map[string]string)
+	}
+	f.dialed[id] = callSid
+	return nil
+}
+
+func (f *fakeStore) UpdateOutboundCall(_ context.Context, id, status string, _ *int, errMsg *string, retryAt *time.Time) error {
+	if f.updates == nil {
+		f.updates = make(map[string]update)
+	}
+	f.updates[id] = update{status: status, errMsg: errMsg, retryAt: retryAt}
+	return nil
+}
+
+func (f *fakeStore) GetOutboundCall(_ context.Context, id string) (*db.OutboundCall, error) {
+	for _, call := range f.bySid {
+		if call.ID == id {
+			return &call, nil
+		}
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeStore) GetOutboundCallBySid(_ context.Context, callSid string) (*db.OutboundCall, error) {
+	call, ok := f.bySid[callSid]
+	if !ok {
+		return nil, db.ErrNotFound
+	}
+	return &call, nil
+}
+
+type fakeDialer struct {
+	to, answerURL, statusURL string
+	err                      error
+}
+
+func (d *fakeDialer) CreateCall(_ context.Context, to, answerURL, statusURL string) (string, error) {
+	d.to, d.answerURL, d.statusURL = to, answerURL, statusURL
+	return "CA1", d.err
+}
+
+type fakeFeatures map[string]bool
+
+func (f fakeFeatures) HasFeature(_ context.Context, userID, _ string) (bool, error) {
+	return f[userID], nil
+}
+
+// Sunday 1 March 2026, 13:00 in Lagos
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestCaller(store Store, dialer Dialer) *Caller {
+	c := NewCaller(store, dialer, fakeFeatures{"u1": true}, Config{BaseURL: "https://api.example.com/"})
+	c.now = func() time.Time { return testNow }
+	return c
+}
+
+func strPtr(s string) *string { return &s }
+
+func testCall(id string) db.OutboundCall {
+	return db.OutboundCall{
+		ID: id, UserID: "u1", Kind: db.CallKindCheckin, Attempts: 1, Phone: strPtr("+2348000000001"),
+		Timezone: "Africa/Lagos", QuietStart: "21:00", QuietEnd: "08:00",
+	}
+}
+
+func TestTick_PlacesDueCalls(t *testing.T) {
+	store := &fakeStore{claimed: []db.OutboundCall{testCall("c1")}}
+	dialer := &fakeDialer{}
+
+	if err := newTestCaller(store, dialer).Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if dialer.to != "+2348000000001" ||
+		dialer.answerURL != "https://api.example.com/api/voice/outbound?callId=c1" ||
+		dialer.statusURL != "https://api.example.com/api/voice/status" {
+		t.Errorf("dialed %+v", dialer)
+	}
+	if store.dialed["c1"] != "CA1" {
+		t.Errorf("dialed = %v", store.dialed)
+	}
+}
+
+func TestTick_DefersQuietHours(t *testing.T) {
+	call := testCall("c1")
+	call.QuietStart, call.QuietEnd = "12:30", "14:00"
+	store := &fakeStore{claimed: []db.OutboundCall{call}}
+	dialer := &fakeDialer{}
+
+	_ = newTestCaller(store, dialer).Tick(context.Background())
+
+	if dialer.to != "" {
+		t.Error("called during quiet hours")
+	}
+	if want := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC); !store.deferred["c1"].Equal(want) {
+		t.Errorf("deferred until %v, want %v", store.deferred["c1"], want)
+	}
+}
+
+func TestTick_SkipsWithoutPhoneOrFeature(t *testing.T) {
+	noPhone := testCall("c1")
+	noPhone.Phone = nil
+	noPlan := testCall("c2")
+	noPlan.UserID = "u2"
+	store := &fakeStore{claimed: []db.OutboundCall{noPhone, noPlan}}
+	dialer := &fakeDialer{}
+
+	_ = newTestCaller(store, dialer).Tick(context.Background())
+
+	if dialer.to != "" {
+		t.Error("placed a call that should have been skipped")
+	}
+	for _, id := range []string{"c1", "c2"} {
+		if u := store.updates[id]; u.status != db.CallSkipped || u.retryAt != nil {
+			t.Errorf("%s: %+v", id, u)
+		}
+	}
+}
+
+func TestTick_RetriesDialErrors(t *testing.T) {
+	store := &fakeStore{claimed: []db.OutboundCall{testCall("c1")}}
+
+	_ = newTestCaller(store, &fakeDialer{err: errors.New("twilio returned status 500")}).Tick(context.Background())
+
+	u := store.updates["c1"]
+	if u.status != db.CallFailed || u.retryAt == nil || !u.retryAt.Equal(testNow.Add(15*time.Minute)) {
+		t.Errorf("update = %+v", u)
+	}
+}
+
+func TestCallStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    twilio.CallStatus
+		attempts  int
+		want      string
+		wantRetry time.Duration
+	}{
+		{name: "answered", status: twilio.CallStatusCompleted, attempts: 1, want: db.CallCompleted},
+		{name: "first no answer", status: twilio.CallStatusNoAnswer, attempts: 1, want: db.CallNoAnswer, wantRetry: 15 * time.Minute},
+		{name: "second busy", status: twilio.CallStatusBusy, attempts: 2, want: db.CallBusy, wantRetry: time.Hour},
+		{name: "out of attempts", status: twilio.CallStatusNoAnswer, attempts: 3, want: db.CallNoAnswer},
+		{name: "canceled", status: twilio.CallStatusCanceled, attempts: 3, want: db.CallFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := testCall("c1")
+			call.Attempts = tt.attempts
+			store := &fakeStore{bySid: map[string]db.OutboundCall{"CA1": call}}
+
+			if err := newTestCaller(store, nil).CallStatus(context.Background(), "CA1", tt.status, 42); err != nil {
+				t.Fatal(err)
+			}
+			u := store.updates["c1"]
+			if u.status != tt.want {
+				t.Errorf("status = %s, want %s", u.status, tt.want)
+			}
+			if tt.wantRetry == 0 && u.retryAt != nil {
+				t.Errorf("retry at %v, want none", u.retryAt)
+			}
+			if tt.wantRetry != 0 && (u.retryAt == nil || !u.retryAt.Equal(testNow.Add(tt.wantRetry))) {
+				t.Errorf("retry at %v, want in %v", u.retryAt, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestCallStatus_InboundCall(t *testing.T) {
+	err := newTestCaller(&fakeStore{}, nil).CallStatus(context.Background(), "CA9", twilio.CallStatusCompleted, 10)
+	if !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSend_QueuesReminderCall(t *testing.T) {
+	store := &fakeStore{}
+	c := newTestCaller(store, nil)
+	msg := notify.Message{
+		UserID: "u1",
+		Body:   "Reminder: Antenatal visit",
+		Phone:  "+2348000000001",
+		Data:   map[string]string{"reminder_id": "r1", "scheduled_for": "2026-03-02T09:00:00Z"},
+	}
+
+	if err := c.Send(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.queued) != 1 || store.queued[0] != "u1|r1|2026-03-02T09:00:00Z|Reminder: Antenatal visit" {
+		t.Errorf("queued = %v", store.queued)
+	}
+
+	msg.Phone = ""
+	if err := c.Send(context.Background(), msg); !errors.Is(err, notify.ErrNoRecipient) {
+		t.Errorf("no phone err = %v, want ErrNoRecipient", err)
+	}
+}
+
+func TestQuietUntil(t *testing.T) {
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 3, day, hour, min, 0, 0, lagos) }
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		start, end string
+		want       time.Time
+		quiet      bool
+	}{
+		{name: "evening, wraps midnight", now: at(1, 22, 15), start: "21:00", end: "08:00", want: at(2, 8, 0), quiet: true},
+		{name: "early morning", now: at(1, 6, 0), start: "21:00", end: "08:00", want: at(1, 8, 0), quiet: true},
+		{name: "daytime", now: at(1, 10, 0), start: "21:00", end: "08:00"},
+		{name: "end is exclusive", now: at(1, 8, 0), start: "21:00", end: "08:00"},
+		{name: "same-day window", now: at(1, 13, 0), start: "12:00", end: "14:00", want: at(1, 14, 0), quiet: true},
+		{name: "disabled", now: at(1, 22, 0), start: "00:00", end: "00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, quiet := quietUntil(tt.now.UTC(), "Africa/Lagos", tt.start, tt.end)
+			if quiet != tt.quiet || !got.Equal(tt.want) {
+				t.Errorf("quietUntil = %v, %v; want %v, %v", got, quiet, tt.want, tt.quiet)
+			}
+		})
+	}
+}
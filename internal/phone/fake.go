@@ -0,0 +1,76 @@
+package phone
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/privacy"
+)
+
+// LogSender drops texts, logging only the masked number; the body holds the
+// code and is never logged. It stands in during development when no SMS
+// provider is configured, so it must not be used in production.
+type LogSender struct{}
+
+// SendSMS implements Sender.SendSMS
+func (LogSender) SendSMS(_ context.Context, to, _ string) (string, error) {
+	log.Printf("[phone] SMS not sent (no provider) to=%s", privacy.MaskPhone(to))
+	return "", nil
+}
+
+// SentSMS is a text recorded by FakeSender
+type SentSMS struct {
+	To   string
+	Body string
+}
+
+// FakeSender records texts instead of sending them, for tests
+type FakeSender struct {
+	mu   sync.Mutex
+	sent []SentSMS
+	errs []error // Returned by successive SendSMS calls; nil entries succeed
+}
+
+// NewFakeSender creates a recording sender. SendSMS returns errs in order,
+// then succeeds.
+func NewFakeSender(errs ...error) *FakeSender {
+	return &FakeSender{errs: errs}
+}
+
+// SendSMS implements Sender.SendSMS
+func (f *FakeSender) SendSMS(_ context.Context, to, body string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return "", err
+		}
+	}
+	f.sent = append(f.sent, SentSMS{To: to, Body: body})
+	return "SM-fake", nil
+}
+
+// Sent returns the texts sent so far
+func (f *FakeSender) Sent() []SentSMS {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sent := make([]SentSMS, len(f.sent))
+	copy(sent, f.sent)
+	return sent
+}
+
+// Last returns the most recent text, or a zero SentSMS if none was sent
+func (f *FakeSender) Last() SentSMS {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.sent) == 0 {
+		return SentSMS{}
+	}
+	return f.sent[len(f.sent)-1]
+}
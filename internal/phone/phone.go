@@ -0,0 +1,268 @@
+// Package phone verifies users' phone numbers with one-time SMS codes. Voice
+// and SMS sign-in only trust verified numbers, and an optional spoken PIN
+// confirms the caller on shared phones.
+package phone
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidNumber means the number is not a valid international number
+	ErrInvalidNumber = errors.New("invalid phone number")
+	// ErrNumberTaken means another user already verified the number
+	ErrNumberTaken = errors.New("phone number belongs to another account")
+	// ErrTooSoon means a code was sent moments ago
+	ErrTooSoon = errors.New("code sent too recently")
+	// ErrNoPendingCode means no code was sent, or it was already used
+	ErrNoPendingCode = errors.New("no pending verification")
+	// ErrCodeExpired means the code is too old to use
+	ErrCodeExpired = errors.New("verification code expired")
+	// ErrTooManyAttempts means the code was guessed too often and must be resent
+	ErrTooManyAttempts = errors.New("too many attempts")
+	// ErrWrongCode means the code does not match
+	ErrWrongCode = errors.New("wrong verification code")
+	// ErrInvalidPin means the PIN is not 4 to 6 digits
+	ErrInvalidPin = errors.New("PIN must be 4 to 6 digits")
+	// ErrUnavailable means no SMS provider is configured to send codes
+	ErrUnavailable = errors.New("phone verification unavailable")
+)
+
+// Voice PIN lockout: MaxPinFailures wrong PINs within PinLockoutWindow, over
+// any number of calls, block PIN entry until the window has passed
+const (
+	MaxPinFailures   = 5
+	PinLockoutWindow = time.Hour
+)
+
+// Store is the persistence verification needs (implemented by *db.DB)
+type Store interface {
+	GetPhoneOwner(ctx context.Context, phone string) (string, error)
+	SavePhoneVerification(ctx context.Context, v *db.PhoneVerification) error
+	GetPhoneVerification(ctx context.Context, userID string) (*db.PhoneVerification, error)
+	CountPhoneVerificationAttempt(ctx context.Context, userID string) (int, error)
+	DeletePhoneVerification(ctx context.Context, userID string) error
+	ConfirmPhone(ctx context.Context, userID, phone string, verifiedAt time.Time) (*db.UserPhone, error)
+	SetVoicePin(ctx context.Context, userID string, pinHash *string) error
+}
+
+// Sender delivers codes by SMS (implemented by *twilio.MessagingClient)
+type Sender interface {
+	SendSMS(ctx context.Context, to, body string) (string, error)
+}
+
+// Config tunes code lifetime and limits
+type Config struct {
+	CodeTTL     time.Duration // How long a code stays valid (default 10m)
+	ResendAfter time.Duration // Minimum gap between codes (default 60s)
+	MaxAttempts int           // Guesses allowed per code (default 5)
+}
+
+// Service sends and checks verification codes
+type Service struct {
+	store  Store
+	sender Sender // nil when no SMS provider is configured
+	cfg    Config
+	now    func() time.Time
+}
+
+// NewService creates a phone verification service. With a nil sender,
+// StartVerification and Verify return ErrUnavailable.
+func NewService(store Store, sender Sender, cfg Config) *Service {
+	if cfg.CodeTTL <= 0 {
+		cfg.CodeTTL = 10 * time.Minute
+	}
+	if cfg.ResendAfter <= 0 {
+		cfg.ResendAfter = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &Service{store: store, sender: sender, cfg: cfg, now: time.Now}
+}
+
+var (
+	e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+	pinPattern  = regexp.MustCompile(`^\d{4,6}$`)
+)
+
+// Normalize returns number in E.164 form, e.g. "+234 801-234 5678" becomes
+// "+2348012345678". Numbers must carry a country code, as + or 00.
+func Normalize(number string) (string, error) {
+	n := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+	if strings.HasPrefix(n, "00") {
+		n = "+" + n[2:]
+	}
+	if !e164Pattern.MatchString(n) {
+		return "", ErrInvalidNumber
+	}
+	return n, nil
+}
+
+// StartVerification texts a fresh code to number and returns the pending
+// verification. Any earlier code for the user stops working.
+func (s *Service) StartVerification(ctx context.Context, userID, number, language string) (*db.PhoneVerification, error) {
+	if s.sender == nil {
+		return nil, ErrUnavailable
+	}
+	phone, err := Normalize(number)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.store.GetPhoneOwner(ctx, phone)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && owner != userID {
+		return nil, ErrNumberTaken
+	}
+
+	now := s.now()
+	pending, err := s.store.GetPhoneVerification(ctx, userID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if pending != nil && now.Sub(pending.SentAt) < s.cfg.ResendAfter {
+		return nil, ErrTooSoon
+	}
+
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+	v := &db.PhoneVerification{
+		UserID:      userID,
+		PhoneNumber: phone,
+		CodeHash:    hashCode(userID, phone, code),
+		SentAt:      now,
+		ExpiresAt:   now.Add(s.cfg.CodeTTL),
+	}
+	if err := s.store.SavePhoneVerification(ctx, v); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.sender.SendSMS(ctx, phone, codeMessage(code, language)); err != nil {
+		// Let the user retry at once rather than wait out the resend gap
+		if delErr := s.store.DeletePhoneVerification(ctx, userID); delErr != nil {
+			log.Printf("Failed to clear unsent verification for user %s: %v", userID, delErr)
+		}
+		return nil, fmt.Errorf("failed to send verification code: %w", err)
+	}
+	return v, nil
+}
+
+// Verify checks code against the user's pending verification and, when it
+// matches, makes the number the user's verified number
+func (s *Service) Verify(ctx context.Context, userID, code string) (*db.UserPhone, error) {
+	if s.sender == nil {
+		return nil, ErrUnavailable
+	}
+	v, err := s.store.GetPhoneVerification(ctx, userID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, ErrNoPendingCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(v.ExpiresAt) {
+		return nil, ErrCodeExpired
+	}
+
+	// Count the guess before checking it so parallel requests cannot exceed the limit
+	attempts, err := s.store.CountPhoneVerificationAttempt(ctx, userID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, ErrNoPendingCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attempts > s.cfg.MaxAttempts {
+		return nil, ErrTooManyAttempts
+	}
+
+	want := hashCode(userID, v.PhoneNumber, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(want), []byte(v.CodeHash)) != 1 {
+		return nil, ErrWrongCode
+	}
+
+	p, err := s.store.ConfirmPhone(ctx, userID, v.PhoneNumber, s.now())
+	if errors.Is(err, db.ErrAlreadyExists) {
+		return nil, ErrNumberTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPin sets the PIN callers are asked for before the assistant talks about
+// the user's pregnancy; an empty pin removes it. It returns db.ErrNotFound if
+// the user has no verified number.
+func (s *Service) SetPin(ctx context.Context, userID, pin string) error {
+	if pin == "" {
+		return s.store.SetVoicePin(ctx, userID, nil)
+	}
+	if !pinPattern.MatchString(pin) {
+		return ErrInvalidPin
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	h := string(hash)
+	return s.store.SetVoicePin(ctx, userID, &h)
+}
+
+// CheckPin reports whether pin matches a hash stored by SetPin
+func CheckPin(hash, pin string) bool {
+	return pinPattern.MatchString(pin) && bcrypt.CompareHashAndPassword([]byte(hash), []byte(pin)) == nil
+}
+
+// PinLocked reports whether too many wrong PINs were entered for p recently
+func PinLocked(p *db.UserPhone, now time.Time) bool {
+	return p.PinFailures >= MaxPinFailures && p.PinFailedSince != nil &&
+		now.Before(p.PinFailedSince.Add(PinLockoutWindow))
+}
+
+// newCode returns a random six-digit code
+func newCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashCode binds a code to the user and number it was sent for
+func hashCode(userID, phone, code string) string {
+	sum := sha256.Sum256([]byte(userID + ":" + phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func codeMessage(code, language string) string {
+	if language == "es" {
+		return fmt.Sprintf("Tu código de MomLaunchpad es %s. No lo compartas con nadie.", code)
+	}
+	return fmt.Sprintf("Your MomLaunchpad code is %s. Do not share it with anyone.", code)
+}
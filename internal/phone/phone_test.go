@@ -0,0 +1,251 @@
+package phone
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+)
+
+type fakeStore struct {
+	phones        map[string]*db.UserPhone // by user ID
+	verifications map[string]*db.PhoneVerification
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		phones:        make(map[string]*db.UserPhone),
+		verifications: make(map[string]*db.PhoneVerification),
+	}
+}
+
+func (f *fakeStore) GetPhoneOwner(_ context.Context, phone string) (string, error) {
+	for _, p := range f.phones {
+		if p.PhoneNumber == phone {
+			return p.UserID, nil
+		}
+	}
+	return "", db.ErrNotFound
+}
+
+func (f *fakeStore) SavePhoneVerification(_ context.Context, v *db.PhoneVerification) error {
+	saved := *v
+	saved.Attempts = 0
+	f.verifications[v.UserID] = &saved
+	return nil
+}
+
+func (f *fakeStore) GetPhoneVerification(_ context.Context, userID string) (*db.PhoneVerification, error) {
+	if v, ok := f.verifications[userID]; ok {
+		return v, nil
+	}
+	return nil, db.ErrNotFound
+}
+
+func (f *fakeStore) CountPhoneVerificationAttempt(_ context.Context, userID string) (int, error) {
+	v, ok := f.verifications[userID]
+	if !ok {
+		return 0, db.ErrNotFound
+	}
+	v.Attempts++
+	return v.Attempts, nil
+}
+
+func (f *fakeStore) DeletePhoneVerification(_ context.Context, userID string) error {
+	delete(f.verifications, userID)
+	return nil
+}
+
+func (f *fakeStore) ConfirmPhone(_ context.Context, userID, phone string, verifiedAt time.Time) (*db.UserPhone, error) {
+	if owner, err := f.GetPhoneOwner(context.Background(), phone); err == nil && owner != userID {
+		return nil, db.ErrAlreadyExists
+	}
+	p := &db.UserPhone{UserID: userID, PhoneNumber: phone, VerifiedAt: verifiedAt}
+	f.phones[userID] = p
+	delete(f.verifications, userID)
+	return p, nil
+}
+
+func (f *fakeStore) SetVoicePin(_ context.Context, userID string, pinHash *string) error {
+	p, ok := f.phones[userID]
+	if !ok {
+		return db.ErrNotFound
+	}
+	p.PinHash = pinHash
+	return nil
+}
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+func newTestService(store Store, sender Sender, now *time.Time) *Service {
+	s := NewService(store, sender, Config{})
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+// wrongCode returns a six-digit code other than code
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"+234 801-234 5678":   "+2348012345678",
+		"00234 (801) 2345678": "+2348012345678",
+		"+1.415.555.0100":     "+14155550100",
+	} {
+		if got, err := Normalize(in); err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"08012345678", "+0123456789", "+234abc", "+12345", ""} {
+		if _, err := Normalize(in); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Normalize(%q) err = %v, want ErrInvalidNumber", in, err)
+		}
+	}
+}
+
+func TestVerify_SendsAndConfirmsCode(t *testing.T) {
+	store := newFakeStore()
+	sender := NewFakeSender()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	s := newTestService(store, sender, &now)
+	ctx := context.Background()
+
+	v, err := s.StartVerification(ctx, "user-1", "+234 801 234 5678", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.PhoneNumber != "+2348012345678" || !v.ExpiresAt.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("verification = %+v", v)
+	}
+	sent := sender.Last()
+	code := codePattern.FindString(sent.Body)
+	if sent.To != "+2348012345678" || code == "" {
+		t.Fatalf("sent %+v", sent)
+	}
+	if store.verifications["user-1"].CodeHash == code {
+		t.Error("code stored in plain text")
+	}
+
+	if _, err := s.Verify(ctx, "user-1", wrongCode(code)); !errors.Is(err, ErrWrongCode) {
+		t.Fatalf("wrong code err = %v, want ErrWrongCode", err)
+	}
+	p, err := s.Verify(ctx, "user-1", " "+code+" ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PhoneNumber != "+2348012345678" || !p.VerifiedAt.Equal(now) {
+		t.Errorf("phone = %+v", p)
+	}
+	if _, err := s.Verify(ctx, "user-1", code); !errors.Is(err, ErrNoPendingCode) {
+		t.Errorf("reused code err = %v, want ErrNoPendingCode", err)
+	}
+}
+
+func TestVerify_Limits(t *testing.T) {
+	store := newFakeStore()
+	sender := NewFakeSender()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	s := newTestService(store, sender, &now)
+	ctx := context.Background()
+
+	if _, err := s.StartVerification(ctx, "user-1", "+2348012345678", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartVerification(ctx, "user-1", "+2348012345678", "en"); !errors.Is(err, ErrTooSoon) {
+		t.Errorf("resend err = %v, want ErrTooSoon", err)
+	}
+	code := codePattern.FindString(sender.Last().Body)
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Verify(ctx, "user-1", wrongCode(code)); !errors.Is(err, ErrWrongCode) {
+			t.Fatalf("attempt %d err = %v, want ErrWrongCode", i+1, err)
+		}
+	}
+	if _, err := s.Verify(ctx, "user-1", code); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("after limit err = %v, want ErrTooManyAttempts", err)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if _, err := s.StartVerification(ctx, "user-1", "+2348012345678", "en"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(11 * time.Minute)
+	if _, err := s.Verify(ctx, "user-1", codePattern.FindString(sender.Last().Body)); !errors.Is(err, ErrCodeExpired) {
+		t.Errorf("expired err = %v, want ErrCodeExpired", err)
+	}
+}
+
+func TestStartVerification_Rejections(t *testing.T) {
+	store := newFakeStore()
+	store.phones["user-2"] = &db.UserPhone{UserID: "user-2", PhoneNumber: "+2348012345678"}
+	now := time.Now()
+	ctx := context.Background()
+
+	s := newTestService(store, NewFakeSender(), &now)
+	if _, err := s.StartVerification(ctx, "user-1", "+2348012345678", "en"); !errors.Is(err, ErrNumberTaken) {
+		t.Errorf("taken err = %v, want ErrNumberTaken", err)
+	}
+	if _, err := s.StartVerification(ctx, "user-1", "08012345678", "en"); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("local number err = %v, want ErrInvalidNumber", err)
+	}
+
+	s = newTestService(store, NewFakeSender(errors.New("provider down")), &now)
+	if _, err := s.StartVerification(ctx, "user-1", "+2348099999999", "en"); err == nil {
+		t.Fatal("expected send error")
+	}
+	if _, ok := store.verifications["user-1"]; ok {
+		t.Error("unsent verification kept")
+	}
+}
+
+func TestSetPin(t *testing.T) {
+	store := newFakeStore()
+	now := time.Now()
+	s := newTestService(store, NewFakeSender(), &now)
+	ctx := context.Background()
+
+	if err := s.SetPin(ctx, "user-1", "1234"); !errors.Is(err, db.ErrNotFound) {
+		t.Errorf("no phone err = %v, want db.ErrNotFound", err)
+	}
+
+	store.phones["user-1"] = &db.UserPhone{UserID: "user-1", PhoneNumber: "+2348012345678"}
+	for _, pin := range []string{"123", "1234567", "12a4"} {
+		if err := s.SetPin(ctx, "user-1", pin); !errors.Is(err, ErrInvalidPin) {
+			t.Errorf("SetPin(%q) err = %v, want ErrInvalidPin", pin, err)
+		}
+	}
+	if err := s.SetPin(ctx, "user-1", "4821"); err != nil {
+		t.Fatal(err)
+	}
+	hash := store.phones["user-1"].PinHash
+	if hash == nil || !CheckPin(*hash, "4821") || CheckPin(*hash, "4822") {
+		t.Errorf("stored PIN hash does not check out")
+	}
+
+	if err := s.SetPin(ctx, "user-1", ""); err != nil || store.phones["user-1"].PinHash != nil {
+		t.Errorf("clear PIN: err = %v, hash = %v", err, store.phones["user-1"].PinHash)
+	}
+}
+
+func TestPinLocked(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	since := now.Add(-30 * time.Minute)
+
+	if PinLocked(&db.UserPhone{PinFailures: MaxPinFailures - 1, PinFailedSince: &since}, now) {
+		t.Error("locked below the limit")
+	}
+	if !PinLocked(&db.UserPhone{PinFailures: MaxPinFailures, PinFailedSince: &since}, now) {
+		t.Error("not locked at the limit")
+	}
+	if PinLocked(&db.UserPhone{PinFailures: MaxPinFailures, PinFailedSince: &since}, since.Add(PinLockoutWindow)) {
+		t.Error("still locked after the window")
+	}
+}
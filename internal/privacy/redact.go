@@ -82,6 +82,14 @@ func ContainsPII(text string) bool {
 		medicalIDRegex.MatchString(text)
 }
 
+// MaskPhone hides all but the last four digits of a phone number for logging
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return strings.Repeat("*", len(phone))
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
 // RedactUserInfo removes user-identifying information
 func RedactUserInfo(email, name string) (string, string) {
 	// Replace email with hashed version
@@ -103,3 +103,16 @@ func TestSanitizeForLogging(t *testing.T) {
 		t.Errorf("truncated text should end with '...'")
 	}
 }
+
+func TestMaskPhone(t *testing.T) {
+	tests := map[string]string{
+		"+2348012345678": "**********5678",
+		"5678":           "****",
+		"":               "",
+	}
+	for phone, want := range tests {
+		if got := MaskPhone(phone); got != want {
+			t.Errorf("MaskPhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
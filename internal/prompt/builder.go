@@ -3,6 +3,7 @@ package prompt
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/conversation"
 	"github.com/themobileprof/momlaunchpad-be/internal/memory"
@@ -19,6 +20,7 @@ type PromptRequest struct {
 	ShortTermMemory     []memory.Message
 	Facts               []memory.UserFact
 	RecentSymptoms      []map[string]interface{} // Recent symptom history
+	RelevantMemories    []memory.Snippet         // Past messages/visit notes related to this message
 	ConversationState   *conversation.State      // Track conversation context
 	AIName              string                   // AI assistant name (e.g., "MomBot")
 }
@@ -201,6 +203,19 @@ func (b *Builder) buildSystemPrompt(req PromptRequest) string {
 		sb.WriteString("\n")
 	}
 
+	// Long-term memories recalled for this message
+	if len(req.RelevantMemories) > 0 {
+		sb.WriteString("RELEVANT PAST CONTEXT (from earlier conversations and visit records; use it naturally, only if it helps):\n")
+		for _, snippet := range req.RelevantMemories {
+			source := "user said"
+			if snippet.Source == memory.SourceDoctorVisit {
+				source = "visit record"
+			}
+			sb.WriteString(fmt.Sprintf("- (%s, %s) %s\n", describeAge(snippet.CreatedAt), source, snippet.Content))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Recent symptom history (CRITICAL for context and safety)
 	if len(req.RecentSymptoms) > 0 {
 		sb.WriteString("RECENT SYMPTOM HISTORY (important for tracking patterns):\n")
@@ -255,6 +270,25 @@ func getFactValue(facts []memory.UserFact, key string) string {
 	return ""
 }
 
+// describeAge renders how long ago something happened, e.g. "3 weeks ago"
+func describeAge(t time.Time) string {
+	days := int(time.Since(t).Hours() / 24)
+	switch {
+	case t.IsZero():
+		return "earlier"
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 14:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 60:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	default:
+		return fmt.Sprintf("%d months ago", days/30)
+	}
+}
+
 // isLikelySmallTalk checks if a message is likely small talk
 func isLikelySmallTalk(content string) bool {
 	content = strings.ToLower(content)
@@ -168,3 +168,32 @@ func TestBuilder_AIName(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_RelevantMemories(t *testing.T) {
+	builder := NewBuilder()
+
+	req := PromptRequest{
+		UserMessage: "Can I have fruit smoothies?",
+		Language:    "en",
+		RelevantMemories: []memory.Snippet{
+			{Source: memory.SourceMessage, Content: "My doctor said I have gestational diabetes", CreatedAt: time.Now().AddDate(0, 0, -21)},
+			{Source: memory.SourceDoctorVisit, Content: "Diagnosis: anemia. Plan: iron supplements", CreatedAt: time.Now()},
+		},
+	}
+
+	system := builder.BuildPrompt(req)[0].Content
+
+	for _, want := range []string{
+		"RELEVANT PAST CONTEXT",
+		"(3 weeks ago, user said) My doctor said I have gestational diabetes",
+		"(today, visit record) Diagnosis: anemia",
+	} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt missing %q", want)
+		}
+	}
+
+	if strings.Contains(builder.BuildPrompt(PromptRequest{UserMessage: "hi there, a question", Language: "en"})[0].Content, "RELEVANT PAST CONTEXT") {
+		t.Error("section should be omitted without memories")
+	}
+}
@@ -0,0 +1,148 @@
+// Package recurrence expands RFC 5545 recurrence rules (RRULE) for repeating
+// reminders. Occurrences are generated on the wall clock of the reminder's
+// time zone, so "every day at 08:00" stays at 08:00 across DST changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// MaxExpansion bounds the occurrences returned by a single Between call
+const MaxExpansion = 1000
+
+// ErrInvalidRule is returned for rules that cannot be used for a reminder
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule is a recurrence rule anchored at its first occurrence
+type Rule struct {
+	option rrule.ROption
+	rule   *rrule.RRule
+	loc    *time.Location
+}
+
+// Normalize validates a rule and returns it in canonical form, without the
+// "RRULE:" prefix. DTSTART is not accepted: a reminder's start is its
+// reminder_time.
+func Normalize(rule string) (string, error) {
+	r, err := Parse(rule, time.Now(), "UTC")
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// Parse parses rule for a series whose first occurrence is start, expanded in
+// the IANA time zone tz ("" means UTC).
+func Parse(rule string, start time.Time, tz string) (*Rule, error) {
+	loc, err := LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	rule = strings.TrimPrefix(rule, "RRULE:")
+	if rule == "" || strings.Contains(rule, "DTSTART") || strings.Contains(rule, "\n") {
+		return nil, fmt.Errorf("%w: expected a single RRULE such as FREQ=DAILY;COUNT=30", ErrInvalidRule)
+	}
+
+	option, err := rrule.StrToROptionInLocation(rule, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	// Sub-hourly repeats are not reminders, and would make expansion expensive
+	if option.Freq == rrule.SECONDLY || option.Freq == rrule.MINUTELY {
+		return nil, fmt.Errorf("%w: FREQ must be HOURLY or longer", ErrInvalidRule)
+	}
+	if option.Count < 0 || option.Interval < 0 {
+		return nil, fmt.Errorf("%w: COUNT and INTERVAL must be positive", ErrInvalidRule)
+	}
+	option.Dtstart = start.In(loc).Truncate(time.Second)
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return &Rule{option: *option, rule: r, loc: loc}, nil
+}
+
+// LoadLocation loads an IANA time zone, treating "" as UTC
+func LoadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", tz)
+	}
+	return loc, nil
+}
+
+// String returns the rule in canonical form, without the "RRULE:" prefix
+func (r *Rule) String() string {
+	option := r.option
+	option.Dtstart = time.Time{}
+	return option.RRuleString()
+}
+
+// Start returns the first occurrence
+func (r *Rule) Start() time.Time {
+	return r.option.Dtstart.UTC()
+}
+
+// Between returns the occurrences in [from, to) in UTC, at most MaxExpansion
+func (r *Rule) Between(from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	next := r.rule.Iterator()
+	for len(occurrences) < MaxExpansion {
+		t, ok := next()
+		if !ok || !t.Before(to) {
+			break
+		}
+		if !t.Before(from) {
+			occurrences = append(occurrences, t.UTC())
+		}
+	}
+	return occurrences
+}
+
+// After returns the first occurrence strictly after t
+func (r *Rule) After(t time.Time) (time.Time, bool) {
+	next := r.rule.After(t, false)
+	if next.IsZero() {
+		return time.Time{}, false
+	}
+	return next.UTC(), true
+}
+
+// IsOccurrence reports whether t is one of the rule's occurrences
+func (r *Rule) IsOccurrence(t time.Time) bool {
+	t = t.Truncate(time.Second)
+	next := r.rule.After(t, true)
+	return !next.IsZero() && next.Equal(t)
+}
+
+// Split divides the series at the occurrence at, for editing "this and
+// following" occurrences. head is the rule for the original series, now
+// ending before at ("" if at is the first occurrence); tail is the rule for
+// a new series starting at at, with any COUNT reduced accordingly.
+func (r *Rule) Split(at time.Time) (head, tail string) {
+	before := r.Between(r.Start(), at)
+	if len(before) == 0 {
+		return "", r.String()
+	}
+
+	headOption, tailOption := r.option, r.option
+	headOption.Dtstart, tailOption.Dtstart = time.Time{}, time.Time{}
+	if r.option.Count > 0 {
+		headOption.Count = len(before)
+		tailOption.Count = r.option.Count - len(before)
+	} else {
+		headOption.Until = at.Add(-time.Second).UTC()
+	}
+	return headOption.RRuleString(), tailOption.RRuleString()
+}
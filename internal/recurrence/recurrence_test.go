@@ -0,0 +1,127 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	rules := []string{
+		"",
+		"COUNT=3",
+		"FREQ=MINUTELY",
+		"FREQ=DAILY;BOGUS=1",
+		"DTSTART:20260301T080000Z\nRRULE:FREQ=DAILY",
+	}
+	for _, rule := range rules {
+		if _, err := Parse(rule, start, "UTC"); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+
+	if _, err := Parse("FREQ=DAILY", start, "Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown time zone")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize("rrule:freq=weekly;byday=mo,th")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "FREQ=WEEKLY;BYDAY=MO,TH" {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestBetween_KeepsWallClockAcrossDST(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	start := time.Date(2026, 3, 6, 8, 0, 0, 0, ny) // DST starts 2026-03-08
+
+	r, err := Parse("FREQ=DAILY", start, "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.Between(start, start.AddDate(0, 0, 4))
+	want := []time.Time{
+		time.Date(2026, 3, 6, 13, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 7, 13, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Between() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+		if local := got[i].In(ny); local.Hour() != 8 {
+			t.Errorf("occurrence %d at %v local, want 08:00", i, local)
+		}
+	}
+}
+
+func TestBetween_Window(t *testing.T) {
+	start := time.Date(2026, 1, 5, 20, 0, 0, 0, time.UTC) // Monday
+	r, err := Parse("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=5", start, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.Between(time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) != 4 { // Jan 8, 12, 15, 19 (COUNT reached)
+		t.Fatalf("Between() = %v, want 4 occurrences", got)
+	}
+	if !got[0].Equal(time.Date(2026, 1, 8, 20, 0, 0, 0, time.UTC)) {
+		t.Errorf("first = %v", got[0])
+	}
+}
+
+func TestAfterAndIsOccurrence(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	r, err := Parse("FREQ=DAILY;INTERVAL=2;COUNT=3", start, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, ok := r.After(start)
+	if !ok || !next.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("After(start) = %v, %v", next, ok)
+	}
+	if _, ok := r.After(start.AddDate(0, 0, 4)); ok {
+		t.Error("After(last) should report no more occurrences")
+	}
+
+	if !r.IsOccurrence(start.AddDate(0, 0, 4)) {
+		t.Error("third occurrence not recognised")
+	}
+	if r.IsOccurrence(start.AddDate(0, 0, 1)) {
+		t.Error("off-interval day recognised as occurrence")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	at := start.AddDate(0, 0, 3)
+
+	r, _ := Parse("FREQ=DAILY;COUNT=10", start, "UTC")
+	head, tail := r.Split(at)
+	if head != "FREQ=DAILY;COUNT=3" || tail != "FREQ=DAILY;COUNT=7" {
+		t.Errorf("Split() with COUNT = %q, %q", head, tail)
+	}
+
+	r, _ = Parse("FREQ=DAILY", start, "UTC")
+	head, tail = r.Split(at)
+	if head != "FREQ=DAILY;UNTIL=20260304T075959Z" || tail != "FREQ=DAILY" {
+		t.Errorf("Split() open-ended = %q, %q", head, tail)
+	}
+
+	head, _ = r.Split(start)
+	if head != "" {
+		t.Errorf("Split() at first occurrence head = %q, want empty", head)
+	}
+}
@@ -0,0 +1,273 @@
+package redflag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Category identifies an obstetric red flag that needs urgent care.
+type Category string
+
+const (
+	CategoryHeavyBleeding        Category = "heavy_bleeding"
+	CategorySevereHeadacheVision Category = "severe_headache_vision"
+	CategoryReducedFetalMovement Category = "reduced_fetal_movement"
+	CategoryFluidLeak            Category = "fluid_leak"
+	CategoryChestPain            Category = "chest_pain"
+	CategorySelfHarm             Category = "self_harm"
+)
+
+// Detection is a matched red flag.
+type Detection struct {
+	Category Category `json:"category"`
+	Matched  string   `json:"matched"` // Phrase that triggered the match (for audit)
+}
+
+// rule matches when every group has at least one matching pattern.
+// Single-group rules fire on one phrase; multi-group rules need co-occurring
+// signals (e.g. headache AND vision changes).
+type rule struct {
+	category Category
+	groups   [][]*regexp.Regexp
+}
+
+// Detector performs deterministic red-flag detection. It never calls an LLM
+// so it keeps working when providers are down.
+type Detector struct {
+	rules []rule
+}
+
+// NewDetector creates a detector with the built-in English, Spanish and French rules.
+// Note: RE2's \b is ASCII-only, so patterns ending in accented letters omit it.
+func NewDetector() *Detector {
+	return &Detector{
+		// Self-harm first: it needs a crisis line rather than an obstetric response.
+		rules: []rule{
+			{
+				category: CategorySelfHarm,
+				groups: [][]*regexp.Regexp{compilePatterns([]string{
+					`\bkill (myself|my self)\b`,
+					`\b(want|wanted|going|trying|tempted|urge) to (hurt|harm|cut) (myself|my self)\b`,
+					`\b(thinking|thoughts|thought) (about|of) (hurting|harming|killing|cutting) (myself|my self)\b`,
+					`\b(i keep|i've been|i have been|i'm|i am) (hurting|harming|cutting) (myself|my self)\b`,
+					`\b(end|take) my (own )?life\b`,
+					`\bsuicid(e|al)\b`,
+					`\b(want|wish) (to die|i was dead|i were dead)\b`,
+					`\bbetter off dead\b`,
+					`\bno reason to live\b`,
+					`\b(don'?t|do not) want to (live|be alive|be here)\b`,
+					// Intent only: "will coffee hurt my baby?" is an ordinary question
+					`\b(want|wanted|going|tempted|urge) to (hurt|harm) (my|the) baby\b`,
+					`\b(thinking|thoughts|thought) (about|of) (hurting|harming) (my|the) baby\b`,
+					`\b(matarme|suicidarme|quitarme la vida|hacerme daño)\b`,
+					`\bquiero morir(me)?\b`,
+					`\bno quiero (vivir|seguir viviendo)\b`,
+					`\b(me tuer|me suicider|mettre fin à (ma|mes) (vie|jours)|me faire du mal)\b`,
+					`\bje veux mourir\b`,
+					`\bje ne veux plus vivre\b`,
+				})},
+			},
+			{
+				category: CategoryHeavyBleeding,
+				groups: [][]*regexp.Regexp{compilePatterns([]string{
+					`\b(heavy|heavily|lots of|a lot of|so much|soaking|gushing) (vaginal )?(bleeding|blood)\b`,
+					`\bbleeding (heavily|a lot|so much|won'?t stop|non.?stop)\b`,
+					`\bsoak(ed|ing)? (through )?(a |my )?(pad|pads)\b`,
+					`\b(blood )?clots?\b.*\bbleed`,
+					`\bbleed.*\bclots?\b`,
+					`\b(sangrado|sangro) (abundante|mucho|fuerte)\b`,
+					`\bmucha sangre\b`,
+					`\b(saignement|saigne) (abondant|beaucoup|fort)\b`,
+					`\bbeaucoup de sang\b`,
+				})},
+			},
+			{
+				category: CategorySevereHeadacheVision,
+				groups: [][]*regexp.Regexp{
+					compilePatterns([]string{
+						`\b(severe|bad|terrible|worst|pounding|intense|strong) (headache|head ache|migraine)\b`,
+						`\b(headache|migraine)\b.*\b(won'?t go away|not going away|severe|worst)\b`,
+						`\bdolor de cabeza (fuerte|intenso|severo)\b`,
+						`\b(fuerte|intenso) dolor de cabeza\b`,
+						`\b(mal de tête|maux de tête|migraine) (sévère|intense|violent)\b`,
+						// With vision changes any headache is a warning sign
+						`\b(headaches?|head ache|migraines?)\b`,
+						`\bdolor de cabeza\b`,
+						`\b(mal|maux) de tête\b`,
+					}),
+					compilePatterns([]string{
+						`\bblurr(y|ed)\b`,
+						`\b(blurry|blurred|double|dim|fuzzy|spotty|losing|lost|loss of|changes? in|problems? with|trouble with) (my )?(vision|eyesight|sight)\b`,
+						`\b(vision|eyesight|sight) (is |has |went |keeps )?(going |gone |getting )?(blurry|blurred|fuzzy|dim|dark|double|spotty|black|weird|strange|worse)\b`,
+						`\b(vision|eyesight) (problems?|changes?|loss|issues?)\b`,
+						`\b(seeing|see) (spots|stars|flashes|flashing lights)\b`,
+						`\b(visión borrosa|veo (manchas|luces|lucecitas))\b`,
+						`\b(vision floue|je vois (des taches|des étoiles|des éclairs))\b`,
+					}),
+				},
+			},
+			{
+				category: CategoryReducedFetalMovement,
+				groups: [][]*regexp.Regexp{compilePatterns([]string{
+					`\bbaby (is not|isn'?t|has not|hasn'?t|stopped|stop) (moving|kicking|moved|kicked)\b`,
+					`\b(no|less|fewer|reduced|decreased) (fetal |baby )?(movements?|kicks?)\b`,
+					`\b(can'?t|cannot|don'?t|do not) feel (the |my )?baby( move| moving| kick| kicking)?\b`,
+					`\b(haven'?t|have not|hasn'?t|has not|didn'?t|did not) (felt|feel|noticed|notice) (the |my )?baby (move|moving|moved|kick|kicking|kicked)\b`,
+					`\b(haven'?t|have not|didn'?t|did not) (felt|feel|noticed|notice) (any )?(movements?|kicks?)\b`,
+					`\bbaby (is )?(moving|kicking) (less|much less)\b`,
+					`\b(el|mi) bebé no se mueve\b`,
+					`\bno siento (a )?(mi|el) bebé`,
+					`\b(menos|no hay) movimientos?\b`,
+					`\b(le|mon) bébé ne bouge (plus|pas)\b`,
+					`\bje ne sens plus (le|mon) bébé`,
+					`\b(moins de|pas de) mouvements?\b`,
+				})},
+			},
+			{
+				category: CategoryFluidLeak,
+				groups: [][]*regexp.Regexp{compilePatterns([]string{
+					`\b(water|waters) (broke|has broken|have broken|breaking)\b`,
+					`\b(leaking|leak|gush|gushing|trickle|trickling) (of )?(clear )?(fluid|liquid|water)\b`,
+					`\b(fluid|liquid|water) (is )?(leaking|coming out|gushing|trickling)\b`,
+					`\bse me rompió (la )?fuente\b`,
+					`\b(pérdida|salida|goteo) de (líquido|agua)\b`,
+					`\b(la )?poche des eaux (s'?est )?(rompue|percée|fissurée)\b`,
+					`\b(perte|fuite|écoulement) de liquide\b`,
+				})},
+			},
+			{
+				category: CategoryChestPain,
+				groups: [][]*regexp.Regexp{compilePatterns([]string{
+					`\bchest (pain|pains|tightness|pressure|hurts)\b`,
+					`\b(pain|tightness|pressure) in (my )?chest\b`,
+					`\bmy chest (hurts|is tight)\b`,
+					`\bdolor (en el|de) pecho\b`,
+					`\bme duele el pecho\b`,
+					`\b(douleur|oppression) (à la|dans la) poitrine\b`,
+					`\bmal à la poitrine\b`,
+				})},
+			},
+		},
+	}
+}
+
+// negators cancel a match when they come directly before it in the same
+// clause ("no chest pain", "I don't want to hurt myself"), allowing for a
+// verb and article in between ("I don't have any chest pain", "no tengo
+// dolor en el pecho"). Other negative words further back ("I can't stop
+// bleeding") do not.
+var negators = map[string]bool{
+	"no": true, "not": true, "never": true, "without": true, "nor": true,
+	"don't": true, "dont": true, "doesn't": true, "doesnt": true, "didn't": true, "didnt": true,
+	"haven't": true, "havent": true, "hasn't": true, "hasnt": true,
+	"sin": true, "nunca": true, "tampoco": true,
+	"pas": true, "sans": true, "jamais": true, "aucun": true, "aucune": true,
+}
+
+// negatedVerbs may stand between a negator and the symptom
+var negatedVerbs = map[string]bool{
+	"have": true, "has": true, "had": true, "having": true, "get": true, "got": true,
+	"feel": true, "felt": true, "notice": true, "noticed": true,
+	"tengo": true, "tiene": true, "tuve": true, "siento": true,
+	"ai": true, "n'ai": true, "a": true, "sens": true,
+}
+
+// negatedFillers may follow the verb ("any", French "pas de")
+var negatedFillers = map[string]bool{
+	"any": true, "a": true, "an": true, "the": true,
+	"de": true, "d'": true, "du": true, "des": true, "ningún": true, "ninguna": true,
+}
+
+// negativeWording marks a phrase that is itself negative ("can't feel the
+// baby", "bleeding won't stop"); a negation before it is not a denial
+var negativeWording = regexp.MustCompile(`n't\b|\b(no|not|never|cannot|cant|dont|didnt|havent|hasnt|isnt|wont|stop|stopped|ne|pas|plus|sin|sans)\b`)
+
+// reportedSpeech marks a clause passing on what a third party said or wrote
+// ("my friend said heavy bleeding is normal") rather than a symptom
+var reportedSpeech = regexp.MustCompile(`\b(she|he|they|friend|sister|mom|mum|mother|aunt|grandma|doctor|nurse|midwife|someone|people|everyone|article|google|internet)\b.*\b(said|says|say|told me|tells me|wrote|writes)\b|\baccording to\b|\b(me dijeron|dicen que|dijo que)\b|\b(on m'a dit|on dit|a dit que)\b`)
+
+// firstPerson after a report means the user is describing themself ("she
+// said I'm bleeding heavily")
+var firstPerson = regexp.MustCompile(`\b(i|i'm|im|i've|i'd|me|my|estoy|tengo|mi|yo|je|j'ai|mon|ma|mes)\b`)
+
+// clauseBoundary ends the clause a negation or report can reach across
+var clauseBoundary = regexp.MustCompile(`[.!?;,:]|\b(but|pero|mais|though|although|aunque)\b`)
+
+// Detect returns the first red flag found in message, in rule priority order.
+func (d *Detector) Detect(message string) (Detection, bool) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(message), " "))
+	if normalized == "" {
+		return Detection{}, false
+	}
+
+	for _, r := range d.rules {
+		matched := make([]string, 0, len(r.groups))
+		for _, group := range r.groups {
+			phrase := firstMatch(normalized, group)
+			if phrase == "" {
+				matched = nil
+				break
+			}
+			matched = append(matched, phrase)
+		}
+		if len(matched) > 0 {
+			return Detection{Category: r.category, Matched: strings.Join(matched, " + ")}, true
+		}
+	}
+
+	return Detection{}, false
+}
+
+// firstMatch returns the first phrase in text matching a pattern that is not
+// negated or reported speech
+func firstMatch(text string, patterns []*regexp.Regexp) string {
+	for _, p := range patterns {
+		for _, loc := range p.FindAllStringIndex(text, -1) {
+			phrase := text[loc[0]:loc[1]]
+			if !guarded(text[:loc[0]], negativeWording.MatchString(phrase)) {
+				return phrase
+			}
+		}
+	}
+	return ""
+}
+
+// guarded reports whether the text before a match negates it or reports a
+// third party's words, looking back only to the start of its clause. When
+// in doubt it does not guard: a missed emergency costs more than a false alarm.
+func guarded(before string, negativePhrase bool) bool {
+	if bounds := clauseBoundary.FindAllStringIndex(before, -1); len(bounds) > 0 {
+		before = before[bounds[len(bounds)-1][1]:]
+	}
+	if reports := reportedSpeech.FindAllStringIndex(before, -1); len(reports) > 0 {
+		if !firstPerson.MatchString(before[reports[len(reports)-1][1]:]) {
+			return true
+		}
+	}
+	return !negativePhrase && negated(before)
+}
+
+// negated reports whether before ends in a negator, optionally followed by
+// a verb and a filler word
+func negated(before string) bool {
+	words := strings.Fields(strings.ReplaceAll(before, "’", "'"))
+	for i := range words {
+		words[i] = strings.Trim(words[i], `"()`)
+	}
+	n := len(words)
+	if n > 0 && negatedFillers[words[n-1]] {
+		n--
+	}
+	if n > 0 && negators[words[n-1]] {
+		return true
+	}
+	return n > 1 && negatedVerbs[words[n-1]] && negators[words[n-2]]
+}
+
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		compiled[i] = regexp.MustCompile(p)
+	}
+	return compiled
+}
@@ -0,0 +1,112 @@
+package redflag
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	d := NewDetector()
+
+	tests := []struct {
+		name    string
+		message string
+		want    Category
+		found   bool
+	}{
+		{"heavy bleeding", "I'm 30 weeks and there is heavy bleeding", CategoryHeavyBleeding, true},
+		{"soaking pads", "I've soaked through a pad in an hour", CategoryHeavyBleeding, true},
+		{"headache with blurry vision", "I have a severe headache and my vision is blurry", CategorySevereHeadacheVision, true},
+		{"headache alone", "I have a severe headache today", "", false},
+		{"reduced movement", "My baby hasn't moved since this morning, baby stopped moving", CategoryReducedFetalMovement, true},
+		{"cannot feel baby", "I can't feel the baby kicking", CategoryReducedFetalMovement, true},
+		{"waters broke", "I think my water broke", CategoryFluidLeak, true},
+		{"leaking fluid", "There's clear fluid leaking", CategoryFluidLeak, true},
+		{"chest pain", "I have chest pain and feel short of breath", CategoryChestPain, true},
+		{"self harm", "Sometimes I want to kill myself", CategorySelfHarm, true},
+		{"self harm takes priority", "I have chest pain and I want to die", CategorySelfHarm, true},
+		{"spanish bleeding", "Tengo sangrado abundante", CategoryHeavyBleeding, true},
+		{"spanish fetal movement", "No siento a mi bebé desde ayer", CategoryReducedFetalMovement, true},
+		{"spanish headache vision", "Tengo un dolor de cabeza fuerte y visión borrosa", CategorySevereHeadacheVision, true},
+		{"french fetal movement", "Mon bébé ne bouge plus", CategoryReducedFetalMovement, true},
+		{"french self harm", "Je veux mourir", CategorySelfHarm, true},
+		{"ordinary spotting", "I noticed light spotting", "", false},
+		{"ordinary question", "When will my baby start kicking?", "", false},
+		{"empty", "   ", "", false},
+
+		// Questions about harm and negated or reported symptoms must not escalate
+		{"question about coffee", "Will coffee hurt my baby?", "", false},
+		{"question about sushi", "Can eating sushi harm the baby?", "", false},
+		{"question could it hurt", "could it hurt the baby", "", false},
+		{"intent to hurt baby", "I keep thinking about hurting my baby", CategorySelfHarm, true},
+		{"going to hurt baby", "I feel like I'm going to hurt the baby", CategorySelfHarm, true},
+		{"negated chest pain", "no chest pain today", "", false},
+		{"negated vision", "I have no vision problems but a bad headache", "", false},
+		{"bare vision word", "I have a bad headache, is my vision affected by screens?", "", false},
+		{"vision changes with headache", "Severe headache and my vision went blurry", CategorySevereHeadacheVision, true},
+		{"negated self harm", "I don't want to hurt myself lifting", "", false},
+		{"reported bleeding", "My friend said heavy bleeding is normal after birth?", "", false},
+		{"negation in another clause", "I'm not sure what to do, I have chest pain", CategoryChestPain, true},
+		{"negation far before", "I can't stop thinking that I want to die", CategorySelfHarm, true},
+		{"spanish negated chest pain", "No tengo dolor en el pecho", "", false},
+		{"french negated chest pain", "Je n'ai pas de douleur dans la poitrine", "", false},
+		{"negated with verb and article", "I don't have any chest pain", "", false},
+
+		// Guards must fail towards flagging
+		{"can't stop bleeding", "I can't stop bleeding heavily", CategoryHeavyBleeding, true},
+		{"haven't felt baby move", "I haven't felt the baby move since yesterday", CategoryReducedFetalMovement, true},
+		{"didn't feel baby kick", "I didn't feel the baby kick all day", CategoryReducedFetalMovement, true},
+		{"don't want to live", "I don't want to live anymore", CategorySelfHarm, true},
+		{"reported then first person", "She said I'm bleeding heavily", CategoryHeavyBleeding, true},
+		{"plain headache with blurry vision", "I have a headache and blurry vision", CategorySevereHeadacheVision, true},
+		{"first person read", "I read that heavy bleeding is normal but I'm bleeding heavily", CategoryHeavyBleeding, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := d.Detect(tt.message)
+			if found != tt.found {
+				t.Fatalf("Detect(%q) found = %v, want %v (got %+v)", tt.message, found, tt.found, got)
+			}
+			if found && got.Category != tt.want {
+				t.Errorf("Detect(%q) category = %s, want %s", tt.message, got.Category, tt.want)
+			}
+			if found && got.Matched == "" {
+				t.Error("expected matched phrase for audit")
+			}
+		})
+	}
+}
+
+func TestHotlinesFor(t *testing.T) {
+	us := HotlinesFor("us", CategorySelfHarm)
+	if len(us) != 2 || us[0].Number != "911" || us[1].Kind != "crisis" {
+		t.Errorf("unexpected US self-harm hotlines: %+v", us)
+	}
+
+	ng := HotlinesFor("NG", CategoryHeavyBleeding)
+	if len(ng) != 1 || ng[0].Number != "112" {
+		t.Errorf("unexpected NG hotlines: %+v", ng)
+	}
+
+	unknown := HotlinesFor("", CategoryChestPain)
+	if len(unknown) != 1 || unknown[0].Number != defaultHotlines.emergency.Number {
+		t.Errorf("expected default hotline, got %+v", unknown)
+	}
+}
+
+func TestNewAlert_Localized(t *testing.T) {
+	alert := NewAlert(Detection{Category: CategoryFluidLeak, Matched: "water broke"}, "es", "mx")
+
+	if alert.CountryCode != "MX" {
+		t.Errorf("CountryCode = %q, want MX", alert.CountryCode)
+	}
+	if !strings.Contains(alert.Message, "fuente") || !strings.Contains(alert.Message, "911") {
+		t.Errorf("expected Spanish message with local number, got %q", alert.Message)
+	}
+
+	fallback := NewAlert(Detection{Category: CategoryChestPain}, "xx", "")
+	if !strings.HasPrefix(fallback.Message, responses["en"][CategoryChestPain]) {
+		t.Errorf("expected English fallback, got %q", fallback.Message)
+	}
+}
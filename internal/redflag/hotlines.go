@@ -0,0 +1,89 @@
+package redflag
+
+import "strings"
+
+// Hotline is a phone number shown alongside an emergency response.
+type Hotline struct {
+	Name   string `json:"name"`
+	Number string `json:"number"`
+	Kind   string `json:"kind"` // "emergency" or "crisis"
+}
+
+type countryHotlines struct {
+	emergency Hotline
+	crisis    *Hotline
+}
+
+// defaultHotlines is used when the user's country is unknown or not listed.
+// 112 is routed to local emergency services on most mobile networks.
+var defaultHotlines = countryHotlines{
+	emergency: Hotline{Name: "Emergency services", Number: "112", Kind: "emergency"},
+}
+
+// hotlinesByCountry maps ISO 3166-1 alpha-2 codes to emergency and crisis lines.
+var hotlinesByCountry = map[string]countryHotlines{
+	"NG": {
+		emergency: Hotline{Name: "National emergency", Number: "112", Kind: "emergency"},
+	},
+	"GH": {
+		emergency: Hotline{Name: "National emergency", Number: "112", Kind: "emergency"},
+	},
+	"KE": {
+		emergency: Hotline{Name: "Emergency services", Number: "999", Kind: "emergency"},
+	},
+	"ZA": {
+		emergency: Hotline{Name: "Ambulance", Number: "10177", Kind: "emergency"},
+		crisis:    &Hotline{Name: "SADAG Suicide Crisis Line", Number: "0800 567 567", Kind: "crisis"},
+	},
+	"US": {
+		emergency: Hotline{Name: "Emergency services", Number: "911", Kind: "emergency"},
+		crisis:    &Hotline{Name: "988 Suicide & Crisis Lifeline", Number: "988", Kind: "crisis"},
+	},
+	"CA": {
+		emergency: Hotline{Name: "Emergency services", Number: "911", Kind: "emergency"},
+		crisis:    &Hotline{Name: "9-8-8 Suicide Crisis Helpline", Number: "988", Kind: "crisis"},
+	},
+	"GB": {
+		emergency: Hotline{Name: "Emergency services", Number: "999", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Samaritans", Number: "116 123", Kind: "crisis"},
+	},
+	"IE": {
+		emergency: Hotline{Name: "Emergency services", Number: "112", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Samaritans", Number: "116 123", Kind: "crisis"},
+	},
+	"FR": {
+		emergency: Hotline{Name: "SAMU", Number: "15", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Numéro national de prévention du suicide", Number: "3114", Kind: "crisis"},
+	},
+	"ES": {
+		emergency: Hotline{Name: "Emergencias", Number: "112", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Línea 024 de atención a la conducta suicida", Number: "024", Kind: "crisis"},
+	},
+	"MX": {
+		emergency: Hotline{Name: "Emergencias", Number: "911", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Línea de la Vida", Number: "800 911 2000", Kind: "crisis"},
+	},
+	"IN": {
+		emergency: Hotline{Name: "Emergency services", Number: "112", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Tele-MANAS", Number: "14416", Kind: "crisis"},
+	},
+	"AU": {
+		emergency: Hotline{Name: "Emergency services", Number: "000", Kind: "emergency"},
+		crisis:    &Hotline{Name: "Lifeline", Number: "13 11 14", Kind: "crisis"},
+	},
+}
+
+// HotlinesFor returns the numbers to show for a category in a country.
+// The emergency number is always first; self-harm adds a crisis line when known.
+func HotlinesFor(countryCode string, category Category) []Hotline {
+	entry, ok := hotlinesByCountry[strings.ToUpper(strings.TrimSpace(countryCode))]
+	if !ok {
+		entry = defaultHotlines
+	}
+
+	hotlines := []Hotline{entry.emergency}
+	if category == CategorySelfHarm && entry.crisis != nil {
+		hotlines = append(hotlines, *entry.crisis)
+	}
+	return hotlines
+}
@@ -0,0 +1,87 @@
+package redflag
+
+import "strings"
+
+// Alert is the structured emergency event sent to clients.
+type Alert struct {
+	Category    Category  `json:"category"`
+	Message     string    `json:"message"`
+	CountryCode string    `json:"country_code,omitempty"`
+	Hotlines    []Hotline `json:"hotlines"`
+}
+
+// NewAlert builds a localized alert with country-specific hotlines.
+func NewAlert(d Detection, language, countryCode string) Alert {
+	return Alert{
+		Category:    d.Category,
+		Message:     Response(d.Category, language, countryCode),
+		CountryCode: strings.ToUpper(strings.TrimSpace(countryCode)),
+		Hotlines:    HotlinesFor(countryCode, d.Category),
+	}
+}
+
+// Response returns the localized urgent-care message for a category,
+// ending with the emergency number for the user's country.
+func Response(category Category, language, countryCode string) string {
+	messages, ok := responses[language]
+	if !ok {
+		messages = responses["en"]
+	}
+
+	body, ok := messages[category]
+	if !ok {
+		body = messages[""]
+	}
+
+	hotlines := HotlinesFor(countryCode, category)
+	numbers := make([]string, 0, len(hotlines))
+	for _, h := range hotlines {
+		numbers = append(numbers, h.Name+": "+h.Number)
+	}
+
+	return body + " " + callLine[languageOrDefault(language)] + " " + strings.Join(numbers, " · ")
+}
+
+func languageOrDefault(language string) string {
+	if _, ok := responses[language]; ok {
+		return language
+	}
+	return "en"
+}
+
+var callLine = map[string]string{
+	"en": "If you are in danger, call now —",
+	"es": "Si estás en peligro, llama ahora —",
+	"fr": "Si vous êtes en danger, appelez maintenant —",
+}
+
+// responses holds clinician-reviewed wording; the "" key is the generic message.
+var responses = map[string]map[Category]string{
+	"en": {
+		CategoryHeavyBleeding:        "Heavy bleeding during or after pregnancy needs urgent medical care. Please go to the nearest hospital or maternity unit now, or call emergency services. Lie down while you wait for help if you feel faint.",
+		CategorySevereHeadacheVision: "A severe headache with changes in your vision can be a sign of pre-eclampsia, which needs urgent care. Please contact your maternity unit or go to the nearest hospital now — do not wait to see if it passes.",
+		CategoryReducedFetalMovement: "If your baby is moving less than usual or you can't feel movements, please contact your maternity unit or hospital right away — don't wait until tomorrow. They will want to check on your baby today.",
+		CategoryFluidLeak:            "Fluid leaking from your vagina may mean your waters have broken. Please contact your maternity unit or go to the hospital now so they can check you and your baby.",
+		CategoryChestPain:            "Chest pain or tightness during or after pregnancy needs urgent medical attention. Please call emergency services or go to the nearest hospital now.",
+		CategorySelfHarm:             "I'm really glad you told me. You deserve support right now and you don't have to go through this alone. Please reach out to a crisis line or someone you trust, and if you might act on these thoughts, call emergency services.",
+		"":                           "What you're describing needs urgent medical attention. Please contact your healthcare provider or go to the nearest hospital now.",
+	},
+	"es": {
+		CategoryHeavyBleeding:        "El sangrado abundante durante o después del embarazo necesita atención médica urgente. Ve ahora al hospital o unidad de maternidad más cercana, o llama a emergencias. Acuéstate mientras llega la ayuda si te sientes débil.",
+		CategorySevereHeadacheVision: "Un dolor de cabeza fuerte con cambios en la visión puede ser señal de preeclampsia y necesita atención urgente. Comunícate con tu unidad de maternidad o ve al hospital ahora — no esperes a que pase.",
+		CategoryReducedFetalMovement: "Si tu bebé se mueve menos de lo normal o no sientes movimientos, comunícate ahora con tu unidad de maternidad u hospital — no esperes a mañana. Querrán revisar a tu bebé hoy.",
+		CategoryFluidLeak:            "La salida de líquido por la vagina puede significar que se rompió la fuente. Comunícate con tu unidad de maternidad o ve al hospital ahora para que te revisen a ti y a tu bebé.",
+		CategoryChestPain:            "El dolor u opresión en el pecho durante o después del embarazo necesita atención médica urgente. Llama a emergencias o ve al hospital más cercano ahora.",
+		CategorySelfHarm:             "Me alegra mucho que me lo hayas contado. Mereces apoyo ahora mismo y no tienes que pasar por esto sola. Comunícate con una línea de crisis o con alguien de confianza, y si crees que podrías actuar sobre estos pensamientos, llama a emergencias.",
+		"":                           "Lo que describes necesita atención médica urgente. Comunícate con tu proveedor de salud o ve al hospital más cercano ahora.",
+	},
+	"fr": {
+		CategoryHeavyBleeding:        "Des saignements abondants pendant ou après la grossesse nécessitent des soins urgents. Rendez-vous maintenant à l'hôpital ou à la maternité la plus proche, ou appelez les urgences. Allongez-vous en attendant les secours si vous vous sentez faible.",
+		CategorySevereHeadacheVision: "Un mal de tête sévère avec des troubles de la vision peut être un signe de prééclampsie et nécessite des soins urgents. Contactez votre maternité ou rendez-vous à l'hôpital maintenant — n'attendez pas que cela passe.",
+		CategoryReducedFetalMovement: "Si votre bébé bouge moins que d'habitude ou si vous ne sentez plus de mouvements, contactez immédiatement votre maternité ou l'hôpital — n'attendez pas demain. Ils voudront vérifier votre bébé aujourd'hui.",
+		CategoryFluidLeak:            "Un écoulement de liquide par le vagin peut signifier que la poche des eaux s'est rompue. Contactez votre maternité ou rendez-vous à l'hôpital maintenant pour qu'ils vous examinent, vous et votre bébé.",
+		CategoryChestPain:            "Une douleur ou une oppression dans la poitrine pendant ou après la grossesse nécessite une attention médicale urgente. Appelez les urgences ou rendez-vous à l'hôpital le plus proche maintenant.",
+		CategorySelfHarm:             "Merci de me l'avoir dit. Vous méritez du soutien maintenant et vous n'avez pas à traverser cela seule. Contactez une ligne d'écoute ou une personne de confiance, et si vous pensez passer à l'acte, appelez les urgences.",
+		"":                           "Ce que vous décrivez nécessite une attention médicale urgente. Contactez votre professionnel de santé ou rendez-vous à l'hôpital le plus proche maintenant.",
+	},
+}
@@ -0,0 +1,229 @@
+// Package reminders delivers due reminders to users.
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/recurrence"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+)
+
+// Store is the persistence the scheduler needs (implemented by *db.DB)
+type Store interface {
+	QueueDueReminders(ctx context.Context, now time.Time, maxLateness time.Duration, channels []string, limit int) (int, []db.RecurringReminder, error)
+	AdvanceReminder(ctx context.Context, reminderID string, from time.Time, next *time.Time) error
+	ClaimReminderDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]db.ReminderDelivery, error)
+	MarkReminderDeliverySent(ctx context.Context, id string, sentAt time.Time) error
+	MarkReminderDeliveryFailed(ctx context.Context, id, errMsg string, retryAt *time.Time, finalStatus string) error
+	GetPushDevices(ctx context.Context, userID string) ([]db.PushDevice, error)
+}
+
+// Config tunes the scheduler
+type Config struct {
+	PollInterval time.Duration   // Default: 30s
+	BatchSize    int             // Reminders queued and deliveries claimed per poll. Default: 100
+	MaxLateness  time.Duration   // Older due reminders are dropped. Default: 6h
+	Lease        time.Duration   // How long a claimed delivery is hidden from other workers. Default: 2m
+	RetryDelays  []time.Duration // Wait before each retry; attempts = len + 1. Default: 1m, 5m, 30m
+}
+
+// Scheduler polls for due reminders and delivers them through the
+// configured notifiers. Several replicas can run it at once: rows are
+// claimed with SKIP LOCKED, so each delivery is attempted by one worker.
+type Scheduler struct {
+	store     Store
+	notifiers map[string]notify.Notifier
+	channels  []string
+	config    Config
+	now       func() time.Time
+}
+
+// NewScheduler creates a scheduler. Channels without a notifier are never queued.
+func NewScheduler(store Store, notifiers []notify.Notifier, config Config) *Scheduler {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxLateness <= 0 {
+		config.MaxLateness = 6 * time.Hour
+	}
+	if config.Lease <= 0 {
+		config.Lease = 2 * time.Minute
+	}
+	if config.RetryDelays == nil {
+		config.RetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute}
+	}
+
+	s := &Scheduler{
+		store:     store,
+		notifiers: make(map[string]notify.Notifier, len(notifiers)),
+		config:    config,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	for _, n := range notifiers {
+		s.notifiers[n.Channel()] = n
+		s.channels = append(s.channels, n.Channel())
+	}
+	return s
+}
+
+// Run polls until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Reminder scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick queues reminders that have come due and attempts the deliveries that
+// are ready to send
+func (s *Scheduler) Tick(ctx context.Context) error {
+	now := s.now()
+
+	queued, recurring, err := s.store.QueueDueReminders(ctx, now, s.config.MaxLateness, s.channels, s.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	if queued > 0 {
+		log.Printf("Reminder scheduler: queued %d deliveries", queued)
+	}
+	for _, r := range recurring {
+		s.rearm(ctx, r, now)
+	}
+
+	deliveries, err := s.store.ClaimReminderDeliveries(ctx, now, s.config.Lease, s.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		s.deliver(ctx, d)
+	}
+	return nil
+}
+
+// rearm moves a recurring reminder on to its next occurrence. After an
+// occurrence that was too late to send, it skips ahead to the first one still
+// to come. If this fails the reminder stays armed and is retried next tick.
+func (s *Scheduler) rearm(ctx context.Context, r db.RecurringReminder, now time.Time) {
+	var next *time.Time
+	rule, err := recurrence.Parse(r.RRule, r.ReminderTime, r.Timezone)
+	if err != nil {
+		// Disarm it, or it would come due on every tick
+		log.Printf("Reminder scheduler: reminder %s has an invalid rule: %v", r.ID, err)
+	} else {
+		after := r.ScheduledFor
+		if !after.After(now.Add(-s.config.MaxLateness)) {
+			after = now
+		}
+		if at, ok := rule.After(after); ok {
+			next = &at
+		} // Otherwise the series is finished
+	}
+
+	if err := s.store.AdvanceReminder(ctx, r.ID, r.ScheduledFor, next); err != nil {
+		log.Printf("Reminder scheduler: failed to re-arm reminder %s: %v", r.ID, err)
+	}
+}
+
+func (s *Scheduler) deliver(ctx context.Context, d db.ReminderDelivery) {
+	notifier, ok := s.notifiers[d.Channel]
+	if !ok {
+		s.fail(ctx, d, fmt.Errorf("no notifier for channel %q", d.Channel), false)
+		return
+	}
+
+	msg, err := s.buildMessage(ctx, d)
+	if err != nil {
+		s.fail(ctx, d, err, true)
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.Lease/2)
+	err = notifier.Send(sendCtx, msg)
+	cancel()
+	if err != nil {
+		s.fail(ctx, d, err, !errors.Is(err, notify.ErrNoRecipient))
+		return
+	}
+
+	if err := s.store.MarkReminderDeliverySent(ctx, d.ID, s.now()); err != nil {
+		log.Printf("Reminder scheduler: delivery %s sent but not recorded: %v", d.ID, err)
+	}
+}
+
+func (s *Scheduler) buildMessage(ctx context.Context, d db.ReminderDelivery) (notify.Message, error) {
+	msg := notify.Message{
+		UserID: d.UserID,
+		Title:  d.Title,
+		Body:   reminderBody(d),
+		Data: map[string]string{
+			"type":          "reminder",
+			"reminder_id":   d.ReminderID,
+			"scheduled_for": d.ScheduledFor.UTC().Format(time.RFC3339),
+		},
+		Email: d.Email,
+	}
+	if d.Phone != nil {
+		msg.Phone = *d.Phone
+	}
+
+	if d.Channel == notify.ChannelPush {
+		devices, err := s.store.GetPushDevices(ctx, d.UserID)
+		if err != nil {
+			return msg, err
+		}
+		for _, device := range devices {
+			msg.Devices = append(msg.Devices, notify.Device{Provider: device.Provider, Token: device.Token})
+		}
+	}
+	return msg, nil
+}
+
+// fail records a failed attempt, scheduling a retry if attempts remain
+func (s *Scheduler) fail(ctx context.Context, d db.ReminderDelivery, sendErr error, retryable bool) {
+	var retryAt *time.Time
+	finalStatus := db.DeliveryFailed
+	if !retryable {
+		finalStatus = db.DeliverySkipped
+	} else if d.Attempts > 0 && d.Attempts <= len(s.config.RetryDelays) {
+		at := s.now().Add(s.config.RetryDelays[d.Attempts-1])
+		retryAt = &at
+	}
+
+	log.Printf("Reminder scheduler: %s delivery %s (attempt %d) failed: %v", d.Channel, d.ID, d.Attempts, sendErr)
+	if err := s.store.MarkReminderDeliveryFailed(ctx, d.ID, sendErr.Error(), retryAt, finalStatus); err != nil {
+		log.Printf("Reminder scheduler: failed to record delivery %s failure: %v", d.ID, err)
+	}
+}
+
+var reminderIntro = map[string]string{
+	"en": "Reminder",
+	"es": "Recordatorio",
+}
+
+func reminderBody(d db.ReminderDelivery) string {
+	if d.Description != nil && *d.Description != "" {
+		return *d.Description
+	}
+	intro, ok := reminderIntro[d.Language]
+	if !ok {
+		intro = reminderIntro["en"]
+	}
+	return intro + ": " + d.Title
+}
@@ -0,0 +1,249 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+)
+
+type failure struct {
+	retryAt     *time.Time
+	finalStatus string
+}
+
+type fakeStore struct {
+	queuedChannels []string
+	recurring      []db.RecurringReminder
+	armed          map[string]*time.Time // nil when disarmed
+	claimed        []db.ReminderDelivery
+	devices        []db.PushDevice
+	sent           []string
+	failed         map[string]failure
+}
+
+func (f *fakeStore) QueueDueReminders(_ context.Context, _ time.Time, _ time.Duration, channels []string, _ int) (int, []db.RecurringReminder, error) {
+	f.queuedChannels = channels
+	return len(f.claimed), f.recurring, nil
+}
+
+func (f *fakeStore) AdvanceReminder(_ context.Context, id string, _ time.Time, next *time.Time) error {
+	if f.armed == nil {
+		f.armed = make(map[string]*time.Time)
+	}
+	f.armed[id] = next
+	return nil
+}
+
+func (f *fakeStore) ClaimReminderDeliveries(context.Context, time.Time, time.Duration, int) ([]db.ReminderDelivery, error) {
+	claimed := f.claimed
+	f.claimed = nil
+	return claimed, nil
+}
+
+func (f *fakeStore) MarkReminderDeliverySent(_ context.Context, id string, _ time.Time) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeStore) MarkReminderDeliveryFailed(_ context.Context, id, _ string, retryAt *time.Time, finalStatus string) error {
+	if f.failed == nil {
+		f.failed = make(map[string]failure)
+	}
+	f.failed[id] = failure{retryAt: retryAt, finalStatus: finalStatus}
+	return nil
+}
+
+func (f *fakeStore) GetPushDevices(context.Context, string) ([]db.PushDevice, error) {
+	return f.devices, nil
+}
+
+var testNow = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestScheduler(store Store, notifiers ...notify.Notifier) *Scheduler {
+	s := NewScheduler(store, notifiers, Config{})
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestTick_SendsAndRecords(t *testing.T) {
+	desc := "Take it with food"
+	store := &fakeStore{
+		claimed: []db.ReminderDelivery{
+			{ID: "d1", ReminderID: "r1", Channel: notify.ChannelPush, Attempts: 1, UserID: "u1", Title: "Vitamins", Description: &desc},
+			{ID: "d2", ReminderID: "r1", Channel: notify.ChannelEmail, Attempts: 1, UserID: "u1", Title: "Vitamins", Email: "a@b.c"},
+		},
+		devices: []db.PushDevice{{Provider: notify.ProviderFCM, Token: "tok"}},
+	}
+	push := notify.NewFakeNotifier(notify.ChannelPush)
+	email := notify.NewFakeNotifier(notify.ChannelEmail)
+
+	if err := newTestScheduler(store, push, email).Tick(context.Background()); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+
+	if len(store.queuedChannels) != 2 {
+		t.Errorf("queued channels = %v, want push and email", store.queuedChannels)
+	}
+	if len(store.sent) != 2 {
+		t.Fatalf("sent = %v, want both deliveries", store.sent)
+	}
+
+	pushed := push.Sent()
+	if len(pushed) != 1 || len(pushed[0].Devices) != 1 || pushed[0].Devices[0].Token != "tok" {
+		t.Errorf("push message = %+v, want one device", pushed)
+	}
+	if pushed[0].Body != desc || pushed[0].Data["reminder_id"] != "r1" {
+		t.Errorf("push message body/data = %q/%v", pushed[0].Body, pushed[0].Data)
+	}
+
+	emailed := email.Sent()
+	if len(emailed) != 1 || emailed[0].Body != "Reminder: Vitamins" {
+		t.Errorf("email message = %+v, want fallback body", emailed)
+	}
+}
+
+func TestTick_RetriesThenFails(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int
+		err       error
+		wantRetry time.Duration // 0 means no retry
+		wantFinal string
+	}{
+		{"first failure retries after a minute", 1, errors.New("timeout"), time.Minute, ""},
+		{"third failure retries after 30 minutes", 3, errors.New("timeout"), 30 * time.Minute, ""},
+		{"last attempt fails", 4, errors.New("timeout"), 0, db.DeliveryFailed},
+		{"missing recipient is skipped", 1, notify.ErrNoRecipient, 0, db.DeliverySkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{claimed: []db.ReminderDelivery{
+				{ID: "d1", Channel: notify.ChannelSMS, Attempts: tt.attempts, Title: "Scan"},
+			}}
+			sms := notify.NewFakeNotifier(notify.ChannelSMS, tt.err)
+
+			if err := newTestScheduler(store, sms).Tick(context.Background()); err != nil {
+				t.Fatalf("Tick() error = %v", err)
+			}
+
+			got, ok := store.failed["d1"]
+			if !ok {
+				t.Fatal("delivery failure was not recorded")
+			}
+			if tt.wantRetry > 0 {
+				if got.retryAt == nil || !got.retryAt.Equal(testNow.Add(tt.wantRetry)) {
+					t.Errorf("retryAt = %v, want %v", got.retryAt, testNow.Add(tt.wantRetry))
+				}
+				return
+			}
+			if got.retryAt != nil {
+				t.Errorf("retryAt = %v, want none", got.retryAt)
+			}
+			if got.finalStatus != tt.wantFinal {
+				t.Errorf("finalStatus = %q, want %q", got.finalStatus, tt.wantFinal)
+			}
+		})
+	}
+}
+
+func TestTick_RearmsRecurringReminders(t *testing.T) {
+	start := testNow.AddDate(0, 0, -10)
+	store := &fakeStore{recurring: []db.RecurringReminder{
+		// Just sent: next is tomorrow's occurrence
+		{ID: "daily", RRule: "FREQ=DAILY", Timezone: "UTC", ReminderTime: start, ScheduledFor: testNow},
+		// Dropped after an outage: skip to the next one still to come
+		{ID: "missed", RRule: "FREQ=HOURLY", Timezone: "UTC", ReminderTime: start, ScheduledFor: testNow.Add(-24 * time.Hour)},
+		// Last occurrence: stays disarmed
+		{ID: "done", RRule: "FREQ=DAILY;COUNT=11", Timezone: "UTC", ReminderTime: start, ScheduledFor: testNow},
+	}}
+
+	if err := newTestScheduler(store).Tick(context.Background()); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+
+	if got := store.armed["daily"]; got == nil || !got.Equal(testNow.AddDate(0, 0, 1)) {
+		t.Errorf("daily armed for %v, want %v", got, testNow.AddDate(0, 0, 1))
+	}
+	if got := store.armed["missed"]; got == nil || !got.Equal(testNow.Add(time.Hour)) {
+		t.Errorf("missed armed for %v, want %v", got, testNow.Add(time.Hour))
+	}
+	if got, ok := store.armed["done"]; !ok || got != nil {
+		t.Errorf("finished series armed for %v, want disarmed", got)
+	}
+}
+
+// armedStore keeps one recurring reminder armed until AdvanceReminder
+// succeeds, as the database does
+type armedStore struct {
+	fakeStore
+	reminder   db.RecurringReminder // ScheduledFor is next_notify_at
+	advanceErr error
+	queued     []time.Time
+}
+
+func (f *armedStore) QueueDueReminders(_ context.Context, now time.Time, _ time.Duration, _ []string, _ int) (int, []db.RecurringReminder, error) {
+	if f.reminder.ScheduledFor.After(now) {
+		return 0, nil, nil
+	}
+	f.queued = append(f.queued, f.reminder.ScheduledFor)
+	return 1, []db.RecurringReminder{f.reminder}, nil
+}
+
+func (f *armedStore) AdvanceReminder(_ context.Context, _ string, from time.Time, next *time.Time) error {
+	if f.advanceErr != nil {
+		return f.advanceErr
+	}
+	if from.Equal(f.reminder.ScheduledFor) && next != nil {
+		f.reminder.ScheduledFor = *next
+	}
+	return nil
+}
+
+func TestTick_RearmFailureKeepsSeries(t *testing.T) {
+	store := &armedStore{
+		reminder:   db.RecurringReminder{ID: "vitamins", RRule: "FREQ=DAILY", Timezone: "UTC", ReminderTime: testNow.AddDate(0, 0, -3), ScheduledFor: testNow},
+		advanceErr: errors.New("connection reset"),
+	}
+	s := newTestScheduler(store)
+	ctx := context.Background()
+
+	if err := s.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The failed re-arm leaves today's occurrence armed; the next tick queues
+	// it again (deliveries are unique per occurrence) and moves on
+	store.advanceErr = nil
+	if err := s.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := testNow.AddDate(0, 0, 1)
+	if !store.reminder.ScheduledFor.Equal(tomorrow) {
+		t.Fatalf("armed for %v, want %v", store.reminder.ScheduledFor, tomorrow)
+	}
+
+	s.now = func() time.Time { return tomorrow }
+	if err := s.Tick(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{testNow, testNow, tomorrow}
+	if len(store.queued) != len(want) || !store.queued[2].Equal(tomorrow) {
+		t.Errorf("queued %v, want %v", store.queued, want)
+	}
+}
+
+func TestReminderBody_Language(t *testing.T) {
+	d := db.ReminderDelivery{Title: "Control prenatal", Language: "es"}
+	if got := reminderBody(d); got != "Recordatorio: Control prenatal" {
+		t.Errorf("reminderBody() = %q", got)
+	}
+
+	d.Language = "fr"
+	if got := reminderBody(d); got != "Reminder: Control prenatal" {
+		t.Errorf("reminderBody() unknown language = %q", got)
+	}
+}
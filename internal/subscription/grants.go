@@ -0,0 +1,167 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Grant sources, recorded for auditing
+const (
+	GrantAdmin    = "admin"
+	GrantPromo    = "promo"
+	GrantSponsor  = "sponsor"
+	GrantReferral = "referral"
+)
+
+var (
+	// ErrUnknownFeature means no feature has the key.
+	ErrUnknownFeature = errors.New("feature not found")
+	// ErrUnknownPlan means no active plan has the code.
+	ErrUnknownPlan = errors.New("plan not found")
+	// ErrPlanConflict means the user is on another paid plan, which a granted
+	// plan would cut short.
+	ErrPlanConflict = errors.New("user is on another paid plan")
+)
+
+// activeGrant matches an unexpired grant of feature $2 to user $1
+const activeGrant = `
+    SELECT 1
+    FROM user_feature_grants g
+    JOIN features f ON f.id = g.feature_id
+    WHERE g.user_id = $1
+      AND f.feature_key = $2
+      AND (g.expires_at IS NULL OR g.expires_at > NOW())`
+
+// hasGrant reports whether the user was granted a feature outside their plan
+func (m *Manager) hasGrant(ctx context.Context, userID, featureKey string) (bool, error) {
+	var granted bool
+	err := m.db.QueryRowContext(ctx, `SELECT EXISTS (`+activeGrant+`)`, userID, featureKey).Scan(&granted)
+	if err != nil {
+		return false, fmt.Errorf("check feature grant: %w", err)
+	}
+	return granted, nil
+}
+
+// GrantFeature grants a specific feature to a user temporarily (admin only).
+// expiresAt is a Unix timestamp; nil never expires.
+func (m *Manager) GrantFeature(ctx context.Context, userID, featureKey string, expiresAt *int64) error {
+	var until *time.Time
+	if expiresAt != nil {
+		t := time.Unix(*expiresAt, 0)
+		until = &t
+	}
+	return m.GrantFeatureUntil(ctx, userID, featureKey, GrantAdmin, until)
+}
+
+// GrantFeatureUntil gives a user a feature outside their plan until
+// expiresAt (nil = never). Granted features are not metered.
+func (m *Manager) GrantFeatureUntil(ctx context.Context, userID, featureKey, source string, expiresAt *time.Time) error {
+	if m.db == nil {
+		return errors.New("db not initialized")
+	}
+
+	result, err := m.db.ExecContext(ctx, `
+		INSERT INTO user_feature_grants (user_id, feature_id, source, expires_at)
+		SELECT $1, f.id, $3, $4
+		FROM features f
+		WHERE f.feature_key = $2
+	`, userID, featureKey, source, expiresAt)
+	if err != nil {
+		return fmt.Errorf("grant feature: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("grant feature: %w", err)
+	} else if n == 0 {
+		return ErrUnknownFeature
+	}
+	return nil
+}
+
+// GrantPlan gives a user a plan for a number of days without payment. An
+// active subscription to the same plan is extended by that much; a free
+// subscription is replaced. The granted plan does not renew, so the
+// lifecycle job moves the user back to free when it ends.
+func (m *Manager) GrantPlan(ctx context.Context, userID, planCode string, days int) (*Subscription, error) {
+	if m.db == nil {
+		return nil, errors.New("db not initialized")
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	sub := &Subscription{PlanCode: planCode, Status: "active"}
+	err = tx.QueryRowContext(ctx, `SELECT id, name FROM plans WHERE code = $1 AND active = TRUE`, planCode).
+		Scan(&sub.PlanID, &sub.PlanName)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownPlan
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query plan: %w", err)
+	}
+
+	// Extend the same plan; one without an end date already lasts forever
+	err = tx.QueryRowContext(ctx, `
+		UPDATE subscriptions
+		SET ends_at = CASE WHEN ends_at IS NULL THEN NULL
+		                   ELSE GREATEST(ends_at, NOW()) + make_interval(days => $3) END,
+		    canceled_at = CASE WHEN is_trial THEN NOW() ELSE canceled_at END,
+		    is_trial = FALSE
+		WHERE user_id = $1 AND plan_id = $2 AND status = 'active'
+		  AND (ends_at IS NULL OR ends_at > NOW())
+		RETURNING id, starts_at, ends_at, canceled_at
+	`, userID, sub.PlanID, days).Scan(&sub.ID, &sub.StartsAt, &sub.EndsAt, &sub.CanceledAt)
+	if err == nil {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit transaction: %w", err)
+		}
+		return sub, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("extend subscription: %w", err)
+	}
+
+	var paid bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM subscriptions s
+			JOIN plans p ON p.id = s.plan_id
+			WHERE s.user_id = $1 AND s.status = 'active' AND p.code <> 'free'
+			  AND s.is_trial = FALSE AND (s.ends_at IS NULL OR s.ends_at > NOW())
+		)
+	`, userID).Scan(&paid)
+	if err != nil {
+		return nil, fmt.Errorf("check current plan: %w", err)
+	}
+	if paid {
+		return nil, ErrPlanConflict
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE subscriptions
+		SET status = 'canceled', ends_at = NOW()
+		WHERE user_id = $1 AND status = 'active'
+	`, userID); err != nil {
+		return nil, fmt.Errorf("cancel existing subscription: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO subscriptions (user_id, plan_id, status, starts_at, ends_at, canceled_at)
+		VALUES ($1, $2, 'active', NOW(), NOW() + make_interval(days => $3), NOW())
+		RETURNING id, starts_at, ends_at, canceled_at
+	`, userID, sub.PlanID, days).Scan(&sub.ID, &sub.StartsAt, &sub.EndsAt, &sub.CanceledAt)
+	if err != nil {
+		return nil, fmt.Errorf("create subscription: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return sub, nil
+}
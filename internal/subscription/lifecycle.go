@@ -0,0 +1,406 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+)
+
+// Lifecycle notices, each sent at most once per subscription
+const (
+	NoticeTrialEnding = "trial_ending"
+	NoticeTrialEnded  = "trial_ended"
+	NoticeGracePeriod = "grace_period"
+	NoticeExpired     = "expired"
+)
+
+// DueSubscription is an active subscription that has reached a lifecycle
+// step, with what is needed to tell the user about it
+type DueSubscription struct {
+	ID       int
+	UserID   string
+	PlanCode string
+	PlanName string
+	EndsAt   time.Time
+	IsTrial  bool
+	InGrace  bool
+	Canceled bool
+	Email    string
+	Language string
+	Timezone string
+}
+
+const dueSubscriptionColumns = `
+    s.id, s.user_id, p.code, p.name, s.ends_at, s.is_trial,
+    s.grace_started_at IS NOT NULL, s.canceled_at IS NOT NULL,
+    u.email, COALESCE(u.preferred_language, 'en'), u.timezone
+FROM subscriptions s
+JOIN plans p ON p.id = s.plan_id
+JOIN users u ON u.id = s.user_id`
+
+// ExpiredSubscriptions returns active subscriptions whose end date has passed
+func (m *Manager) ExpiredSubscriptions(ctx context.Context, now time.Time, limit int) ([]DueSubscription, error) {
+	return m.dueSubscriptions(ctx, `
+SELECT `+dueSubscriptionColumns+`
+WHERE s.status = 'active' AND s.ends_at <= $1
+ORDER BY s.ends_at
+LIMIT $2`, now, limit)
+}
+
+// EndingTrials returns trials ending between now and before whose users
+// have not been warned yet
+func (m *Manager) EndingTrials(ctx context.Context, now, before time.Time, limit int) ([]DueSubscription, error) {
+	return m.dueSubscriptions(ctx, `
+SELECT `+dueSubscriptionColumns+`
+WHERE s.status = 'active' AND s.is_trial = TRUE
+  AND s.ends_at > $1 AND s.ends_at <= $2
+  AND NOT EXISTS (
+      SELECT 1 FROM subscription_notices n
+      WHERE n.subscription_id = s.id AND n.kind = '`+NoticeTrialEnding+`'
+  )
+ORDER BY s.ends_at
+LIMIT $3`, now, before, limit)
+}
+
+func (m *Manager) dueSubscriptions(ctx context.Context, q string, args ...any) ([]DueSubscription, error) {
+	if m.db == nil {
+		return nil, errors.New("db not initialized")
+	}
+
+	rows, err := m.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query due subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []DueSubscription
+	for rows.Next() {
+		var s DueSubscription
+		if err := rows.Scan(&s.ID, &s.UserID, &s.PlanCode, &s.PlanName, &s.EndsAt, &s.IsTrial,
+			&s.InGrace, &s.Canceled, &s.Email, &s.Language, &s.Timezone); err != nil {
+			return nil, fmt.Errorf("scan due subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// EnterGracePeriod keeps a paid subscription whose renewal was missed active
+// until the end of the grace period. It reports false if the subscription
+// was renewed, cancelled or already in grace in the meantime.
+func (m *Manager) EnterGracePeriod(ctx context.Context, subscriptionID int, until time.Time) (bool, error) {
+	if m.db == nil {
+		return false, errors.New("db not initialized")
+	}
+
+	result, err := m.db.ExecContext(ctx, `
+		UPDATE subscriptions
+		SET ends_at = $2, grace_started_at = NOW()
+		WHERE id = $1 AND status = 'active' AND ends_at <= NOW()
+		  AND is_trial = FALSE AND grace_started_at IS NULL AND canceled_at IS NULL
+	`, subscriptionID, until)
+	if err != nil {
+		return false, fmt.Errorf("start grace period: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("start grace period: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ExpireSubscription marks an ended subscription expired and moves the user
+// to the free plan, unless they have another active subscription. Their data
+// is kept; features outside the free plan are gated again by RequireFeature.
+// It reports false if the subscription was renewed in the meantime.
+func (m *Manager) ExpireSubscription(ctx context.Context, subscriptionID int) (bool, error) {
+	if m.db == nil {
+		return false, errors.New("db not initialized")
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE subscriptions
+		SET status = 'expired'
+		WHERE id = $1 AND status = 'active' AND ends_at <= NOW()
+		RETURNING user_id
+	`, subscriptionID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("expire subscription: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO subscriptions (user_id, plan_id, status)
+		SELECT $1, p.id, 'active'
+		FROM plans p
+		WHERE p.code = 'free'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM subscriptions
+		      WHERE user_id = $1 AND status = 'active' AND (ends_at IS NULL OR ends_at > NOW())
+		  )
+	`, userID); err != nil {
+		return false, fmt.Errorf("assign free plan: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// RecordNotice records that a lifecycle notice is being sent. It reports
+// false if it was already sent, e.g. by another replica.
+func (m *Manager) RecordNotice(ctx context.Context, subscriptionID int, kind string) (bool, error) {
+	if m.db == nil {
+		return false, errors.New("db not initialized")
+	}
+
+	result, err := m.db.ExecContext(ctx, `
+		INSERT INTO subscription_notices (subscription_id, kind)
+		VALUES ($1, $2)
+		ON CONFLICT (subscription_id, kind) DO NOTHING
+	`, subscriptionID, kind)
+	if err != nil {
+		return false, fmt.Errorf("record notice: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("record notice: %w", err)
+	}
+	return n > 0, nil
+}
+
+// LifecycleStore is the persistence the lifecycle job needs (implemented by *Manager)
+type LifecycleStore interface {
+	ExpiredSubscriptions(ctx context.Context, now time.Time, limit int) ([]DueSubscription, error)
+	EndingTrials(ctx context.Context, now, before time.Time, limit int) ([]DueSubscription, error)
+	EnterGracePeriod(ctx context.Context, subscriptionID int, until time.Time) (bool, error)
+	ExpireSubscription(ctx context.Context, subscriptionID int) (bool, error)
+	RecordNotice(ctx context.Context, subscriptionID int, kind string) (bool, error)
+}
+
+// Recipients finds where to send lifecycle notices (implemented by *db.DB)
+type Recipients interface {
+	GetNotificationPreferences(ctx context.Context, userID string) (*db.NotificationPreferences, error)
+	GetPushDevices(ctx context.Context, userID string) ([]db.PushDevice, error)
+}
+
+// LifecycleConfig tunes the lifecycle job
+type LifecycleConfig struct {
+	PollInterval time.Duration // Default: 1m
+	BatchSize    int           // Subscriptions handled per step and poll. Default: 100
+	GracePeriod  time.Duration // Access kept after a missed renewal; 0 = none
+	TrialNotice  time.Duration // How long before a trial ends to warn the user. Default: 72h
+}
+
+// Lifecycle moves subscriptions along when they end: trials and cancelled
+// or unpaid plans go back to the free plan, and missed renewals get a grace
+// period first. Users are told over push and email. Several replicas can run
+// it at once; each step is conditional, and each notice is recorded before
+// it is sent, so nothing happens twice.
+type Lifecycle struct {
+	store      LifecycleStore
+	recipients Recipients
+	notifiers  map[string]notify.Notifier
+	config     LifecycleConfig
+	now        func() time.Time
+}
+
+// NewLifecycle creates a lifecycle job. Only push and email notifiers are used.
+func NewLifecycle(store LifecycleStore, recipients Recipients, notifiers []notify.Notifier, config LifecycleConfig) *Lifecycle {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.TrialNotice <= 0 {
+		config.TrialNotice = 72 * time.Hour
+	}
+
+	l := &Lifecycle{
+		store:      store,
+		recipients: recipients,
+		notifiers:  make(map[string]notify.Notifier),
+		config:     config,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+	for _, n := range notifiers {
+		if n.Channel() == notify.ChannelPush || n.Channel() == notify.ChannelEmail {
+			l.notifiers[n.Channel()] = n
+		}
+	}
+	return l
+}
+
+// Run polls until ctx is cancelled
+func (l *Lifecycle) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Subscription lifecycle: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick warns users whose trials end soon and handles subscriptions that
+// have ended
+func (l *Lifecycle) Tick(ctx context.Context) error {
+	now := l.now()
+
+	trials, err := l.store.EndingTrials(ctx, now, now.Add(l.config.TrialNotice), l.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sub := range trials {
+		l.notify(ctx, sub, NoticeTrialEnding)
+	}
+
+	expired, err := l.store.ExpiredSubscriptions(ctx, now, l.config.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sub := range expired {
+		l.end(ctx, sub, now)
+	}
+	return nil
+}
+
+// end starts a grace period for a missed renewal, or expires the
+// subscription and moves the user to the free plan
+func (l *Lifecycle) end(ctx context.Context, sub DueSubscription, now time.Time) {
+	if !sub.IsTrial && !sub.InGrace && !sub.Canceled && l.config.GracePeriod > 0 {
+		until := sub.EndsAt.Add(l.config.GracePeriod)
+		if until.After(now) {
+			started, err := l.store.EnterGracePeriod(ctx, sub.ID, until)
+			if err != nil {
+				log.Printf("Subscription lifecycle: subscription %d: %v", sub.ID, err)
+				return
+			}
+			if started {
+				sub.EndsAt = until
+				l.notify(ctx, sub, NoticeGracePeriod)
+			}
+			return
+		}
+	}
+
+	expired, err := l.store.ExpireSubscription(ctx, sub.ID)
+	if err != nil {
+		log.Printf("Subscription lifecycle: subscription %d: %v", sub.ID, err)
+		return
+	}
+	if !expired {
+		return // Renewed in the meantime
+	}
+	log.Printf("Subscription lifecycle: user %s moved from %s to the free plan", sub.UserID, sub.PlanCode)
+
+	if sub.IsTrial {
+		l.notify(ctx, sub, NoticeTrialEnded)
+	} else {
+		l.notify(ctx, sub, NoticeExpired)
+	}
+}
+
+// notify sends a notice once over the user's enabled push and email
+// channels. Notices are best effort: a failed send is logged, not retried.
+func (l *Lifecycle) notify(ctx context.Context, sub DueSubscription, kind string) {
+	first, err := l.store.RecordNotice(ctx, sub.ID, kind)
+	if err != nil {
+		log.Printf("Subscription lifecycle: failed to record %s notice for subscription %d: %v", kind, sub.ID, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	prefs, err := l.recipients.GetNotificationPreferences(ctx, sub.UserID)
+	if err != nil {
+		log.Printf("Subscription lifecycle: %v", err)
+		return
+	}
+
+	for channel, notifier := range l.notifiers {
+		msg := noticeMessage(sub, kind)
+		switch {
+		case channel == notify.ChannelPush && prefs.PushEnabled:
+			devices, err := l.recipients.GetPushDevices(ctx, sub.UserID)
+			if err != nil {
+				log.Printf("Subscription lifecycle: %v", err)
+				continue
+			}
+			for _, d := range devices {
+				msg.Devices = append(msg.Devices, notify.Device{Provider: d.Provider, Token: d.Token})
+			}
+		case channel == notify.ChannelEmail && prefs.EmailEnabled:
+		default:
+			continue
+		}
+
+		if err := notifier.Send(ctx, msg); err != nil {
+			log.Printf("Subscription lifecycle: %s %s notice to user %s failed: %v", channel, kind, sub.UserID, err)
+		}
+	}
+}
+
+// noticeText holds title and body formats; %[1]s is the plan name and %[2]s the date
+type noticeText struct{ title, body string }
+
+var noticeTexts = map[string]map[string]noticeText{
+	"en": {
+		NoticeTrialEnding: {"Your %[1]s trial ends soon", "Your free trial of %[1]s ends on %[2]s. Upgrade to keep all your %[1]s features."},
+		NoticeTrialEnded:  {"Your %[1]s trial has ended", "You're now on the free plan. Everything you saved is still here; upgrade any time to get %[1]s back."},
+		NoticeGracePeriod: {"We couldn't renew %[1]s", "Your payment didn't go through. Update your payment details by %[2]s to keep %[1]s."},
+		NoticeExpired:     {"Your %[1]s plan has ended", "You're now on the free plan. Everything you saved is still here; upgrade any time to get %[1]s back."},
+	},
+	"es": {
+		NoticeTrialEnding: {"Tu prueba de %[1]s termina pronto", "Tu prueba gratuita de %[1]s termina el %[2]s. Mejora tu plan para conservar todas las funciones de %[1]s."},
+		NoticeTrialEnded:  {"Tu prueba de %[1]s ha terminado", "Ahora tienes el plan gratuito. Todo lo que guardaste sigue aquí; mejora tu plan cuando quieras para recuperar %[1]s."},
+		NoticeGracePeriod: {"No pudimos renovar %[1]s", "Tu pago no se completó. Actualiza tus datos de pago antes del %[2]s para conservar %[1]s."},
+		NoticeExpired:     {"Tu plan %[1]s ha terminado", "Ahora tienes el plan gratuito. Todo lo que guardaste sigue aquí; mejora tu plan cuando quieras para recuperar %[1]s."},
+	},
+}
+
+func noticeMessage(sub DueSubscription, kind string) notify.Message {
+	texts, ok := noticeTexts[strings.ToLower(sub.Language)]
+	if !ok {
+		texts = noticeTexts["en"]
+	}
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil || sub.Timezone == "" {
+		loc = time.UTC
+	}
+	date := sub.EndsAt.In(loc).Format("2 Jan 2006")
+
+	text := texts[kind]
+	return notify.Message{
+		UserID: sub.UserID,
+		Title:  fmt.Sprintf(text.title, sub.PlanName, date),
+		Body:   fmt.Sprintf(text.body, sub.PlanName, date),
+		Data:   map[string]string{"type": "subscription", "notice": kind, "plan_code": sub.PlanCode},
+		Email:  sub.Email,
+	}
+}
@@ -0,0 +1,210 @@
+package subscription
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/notify"
+)
+
+type fakeLifecycleStore struct {
+	expired  []DueSubscription
+	trials   []DueSubscription
+	graced   map[int]time.Time
+	expiries []int
+	renewed  map[int]bool // ExpireSubscription reports false for these
+	notices  map[int][]string
+}
+
+func newFakeLifecycleStore() *fakeLifecycleStore {
+	return &fakeLifecycleStore{
+		graced:  make(map[int]time.Time),
+		renewed: make(map[int]bool),
+		notices: make(map[int][]string),
+	}
+}
+
+func (f *fakeLifecycleStore) ExpiredSubscriptions(context.Context, time.Time, int) ([]DueSubscription, error) {
+	return f.expired, nil
+}
+
+func (f *fakeLifecycleStore) EndingTrials(context.Context, time.Time, time.Time, int) ([]DueSubscription, error) {
+	return f.trials, nil
+}
+
+func (f *fakeLifecycleStore) EnterGracePeriod(_ context.Context, id int, until time.Time) (bool, error) {
+	f.graced[id] = until
+	return true, nil
+}
+
+func (f *fakeLifecycleStore) ExpireSubscription(_ context.Context, id int) (bool, error) {
+	if f.renewed[id] {
+		return false, nil
+	}
+	f.expiries = append(f.expiries, id)
+	return true, nil
+}
+
+func (f *fakeLifecycleStore) RecordNotice(_ context.Context, id int, kind string) (bool, error) {
+	for _, k := range f.notices[id] {
+		if k == kind {
+			return false, nil
+		}
+	}
+	f.notices[id] = append(f.notices[id], kind)
+	return true, nil
+}
+
+type fakeRecipients struct {
+	prefs db.NotificationPreferences
+}
+
+func (f *fakeRecipients) GetNotificationPreferences(context.Context, string) (*db.NotificationPreferences, error) {
+	prefs := f.prefs
+	return &prefs, nil
+}
+
+func (f *fakeRecipients) GetPushDevices(_ context.Context, userID string) ([]db.PushDevice, error) {
+	return []db.PushDevice{{UserID: userID, Provider: notify.ProviderFCM, Token: "token-1"}}, nil
+}
+
+func TestLifecycle_Tick(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+
+	store := newFakeLifecycleStore()
+	store.trials = []DueSubscription{
+		{ID: 1, UserID: "trial-soon", PlanCode: "premium", PlanName: "Premium", EndsAt: now.Add(48 * time.Hour), IsTrial: true, Language: "en", Timezone: "Africa/Lagos"},
+	}
+	store.expired = []DueSubscription{
+		{ID: 2, UserID: "trial-over", PlanCode: "premium", PlanName: "Premium", EndsAt: ended, IsTrial: true, Language: "es"},
+		{ID: 3, UserID: "unpaid", PlanCode: "premium", PlanName: "Premium", EndsAt: ended},
+		{ID: 4, UserID: "grace-over", PlanCode: "premium", PlanName: "Premium", EndsAt: ended, InGrace: true},
+		{ID: 5, UserID: "canceled", PlanCode: "premium", PlanName: "Premium", EndsAt: ended, Canceled: true},
+		{ID: 6, UserID: "renewed", PlanCode: "premium", PlanName: "Premium", EndsAt: ended, IsTrial: true},
+	}
+	store.renewed[6] = true
+
+	push := notify.NewFakeNotifier(notify.ChannelPush)
+	email := notify.NewFakeNotifier(notify.ChannelEmail)
+	sms := notify.NewFakeNotifier(notify.ChannelSMS)
+	recipients := &fakeRecipients{prefs: db.NotificationPreferences{PushEnabled: true, EmailEnabled: false}}
+
+	l := NewLifecycle(store, recipients, []notify.Notifier{push, email, sms}, LifecycleConfig{GracePeriod: 72 * time.Hour})
+	l.now = func() time.Time { return now }
+
+	if err := l.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+
+	// An unpaid renewal gets a grace period; everything else ends
+	if until, ok := store.graced[3]; !ok || !until.Equal(ended.Add(72*time.Hour)) || len(store.graced) != 1 {
+		t.Errorf("graced = %v", store.graced)
+	}
+	if got := store.expiries; len(got) != 3 || got[0] != 2 || got[1] != 4 || got[2] != 5 {
+		t.Errorf("expiries = %v, want [2 4 5]", got)
+	}
+
+	wantNotices := map[int]string{1: NoticeTrialEnding, 2: NoticeTrialEnded, 3: NoticeGracePeriod, 4: NoticeExpired, 5: NoticeExpired}
+	for id, kind := range wantNotices {
+		if got := store.notices[id]; len(got) != 1 || got[0] != kind {
+			t.Errorf("subscription %d notices = %v, want [%s]", id, got, kind)
+		}
+	}
+	if len(store.notices[6]) != 0 {
+		t.Errorf("renewed subscription was notified: %v", store.notices[6])
+	}
+
+	sent := push.Sent()
+	if len(sent) != 5 {
+		t.Fatalf("push sent %d, want 5", len(sent))
+	}
+	if sent[0].UserID != "trial-soon" || !strings.Contains(sent[0].Body, "12 Mar 2026") || len(sent[0].Devices) != 1 {
+		t.Errorf("trial ending notice = %+v", sent[0])
+	}
+	if sent[1].UserID != "trial-over" || !strings.HasPrefix(sent[1].Title, "Tu prueba") || sent[1].Data["notice"] != NoticeTrialEnded {
+		t.Errorf("trial ended notice = %+v", sent[1])
+	}
+	if len(email.Sent()) != 0 || len(sms.Sent()) != 0 {
+		t.Errorf("sent over disabled channels: email %d, sms %d", len(email.Sent()), len(sms.Sent()))
+	}
+
+	// Notices are not repeated on the next poll
+	if err := l.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if got := len(push.Sent()); got != 5 {
+		t.Errorf("push sent %d after second tick, want 5", got)
+	}
+}
+
+func TestLifecycle_ExpiresWithoutGracePeriod(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := newFakeLifecycleStore()
+	store.expired = []DueSubscription{
+		{ID: 1, UserID: "unpaid", PlanCode: "premium", PlanName: "Premium", EndsAt: now.Add(-time.Hour)},
+		{ID: 2, UserID: "long-gone", PlanCode: "premium", PlanName: "Premium", EndsAt: now.Add(-30 * 24 * time.Hour)},
+	}
+
+	l := NewLifecycle(store, &fakeRecipients{}, nil, LifecycleConfig{})
+	l.now = func() time.Time { return now }
+	if err := l.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(store.graced) != 0 || len(store.expiries) != 2 {
+		t.Errorf("graced = %v, expiries = %v", store.graced, store.expiries)
+	}
+
+	// A grace period that would already be over is skipped
+	store = newFakeLifecycleStore()
+	store.expired = []DueSubscription{{ID: 2, UserID: "long-gone", PlanCode: "premium", EndsAt: now.Add(-30 * 24 * time.Hour)}}
+	l = NewLifecycle(store, &fakeRecipients{}, nil, LifecycleConfig{GracePeriod: 72 * time.Hour})
+	l.now = func() time.Time { return now }
+	if err := l.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick: %v", err)
+	}
+	if len(store.graced) != 0 || len(store.expiries) != 1 {
+		t.Errorf("graced = %v, expiries = %v", store.graced, store.expiries)
+	}
+}
+
+func TestManager_ExpireSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE subscriptions\s+SET status = 'expired'`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user1"))
+	mock.ExpectExec(`INSERT INTO subscriptions .+ p.code = 'free'`).
+		WithArgs("user1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	expired, err := NewManager(db).ExpireSubscription(context.Background(), 7)
+	if err != nil || !expired {
+		t.Fatalf("ExpireSubscription = %v, %v", expired, err)
+	}
+
+	// Renewed before the job got to it
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE subscriptions\s+SET status = 'expired'`).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectRollback()
+
+	expired, err = NewManager(db).ExpireSubscription(context.Background(), 8)
+	if err != nil || expired {
+		t.Fatalf("ExpireSubscription of renewed = %v, %v", expired, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
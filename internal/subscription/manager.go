@@ -32,6 +32,7 @@ SELECT EXISTS (
       AND s.status = 'active'
       AND (s.ends_at IS NULL OR s.ends_at > NOW())
       AND f.feature_key = $2
+) OR EXISTS (` + activeGrant + `
 );`
 
 	row := m.db.QueryRowContext(ctx, q, userID, featureKey)
@@ -42,18 +43,21 @@ SELECT EXISTS (
 	return exists, nil
 }
 
-// CheckQuota verifies if user is within quota limits for a feature
+// CheckQuota verifies if user is within quota limits for a feature.
+// Both the message quota and the optional token budget must have room left.
 func (m *Manager) CheckQuota(ctx context.Context, userID string, featureCode string) (bool, error) {
 	if m.db == nil {
 		return false, errors.New("db not initialized")
 	}
 
-	// Query: get quota limit, period, and current usage
+	// Query: get quota limits, period, and current usage
 	const q = `
 SELECT 
     pf.quota_limit,
     pf.quota_period,
-    COALESCE(fu.usage_count, 0) as usage_count
+    COALESCE(fu.usage_count, 0) as usage_count,
+    pf.token_limit,
+    COALESCE(fu.tokens_used, 0) as tokens_used
 FROM subscriptions s
 JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
 JOIN plan_features pf ON pf.plan_id = p.id
@@ -66,26 +70,37 @@ WHERE s.user_id = $1
   AND (s.ends_at IS NULL OR s.ends_at > NOW())
   AND f.feature_key = $2;`
 
-	var quotaLimit sql.NullInt64
+	var quotaLimit, tokenLimit sql.NullInt64
 	var quotaPeriod string
 	var usageCount int
+	var tokensUsed int64
 
-	err := m.db.QueryRowContext(ctx, q, userID, featureCode).Scan(&quotaLimit, &quotaPeriod, &usageCount)
+	err := m.db.QueryRowContext(ctx, q, userID, featureCode).
+		Scan(&quotaLimit, &quotaPeriod, &usageCount, &tokenLimit, &tokensUsed)
 	if err == sql.ErrNoRows {
-		// No active subscription or feature not available
-		return false, nil
+		// Not in the plan; a granted feature is unmetered
+		return m.hasGrant(ctx, userID, featureCode)
 	}
 	if err != nil {
 		return false, fmt.Errorf("check quota: %w", err)
 	}
 
-	// Unlimited quota (NULL quota_limit)
-	if !quotaLimit.Valid || quotaPeriod == "unlimited" {
+	// Unlimited period: neither messages nor tokens are metered
+	if quotaPeriod == "unlimited" {
 		return true, nil
 	}
 
-	// Check if within quota
-	return usageCount < int(quotaLimit.Int64), nil
+	// Check message quota (NULL quota_limit = unlimited messages)
+	if quotaLimit.Valid && usageCount >= int(quotaLimit.Int64) {
+		return false, nil
+	}
+
+	// Check token budget (NULL token_limit = no budget)
+	if tokenLimit.Valid && tokensUsed >= tokenLimit.Int64 {
+		return false, nil
+	}
+
+	return true, nil
 }
 
 // IncrementUsage increments the usage counter for a feature
@@ -96,25 +111,27 @@ func (m *Manager) IncrementUsage(ctx context.Context, userID string, featureCode
 
 	// Get quota period for the feature
 	const getPeriodQuery = `
-SELECT pf.quota_period
+SELECT pf.quota_period, u.timezone, cur.period_start, cur.period_end
 FROM subscriptions s
+JOIN users u ON u.id = s.user_id
 JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
 JOIN plan_features pf ON pf.plan_id = p.id
 JOIN features f ON f.id = pf.feature_id
+` + openUsageJoin + `
 WHERE s.user_id = $1
   AND s.status = 'active'
   AND (s.ends_at IS NULL OR s.ends_at > NOW())
   AND f.feature_key = $2;`
 
-	var quotaPeriod string
-	err := m.db.QueryRowContext(ctx, getPeriodQuery, userID, featureCode).Scan(&quotaPeriod)
+	var quotaPeriod, timezone string
+	var openStart, openEnd sql.NullTime
+	err := m.db.QueryRowContext(ctx, getPeriodQuery, userID, featureCode).Scan(&quotaPeriod, &timezone, &openStart, &openEnd)
 	if err != nil {
 		return fmt.Errorf("get quota period: %w", err)
 	}
 
 	// Calculate period bounds
-	now := time.Now()
-	periodStart, periodEnd := calculatePeriodBounds(now, quotaPeriod)
+	periodStart, periodEnd := userPeriodBounds(time.Now(), quotaPeriod, timezone, openStart, openEnd)
 
 	// Upsert usage record
 	const upsertQuery = `
@@ -133,7 +150,85 @@ DO UPDATE SET
 	return nil
 }
 
-// calculatePeriodBounds returns start and end timestamps for a quota period
+// DebitTokens adds LLM tokens consumed by a feature to the user's usage for
+// the current period. The increment happens in a single UPSERT so concurrent
+// debits never lose updates. Features with an unlimited period and users
+// without an active subscription are not metered.
+func (m *Manager) DebitTokens(ctx context.Context, userID string, featureCode string, tokens int) error {
+	if m.db == nil {
+		return errors.New("db not initialized")
+	}
+	if tokens <= 0 {
+		return nil
+	}
+
+	const getPeriodQuery = `
+SELECT pf.quota_period, u.timezone, cur.period_start, cur.period_end
+FROM subscriptions s
+JOIN users u ON u.id = s.user_id
+JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
+JOIN plan_features pf ON pf.plan_id = p.id
+JOIN features f ON f.id = pf.feature_id
+` + openUsageJoin + `
+WHERE s.user_id = $1
+  AND s.status = 'active'
+  AND (s.ends_at IS NULL OR s.ends_at > NOW())
+  AND f.feature_key = $2;`
+
+	var quotaPeriod, timezone string
+	var openStart, openEnd sql.NullTime
+	err := m.db.QueryRowContext(ctx, getPeriodQuery, userID, featureCode).Scan(&quotaPeriod, &timezone, &openStart, &openEnd)
+	if err == sql.ErrNoRows || (err == nil && quotaPeriod == "unlimited") {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get quota period: %w", err)
+	}
+
+	periodStart, periodEnd := userPeriodBounds(time.Now(), quotaPeriod, timezone, openStart, openEnd)
+
+	const upsertQuery = `
+INSERT INTO feature_usage (user_id, feature_key, usage_count, tokens_used, period_start, period_end, updated_at)
+VALUES ($1, $2, 0, $3, $4, $5, NOW())
+ON CONFLICT (user_id, feature_key, period_start)
+DO UPDATE SET 
+    tokens_used = feature_usage.tokens_used + EXCLUDED.tokens_used,
+    updated_at = NOW();`
+
+	_, err = m.db.ExecContext(ctx, upsertQuery, userID, featureCode, tokens, periodStart, periodEnd)
+	if err != nil {
+		return fmt.Errorf("debit tokens: %w", err)
+	}
+
+	return nil
+}
+
+// openUsageJoin joins the bounds of the feature's usage period that is still
+// open (period_end in the future), if any, as cur.period_start/period_end
+const openUsageJoin = `LEFT JOIN LATERAL (
+    SELECT period_start, period_end
+    FROM feature_usage
+    WHERE user_id = s.user_id AND feature_key = f.feature_key AND period_end > NOW()
+    ORDER BY period_start DESC
+    LIMIT 1
+) cur ON TRUE`
+
+// userPeriodBounds returns the user's current quota period: the open usage
+// period if there is one, so changing time zone does not reset usage midway,
+// or else the period containing now in the user's time zone (UTC if unknown)
+func userPeriodBounds(now time.Time, period, timezone string, openStart, openEnd sql.NullTime) (time.Time, time.Time) {
+	if period != "unlimited" && openStart.Valid && openEnd.Valid {
+		return openStart.Time, openEnd.Time
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		loc = time.UTC
+	}
+	return calculatePeriodBounds(now.In(loc), period)
+}
+
+// calculatePeriodBounds returns start and end timestamps for a quota period,
+// with days starting at midnight in now's location
 func calculatePeriodBounds(now time.Time, period string) (time.Time, time.Time) {
 	switch period {
 	case "daily":
@@ -161,10 +256,13 @@ func calculatePeriodBounds(now time.Time, period string) (time.Time, time.Time)
 
 // QuotaInfo contains detailed quota information
 type QuotaInfo struct {
-	QuotaLimit  *int      `json:"quota_limit"`  // nil = unlimited
-	QuotaPeriod string    `json:"quota_period"` // daily/weekly/monthly/unlimited
-	UsageCount  int       `json:"usage_count"`
-	PeriodEnd   time.Time `json:"period_end"`
+	QuotaLimit      *int      `json:"quota_limit"`  // nil = unlimited
+	QuotaPeriod     string    `json:"quota_period"` // daily/weekly/monthly/unlimited
+	UsageCount      int       `json:"usage_count"`
+	TokenLimit      *int64    `json:"token_limit"` // nil = no token budget
+	TokensUsed      int64     `json:"tokens_used"`
+	TokensRemaining *int64    `json:"tokens_remaining"` // nil = no token budget
+	PeriodEnd       time.Time `json:"period_end"`
 }
 
 // GetQuotaInfo returns detailed quota information for a user/feature
@@ -178,6 +276,8 @@ SELECT
     pf.quota_limit,
     pf.quota_period,
     COALESCE(fu.usage_count, 0) as usage_count,
+    pf.token_limit,
+    COALESCE(fu.tokens_used, 0) as tokens_used,
     COALESCE(fu.period_end, NOW() + INTERVAL '1 day') as period_end
 FROM subscriptions s
 JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
@@ -192,13 +292,20 @@ WHERE s.user_id = $1
   AND f.feature_key = $2;`
 
 	var info QuotaInfo
-	var quotaLimit sql.NullInt64
+	var quotaLimit, tokenLimit sql.NullInt64
 
 	err := m.db.QueryRowContext(ctx, q, userID, featureCode).
-		Scan(&quotaLimit, &info.QuotaPeriod, &info.UsageCount, &info.PeriodEnd)
+		Scan(&quotaLimit, &info.QuotaPeriod, &info.UsageCount, &tokenLimit, &info.TokensUsed, &info.PeriodEnd)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("feature not available")
+		granted, err := m.hasGrant(ctx, userID, featureCode)
+		if err != nil {
+			return nil, err
+		}
+		if !granted {
+			return nil, errors.New("feature not available")
+		}
+		return &QuotaInfo{QuotaPeriod: "unlimited", PeriodEnd: time.Now().Add(24 * time.Hour)}, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("get quota info: %w", err)
@@ -208,6 +315,15 @@ WHERE s.user_id = $1
 		limit := int(quotaLimit.Int64)
 		info.QuotaLimit = &limit
 	}
+	if tokenLimit.Valid {
+		limit := tokenLimit.Int64
+		remaining := limit - info.TokensUsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		info.TokenLimit = &limit
+		info.TokensRemaining = &remaining
+	}
 
 	return &info, nil
 }
@@ -219,6 +335,7 @@ type UserFeature struct {
 	Description string `json:"description"`
 	QuotaLimit  *int   `json:"quota_limit"`
 	QuotaPeriod string `json:"quota_period"`
+	TokenLimit  *int64 `json:"token_limit"`
 }
 
 // GetUserFeatures returns all features available to a user
@@ -233,7 +350,8 @@ SELECT
     f.name,
     f.description,
     pf.quota_limit,
-    pf.quota_period
+    pf.quota_period,
+    pf.token_limit
 FROM subscriptions s
 JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
 JOIN plan_features pf ON pf.plan_id = p.id
@@ -241,7 +359,22 @@ JOIN features f ON f.id = pf.feature_id
 WHERE s.user_id = $1
   AND s.status = 'active'
   AND (s.ends_at IS NULL OR s.ends_at > NOW())
-ORDER BY f.feature_key;`
+UNION
+SELECT f.feature_key, f.name, f.description, NULL, 'unlimited', NULL
+FROM user_feature_grants g
+JOIN features f ON f.id = g.feature_id
+WHERE g.user_id = $1
+  AND (g.expires_at IS NULL OR g.expires_at > NOW())
+  AND NOT EXISTS (
+      SELECT 1
+      FROM subscriptions s
+      JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
+      JOIN plan_features pf ON pf.plan_id = p.id AND pf.feature_id = f.id
+      WHERE s.user_id = $1
+        AND s.status = 'active'
+        AND (s.ends_at IS NULL OR s.ends_at > NOW())
+  )
+ORDER BY 1;`
 
 	rows, err := m.db.QueryContext(ctx, q, userID)
 	if err != nil {
@@ -252,9 +385,9 @@ ORDER BY f.feature_key;`
 	var features []UserFeature
 	for rows.Next() {
 		var f UserFeature
-		var quotaLimit sql.NullInt64
+		var quotaLimit, tokenLimit sql.NullInt64
 
-		if err := rows.Scan(&f.FeatureKey, &f.Name, &f.Description, &quotaLimit, &f.QuotaPeriod); err != nil {
+		if err := rows.Scan(&f.FeatureKey, &f.Name, &f.Description, &quotaLimit, &f.QuotaPeriod, &tokenLimit); err != nil {
 			return nil, fmt.Errorf("scan feature: %w", err)
 		}
 
@@ -262,6 +395,10 @@ ORDER BY f.feature_key;`
 			limit := int(quotaLimit.Int64)
 			f.QuotaLimit = &limit
 		}
+		if tokenLimit.Valid {
+			limit := tokenLimit.Int64
+			f.TokenLimit = &limit
+		}
 
 		features = append(features, f)
 	}
@@ -271,13 +408,16 @@ ORDER BY f.feature_key;`
 
 // Subscription represents a user's subscription
 type Subscription struct {
-	ID       int        `json:"id"`
-	PlanID   int        `json:"plan_id"`
-	PlanCode string     `json:"plan_code"`
-	PlanName string     `json:"plan_name"`
-	Status   string     `json:"status"`
-	StartsAt time.Time  `json:"starts_at"`
-	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	ID             int        `json:"id"`
+	PlanID         int        `json:"plan_id"`
+	PlanCode       string     `json:"plan_code"`
+	PlanName       string     `json:"plan_name"`
+	Status         string     `json:"status"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	IsTrial        bool       `json:"is_trial"`
+	GraceStartedAt *time.Time `json:"grace_started_at,omitempty"` // Renewal missed; EndsAt is the end of the grace period
+	CanceledAt     *time.Time `json:"canceled_at,omitempty"`      // Will not renew
 }
 
 // GetActiveSubscription returns a user's active subscription
@@ -287,7 +427,8 @@ func (m *Manager) GetActiveSubscription(ctx context.Context, userID string) (*Su
 	}
 
 	const q = `
-SELECT s.id, s.plan_id, p.code, p.name, s.status, s.starts_at, s.ends_at
+SELECT s.id, s.plan_id, p.code, p.name, s.status, s.starts_at, s.ends_at,
+       s.is_trial, s.grace_started_at, s.canceled_at
 FROM subscriptions s
 JOIN plans p ON p.id = s.plan_id
 WHERE s.user_id = $1
@@ -298,7 +439,8 @@ LIMIT 1;`
 
 	var sub Subscription
 	err := m.db.QueryRowContext(ctx, q, userID).
-		Scan(&sub.ID, &sub.PlanID, &sub.PlanCode, &sub.PlanName, &sub.Status, &sub.StartsAt, &sub.EndsAt)
+		Scan(&sub.ID, &sub.PlanID, &sub.PlanCode, &sub.PlanName, &sub.Status, &sub.StartsAt, &sub.EndsAt,
+			&sub.IsTrial, &sub.GraceStartedAt, &sub.CanceledAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -317,6 +459,7 @@ type Plan struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Active      bool   `json:"active"`
+	TrialDays   int    `json:"trial_days"`
 }
 
 // ListPlans returns all subscription plans
@@ -325,7 +468,7 @@ func (m *Manager) ListPlans(ctx context.Context) ([]Plan, error) {
 		return nil, errors.New("db not initialized")
 	}
 
-	const q = `SELECT id, code, name, description, active FROM plans ORDER BY id;`
+	const q = `SELECT id, code, name, description, active, trial_days FROM plans ORDER BY id;`
 
 	rows, err := m.db.QueryContext(ctx, q)
 	if err != nil {
@@ -336,7 +479,7 @@ func (m *Manager) ListPlans(ctx context.Context) ([]Plan, error) {
 	var plans []Plan
 	for rows.Next() {
 		var p Plan
-		if err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.Description, &p.Active); err != nil {
+		if err := rows.Scan(&p.ID, &p.Code, &p.Name, &p.Description, &p.Active, &p.TrialDays); err != nil {
 			return nil, fmt.Errorf("scan plan: %w", err)
 		}
 		plans = append(plans, p)
@@ -383,6 +526,165 @@ func (m *Manager) UpdateUserPlan(ctx context.Context, userID, planCode string) e
 	return nil
 }
 
+// ActivatePlan puts the user on a paid plan until endsAt. An active
+// subscription to the same plan is extended (a renewal, or a trial
+// converting) but never shortened, so a late or replayed event for an
+// earlier period is harmless; any other active subscription is replaced.
+func (m *Manager) ActivatePlan(ctx context.Context, userID, planCode string, endsAt time.Time) error {
+	if m.db == nil {
+		return errors.New("db not initialized")
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var planID int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM plans WHERE code = $1 AND active = TRUE`, planCode).Scan(&planID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("plan not found: %s", planCode)
+	}
+	if err != nil {
+		return fmt.Errorf("query plan: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE subscriptions
+		SET ends_at = GREATEST(COALESCE(ends_at, $3), $3), is_trial = FALSE, grace_started_at = NULL, canceled_at = NULL
+		WHERE user_id = $1 AND plan_id = $2 AND status = 'active'
+		  AND (ends_at IS NULL OR ends_at > NOW())
+	`, userID, planID, endsAt)
+	if err != nil {
+		return fmt.Errorf("extend subscription: %w", err)
+	}
+	if extended, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("extend subscription: %w", err)
+	} else if extended == 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE subscriptions
+			SET status = 'canceled', ends_at = NOW()
+			WHERE user_id = $1 AND status = 'active'
+		`, userID); err != nil {
+			return fmt.Errorf("cancel existing subscription: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO subscriptions (user_id, plan_id, status, starts_at, ends_at)
+			VALUES ($1, $2, 'active', NOW(), $3)
+		`, userID, planID, endsAt); err != nil {
+			return fmt.Errorf("create subscription: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CancelPlanAt stops the user's active subscription to planCode renewing
+// and ends it at endsAt (the end of the paid period, or now). A
+// subscription already ending earlier keeps its end date. Cancelled
+// subscriptions get no grace period.
+func (m *Manager) CancelPlanAt(ctx context.Context, userID, planCode string, endsAt time.Time) error {
+	if m.db == nil {
+		return errors.New("db not initialized")
+	}
+
+	_, err := m.db.ExecContext(ctx, `
+		UPDATE subscriptions s
+		SET ends_at = LEAST(COALESCE(s.ends_at, $3), $3),
+		    canceled_at = COALESCE(s.canceled_at, NOW())
+		FROM plans p
+		WHERE p.id = s.plan_id AND p.code = $2
+		  AND s.user_id = $1 AND s.status = 'active'
+	`, userID, planCode, endsAt)
+	if err != nil {
+		return fmt.Errorf("cancel subscription: %w", err)
+	}
+	return nil
+}
+
+var (
+	// ErrNoTrial means the plan does not offer a free trial.
+	ErrNoTrial = errors.New("plan has no trial")
+	// ErrTrialNotAllowed means the user already had this plan (trial or paid)
+	// or is on another paid plan.
+	ErrTrialNotAllowed = errors.New("trial not available")
+)
+
+// StartTrial puts the user on a free trial of planCode for the plan's
+// trial_days. When the trial ends without a payment, the lifecycle job moves
+// the user back to the free plan.
+func (m *Manager) StartTrial(ctx context.Context, userID, planCode string) (*Subscription, error) {
+	if m.db == nil {
+		return nil, errors.New("db not initialized")
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	sub := &Subscription{PlanCode: planCode, Status: "active", IsTrial: true}
+	var trialDays int
+	err = tx.QueryRowContext(ctx, `SELECT id, name, trial_days FROM plans WHERE code = $1 AND active = TRUE`, planCode).
+		Scan(&sub.PlanID, &sub.PlanName, &trialDays)
+	if err == sql.ErrNoRows || (err == nil && trialDays == 0) {
+		return nil, ErrNoTrial
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query plan: %w", err)
+	}
+
+	// Lock the user so concurrent requests cannot both start a trial
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, fmt.Errorf("lock user: %w", err)
+	}
+
+	var ineligible bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM subscriptions s
+			JOIN plans p ON p.id = s.plan_id
+			WHERE s.user_id = $1
+			  AND (s.plan_id = $2
+			       OR (p.code <> 'free' AND s.status = 'active' AND (s.ends_at IS NULL OR s.ends_at > NOW())))
+		)
+	`, userID, sub.PlanID).Scan(&ineligible)
+	if err != nil {
+		return nil, fmt.Errorf("check trial eligibility: %w", err)
+	}
+	if ineligible {
+		return nil, ErrTrialNotAllowed
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE subscriptions
+		SET status = 'canceled', ends_at = NOW()
+		WHERE user_id = $1 AND status = 'active'
+	`, userID); err != nil {
+		return nil, fmt.Errorf("cancel existing subscription: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO subscriptions (user_id, plan_id, status, starts_at, ends_at, is_trial)
+		VALUES ($1, $2, 'active', NOW(), NOW() + make_interval(days => $3), TRUE)
+		RETURNING id, starts_at, ends_at
+	`, userID, sub.PlanID, trialDays).Scan(&sub.ID, &sub.StartsAt, &sub.EndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("create trial subscription: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+	return sub, nil
+}
+
 // ResetQuota resets quota usage for a user/feature
 func (m *Manager) ResetQuota(ctx context.Context, userID, featureCode string) error {
 	if m.db == nil {
@@ -424,10 +726,3 @@ func (m *Manager) GetQuotaStats(ctx context.Context, featureCode, planCode, peri
 		AverageUsage: 0,
 	}, nil
 }
-
-// GrantFeature grants a feature to a user (stub for now)
-func (m *Manager) GrantFeature(ctx context.Context, userID, featureKey string, expiresAt *int64) error {
-	// TODO: Implement feature grant logic
-	// This would require a new table for user_feature_grants
-	return errors.New("not implemented yet")
-}
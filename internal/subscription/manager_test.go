@@ -3,7 +3,9 @@ package subscription
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -94,8 +96,8 @@ func TestManager_CheckQuota(t *testing.T) {
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
 				// Query returns quota limit and current usage
-				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count"}).
-					AddRow(100, "monthly", 0)
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(100, "monthly", 0, nil, 0)
 				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
@@ -107,8 +109,8 @@ func TestManager_CheckQuota(t *testing.T) {
 			userID:     "user1",
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count"}).
-					AddRow(100, "monthly", 50)
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(100, "monthly", 50, nil, 0)
 				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
@@ -120,8 +122,8 @@ func TestManager_CheckQuota(t *testing.T) {
 			userID:     "user1",
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count"}).
-					AddRow(100, "monthly", 100)
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(100, "monthly", 100, nil, 0)
 				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
@@ -133,14 +135,40 @@ func TestManager_CheckQuota(t *testing.T) {
 			userID:     "user1",
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count"}).
-					AddRow(nil, "unlimited", 1000)
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(nil, "unlimited", 1000, nil, 0)
 				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
 			},
 			want: true,
 		},
+		{
+			name:       "within token budget",
+			userID:     "user1",
+			featureKey: "chat",
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(nil, "monthly", 500, 200000, 199999)
+				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
+					WithArgs("user1", "chat").
+					WillReturnRows(rows)
+			},
+			want: true,
+		},
+		{
+			name:       "token budget exhausted",
+			userID:     "user1",
+			featureKey: "chat",
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used"}).
+					AddRow(100, "monthly", 3, 200000, 201500)
+				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
+					WithArgs("user1", "chat").
+					WillReturnRows(rows)
+			},
+			want: false,
+		},
 		{
 			name:       "no active subscription",
 			userID:     "user1",
@@ -149,6 +177,9 @@ func TestManager_CheckQuota(t *testing.T) {
 				m.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
 					WithArgs("user1", "chat").
 					WillReturnError(sql.ErrNoRows)
+				m.ExpectQuery(`FROM user_feature_grants`).
+					WithArgs("user1", "chat").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 			},
 			want: false,
 		},
@@ -193,6 +224,8 @@ func TestManager_CheckQuota(t *testing.T) {
 	}
 }
 
+var periodColumns = []string{"quota_period", "timezone", "period_start", "period_end"}
+
 func TestManager_IncrementUsage(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -207,7 +240,7 @@ func TestManager_IncrementUsage(t *testing.T) {
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
 				// Query quota period
-				rows := sqlmock.NewRows([]string{"quota_period"}).AddRow("monthly")
+				rows := sqlmock.NewRows(periodColumns).AddRow("monthly", "UTC", nil, nil)
 				m.ExpectQuery(`SELECT pf.quota_period`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
@@ -224,7 +257,7 @@ func TestManager_IncrementUsage(t *testing.T) {
 			userID:     "user1",
 			featureKey: "chat",
 			setupMock: func(m sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"quota_period"}).AddRow("daily")
+				rows := sqlmock.NewRows(periodColumns).AddRow("daily", "UTC", nil, nil)
 				m.ExpectQuery(`SELECT pf.quota_period`).
 					WithArgs("user1", "chat").
 					WillReturnRows(rows)
@@ -283,3 +316,264 @@ func TestManager_IncrementUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_DebitTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		tokens    int
+		setupMock func(sqlmock.Sqlmock)
+		wantErr   bool
+	}{
+		{
+			name:   "adds tokens to current period",
+			tokens: 1250,
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(periodColumns).AddRow("monthly", "UTC", nil, nil)
+				m.ExpectQuery(`SELECT pf.quota_period`).
+					WithArgs("user1", "chat").
+					WillReturnRows(rows)
+
+				m.ExpectExec(`INSERT INTO feature_usage (.+) tokens_used = feature_usage.tokens_used \+ EXCLUDED.tokens_used`).
+					WithArgs("user1", "chat", 1250, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:   "unlimited period is not metered",
+			tokens: 1250,
+			setupMock: func(m sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(periodColumns).AddRow("unlimited", "UTC", nil, nil)
+				m.ExpectQuery(`SELECT pf.quota_period`).
+					WithArgs("user1", "chat").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:   "no active subscription",
+			tokens: 1250,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT pf.quota_period`).
+					WithArgs("user1", "chat").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name:   "zero tokens",
+			tokens: 0,
+		},
+		{
+			name:   "database error",
+			tokens: 10,
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT pf.quota_period`).
+					WithArgs("user1", "chat").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New: %v", err)
+			}
+			defer db.Close()
+
+			if tt.setupMock != nil {
+				tt.setupMock(mock)
+			}
+
+			mgr := NewManager(db)
+			err = mgr.DebitTokens(context.Background(), "user1", "chat", tt.tokens)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DebitTokens error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_GetQuotaInfo_TokensRemaining(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	periodEnd := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"quota_limit", "quota_period", "usage_count", "token_limit", "tokens_used", "period_end"}).
+		AddRow(100, "monthly", 12, 50000, 42000, periodEnd)
+	mock.ExpectQuery(`SELECT (.+) FROM subscriptions s`).
+		WithArgs("user1", "chat").
+		WillReturnRows(rows)
+
+	info, err := NewManager(db).GetQuotaInfo(context.Background(), "user1", "chat")
+	if err != nil {
+		t.Fatalf("GetQuotaInfo: %v", err)
+	}
+	if info.TokenLimit == nil || *info.TokenLimit != 50000 || info.TokensUsed != 42000 {
+		t.Fatalf("unexpected token info: %+v", info)
+	}
+	if info.TokensRemaining == nil || *info.TokensRemaining != 8000 {
+		t.Errorf("tokens remaining = %v, want 8000", info.TokensRemaining)
+	}
+}
+
+func TestUserPeriodBounds(t *testing.T) {
+	lagos, err := time.LoadLocation("Africa/Lagos")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	// 23:30 UTC on the 9th is already the 10th in Lagos
+	now := time.Date(2026, 3, 9, 23, 30, 0, 0, time.UTC)
+
+	start, end := userPeriodBounds(now, "daily", "Africa/Lagos", sql.NullTime{}, sql.NullTime{})
+	if want := time.Date(2026, 3, 10, 0, 0, 0, 0, lagos); !start.Equal(want) || !end.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("daily bounds = %v - %v, want the 10th in Lagos", start, end)
+	}
+
+	start, _ = userPeriodBounds(now, "daily", "Nowhere/City", sql.NullTime{}, sql.NullTime{})
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("unknown zone start = %v, want %v", start, want)
+	}
+
+	// An open period is kept, so changing timezone does not reset usage
+	openStart := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	openEnd := openStart.AddDate(0, 0, 1)
+	start, end = userPeriodBounds(now, "daily", "Africa/Lagos",
+		sql.NullTime{Time: openStart, Valid: true}, sql.NullTime{Time: openEnd, Valid: true})
+	if !start.Equal(openStart) || !end.Equal(openEnd) {
+		t.Errorf("bounds = %v - %v, want the open period", start, end)
+	}
+}
+
+func TestManager_ActivatePlan(t *testing.T) {
+	endsAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		extended int64
+	}{
+		{name: "renewal extends the current subscription", extended: 1},
+		{name: "new plan replaces the current subscription", extended: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT id FROM plans`).
+				WithArgs("premium").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+			mock.ExpectExec(`UPDATE subscriptions\s+SET ends_at = GREATEST\(COALESCE\(ends_at, \$3\), \$3\)`).
+				WithArgs("user1", 2, endsAt).
+				WillReturnResult(sqlmock.NewResult(0, tt.extended))
+			if tt.extended == 0 {
+				mock.ExpectExec(`SET status = 'canceled'`).
+					WithArgs("user1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO subscriptions`).
+					WithArgs("user1", 2, endsAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			if err := NewManager(db).ActivatePlan(context.Background(), "user1", "premium", endsAt); err != nil {
+				t.Fatalf("ActivatePlan: %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_CancelPlanAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	endsAt := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`UPDATE subscriptions s`).
+		WithArgs("user1", "premium", endsAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := NewManager(db).CancelPlanAt(context.Background(), "user1", "premium", endsAt); err != nil {
+		t.Fatalf("CancelPlanAt: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestManager_StartTrial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, name, trial_days FROM plans`).
+		WithArgs("premium").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "trial_days"}).AddRow(2, "Premium", 14))
+	mock.ExpectExec(`FOR UPDATE`).WithArgs("user1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("user1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`SET status = 'canceled'`).WithArgs("user1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO subscriptions`).
+		WithArgs("user1", 2, 14).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starts_at", "ends_at"}).AddRow(9, now, now.AddDate(0, 0, 14)))
+	mock.ExpectCommit()
+
+	sub, err := NewManager(db).StartTrial(context.Background(), "user1", "premium")
+	if err != nil {
+		t.Fatalf("StartTrial: %v", err)
+	}
+	if sub.ID != 9 || !sub.IsTrial || sub.PlanCode != "premium" || sub.EndsAt == nil {
+		t.Errorf("subscription = %+v", sub)
+	}
+
+	// A second trial of the same plan is refused
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, name, trial_days FROM plans`).
+		WithArgs("premium").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "trial_days"}).AddRow(2, "Premium", 14))
+	mock.ExpectExec(`FOR UPDATE`).WithArgs("user1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("user1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	if _, err := NewManager(db).StartTrial(context.Background(), "user1", "premium"); !errors.Is(err, ErrTrialNotAllowed) {
+		t.Errorf("second trial error = %v, want ErrTrialNotAllowed", err)
+	}
+
+	// Plans without trial days have no trial
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, name, trial_days FROM plans`).
+		WithArgs("free").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "trial_days"}).AddRow(1, "Free", 0))
+	mock.ExpectRollback()
+
+	if _, err := NewManager(db).StartTrial(context.Background(), "user1", "free"); !errors.Is(err, ErrNoTrial) {
+		t.Errorf("free plan error = %v, want ErrNoTrial", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
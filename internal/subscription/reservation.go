@@ -0,0 +1,181 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoAccess means the user's plan does not include the feature.
+	ErrNoAccess = errors.New("feature not available")
+	// ErrQuotaExceeded means the message quota or token budget is used up.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// TokenHold is the token estimate a reservation holds against a feature's
+// token budget while its request runs: a chat turn's prompt, history and
+// reply. The usage ledger debits the tokens actually used as each LLM call
+// finishes, and Commit or Release then returns the hold.
+const TokenHold = 3000
+
+// Reservation holds one unit of a feature's quota, and TokenHold tokens of
+// its budget, while a request runs. Commit keeps the unit; Release gives it
+// back (e.g. when the LLM call fails). Whichever is called first wins, so
+// `defer res.Release(ctx)` after a successful Commit is safe.
+type Reservation struct {
+	UserID      string
+	FeatureKey  string
+	periodStart time.Time
+	tokens      int64   // Held against the token budget
+	db          *sql.DB // nil = not metered (unlimited period)
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Reserve atomically takes one unit of quota for a feature, and holds
+// TokenHold tokens when the feature has a token budget. The usage row is
+// incremented by a single conditional UPSERT that only succeeds while the
+// message quota and token budget have room, so concurrent requests (several
+// tabs, a voice call alongside chat) cannot overspend: each sees the tokens
+// the others hold before their usage is debited.
+func (m *Manager) Reserve(ctx context.Context, userID string, featureCode string) (*Reservation, error) {
+	if m.db == nil {
+		return nil, errors.New("db not initialized")
+	}
+
+	const limitsQuery = `
+SELECT pf.quota_limit, pf.quota_period, pf.token_limit, u.timezone, cur.period_start, cur.period_end
+FROM subscriptions s
+JOIN users u ON u.id = s.user_id
+JOIN plans p ON p.id = s.plan_id AND p.active = TRUE
+JOIN plan_features pf ON pf.plan_id = p.id
+JOIN features f ON f.id = pf.feature_id
+` + openUsageJoin + `
+WHERE s.user_id = $1
+  AND s.status = 'active'
+  AND (s.ends_at IS NULL OR s.ends_at > NOW())
+  AND f.feature_key = $2;`
+
+	var quotaLimit, tokenLimit sql.NullInt64
+	var quotaPeriod, timezone string
+	var openStart, openEnd sql.NullTime
+
+	err := m.db.QueryRowContext(ctx, limitsQuery, userID, featureCode).
+		Scan(&quotaLimit, &quotaPeriod, &tokenLimit, &timezone, &openStart, &openEnd)
+	if err == sql.ErrNoRows {
+		// Not in the plan; a granted feature is unmetered
+		granted, err := m.hasGrant(ctx, userID, featureCode)
+		if err != nil {
+			return nil, err
+		}
+		if !granted {
+			return nil, ErrNoAccess
+		}
+		return &Reservation{UserID: userID, FeatureKey: featureCode}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get quota limits: %w", err)
+	}
+
+	res := &Reservation{UserID: userID, FeatureKey: featureCode}
+	if quotaPeriod == "unlimited" {
+		return res, nil
+	}
+
+	// A zero limit can never be satisfied; the INSERT branch below would
+	// otherwise create the first usage row unconditionally.
+	if (quotaLimit.Valid && quotaLimit.Int64 <= 0) || (tokenLimit.Valid && tokenLimit.Int64 <= 0) {
+		return nil, ErrQuotaExceeded
+	}
+
+	periodStart, periodEnd := userPeriodBounds(time.Now(), quotaPeriod, timezone, openStart, openEnd)
+	var hold int64
+	if tokenLimit.Valid {
+		hold = TokenHold
+	}
+
+	// ON CONFLICT DO UPDATE locks the usage row, so concurrent reservations
+	// are serialized and each sees the previous one's increment. When the
+	// WHERE clause fails no row is returned.
+	const reserveQuery = `
+INSERT INTO feature_usage (user_id, feature_key, usage_count, tokens_used, period_start, period_end, updated_at)
+VALUES ($1, $2, 1, $7, $3, $4, NOW())
+ON CONFLICT (user_id, feature_key, period_start)
+DO UPDATE SET
+    usage_count = feature_usage.usage_count + 1,
+    tokens_used = feature_usage.tokens_used + EXCLUDED.tokens_used,
+    updated_at = NOW()
+WHERE ($5::INTEGER IS NULL OR feature_usage.usage_count < $5)
+  AND ($6::BIGINT IS NULL OR feature_usage.tokens_used < $6)
+RETURNING usage_count;`
+
+	var usageCount int
+	err = m.db.QueryRowContext(ctx, reserveQuery, userID, featureCode, periodStart, periodEnd, quotaLimit, tokenLimit, hold).
+		Scan(&usageCount)
+	if err == sql.ErrNoRows {
+		return nil, ErrQuotaExceeded
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reserve quota: %w", err)
+	}
+
+	res.periodStart = periodStart
+	res.tokens = hold
+	res.db = m.db
+	return res, nil
+}
+
+// Commit keeps the reserved unit of quota and returns the token hold, leaving
+// the tokens the ledger debited for the request. It is a no-op after Release
+// or a previous Commit.
+func (r *Reservation) Commit(ctx context.Context) error {
+	if !r.finish() || r.db == nil || r.tokens == 0 {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE feature_usage
+		SET tokens_used = GREATEST(tokens_used - $4, 0), updated_at = NOW()
+		WHERE user_id = $1 AND feature_key = $2 AND period_start = $3
+	`, r.UserID, r.FeatureKey, r.periodStart, r.tokens)
+	if err != nil {
+		return fmt.Errorf("commit quota: %w", err)
+	}
+	return nil
+}
+
+// Release returns the reserved unit of quota and the token hold. It is a
+// no-op after Commit or a previous Release.
+func (r *Reservation) Release(ctx context.Context) error {
+	if !r.finish() || r.db == nil {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE feature_usage
+		SET usage_count = GREATEST(usage_count - 1, 0),
+		    tokens_used = GREATEST(tokens_used - $4, 0),
+		    updated_at = NOW()
+		WHERE user_id = $1 AND feature_key = $2 AND period_start = $3
+	`, r.UserID, r.FeatureKey, r.periodStart, r.tokens)
+	if err != nil {
+		return fmt.Errorf("release quota: %w", err)
+	}
+	return nil
+}
+
+// finish marks the reservation settled and reports whether it was still open.
+func (r *Reservation) finish() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return false
+	}
+	r.done = true
+	return true
+}
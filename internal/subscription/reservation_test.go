@@ -0,0 +1,330 @@
+package subscription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectLimits(m sqlmock.Sqlmock, quotaLimit interface{}, period string, tokenLimit interface{}) {
+	m.ExpectQuery(`SELECT pf.quota_limit, pf.quota_period, pf.token_limit`).
+		WithArgs("user1", "chat").
+		WillReturnRows(sqlmock.NewRows([]string{"quota_limit", "quota_period", "token_limit", "timezone", "period_start", "period_end"}).
+			AddRow(quotaLimit, period, tokenLimit, "UTC", nil, nil))
+}
+
+func TestManager_Reserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		setupMock func(sqlmock.Sqlmock)
+		wantErr   error
+		metered   bool
+	}{
+		{
+			name: "reserved",
+			setupMock: func(m sqlmock.Sqlmock) {
+				expectLimits(m, 100, "monthly", 50000)
+				m.ExpectQuery(`INSERT INTO feature_usage (.+) ON CONFLICT (.+) WHERE (.+) RETURNING usage_count`).
+					WithArgs("user1", "chat", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"usage_count"}).AddRow(7))
+			},
+			metered: true,
+		},
+		{
+			name: "quota exceeded",
+			setupMock: func(m sqlmock.Sqlmock) {
+				expectLimits(m, 100, "monthly", nil)
+				// Conditional UPSERT updated nothing
+				m.ExpectQuery(`INSERT INTO feature_usage`).
+					WillReturnRows(sqlmock.NewRows([]string{"usage_count"}))
+			},
+			wantErr: ErrQuotaExceeded,
+		},
+		{
+			name: "zero limit",
+			setupMock: func(m sqlmock.Sqlmock) {
+				expectLimits(m, 0, "daily", nil)
+			},
+			wantErr: ErrQuotaExceeded,
+		},
+		{
+			name: "unlimited period is not metered",
+			setupMock: func(m sqlmock.Sqlmock) {
+				expectLimits(m, nil, "unlimited", nil)
+			},
+		},
+		{
+			name: "no active subscription",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT pf.quota_limit`).
+					WithArgs("user1", "chat").
+					WillReturnError(sql.ErrNoRows)
+				m.ExpectQuery(`FROM user_feature_grants`).
+					WithArgs("user1", "chat").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantErr: ErrNoAccess,
+		},
+		{
+			name: "granted feature is not metered",
+			setupMock: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery(`SELECT pf.quota_limit`).
+					WithArgs("user1", "chat").
+					WillReturnError(sql.ErrNoRows)
+				m.ExpectQuery(`FROM user_feature_grants`).
+					WithArgs("user1", "chat").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock.New: %v", err)
+			}
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			res, err := NewManager(db).Reserve(context.Background(), "user1", "chat")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reserve error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (res.db != nil) != tt.metered {
+				t.Errorf("metered = %v, want %v", res.db != nil, tt.metered)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestReservation_ReleaseOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	expectLimits(mock, 100, "monthly", nil)
+	mock.ExpectQuery(`INSERT INTO feature_usage`).
+		WillReturnRows(sqlmock.NewRows([]string{"usage_count"}).AddRow(1))
+	mock.ExpectExec(`UPDATE feature_usage\s+SET usage_count = GREATEST\(usage_count - 1, 0\)`).
+		WithArgs("user1", "chat", sqlmock.AnyArg(), int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mgr := NewManager(db)
+	res, err := mgr.Reserve(context.Background(), "user1", "chat")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+
+	// Only the first Release touches the database
+	for i := 0; i < 2; i++ {
+		if err := res.Release(context.Background()); err != nil {
+			t.Fatalf("Release %d: %v", i, err)
+		}
+	}
+	if err := res.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestReservation_CommitThenReleaseKeepsUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	expectLimits(mock, 100, "monthly", nil)
+	mock.ExpectQuery(`INSERT INTO feature_usage`).
+		WillReturnRows(sqlmock.NewRows([]string{"usage_count"}).AddRow(1))
+
+	res, err := NewManager(db).Reserve(context.Background(), "user1", "chat")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := res.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := res.Release(context.Background()); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	// No UPDATE expected: sqlmock fails the Release above if one is issued
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// TestManager_ReserveConcurrent runs reservations in parallel against a
+// database that has room for only two more messages; the conditional UPSERT
+// decides, so exactly two callers must win.
+func TestManager_ReserveConcurrent(t *testing.T) {
+	const callers, room = 8, 2
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	for i := 0; i < callers; i++ {
+		expectLimits(mock, 10, "daily", nil)
+		rows := sqlmock.NewRows([]string{"usage_count"})
+		if i < room {
+			rows.AddRow(9 + i)
+		}
+		mock.ExpectQuery(`INSERT INTO feature_usage`).
+			WithArgs("user1", "chat", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(rows)
+	}
+
+	mgr := NewManager(db)
+	var (
+		wg               sync.WaitGroup
+		mu               sync.Mutex
+		granted, refused int
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.Reserve(context.Background(), "user1", "chat")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				granted++
+			case errors.Is(err, ErrQuotaExceeded):
+				refused++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted != room || refused != callers-room {
+		t.Errorf("granted=%d refused=%d, want %d and %d", granted, refused, room, callers-room)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestReservation_TokenHold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		expectLimits(mock, 100, "monthly", 50000)
+		mock.ExpectQuery(`INSERT INTO feature_usage (.+) tokens_used = feature_usage.tokens_used \+ EXCLUDED.tokens_used`).
+			WithArgs("user1", "chat", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(TokenHold)).
+			WillReturnRows(sqlmock.NewRows([]string{"usage_count"}).AddRow(i + 1))
+	}
+	// Commit returns only the hold; Release returns the message too
+	mock.ExpectExec(`UPDATE feature_usage\s+SET tokens_used = GREATEST\(tokens_used - \$4, 0\)`).
+		WithArgs("user1", "chat", sqlmock.AnyArg(), int64(TokenHold)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SET usage_count = GREATEST\(usage_count - 1, 0\),\s+tokens_used = GREATEST\(tokens_used - \$4, 0\)`).
+		WithArgs("user1", "chat", sqlmock.AnyArg(), int64(TokenHold)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mgr := NewManager(db)
+	committed, err := mgr.Reserve(context.Background(), "user1", "chat")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	released, err := mgr.Reserve(context.Background(), "user1", "chat")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := committed.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := released.Release(context.Background()); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+// TestManager_ReserveConcurrentTokenBudget runs turns in parallel against a
+// token budget with room left for one: the first reservation's hold puts
+// tokens_used over the limit before its turn is debited, so the conditional
+// UPSERT refuses the rest.
+func TestManager_ReserveConcurrentTokenBudget(t *testing.T) {
+	const callers, limit = 8, 50000
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	for i := 0; i < callers; i++ {
+		expectLimits(mock, nil, "monthly", limit)
+		rows := sqlmock.NewRows([]string{"usage_count"})
+		if i == 0 {
+			rows.AddRow(41)
+		}
+		mock.ExpectQuery(`INSERT INTO feature_usage (.+) WHERE (.+) feature_usage.tokens_used < \$6`).
+			WithArgs("user1", "chat", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, int64(limit), int64(TokenHold)).
+			WillReturnRows(rows)
+	}
+
+	mgr := NewManager(db)
+	var (
+		wg               sync.WaitGroup
+		mu               sync.Mutex
+		granted, refused int
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.Reserve(context.Background(), "user1", "chat")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				granted++
+			case errors.Is(err, ErrQuotaExceeded):
+				refused++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if granted != 1 || refused != callers-1 {
+		t.Errorf("granted=%d refused=%d, want 1 and %d", granted, refused, callers-1)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
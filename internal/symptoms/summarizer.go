@@ -14,13 +14,12 @@ const maxSummaryWords = 25
 
 // Summarizer generates one-sentence symptom summaries for health tracker UI.
 type Summarizer struct {
-	gemini   llm.Client
-	deepseek llm.Client
+	llmClient llm.Client
 }
 
-// NewSummarizer creates a summarizer. Either LLM client may be nil.
-func NewSummarizer(gemini, deepseek llm.Client) *Summarizer {
-	return &Summarizer{gemini: gemini, deepseek: deepseek}
+// NewSummarizer creates a summarizer. The LLM client may be nil.
+func NewSummarizer(client llm.Client) *Summarizer {
+	return &Summarizer{llmClient: client}
 }
 
 // Summarize returns a one-sentence summary, falling back to a template if LLM fails.
@@ -52,17 +51,10 @@ func (s *Summarizer) summarizeWithLLM(
 ) (string, error) {
 	prompt := buildSummaryPrompt(symptomType, description, severity, frequency)
 
-	if s.gemini != nil {
-		if summary, err := s.complete(ctx, s.gemini, prompt); err == nil {
-			return summary, nil
-		}
-	}
-	if s.deepseek != nil {
-		if summary, err := s.complete(ctx, s.deepseek, prompt); err == nil {
-			return summary, nil
-		}
+	if s.llmClient == nil {
+		return "", fmt.Errorf("no LLM available")
 	}
-	return "", fmt.Errorf("no LLM available")
+	return s.complete(ctx, prompt)
 }
 
 func buildSummaryPrompt(symptomType, description, severity, frequency string) string {
@@ -81,11 +73,11 @@ Output ONLY the summary sentence, no quotes or labels.`,
 		maxSummaryWords, typeLabel, severity, frequency, description)
 }
 
-func (s *Summarizer) complete(ctx context.Context, client llm.Client, prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 12*time.Second)
+func (s *Summarizer) complete(ctx context.Context, prompt string) (string, error) {
+	ctx, cancel := context.WithTimeout(llm.WithTask(ctx, llm.TaskSummary), 12*time.Second)
 	defer cancel()
 
-	resp, err := client.ChatCompletion(ctx, llm.ChatRequest{
+	resp, err := s.llmClient.ChatCompletion(ctx, llm.ChatRequest{
 		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
 		Temperature: 0.4,
 		MaxTokens:   80,
@@ -0,0 +1,104 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+// Store persists ledger entries.
+type Store interface {
+	RecordLLMUsage(ctx context.Context, u *db.LLMUsage) error
+}
+
+// TokenDebiter charges LLM tokens against a user's feature quota.
+type TokenDebiter interface {
+	DebitTokens(ctx context.Context, userID string, featureCode string, tokens int) error
+}
+
+// quotaFeatures maps LLM tasks to the feature whose token budget they consume.
+// Background tasks (titles, summaries, moderation) are not charged to users.
+var quotaFeatures = map[llm.Task]string{
+	llm.TaskChat: "chat",
+}
+
+// Ledger prices LLM usage and writes it to the usage ledger.
+// It implements llm.UsageRecorder; writes happen in the background so
+// accounting never slows down a chat reply.
+type Ledger struct {
+	store   Store
+	prices  llm.PriceTable
+	quota   TokenDebiter
+	timeout time.Duration
+	wg      sync.WaitGroup
+}
+
+// Ensure Ledger implements llm.UsageRecorder
+var _ llm.UsageRecorder = (*Ledger)(nil)
+
+// NewLedger creates a ledger using the given price table.
+func NewLedger(store Store, prices llm.PriceTable) *Ledger {
+	return &Ledger{store: store, prices: prices, timeout: 5 * time.Second}
+}
+
+// WithQuota makes the ledger debit user-facing tokens from subscription quotas.
+func (l *Ledger) WithQuota(quota TokenDebiter) *Ledger {
+	l.quota = quota
+	return l
+}
+
+// RecordUsage implements llm.UsageRecorder.
+func (l *Ledger) RecordUsage(_ context.Context, record llm.UsageRecord) {
+	entry := l.Entry(record)
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		// Detached from the request: the caller may already be gone.
+		ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+		defer cancel()
+		if err := l.store.RecordLLMUsage(ctx, entry); err != nil {
+			log.Printf("Failed to record LLM usage (%s/%s, %s): %v", entry.Provider, entry.Model, entry.Task, err)
+		}
+		l.debit(ctx, record)
+	}()
+}
+
+// debit charges the record's tokens to the user's quota, if the task is metered.
+func (l *Ledger) debit(ctx context.Context, record llm.UsageRecord) {
+	feature, ok := quotaFeatures[record.Task]
+	if l.quota == nil || !ok || record.UserID == "" {
+		return
+	}
+	tokens := record.PromptTokens + record.CompletionTokens
+	if err := l.quota.DebitTokens(ctx, record.UserID, feature, tokens); err != nil {
+		log.Printf("Failed to debit %d %s tokens for user %s: %v", tokens, feature, record.UserID, err)
+	}
+}
+
+// Entry converts a usage record into a priced ledger entry.
+func (l *Ledger) Entry(record llm.UsageRecord) *db.LLMUsage {
+	entry := &db.LLMUsage{
+		Provider:         record.Provider,
+		Model:            record.Model,
+		Task:             string(record.Task),
+		PromptTokens:     record.PromptTokens,
+		CompletionTokens: record.CompletionTokens,
+		TokensEstimated:  record.Estimated,
+		CostUSD:          l.prices.Cost(record.Provider, record.Model, record.PromptTokens, record.CompletionTokens),
+	}
+	if record.UserID != "" {
+		userID := record.UserID
+		entry.UserID = &userID
+	}
+	return entry
+}
+
+// Wait blocks until in-flight writes finish (used on shutdown and in tests).
+func (l *Ledger) Wait() {
+	l.wg.Wait()
+}
@@ -0,0 +1,115 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+type fakeStore struct {
+	mu      sync.Mutex
+	entries []db.LLMUsage
+	err     error
+}
+
+func (f *fakeStore) RecordLLMUsage(_ context.Context, u *db.LLMUsage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, *u)
+	return f.err
+}
+
+func TestLedger_RecordUsagePricesEntry(t *testing.T) {
+	store := &fakeStore{}
+	prices, err := llm.ParsePriceTable("deepseek/deepseek-chat=0.27:1.10,gemini/*=0.10:0.40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger := NewLedger(store, prices)
+
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{
+		UserID:           "user-1",
+		Provider:         "deepseek",
+		Model:            "deepseek-chat",
+		Task:             llm.TaskChat,
+		PromptTokens:     1000,
+		CompletionTokens: 500,
+	})
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{
+		Provider:         "gemini",
+		Model:            "gemini-2.0-flash",
+		Task:             llm.TaskWelcome,
+		PromptTokens:     2_000_000,
+		CompletionTokens: 0,
+		Estimated:        true,
+	})
+	ledger.Wait()
+
+	if len(store.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(store.entries))
+	}
+	byTask := map[string]db.LLMUsage{}
+	for _, e := range store.entries {
+		byTask[e.Task] = e
+	}
+
+	chat := byTask["chat"]
+	if chat.UserID == nil || *chat.UserID != "user-1" {
+		t.Errorf("chat entry user = %v", chat.UserID)
+	}
+	if want := (1000*0.27 + 500*1.10) / 1e6; math.Abs(chat.CostUSD-want) > 1e-12 {
+		t.Errorf("chat cost = %v, want %v", chat.CostUSD, want)
+	}
+
+	welcome := byTask["welcome"]
+	if welcome.UserID != nil || !welcome.TokensEstimated {
+		t.Errorf("unexpected system entry: %+v", welcome)
+	}
+	if math.Abs(welcome.CostUSD-0.20) > 1e-12 {
+		t.Errorf("wildcard price cost = %v, want 0.20", welcome.CostUSD)
+	}
+}
+
+func TestLedger_StoreErrorIsNotFatal(t *testing.T) {
+	store := &fakeStore{err: errors.New("db down")}
+	ledger := NewLedger(store, nil)
+
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{Provider: "ollama", Task: llm.TaskTitle})
+	ledger.Wait()
+
+	if len(store.entries) != 1 || store.entries[0].CostUSD != 0 {
+		t.Errorf("unexpected entries: %+v", store.entries)
+	}
+}
+
+type fakeDebiter struct {
+	mu     sync.Mutex
+	debits map[string]int
+}
+
+func (f *fakeDebiter) DebitTokens(_ context.Context, userID, featureCode string, tokens int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.debits[userID+"/"+featureCode] += tokens
+	return nil
+}
+
+func TestLedger_DebitsChatTokensFromQuota(t *testing.T) {
+	quota := &fakeDebiter{debits: map[string]int{}}
+	ledger := NewLedger(&fakeStore{}, nil).WithQuota(quota)
+
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{UserID: "user-1", Task: llm.TaskChat, PromptTokens: 900, CompletionTokens: 300})
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{UserID: "user-1", Task: llm.TaskChat, PromptTokens: 20, CompletionTokens: 5})
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{UserID: "user-1", Task: llm.TaskTitle, PromptTokens: 50, CompletionTokens: 10})
+	ledger.RecordUsage(context.Background(), llm.UsageRecord{Task: llm.TaskChat, PromptTokens: 50})
+	ledger.Wait()
+
+	if len(quota.debits) != 1 || quota.debits["user-1/chat"] != 1225 {
+		t.Errorf("debits = %v, want only user-1/chat=1225", quota.debits)
+	}
+}
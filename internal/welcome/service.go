@@ -8,6 +8,7 @@ import (
 
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
 	"github.com/themobileprof/momlaunchpad-be/internal/profile"
+	"github.com/themobileprof/momlaunchpad-be/internal/recurrence"
 	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
 )
 
@@ -23,26 +24,33 @@ type Result struct {
 
 // Service generates and caches personalized welcome messages (7-day rolling window).
 type Service struct {
-	db       *db.DB
-	gemini   llm.Client
-	deepseek llm.Client
+	db        *db.DB
+	llmClient llm.Client
 }
 
 // NewService creates a welcome message service.
-// Either LLM client may be nil; generation falls back to the next provider, then a static template.
-func NewService(database *db.DB, gemini, deepseek llm.Client) *Service {
-	return &Service{db: database, gemini: gemini, deepseek: deepseek}
+// The LLM client may be nil (or an llm.Router that fails over between providers);
+// generation falls back to a static template when it is unavailable.
+func NewService(database *db.DB, client llm.Client) *Service {
+	return &Service{db: database, llmClient: client}
 }
 
 // GetWeeklyWelcome returns a cached welcome message or generates one if older than 7 days.
 func (s *Service) GetWeeklyWelcome(ctx context.Context, userID string) (*Result, error) {
 	now := time.Now()
 
+	user, err := s.db.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	// Weeks start at midnight in the user's own timezone
+	loc := user.Location()
+
 	cached, err := s.db.GetLatestWelcomeMessage(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	if cached != nil && now.Sub(cached.CacheDate) < welcomeCacheTTL {
+	if cached != nil && now.Sub(dateIn(cached.CacheDate, loc)) < welcomeCacheTTL {
 		return &Result{
 			Message:   cached.Message,
 			CacheDate: cached.CacheDate,
@@ -50,19 +58,14 @@ func (s *Service) GetWeeklyWelcome(ctx context.Context, userID string) (*Result,
 		}, nil
 	}
 
-	cacheDate := startOfDayUTC(now)
-
-	user, err := s.db.GetUserByID(ctx, userID)
-	if err != nil || user == nil {
-		return nil, fmt.Errorf("user not found")
-	}
+	cacheDate := startOfDay(now, loc)
 
 	contextText, err := s.buildHealthContext(ctx, userID, user)
 	if err != nil {
 		return nil, err
 	}
 
-	message, source, genErr := s.generateMessage(ctx, user, contextText)
+	message, source, genErr := s.generateMessage(llm.WithUser(ctx, userID), user, contextText)
 	if genErr != nil {
 		message = fallbackWelcome(user)
 		source = "fallback"
@@ -83,25 +86,15 @@ func (s *Service) GetWeeklyWelcome(ctx context.Context, userID string) (*Result,
 func (s *Service) generateMessage(ctx context.Context, user *db.User, contextText string) (string, string, error) {
 	prompt := buildWelcomePrompt(user, contextText)
 
-	if s.gemini != nil {
-		message, err := s.completeWithLLM(ctx, s.gemini, prompt)
-		if err == nil {
-			return message, "gemini", nil
-		}
-	}
-
-	if s.deepseek != nil {
-		message, err := s.completeWithLLM(ctx, s.deepseek, prompt)
-		if err == nil {
-			return message, "deepseek", nil
-		}
-	}
-
-	if s.gemini == nil && s.deepseek == nil {
+	if s.llmClient == nil {
 		return fallbackWelcome(user), "fallback", fmt.Errorf("no LLM client configured")
 	}
 
-	return fallbackWelcome(user), "fallback", fmt.Errorf("all LLM providers failed")
+	message, source, err := s.completeWithLLM(ctx, prompt)
+	if err != nil {
+		return fallbackWelcome(user), "fallback", err
+	}
+	return message, source, nil
 }
 
 func buildWelcomePrompt(user *db.User, contextText string) string {
@@ -162,11 +155,12 @@ func journeyStageValue(user *db.User) string {
 	return ""
 }
 
-func (s *Service) completeWithLLM(ctx context.Context, client llm.Client, prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+// completeWithLLM returns the welcome text and the provider that generated it.
+func (s *Service) completeWithLLM(ctx context.Context, prompt string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(llm.WithTask(ctx, llm.TaskWelcome), 20*time.Second)
 	defer cancel()
 
-	resp, err := client.ChatCompletion(ctx, llm.ChatRequest{
+	resp, err := s.llmClient.ChatCompletion(ctx, llm.ChatRequest{
 		Messages: []llm.ChatMessage{
 			{Role: "user", Content: prompt},
 		},
@@ -174,19 +168,23 @@ func (s *Service) completeWithLLM(ctx context.Context, client llm.Client, prompt
 		MaxTokens:   220,
 	})
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("empty LLM response")
+		return "", "", fmt.Errorf("empty LLM response")
 	}
 
 	message := strings.TrimSpace(resp.Choices[0].Message.Content)
 	message = strings.Trim(message, "\"'`")
 	if message == "" {
-		return "", fmt.Errorf("empty welcome text")
+		return "", "", fmt.Errorf("empty welcome text")
 	}
 
-	return message, nil
+	source := resp.Provider
+	if source == "" {
+		source = "llm"
+	}
+	return message, source, nil
 }
 
 func (s *Service) buildHealthContext(ctx context.Context, userID string, user *db.User) (string, error) {
@@ -288,9 +286,23 @@ func (s *Service) buildHealthContext(ctx context.Context, userID string, user *d
 	now := time.Now()
 	upcoming := 0
 	for _, r := range reminders {
-		if !r.IsCompleted && r.ReminderTime.After(now) && upcoming < 3 {
+		if r.IsCompleted || upcoming >= 3 {
+			continue
+		}
+		next := r.ReminderTime
+		if r.RRule != nil {
+			rule, err := recurrence.Parse(*r.RRule, r.ReminderTime, r.Timezone)
+			if err != nil {
+				continue
+			}
+			var ok bool
+			if next, ok = rule.After(now); !ok {
+				continue
+			}
+		}
+		if next.After(now) {
 			b.WriteString(fmt.Sprintf("\nUpcoming reminder: %s on %s\n",
-				r.Title, r.ReminderTime.Format("2006-01-02")))
+				r.Title, next.Format("2006-01-02")))
 			upcoming++
 		}
 	}
@@ -356,9 +368,17 @@ func truncate(text string, max int) string {
 	return text[:max] + "…"
 }
 
-func startOfDayUTC(t time.Time) time.Time {
-	y, m, d := t.UTC().Date()
-	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+// startOfDay returns midnight in loc of the day containing t
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// dateIn returns midnight in loc of the calendar date stored in t, for DATE
+// columns which are read back as UTC midnight
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
 }
 
 // StartOfWeekUTC returns Monday 00:00 UTC for the week containing t.
@@ -366,5 +386,5 @@ func StartOfWeekUTC(t time.Time) time.Time {
 	utc := t.UTC()
 	daysSinceMonday := (int(utc.Weekday()) + 6) % 7
 	monday := utc.AddDate(0, 0, -daysSinceMonday)
-	return startOfDayUTC(monday)
+	return startOfDay(monday, time.UTC)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
 	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
@@ -53,7 +54,10 @@ func TestGenerateMessageFallsBackToDeepseek(t *testing.T) {
 	gemini := &mockLLM{err: context.DeadlineExceeded}
 	deepseek := &mockLLM{response: "Hi Sarah! DeepSeek welcome."}
 
-	svc := NewService(nil, gemini, deepseek)
+	svc := NewService(nil, llm.NewRouter(llm.RouterConfig{},
+		llm.Provider{Name: "gemini", Client: gemini},
+		llm.Provider{Name: "deepseek", Client: deepseek},
+	))
 	message, source, err := svc.generateMessage(context.Background(), user, "No detailed health records yet.")
 
 	if err != nil {
@@ -77,7 +81,10 @@ func TestGenerateMessageUsesGeminiWhenAvailable(t *testing.T) {
 	gemini := &mockLLM{response: "Hi Sarah! Gemini welcome."}
 	deepseek := &mockLLM{response: "should not be used"}
 
-	svc := NewService(nil, gemini, deepseek)
+	svc := NewService(nil, llm.NewRouter(llm.RouterConfig{},
+		llm.Provider{Name: "gemini", Client: gemini},
+		llm.Provider{Name: "deepseek", Client: deepseek},
+	))
 	message, source, err := svc.generateMessage(context.Background(), user, "context")
 
 	if err != nil {
@@ -94,6 +101,21 @@ func TestGenerateMessageUsesGeminiWhenAvailable(t *testing.T) {
 	}
 }
 
+func TestGenerateMessageWithoutLLMUsesFallback(t *testing.T) {
+	name := "Sarah"
+	user := &db.User{Name: &name}
+
+	svc := NewService(nil, nil)
+	message, source, err := svc.generateMessage(context.Background(), user, "context")
+
+	if err == nil {
+		t.Fatal("expected error when no LLM is configured")
+	}
+	if source != "fallback" || !strings.Contains(message, "Sarah") {
+		t.Fatalf("unexpected fallback: source=%q message=%q", source, message)
+	}
+}
+
 func TestFallbackWelcomeUsesFirstName(t *testing.T) {
 	name := "Sarah Johnson"
 	week := 32
@@ -118,3 +140,27 @@ func TestDisplayFirstName(t *testing.T) {
 		t.Fatalf("got %q", got)
 	}
 }
+
+func TestStartOfDayUsesUserTimezone(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	// 05:00 UTC on the 10th is still the evening of the 9th in Los Angeles
+	now := time.Date(2026, 3, 10, 5, 0, 0, 0, time.UTC)
+
+	got := startOfDay(now, la)
+	want := time.Date(2026, 3, 9, 0, 0, 0, 0, la)
+	if !got.Equal(want) {
+		t.Errorf("startOfDay = %v, want %v", got, want)
+	}
+	if got.Format("2006-01-02") != "2026-03-09" {
+		t.Errorf("cache date = %s, want 2026-03-09", got.Format("2006-01-02"))
+	}
+
+	// A DATE column read back as UTC midnight is that date's midnight in LA
+	stored := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	if !dateIn(stored, la).Equal(want) {
+		t.Errorf("dateIn = %v, want %v", dateIn(stored, la), want)
+	}
+}
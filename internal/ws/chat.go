@@ -1,9 +1,13 @@
 package ws
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -12,6 +16,7 @@ import (
 	"github.com/themobileprof/momlaunchpad-be/internal/calendar"
 	"github.com/themobileprof/momlaunchpad-be/internal/chat"
 	"github.com/themobileprof/momlaunchpad-be/internal/db"
+	"github.com/themobileprof/momlaunchpad-be/internal/redflag"
 	"github.com/themobileprof/momlaunchpad-be/internal/subscription"
 )
 
@@ -28,6 +33,7 @@ type ChatHandler struct {
 	jwtSecret       string
 	wsLimiterPerMin int
 	subManager      *subscription.Manager
+	suggestions     *calendar.Resolver
 }
 
 // NewChatHandler creates a new chat handler
@@ -43,18 +49,33 @@ func NewChatHandler(
 		jwtSecret:       jwtSecret,
 		wsLimiterPerMin: 10,
 		subManager:      subMgr,
+		suggestions:     calendar.NewResolver(database),
 	}
 }
 
+// Incoming message types; an empty type is a chat message
+const (
+	TypeSuggestionAccept  = "suggestion_accept"
+	TypeSuggestionDismiss = "suggestion_dismiss"
+)
+
 // IncomingMessage represents a message from the client
 type IncomingMessage struct {
+	Type           string `json:"type,omitempty"`
 	Content        string `json:"content"`
 	ConversationID string `json:"conversation_id,omitempty"`
+	Stream         bool   `json:"stream,omitempty"` // Opt in to "delta" messages
+
+	// Accepting or dismissing a calendar suggestion; Edit changes it first
+	SuggestionID string         `json:"suggestion_id,omitempty"`
+	Edit         *calendar.Edit `json:"edit,omitempty"`
 }
 
-// OutgoingMessage represents a message to the client
+// OutgoingMessage represents a message to the client.
+// When streaming, "delta" messages carry incremental text; a "message" that
+// follows deltas replaces the streamed draft (used for fallbacks).
 type OutgoingMessage struct {
-	Type           string      `json:"type"` // "message", "calendar", "error", "done"
+	Type           string      `json:"type"` // "message", "delta", "calendar", "suggestion_accepted", "suggestion_dismissed", "emergency", "error", "done"
 	Content        string      `json:"content,omitempty"`
 	Data           interface{} `json:"data,omitempty"`
 	ConversationID string      `json:"conversation_id,omitempty"`
@@ -92,6 +113,7 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 		return
 	}
 	defer conn.Close()
+	out := &wsWriter{conn: conn}
 
 	userID := claims.UserID
 
@@ -103,6 +125,17 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 	}
 
 	userLanguage := user.Language
+	countryCode := ""
+	if user.CountryCode != nil {
+		countryCode = *user.CountryCode
+	}
+
+	// Calendar suggestions are resolved in the client's time zone
+	// (?tz=Africa/Lagos), defaulting to the one saved on the profile
+	timezone := c.Query("tz")
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		timezone = user.Timezone
+	}
 
 	log.Printf("WebSocket connected: user=%s, language=%s", userID, userLanguage)
 
@@ -121,24 +154,30 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 
 		// Rate limiting
 		if !wsLimiter.Allow() {
-			_ = h.sendError(conn, "Too many messages. Please slow down.")
+			_ = h.sendError(out, "Too many messages. Please slow down.")
 			continue
 		}
 
-		// Check quota before processing
-		withinQuota, err := h.subManager.CheckQuota(c.Request.Context(), userID, "chat")
-		if err != nil {
-			log.Printf("Error checking quota for user %s: %v", userID, err)
-			_ = h.sendError(conn, "Sorry, I encountered an error checking your quota.")
+		// Suggestion responses are not chat messages and use no quota
+		if msg.Type == TypeSuggestionAccept || msg.Type == TypeSuggestionDismiss {
+			h.resolveSuggestion(c.Request.Context(), out, userID, msg)
 			continue
 		}
-		if !withinQuota {
-			_ = h.sendError(conn, "You've reached your message quota for this period. Please upgrade your plan or try again later.")
+
+		// Reserve quota before processing; released again if processing fails
+		reservation, err := h.subManager.Reserve(c.Request.Context(), userID, "chat")
+		if errors.Is(err, subscription.ErrQuotaExceeded) || errors.Is(err, subscription.ErrNoAccess) {
+			_ = h.sendError(out, "You've reached your message quota for this period. Please upgrade your plan or try again later.")
+			continue
+		}
+		if err != nil {
+			log.Printf("Error reserving quota for user %s: %v", userID, err)
+			_ = h.sendError(out, "Sorry, I encountered an error checking your quota.")
 			continue
 		}
 
 		// Create WebSocket responder
-		responder := &wsResponder{conn: conn}
+		responder := &wsResponder{out: out}
 
 		// Delegate to transport-agnostic engine
 		req := chat.ProcessRequest{
@@ -147,25 +186,41 @@ func (h *ChatHandler) HandleChat(c *gin.Context) {
 			Message:        msg.Content,
 			Language:       userLanguage,
 			Responder:      responder,
+			Stream:         msg.Stream,
+			CountryCode:    countryCode,
+			Timezone:       timezone,
 		}
 
 		if _, err := h.engine.ProcessMessage(c.Request.Context(), req); err != nil {
 			log.Printf("Error processing message: %v", err)
-			_ = h.sendError(conn, "Sorry, I encountered an error processing your message.")
+			if err := reservation.Release(context.WithoutCancel(c.Request.Context())); err != nil {
+				log.Printf("Error releasing quota for user %s: %v", userID, err)
+			}
+			_ = h.sendError(out, "Sorry, I encountered an error processing your message.")
 			continue
 		}
-
-		// Increment usage after successful processing
-		if err := h.subManager.IncrementUsage(c.Request.Context(), userID, "chat"); err != nil {
-			log.Printf("Error incrementing usage for user %s: %v", userID, err)
-			// Don't fail the request, just log the error
+		if err := reservation.Commit(context.WithoutCancel(c.Request.Context())); err != nil {
+			log.Printf("Error committing quota for user %s: %v", userID, err)
 		}
 	}
 }
 
+// wsWriter serializes writes to a connection; gorilla/websocket allows only
+// one concurrent writer and title updates arrive from a background goroutine.
+type wsWriter struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (w *wsWriter) WriteJSON(v interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.WriteJSON(v)
+}
+
 // wsResponder implements chat.Responder for WebSocket transport
 type wsResponder struct {
-	conn           *websocket.Conn
+	out            *wsWriter
 	conversationID string
 }
 
@@ -174,23 +229,39 @@ func (w *wsResponder) SetConversationID(id string) {
 }
 
 func (w *wsResponder) SendMessage(content string) error {
-	return w.conn.WriteJSON(OutgoingMessage{
+	return w.out.WriteJSON(OutgoingMessage{
 		Type:           "message",
 		Content:        content,
 		ConversationID: w.conversationID,
 	})
 }
 
+func (w *wsResponder) SendDelta(content string) error {
+	return w.out.WriteJSON(OutgoingMessage{
+		Type:           "delta",
+		Content:        content,
+		ConversationID: w.conversationID,
+	})
+}
+
 func (w *wsResponder) SendCalendarSuggestion(suggestion calendar.Suggestion) error {
-	return w.conn.WriteJSON(OutgoingMessage{
+	return w.out.WriteJSON(OutgoingMessage{
 		Type:           "calendar",
 		Data:           suggestion,
 		ConversationID: w.conversationID,
 	})
 }
 
+func (w *wsResponder) SendEmergency(alert redflag.Alert) error {
+	return w.out.WriteJSON(OutgoingMessage{
+		Type:           "emergency",
+		Data:           alert,
+		ConversationID: w.conversationID,
+	})
+}
+
 func (w *wsResponder) SendError(message string) error {
-	return w.conn.WriteJSON(OutgoingMessage{
+	return w.out.WriteJSON(OutgoingMessage{
 		Type:           "error",
 		Content:        message,
 		ConversationID: w.conversationID,
@@ -198,23 +269,72 @@ func (w *wsResponder) SendError(message string) error {
 }
 
 func (w *wsResponder) SendDone() error {
-	return w.conn.WriteJSON(OutgoingMessage{
+	return w.out.WriteJSON(OutgoingMessage{
 		Type:           "done",
 		ConversationID: w.conversationID,
 	})
 }
 
 func (w *wsResponder) SendTitleUpdated(title string) error {
-	return w.conn.WriteJSON(OutgoingMessage{
+	return w.out.WriteJSON(OutgoingMessage{
 		Type:           "title_updated",
 		Content:        title,
 		ConversationID: w.conversationID,
 	})
 }
 
+// resolveSuggestion accepts or dismisses a calendar suggestion, replying
+// with "suggestion_accepted" (the reminder) or "suggestion_dismissed"
+func (h *ChatHandler) resolveSuggestion(ctx context.Context, out *wsWriter, userID string, msg IncomingMessage) {
+	if msg.SuggestionID == "" {
+		_ = h.sendError(out, "suggestion_id is required")
+		return
+	}
+
+	if msg.Type == TypeSuggestionDismiss {
+		if err := h.suggestions.Dismiss(ctx, userID, msg.SuggestionID); err != nil {
+			_ = h.sendError(out, suggestionErrorMessage(err))
+			return
+		}
+		_ = out.WriteJSON(OutgoingMessage{
+			Type: "suggestion_dismissed",
+			Data: gin.H{"suggestion_id": msg.SuggestionID},
+		})
+		return
+	}
+
+	var edit calendar.Edit
+	if msg.Edit != nil {
+		edit = *msg.Edit
+	}
+	reminder, err := h.suggestions.Accept(ctx, userID, msg.SuggestionID, edit)
+	if err != nil {
+		_ = h.sendError(out, suggestionErrorMessage(err))
+		return
+	}
+	_ = out.WriteJSON(OutgoingMessage{
+		Type: "suggestion_accepted",
+		Data: gin.H{"suggestion_id": msg.SuggestionID, "reminder": reminder},
+	})
+}
+
+func suggestionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return "Suggestion not found"
+	case errors.Is(err, db.ErrSuggestionResolved):
+		return "Suggestion was already accepted or dismissed"
+	case errors.Is(err, calendar.ErrInvalidEdit):
+		return err.Error()
+	default:
+		log.Printf("Error resolving calendar suggestion: %v", err)
+		return "Sorry, I couldn't update that suggestion."
+	}
+}
+
 // sendError is a helper for handler-level errors
-func (h *ChatHandler) sendError(conn *websocket.Conn, message string) error {
-	return conn.WriteJSON(OutgoingMessage{
+func (h *ChatHandler) sendError(out *wsWriter, message string) error {
+	return out.WriteJSON(OutgoingMessage{
 		Type:    "error",
 		Content: message,
 	})
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS red_flag_escalations;
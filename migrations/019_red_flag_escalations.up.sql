@@ -0,0 +1,23 @@
+-- Red-flag escalations raised by the deterministic safety check in chat
+CREATE TABLE IF NOT EXISTS red_flag_escalations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    category VARCHAR(50) NOT NULL,
+    matched_phrase TEXT NOT NULL,
+    language VARCHAR(10) NOT NULL DEFAULT 'en',
+    country_code VARCHAR(2),
+    response TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_red_flag_escalations_status
+    ON red_flag_escalations(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_red_flag_escalations_user
+    ON red_flag_escalations(user_id, created_at DESC);
@@ -0,0 +1,19 @@
+-- Token usage and estimated cost for every LLM call
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    plan_code TEXT, -- Plan at the time of the call (NULL = no active subscription)
+    provider VARCHAR(32) NOT NULL,
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    task VARCHAR(32) NOT NULL,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    tokens_estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    cost_usd NUMERIC(14, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_created
+    ON llm_usage(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_user
+    ON llm_usage(user_id, created_at DESC);
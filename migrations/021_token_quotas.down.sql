@@ -0,0 +1,2 @@
+ALTER TABLE feature_usage DROP COLUMN IF EXISTS tokens_used;
+ALTER TABLE plan_features DROP COLUMN IF EXISTS token_limit;
@@ -0,0 +1,3 @@
+-- Optional per-period LLM token budgets alongside message quotas
+ALTER TABLE plan_features ADD COLUMN IF NOT EXISTS token_limit BIGINT; -- NULL = no token budget
+ALTER TABLE feature_usage ADD COLUMN IF NOT EXISTS tokens_used BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Embedded chat messages and doctor visit notes for long-term semantic recall
+CREATE TABLE IF NOT EXISTS memory_embeddings (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Exactly one source is set; deleting the source deletes its embedding
+    message_id UUID UNIQUE REFERENCES messages(id) ON DELETE CASCADE,
+    doctor_visit_id UUID UNIQUE REFERENCES doctor_visits(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    model VARCHAR(100) NOT NULL, -- Vectors are only comparable within one model
+    embedding REAL[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((message_id IS NULL) <> (doctor_visit_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_embeddings_user
+    ON memory_embeddings(user_id, model, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS memory_reset_at;
+ALTER TABLE user_facts DROP COLUMN IF EXISTS source;
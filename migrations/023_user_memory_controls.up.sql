@@ -0,0 +1,6 @@
+-- Track where each fact came from and let users wipe the assistant's memory
+ALTER TABLE user_facts ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'chat'; -- chat, profile or user
+UPDATE user_facts SET source = 'profile' WHERE confidence >= 1.0;
+
+-- Messages before this are no longer loaded into the assistant's short-term memory
+ALTER TABLE users ADD COLUMN IF NOT EXISTS memory_reset_at TIMESTAMP;
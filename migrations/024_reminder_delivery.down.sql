@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reminder_deliveries;
+DROP TABLE IF EXISTS push_devices;
+DROP TABLE IF EXISTS notification_preferences;
+DROP INDEX IF EXISTS idx_reminders_next_notify;
+ALTER TABLE reminders DROP COLUMN IF EXISTS next_notify_at;
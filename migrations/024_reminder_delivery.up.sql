@@ -0,0 +1,51 @@
+-- Reminder delivery: when each reminder is next due to be sent, and where to send it
+
+-- NULL once the reminder has been queued for delivery (or is not due to notify)
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS next_notify_at TIMESTAMP;
+UPDATE reminders SET next_notify_at = reminder_time
+WHERE next_notify_at IS NULL AND is_completed = FALSE AND reminder_time > CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_reminders_next_notify
+    ON reminders(next_notify_at)
+    WHERE next_notify_at IS NOT NULL;
+
+-- Per-user channel preferences (a missing row means the defaults below)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sms_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    sms_phone_number VARCHAR(20),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Push notification targets registered by the mobile apps
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(10) NOT NULL CHECK (provider IN ('fcm', 'apns')),
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user ON push_devices(user_id);
+
+-- One row per reminder occurrence and channel; workers claim rows with FOR UPDATE SKIP LOCKED
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMP NOT NULL, -- The occurrence being announced
+    channel VARCHAR(10) NOT NULL, -- push, email, sms
+    status VARCHAR(10) NOT NULL DEFAULT 'pending', -- pending, sending, retry, sent, failed, skipped
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (reminder_id, scheduled_for, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminder_deliveries_due
+    ON reminder_deliveries(next_attempt_at)
+    WHERE status IN ('pending', 'sending', 'retry');
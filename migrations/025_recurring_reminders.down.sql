@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reminder_occurrences;
+ALTER TABLE reminders DROP COLUMN IF EXISTS timezone;
+ALTER TABLE reminders DROP COLUMN IF EXISTS rrule;
@@ -0,0 +1,14 @@
+-- Recurring reminders: an RFC 5545 RRULE expanded from reminder_time on the
+-- wall clock of the reminder's time zone
+
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS rrule TEXT;
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Completion of individual occurrences of a recurring reminder
+-- (reminders.is_completed ends the whole series)
+CREATE TABLE IF NOT EXISTS reminder_occurrences (
+    reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    occurrence_time TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (reminder_id, occurrence_time)
+);
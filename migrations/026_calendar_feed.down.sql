@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reminders_user_ical_uid;
+ALTER TABLE reminders DROP COLUMN IF EXISTS ical_uid;
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,13 @@
+-- iCalendar subscription feeds (one secret URL per user; rotating replaces it)
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 of the token in the URL
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_accessed_at TIMESTAMP
+);
+
+-- UID of reminders imported from an ICS file, so importing it again updates them
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS ical_uid VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_user_ical_uid
+    ON reminders(user_id, ical_uid)
+    WHERE ical_uid IS NOT NULL;
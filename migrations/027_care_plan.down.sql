@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reminders_user_care_plan_item;
+ALTER TABLE reminders DROP COLUMN IF EXISTS care_plan_item;
+ALTER TABLE reminders DROP COLUMN IF EXISTS source;
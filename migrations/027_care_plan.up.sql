@@ -0,0 +1,10 @@
+-- Where a reminder came from: 'user' (created by the user, imported or from
+-- a community event) or 'care_plan' (generated from the journey stage)
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'user';
+
+-- Care plan template item a generated reminder is for, so regenerating the
+-- plan updates reminders instead of duplicating them
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS care_plan_item VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_user_care_plan_item
+    ON reminders(user_id, care_plan_item)
+    WHERE care_plan_item IS NOT NULL;
@@ -0,0 +1,29 @@
+-- Calendar suggestions offered in chat, so clients can accept or dismiss them
+-- by ID and acceptance can be measured
+CREATE TABLE IF NOT EXISTS calendar_suggestions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    type VARCHAR(32) NOT NULL,
+    visit_type VARCHAR(32),
+    priority VARCHAR(16),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    suggested_time TIMESTAMP NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    location VARCHAR(255),
+    provider VARCHAR(255),
+    time_source VARCHAR(16) NOT NULL DEFAULT 'default',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'dismissed')),
+    edited BOOLEAN NOT NULL DEFAULT FALSE,
+    reminder_id UUID REFERENCES reminders(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_suggestions_user
+    ON calendar_suggestions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_calendar_suggestions_created
+    ON calendar_suggestions(created_at);
@@ -0,0 +1,15 @@
+ALTER TABLE calendar_suggestions
+    ALTER COLUMN suggested_time TYPE TIMESTAMP USING suggested_time AT TIME ZONE 'UTC';
+
+ALTER TABLE reminder_deliveries
+    ALTER COLUMN scheduled_for TYPE TIMESTAMP USING scheduled_for AT TIME ZONE 'UTC',
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMP USING next_attempt_at AT TIME ZONE 'UTC';
+
+ALTER TABLE reminder_occurrences
+    ALTER COLUMN occurrence_time TYPE TIMESTAMP USING occurrence_time AT TIME ZONE 'UTC';
+
+ALTER TABLE reminders
+    ALTER COLUMN reminder_time TYPE TIMESTAMP USING reminder_time AT TIME ZONE 'UTC',
+    ALTER COLUMN next_notify_at TYPE TIMESTAMP USING next_notify_at AT TIME ZONE 'UTC';
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
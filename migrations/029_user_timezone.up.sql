@@ -0,0 +1,18 @@
+-- Per-user IANA timezone, set at onboarding, so quota periods, welcome
+-- messages and reminders follow the user's local day
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Reminder times were stored as UTC wall-clock TIMESTAMPs; make them absolute
+ALTER TABLE reminders
+    ALTER COLUMN reminder_time TYPE TIMESTAMPTZ USING reminder_time AT TIME ZONE 'UTC',
+    ALTER COLUMN next_notify_at TYPE TIMESTAMPTZ USING next_notify_at AT TIME ZONE 'UTC';
+
+ALTER TABLE reminder_occurrences
+    ALTER COLUMN occurrence_time TYPE TIMESTAMPTZ USING occurrence_time AT TIME ZONE 'UTC';
+
+ALTER TABLE reminder_deliveries
+    ALTER COLUMN scheduled_for TYPE TIMESTAMPTZ USING scheduled_for AT TIME ZONE 'UTC',
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMPTZ USING next_attempt_at AT TIME ZONE 'UTC';
+
+ALTER TABLE calendar_suggestions
+    ALTER COLUMN suggested_time TYPE TIMESTAMPTZ USING suggested_time AT TIME ZONE 'UTC';
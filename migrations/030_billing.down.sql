@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS billing_payments;
+DROP TABLE IF EXISTS billing_events;
+DROP TABLE IF EXISTS billing_checkouts;
+DROP TABLE IF EXISTS plan_prices;
@@ -0,0 +1,76 @@
+-- Self-serve plan purchases through Stripe and Paystack
+
+-- What a plan costs with each provider
+CREATE TABLE IF NOT EXISTS plan_prices (
+    id SERIAL PRIMARY KEY,
+    plan_id INTEGER NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+    provider VARCHAR(16) NOT NULL, -- stripe, paystack
+    currency CHAR(3) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0), -- Minor units (cents, kobo)
+    billing_interval VARCHAR(8) NOT NULL DEFAULT 'month'
+        CHECK (billing_interval IN ('month', 'year')),
+    provider_price_id VARCHAR(128), -- Stripe price ID or Paystack plan code
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (plan_id, provider)
+);
+
+-- A checkout started by a user; webhooks are matched to it by reference, or
+-- later by the provider's customer and subscription IDs
+CREATE TABLE IF NOT EXISTS billing_checkouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reference VARCHAR(64) NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_code TEXT NOT NULL,
+    provider VARCHAR(16) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    amount BIGINT NOT NULL,
+    billing_interval VARCHAR(8) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    provider_customer_id VARCHAR(128),
+    provider_subscription_id VARCHAR(128),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_checkouts_user ON billing_checkouts(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_billing_checkouts_subscription
+    ON billing_checkouts(provider, provider_subscription_id) WHERE provider_subscription_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_billing_checkouts_customer
+    ON billing_checkouts(provider, provider_customer_id) WHERE provider_customer_id IS NOT NULL;
+
+-- Every webhook received, so redelivered events are applied once
+CREATE TABLE IF NOT EXISTS billing_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(16) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'received'
+        CHECK (status IN ('received', 'processed', 'ignored', 'failed')),
+    error TEXT,
+    deliveries INTEGER NOT NULL DEFAULT 1,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ,
+    UNIQUE (provider, event_id)
+);
+
+-- Payments shown in the user's billing history
+CREATE TABLE IF NOT EXISTS billing_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    checkout_id UUID REFERENCES billing_checkouts(id) ON DELETE SET NULL,
+    provider VARCHAR(16) NOT NULL,
+    provider_reference VARCHAR(128) NOT NULL, -- Invoice ID or transaction reference
+    plan_code TEXT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    amount BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    period_end TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, provider_reference, status)
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_payments_user ON billing_payments(user_id, created_at DESC);
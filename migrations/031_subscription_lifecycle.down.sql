@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS subscription_notices;
+DROP INDEX IF EXISTS idx_subscriptions_user_trial;
+DROP INDEX IF EXISTS idx_subscriptions_active_ends;
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS grace_started_at,
+    DROP COLUMN IF EXISTS is_trial;
+ALTER TABLE plans DROP COLUMN IF EXISTS trial_days;
@@ -0,0 +1,29 @@
+-- Subscription lifecycle: trials, renewal grace periods and expiry to the free plan
+
+-- Days of free trial a plan offers (0 = no trial)
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS trial_days INTEGER NOT NULL DEFAULT 0
+    CHECK (trial_days >= 0);
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS is_trial BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Set when a renewal was missed; ends_at is then the end of the grace period
+    ADD COLUMN IF NOT EXISTS grace_started_at TIMESTAMPTZ,
+    -- Set when the user or provider cancelled; the plan runs to ends_at without renewing
+    ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMPTZ;
+
+-- The expiry job scans active subscriptions by end date
+CREATE INDEX IF NOT EXISTS idx_subscriptions_active_ends
+    ON subscriptions(ends_at)
+    WHERE status = 'active' AND ends_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_trial
+    ON subscriptions(user_id, plan_id)
+    WHERE is_trial = TRUE;
+
+-- Lifecycle notices already sent, so each is sent once per subscription
+CREATE TABLE IF NOT EXISTS subscription_notices (
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL, -- trial_ending, trial_ended, grace_period, expired
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subscription_id, kind)
+);
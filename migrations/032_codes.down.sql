@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS referrals;
+DROP TABLE IF EXISTS referral_codes;
+DROP TABLE IF EXISTS code_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+DROP TABLE IF EXISTS sponsors;
+DROP TABLE IF EXISTS user_feature_grants;
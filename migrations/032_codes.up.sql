@@ -0,0 +1,76 @@
+-- Promo codes, referral credits and sponsored (NGO/employer) access
+
+-- Features granted to a user outside their plan; unmetered until expires_at
+CREATE TABLE IF NOT EXISTS user_feature_grants (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feature_id INTEGER NOT NULL REFERENCES features(id) ON DELETE CASCADE,
+    source VARCHAR(16) NOT NULL DEFAULT 'admin'
+        CHECK (source IN ('admin', 'promo', 'sponsor', 'referral')),
+    expires_at TIMESTAMPTZ, -- NULL = never
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_feature_grants_user ON user_feature_grants(user_id, feature_id);
+
+-- Organizations that pay for mothers' access, provisioned as seat codes
+CREATE TABLE IF NOT EXISTS sponsors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT 'ngo' CHECK (kind IN ('ngo', 'employer', 'other')),
+    contact_email VARCHAR(255),
+    seats INTEGER NOT NULL CHECK (seats >= 0), -- Codes that may be issued
+    plan_code TEXT NOT NULL,
+    duration_days INTEGER NOT NULL CHECK (duration_days > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Redeemable codes granting a plan or a feature for a number of days.
+-- max_redemptions = 1 is single-use; NULL is unlimited.
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(32) NOT NULL UNIQUE, -- Stored upper-case
+    description TEXT NOT NULL DEFAULT '',
+    grant_type VARCHAR(8) NOT NULL CHECK (grant_type IN ('plan', 'feature')),
+    grant_target TEXT NOT NULL, -- Plan code or feature key
+    duration_days INTEGER NOT NULL CHECK (duration_days > 0),
+    max_redemptions INTEGER CHECK (max_redemptions > 0),
+    redemption_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    sponsor_id UUID REFERENCES sponsors(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_codes_sponsor ON promo_codes(sponsor_id) WHERE sponsor_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS code_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (code_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_code_redemptions_redeemed ON code_redemptions(redeemed_at);
+
+-- Each user's referral code, created the first time they ask for it
+CREATE TABLE IF NOT EXISTS referral_codes (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A user can be referred once
+CREATE TABLE IF NOT EXISTS referrals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    referrer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referred_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals(referrer_id);
+CREATE INDEX IF NOT EXISTS idx_referrals_created ON referrals(created_at);
@@ -0,0 +1,17 @@
+-- Voice call state shared by all replicas, so a Gather callback can land on
+-- any instance and a deploy mid-call does not drop the conversation
+CREATE TABLE IF NOT EXISTS voice_sessions (
+    call_sid VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    language VARCHAR(10) NOT NULL DEFAULT 'en',
+    country_code VARCHAR(8) NOT NULL DEFAULT '',
+    from_number VARCHAR(32) NOT NULL DEFAULT '',
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    messages TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_voice_sessions_expires ON voice_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_voice_sessions_user ON voice_sessions(user_id);
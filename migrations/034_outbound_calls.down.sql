@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS outbound_calls;
+DROP TABLE IF EXISTS voice_call_preferences;
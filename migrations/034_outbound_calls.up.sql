@@ -0,0 +1,51 @@
+-- Outbound voice calls: weekly check-ins and reminder calls, with per-user
+-- opt-in and quiet hours
+
+-- A missing row means no outbound calls
+CREATE TABLE IF NOT EXISTS voice_call_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    checkin_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    checkin_weekday SMALLINT NOT NULL DEFAULT 1 CHECK (checkin_weekday BETWEEN 1 AND 7), -- ISO: 1 = Monday
+    checkin_time TIME NOT NULL DEFAULT '10:00', -- In the user's timezone
+    reminder_calls_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    quiet_start TIME NOT NULL DEFAULT '21:00',
+    quiet_end TIME NOT NULL DEFAULT '08:00',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_voice_call_preferences_checkin
+    ON voice_call_preferences(user_id)
+    WHERE checkin_enabled = TRUE;
+
+-- One row per call we place, through retries; workers claim rows with FOR UPDATE SKIP LOCKED
+CREATE TABLE IF NOT EXISTS outbound_calls (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('checkin', 'reminder')),
+    reminder_id UUID REFERENCES reminders(id) ON DELETE SET NULL,
+    scheduled_for TIMESTAMPTZ NOT NULL, -- Check-in slot or reminder occurrence
+    message TEXT NOT NULL DEFAULT '', -- What a reminder call says
+    -- pending, dialing, in_progress, retry, completed, no_answer, busy, failed, skipped
+    status VARCHAR(12) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    call_sid VARCHAR(64) UNIQUE,
+    duration_seconds INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbound_calls_checkin_slot
+    ON outbound_calls(user_id, scheduled_for)
+    WHERE kind = 'checkin';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbound_calls_reminder_occurrence
+    ON outbound_calls(reminder_id, scheduled_for)
+    WHERE kind = 'reminder';
+
+CREATE INDEX IF NOT EXISTS idx_outbound_calls_due
+    ON outbound_calls(next_attempt_at)
+    WHERE status IN ('pending', 'dialing', 'retry');
+
+CREATE INDEX IF NOT EXISTS idx_outbound_calls_created ON outbound_calls(created_at DESC);
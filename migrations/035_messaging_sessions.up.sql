@@ -0,0 +1,14 @@
+-- SMS and WhatsApp conversations: one session per phone number and channel,
+-- continuing the same chat conversation until it goes idle
+CREATE TABLE IF NOT EXISTS messaging_sessions (
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('sms', 'whatsapp')),
+    phone VARCHAR(32) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    pending_suggestion_id UUID REFERENCES calendar_suggestions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (channel, phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_messaging_sessions_user ON messaging_sessions(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE voice_sessions DROP COLUMN IF EXISTS pin_attempts;
+ALTER TABLE voice_sessions DROP COLUMN IF EXISTS pin_pending;
+DROP TABLE IF EXISTS phone_verifications;
+DROP TABLE IF EXISTS user_phones;
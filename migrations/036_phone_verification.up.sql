@@ -0,0 +1,24 @@
+-- Verified phone numbers identify callers and texters on voice, SMS and
+-- WhatsApp. A number belongs to at most one user once verified.
+CREATE TABLE IF NOT EXISTS user_phones (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    phone_number VARCHAR(16) NOT NULL UNIQUE, -- E.164
+    verified_at TIMESTAMPTZ NOT NULL,
+    voice_pin_hash TEXT, -- Optional PIN callers enter to confirm identity on shared phones
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One pending one-time code per user
+CREATE TABLE IF NOT EXISTS phone_verifications (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    phone_number VARCHAR(16) NOT NULL,
+    code_hash TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    sent_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Voice calls from a phone with a PIN wait for it before the conversation starts
+ALTER TABLE voice_sessions ADD COLUMN IF NOT EXISTS pin_pending BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE voice_sessions ADD COLUMN IF NOT EXISTS pin_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE user_phones DROP COLUMN IF EXISTS pin_failed_since;
+ALTER TABLE user_phones DROP COLUMN IF EXISTS pin_failures;
@@ -0,0 +1,4 @@
+-- Wrong voice PINs are counted per user across calls, so redialling does not
+-- reset the limit. pin_failed_since starts the lockout window.
+ALTER TABLE user_phones ADD COLUMN IF NOT EXISTS pin_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_phones ADD COLUMN IF NOT EXISTS pin_failed_since TIMESTAMPTZ;
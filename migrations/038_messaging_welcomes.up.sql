@@ -0,0 +1,8 @@
+-- When a number that isn't linked to an account was last sent the welcome
+-- message, so repeated texts from it don't each trigger a paid reply
+CREATE TABLE IF NOT EXISTS messaging_welcomes (
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('sms', 'whatsapp')),
+    phone VARCHAR(32) NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (channel, phone)
+);
@@ -0,0 +1,5 @@
+UPDATE billing_events SET status = 'received' WHERE status = 'processing';
+ALTER TABLE billing_events DROP CONSTRAINT IF EXISTS billing_events_status_check;
+ALTER TABLE billing_events ADD CONSTRAINT billing_events_status_check
+    CHECK (status IN ('received', 'processed', 'ignored', 'failed'));
+ALTER TABLE billing_events DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,7 @@
+-- A webhook delivery claims its event (status 'processing') before applying
+-- it, so a provider retry arriving meanwhile is not applied a second time.
+-- claimed_at lets a claim left behind by a crashed worker be taken over.
+ALTER TABLE billing_events ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
+ALTER TABLE billing_events DROP CONSTRAINT IF EXISTS billing_events_status_check;
+ALTER TABLE billing_events ADD CONSTRAINT billing_events_status_check
+    CHECK (status IN ('received', 'processing', 'processed', 'ignored', 'failed'));
@@ -0,0 +1 @@
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS sms_phone_number VARCHAR(20);
@@ -0,0 +1,3 @@
+-- SMS reminders go to the user's verified number in user_phones, like calls,
+-- instead of a number typed into the settings that was never confirmed.
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS sms_phone_number;
@@ -80,8 +80,9 @@ func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatReque
 		req.Model = c.model
 	}
 
-	// Force streaming
+	// Force streaming, with token usage in the final chunk
 	req.Stream = true
+	req.StreamOptions = &llm.StreamOptions{IncludeUsage: true}
 
 	// Prepare request body
 	body, err := json.Marshal(req)
@@ -170,6 +171,7 @@ func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*
 
 	// Force non-streaming
 	req.Stream = false
+	req.StreamOptions = nil
 
 	// Prepare request body
 	body, err := json.Marshal(req)
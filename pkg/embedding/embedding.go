@@ -0,0 +1,130 @@
+// Package embedding turns text into vectors for semantic retrieval.
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Embedder converts texts into fixed-size vectors.
+type Embedder interface {
+	// Embed returns one vector per input text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model identifies the vector space; vectors from different models are not comparable.
+	Model() string
+}
+
+// HashEmbedder is a deterministic, offline embedder based on feature hashing
+// of word stems. It captures lexical overlap only, which is enough for tests
+// and as a zero-cost default when no embedding API is configured.
+type HashEmbedder struct {
+	dims int
+}
+
+// Ensure HashEmbedder implements Embedder
+var _ Embedder = (*HashEmbedder)(nil)
+
+// NewHashEmbedder creates a hashing embedder. Default: 256 dimensions.
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+// Model implements Embedder.Model
+func (h *HashEmbedder) Model() string {
+	return "hash-" + strconv.Itoa(h.dims)
+}
+
+// Embed implements Embedder.Embed
+func (h *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = h.embed(text)
+	}
+	return vectors, nil
+}
+
+func (h *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, h.dims)
+	for _, word := range tokenize(text) {
+		hasher := fnv.New32a()
+		_, _ = hasher.Write([]byte(word))
+		sum := hasher.Sum32()
+		// The top bit picks the sign so collisions tend to cancel out
+		if sum&(1<<31) != 0 {
+			vec[int(sum%uint32(h.dims))]--
+		} else {
+			vec[int(sum%uint32(h.dims))]++
+		}
+	}
+	normalize(vec)
+	return vec
+}
+
+// stopwords are frequent English/Spanish words that carry no topic.
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"have": true, "has": true, "was": true, "are": true, "you": true, "your": true,
+	"what": true, "can": true, "how": true, "but": true, "not": true, "about": true,
+	"from": true, "been": true, "will": true, "would": true, "should": true, "there": true,
+	"they": true, "them": true, "she": true, "her": true, "him": true, "his": true,
+	"que": true, "los": true, "las": true, "por": true, "con": true, "una": true,
+	"para": true, "como": true, "pero": true, "mas": true, "del": true, "esta": true,
+}
+
+// tokenize lowercases text, drops short and stop words and applies a crude
+// stem (first 6 runes) so "diabetes"/"diabetic" land in the same bucket.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len([]rune(f)) < 3 || stopwords[f] {
+			continue
+		}
+		if r := []rune(f); len(r) > 6 {
+			f = string(r[:6])
+		}
+		words = append(words, f)
+	}
+	return words
+}
+
+// Cosine returns the cosine similarity of two vectors (0 if either is empty
+// or their sizes differ).
+func Cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func normalize(vec []float32) {
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+}
@@ -0,0 +1,83 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHashEmbedder_Deterministic(t *testing.T) {
+	e := NewHashEmbedder(0)
+	a, _ := e.Embed(context.Background(), []string{"I have gestational diabetes"})
+	b, _ := e.Embed(context.Background(), []string{"I have gestational diabetes"})
+
+	if len(a[0]) != 256 || e.Model() != "hash-256" {
+		t.Fatalf("dims = %d, model = %s", len(a[0]), e.Model())
+	}
+	if Cosine(a[0], b[0]) < 0.999 {
+		t.Errorf("same text should embed identically, cosine = %v", Cosine(a[0], b[0]))
+	}
+}
+
+func TestHashEmbedder_RanksRelatedTextHigher(t *testing.T) {
+	e := NewHashEmbedder(512)
+	vecs, _ := e.Embed(context.Background(), []string{
+		"What foods are safe with my diabetes?",
+		"My doctor said I have gestational diabetes",
+		"We painted the nursery yellow last weekend",
+	})
+
+	related := Cosine(vecs[0], vecs[1])
+	unrelated := Cosine(vecs[0], vecs[2])
+	if related <= unrelated || related <= 0 {
+		t.Errorf("related = %v, unrelated = %v", related, unrelated)
+	}
+}
+
+func TestCosine_MismatchedVectors(t *testing.T) {
+	if got := Cosine([]float32{1, 0}, []float32{1}); got != 0 {
+		t.Errorf("cosine of mismatched sizes = %v, want 0", got)
+	}
+	if got := Cosine([]float32{0, 0}, []float32{1, 0}); got != 0 {
+		t.Errorf("cosine with zero vector = %v, want 0", got)
+	}
+}
+
+func TestHTTPEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request: %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req embeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("unexpected body: %+v", req)
+		}
+		// Out of order on purpose: results are matched by index
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	e := NewHTTPEmbedder(Config{APIKey: "key", BaseURL: server.URL + "/v1/", Model: "nomic-embed-text"})
+	vecs, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vecs[0][0] != 1 || vecs[1][1] != 1 {
+		t.Errorf("vectors = %v", vecs)
+	}
+}
+
+func TestHTTPEmbedder_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad key", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	e := NewHTTPEmbedder(Config{BaseURL: server.URL})
+	if _, err := e.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
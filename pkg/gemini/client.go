@@ -104,6 +104,14 @@ type geminiResponse struct {
 	} `json:"usageMetadata"`
 }
 
+// modelFor returns the request's model, or the client default when unset
+func (c *HTTPClient) modelFor(req llm.ChatRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return c.model
+}
+
 // Helper to convert llm.ChatRequest to Gemini request
 func (c *HTTPClient) toGeminiRequest(req llm.ChatRequest) geminiRequest {
 	contents := make([]geminiContent, len(req.Messages))
@@ -143,7 +151,8 @@ func (c *HTTPClient) toGeminiRequest(req llm.ChatRequest) geminiRequest {
 // StreamChatCompletion implements llm.Client.StreamChatCompletion
 func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatRequest) (<-chan llm.ChatChunk, error) {
 	// Gemini streaming endpoint: ...:streamGenerateContent
-	url := fmt.Sprintf("%s/%s:streamGenerateContent?key=%s&alt=sse", c.baseURL, c.model, c.apiKey)
+	model := c.modelFor(req)
+	url := fmt.Sprintf("%s/%s:streamGenerateContent?key=%s&alt=sse", c.baseURL, model, c.apiKey)
 
 	gemReq := c.toGeminiRequest(req)
 	body, err := json.Marshal(gemReq)
@@ -200,14 +209,28 @@ func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatReque
 			}
 
 			// Convert to llm.ChatChunk
+			var usage *llm.Usage
+			if gResp.UsageMetadata.TotalTokenCount > 0 {
+				usage = &llm.Usage{
+					PromptTokens:     gResp.UsageMetadata.PromptTokenCount,
+					CompletionTokens: gResp.UsageMetadata.CandidatesTokenCount,
+					TotalTokens:      gResp.UsageMetadata.TotalTokenCount,
+				}
+			}
+
 			if len(gResp.Candidates) > 0 {
 				content := ""
 				if len(gResp.Candidates[0].Content.Parts) > 0 {
 					content = gResp.Candidates[0].Content.Parts[0].Text
 				}
 
+				var finishReason *string
+				if reason := gResp.Candidates[0].FinishReason; reason != "" {
+					finishReason = &reason
+				}
+
 				chunk := llm.ChatChunk{
-					Model: c.model,
+					Model: model,
 					Choices: []struct {
 						Index        int       `json:"index"`
 						Delta        llm.Delta `json:"delta"`
@@ -217,8 +240,10 @@ func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatReque
 							Delta: llm.Delta{
 								Content: content,
 							},
+							FinishReason: finishReason,
 						},
 					},
+					Usage: usage,
 				}
 
 				select {
@@ -236,7 +261,8 @@ func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatReque
 // ChatCompletion implements llm.Client.ChatCompletion
 func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
 	// Gemini content generation endpoint: ...:generateContent
-	url := fmt.Sprintf("%s/%s:generateContent?key=%s", c.baseURL, c.model, c.apiKey)
+	model := c.modelFor(req)
+	url := fmt.Sprintf("%s/%s:generateContent?key=%s", c.baseURL, model, c.apiKey)
 
 	gemReq := c.toGeminiRequest(req)
 	body, err := json.Marshal(gemReq)
@@ -277,7 +303,7 @@ func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*
 	}
 
 	return &llm.ChatResponse{
-		Model: c.model,
+		Model: model,
 		Choices: []struct {
 			Index   int `json:"index"`
 			Message struct {
@@ -297,11 +323,7 @@ func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*
 				FinishReason: finishReason,
 			},
 		},
-		Usage: struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		}{
+		Usage: llm.Usage{
 			PromptTokens:     gResp.UsageMetadata.PromptTokenCount,
 			CompletionTokens: gResp.UsageMetadata.CandidatesTokenCount,
 			TotalTokens:      gResp.UsageMetadata.TotalTokenCount,
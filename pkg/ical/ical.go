@@ -0,0 +1,249 @@
+// Package ical reads and writes iCalendar (RFC 5545) files: enough of the
+// format for calendar subscription feeds and importing events.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeFormat    = "20060102T150405"
+	dateTimeUTCFormat = "20060102T150405Z"
+	dateFormat        = "20060102"
+)
+
+// Event is a VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time // Zero for none
+	AllDay      bool      // Start and End are dates
+	TZID        string    // Time zone the times are written in (and were read in); "" for UTC
+	RRule       string    // Without the "RRULE:" prefix
+	Status      string    // CONFIRMED, TENTATIVE or CANCELLED
+	URL         string
+	Updated     time.Time // LAST-MODIFIED
+}
+
+// Calendar is a VCALENDAR
+type Calendar struct {
+	ProdID  string
+	Name    string        // X-WR-CALNAME
+	Refresh time.Duration // Suggested polling interval for subscribers
+	Events  []Event
+}
+
+// WriteTo writes the calendar with a VTIMEZONE for every zone its events use.
+// stamp is the DTSTAMP of every event (the time the feed was generated).
+func (cal *Calendar) WriteTo(w io.Writer, stamp time.Time) error {
+	lw := &lineWriter{w: w}
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + cal.ProdID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		lw.line("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+	if cal.Refresh > 0 {
+		lw.line("REFRESH-INTERVAL;VALUE=DURATION:" + formatDuration(cal.Refresh))
+		lw.line("X-PUBLISHED-TTL:" + formatDuration(cal.Refresh))
+	}
+
+	for _, tz := range cal.timezones(stamp) {
+		writeTimezone(lw, tz.loc, tz.from, tz.to)
+	}
+	for i := range cal.Events {
+		writeEvent(lw, &cal.Events[i], stamp)
+	}
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+type zoneRange struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// timezones returns the zones used by events, each with the span its
+// VTIMEZONE must cover: from the first event to two years after the last
+// (or after stamp, for recurring events that run on).
+func (cal *Calendar) timezones(stamp time.Time) []zoneRange {
+	ranges := make(map[string]*zoneRange)
+	for _, e := range cal.Events {
+		if e.TZID == "" || e.TZID == "UTC" || e.AllDay {
+			continue
+		}
+		loc, err := time.LoadLocation(e.TZID)
+		if err != nil {
+			continue
+		}
+		last := e.Start
+		if e.RRule != "" && stamp.After(last) {
+			last = stamp
+		}
+		r, ok := ranges[e.TZID]
+		if !ok {
+			ranges[e.TZID] = &zoneRange{loc: loc, from: e.Start, to: last}
+			continue
+		}
+		if e.Start.Before(r.from) {
+			r.from = e.Start
+		}
+		if last.After(r.to) {
+			r.to = last
+		}
+	}
+
+	names := make([]string, 0, len(ranges))
+	for name := range ranges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zones := make([]zoneRange, 0, len(names))
+	for _, name := range names {
+		r := ranges[name]
+		zones = append(zones, zoneRange{loc: r.loc, from: r.from.AddDate(0, 0, -1), to: r.to.AddDate(2, 0, 0)})
+	}
+	return zones
+}
+
+// writeTimezone writes a VTIMEZONE with one observance per offset change in
+// [from, to), from the Go time zone database
+func writeTimezone(lw *lineWriter, loc *time.Location, from, to time.Time) {
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + loc.String())
+	lw.line("X-LIC-LOCATION:" + loc.String())
+
+	t := from.In(loc)
+	name, offset := t.Zone()
+	writeObservance(lw, t.IsDST(), t, name, offset, offset)
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.Before(to) {
+			break
+		}
+		next := end.In(loc)
+		nextName, nextOffset := next.Zone()
+		if nextOffset != offset {
+			// DTSTART is the local time of the change in the old offset
+			onset := next.UTC().Add(time.Duration(offset) * time.Second)
+			writeObservance(lw, next.IsDST(), onset, nextName, offset, nextOffset)
+		}
+		t, offset = next, nextOffset
+	}
+	lw.line("END:VTIMEZONE")
+}
+
+func writeObservance(lw *lineWriter, dst bool, localStart time.Time, name string, from, to int) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	lw.line("BEGIN:" + kind)
+	lw.line("DTSTART:" + localStart.Format(dateTimeFormat))
+	lw.line("TZOFFSETFROM:" + formatOffset(from))
+	lw.line("TZOFFSETTO:" + formatOffset(to))
+	if name != "" && !strings.HasPrefix(name, "+") && !strings.HasPrefix(name, "-") {
+		lw.line("TZNAME:" + name)
+	}
+	lw.line("END:" + kind)
+}
+
+func writeEvent(lw *lineWriter, e *Event, stamp time.Time) {
+	lw.line("BEGIN:VEVENT")
+	lw.line("UID:" + e.UID)
+	lw.line("DTSTAMP:" + stamp.UTC().Format(dateTimeUTCFormat))
+	lw.line("DTSTART" + formatTime(e.Start, e.TZID, e.AllDay))
+	if !e.End.IsZero() {
+		lw.line("DTEND" + formatTime(e.End, e.TZID, e.AllDay))
+	}
+	if e.RRule != "" {
+		lw.line("RRULE:" + e.RRule)
+	}
+	lw.line("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		lw.line("DESCRIPTION:" + escapeText(e.Description))
+	}
+	if e.Location != "" {
+		lw.line("LOCATION:" + escapeText(e.Location))
+	}
+	if e.URL != "" {
+		lw.line("URL:" + e.URL)
+	}
+	if e.Status != "" {
+		lw.line("STATUS:" + e.Status)
+	}
+	if !e.Updated.IsZero() {
+		lw.line("LAST-MODIFIED:" + e.Updated.UTC().Format(dateTimeUTCFormat))
+	}
+	lw.line("END:VEVENT")
+}
+
+// formatTime returns the parameters and value of a DTSTART/DTEND property
+func formatTime(t time.Time, tzid string, allDay bool) string {
+	if allDay {
+		return ";VALUE=DATE:" + t.Format(dateFormat)
+	}
+	if tzid != "" && tzid != "UTC" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			return ";TZID=" + tzid + ":" + t.In(loc).Format(dateTimeFormat)
+		}
+	}
+	return ":" + t.UTC().Format(dateTimeUTCFormat)
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+func formatDuration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// lineWriter writes CRLF-terminated content lines folded at 75 octets
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
@@ -0,0 +1,208 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTo(t *testing.T) {
+	stamp := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	cal := &Calendar{
+		ProdID:  "-//Test//EN",
+		Name:    "Test",
+		Refresh: time.Hour,
+		Events: []Event{
+			{
+				UID:         "r1@test",
+				Summary:     "Vitamins; folic acid, iron",
+				Description: "Line one\nLine two",
+				Start:       time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC),
+				TZID:        "America/New_York",
+				RRule:       "FREQ=DAILY",
+			},
+			{
+				UID:     "v1@test",
+				Summary: "Doctor appointment",
+				Start:   time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC),
+				End:     time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := cal.WriteTo(&buf, stamp); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n",
+		"TZID:America/New_York\r\n",
+		// 2026-03-08 02:00 EST -> EDT
+		"BEGIN:DAYLIGHT\r\nDTSTART:20260308T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n",
+		"DTSTART;TZID=America/New_York:20260301T080000\r\n",
+		"RRULE:FREQ=DAILY\r\n",
+		`SUMMARY:Vitamins\; folic acid\, iron` + "\r\n",
+		`DESCRIPTION:Line one\nLine two` + "\r\n",
+		"DTSTART:20260305T090000Z\r\nDTEND:20260305T100000Z\r\n",
+		"DTSTAMP:20260110T120000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTo_FoldsLongLines(t *testing.T) {
+	cal := &Calendar{ProdID: "-//Test//EN", Events: []Event{{
+		UID:         "long@test",
+		Summary:     "x",
+		Description: strings.Repeat("ñ", 100),
+		Start:       time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+	}}}
+
+	var buf bytes.Buffer
+	if err := cal.WriteTo(&buf, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+
+	events, _, err := Parse(&buf, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Description != strings.Repeat("ñ", 100) {
+		t.Errorf("folded description did not round-trip: %+v", events)
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTIMEZONE",
+		"TZID:Custom Lagos",
+		"BEGIN:STANDARD",
+		"DTSTART:19700101T000000",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:a@example.com",
+		"SUMMARY:Antenatal class\\, week 2",
+		"DTSTART;TZID=Eastern Standard Time:20260310T090000",
+		"DURATION:PT1H30M",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:b@example.com",
+		"SUMMARY:Scan",
+		"DTSTART;TZID=\"Custom Lagos\":20260312T100000",
+		"LOCATION:General Hospital",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:c@example.com",
+		"SUMMARY:Baby shower",
+		"DTSTART;VALUE=DATE:20260320",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:d@example.com",
+		"SUMMARY:Broken",
+		"DTSTART:tomorrow",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	events, invalid, err := Parse(strings.NewReader(input), lagos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid != 1 || len(events) != 3 {
+		t.Fatalf("got %d events, %d invalid", len(events), invalid)
+	}
+
+	class := events[0]
+	if class.Summary != "Antenatal class, week 2" || class.RRule != "FREQ=WEEKLY;COUNT=4" {
+		t.Errorf("class = %+v", class)
+	}
+	// 09:00 EDT (DST began 2026-03-08)
+	if !class.Start.Equal(time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)) || class.TZID != "America/New_York" {
+		t.Errorf("class start = %v %s", class.Start, class.TZID)
+	}
+	if class.End.Sub(class.Start) != 90*time.Minute {
+		t.Errorf("class duration = %v", class.End.Sub(class.Start))
+	}
+
+	scan := events[1]
+	if !scan.Start.Equal(time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("scan start = %v, want 09:00 UTC from the VTIMEZONE", scan.Start)
+	}
+	if scan.Location != "General Hospital" {
+		t.Errorf("scan location = %q", scan.Location)
+	}
+
+	shower := events[2]
+	if !shower.AllDay || !shower.Start.Equal(time.Date(2026, 3, 20, 0, 0, 0, 0, lagos)) {
+		t.Errorf("shower = %+v", shower)
+	}
+}
+
+func TestParse_NotCalendar(t *testing.T) {
+	if _, _, err := Parse(strings.NewReader("hello"), nil); err != ErrNotCalendar {
+		t.Errorf("error = %v, want ErrNotCalendar", err)
+	}
+}
+
+func TestZoneOffset_DaylightRule(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:Eastern",
+		"BEGIN:STANDARD",
+		"DTSTART:19701101T020000",
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU",
+		"TZOFFSETFROM:-0400",
+		"TZOFFSETTO:-0500",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:19700308T020000",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU",
+		"TZOFFSETFROM:-0500",
+		"TZOFFSETTO:-0400",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:x",
+		"DTSTART;TZID=Eastern:20260701T090000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:y",
+		"DTSTART;TZID=Eastern:20261215T090000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\n")
+
+	events, _, err := Parse(strings.NewReader(input), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !events[0].Start.Equal(time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("summer start = %v, want 13:00 UTC", events[0].Start)
+	}
+	if !events[1].Start.Equal(time.Date(2026, 12, 15, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("winter start = %v, want 14:00 UTC", events[1].Start)
+	}
+}
@@ -0,0 +1,410 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// ErrNotCalendar is returned for input that is not an iCalendar file
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type component struct {
+	name       string
+	props      []property
+	components []*component
+}
+
+func (c *component) get(name string) *property {
+	for i := range c.props {
+		if c.props[i].name == name {
+			return &c.props[i]
+		}
+	}
+	return nil
+}
+
+func (c *component) text(name string) string {
+	if p := c.get(name); p != nil {
+		return unescapeText(p.value)
+	}
+	return ""
+}
+
+// Parse reads the events of an iCalendar file. Times are resolved to UTC
+// using the file's TZIDs and VTIMEZONEs; floating times and dates are read
+// in defaultLoc. Events whose start cannot be read are skipped and counted
+// in invalid.
+func Parse(r io.Reader, defaultLoc *time.Location) (events []Event, invalid int, err error) {
+	root, err := parseComponents(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if defaultLoc == nil {
+		defaultLoc = time.UTC
+	}
+
+	zones := make(map[string]*component)
+	for _, c := range root.components {
+		if c.name == "VTIMEZONE" {
+			zones[c.text("TZID")] = c
+		}
+	}
+	resolver := &timeResolver{zones: zones, defaultLoc: defaultLoc}
+
+	for _, c := range root.components {
+		if c.name != "VEVENT" {
+			continue
+		}
+		e, err := resolver.event(c)
+		if err != nil {
+			invalid++
+			continue
+		}
+		events = append(events, e)
+	}
+	return events, invalid, nil
+}
+
+// parseComponents unfolds content lines and returns the VCALENDAR component
+func parseComponents(r io.Reader) (*component, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, ErrNotCalendar
+	}
+
+	var stack []*component
+	var root *component
+	for _, line := range lines {
+		p, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		switch p.name {
+		case "BEGIN":
+			c := &component{name: strings.ToUpper(p.value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.components = append(parent.components, c)
+			} else if root == nil {
+				root = c
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			if len(stack) > 0 {
+				c := stack[len(stack)-1]
+				c.props = append(c.props, p)
+			}
+		}
+	}
+	if root == nil || root.name != "VCALENDAR" {
+		return nil, ErrNotCalendar
+	}
+	return root, nil
+}
+
+// parseProperty splits NAME;PARAM=value;PARAM="quoted":value
+func parseProperty(line string) (property, bool) {
+	p := property{params: make(map[string]string)}
+
+	i := strings.IndexAny(line, ";:")
+	if i < 0 {
+		return p, false
+	}
+	p.name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return p, false
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return p, false
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return p, false
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		p.params[key] = value
+
+		i = len(line) - len(rest)
+		if i >= len(line) {
+			return p, false
+		}
+	}
+
+	p.value = line[i+1:]
+	return p, true
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+type timeResolver struct {
+	zones      map[string]*component
+	defaultLoc *time.Location
+}
+
+func (tr *timeResolver) event(c *component) (Event, error) {
+	e := Event{
+		UID:         c.text("UID"),
+		Summary:     strings.TrimSpace(c.text("SUMMARY")),
+		Description: strings.TrimSpace(c.text("DESCRIPTION")),
+		Location:    strings.TrimSpace(c.text("LOCATION")),
+		Status:      strings.ToUpper(c.text("STATUS")),
+		URL:         c.text("URL"),
+	}
+
+	start := c.get("DTSTART")
+	if start == nil {
+		return e, errors.New("event has no DTSTART")
+	}
+	var err error
+	e.Start, e.AllDay, e.TZID, err = tr.resolve(start)
+	if err != nil {
+		return e, err
+	}
+
+	if end := c.get("DTEND"); end != nil {
+		if e.End, _, _, err = tr.resolve(end); err != nil {
+			e.End = time.Time{}
+		}
+	} else if d := c.get("DURATION"); d != nil {
+		if dur, err := parseDuration(d.value); err == nil {
+			e.End = e.Start.Add(dur)
+		}
+	}
+
+	if rule := c.get("RRULE"); rule != nil {
+		e.RRule = strings.TrimSpace(rule.value)
+	}
+	if modified := c.get("LAST-MODIFIED"); modified != nil {
+		e.Updated, _, _, _ = tr.resolve(modified)
+	}
+	return e, nil
+}
+
+// resolve converts a date or date-time property to an absolute time
+func (tr *timeResolver) resolve(p *property) (t time.Time, allDay bool, tzid string, err error) {
+	value := strings.TrimSpace(p.value)
+
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateFormat) {
+		t, err = time.ParseInLocation(dateFormat, value, tr.defaultLoc)
+		return t, true, "", err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(dateTimeUTCFormat, value)
+		return t, false, "", err
+	}
+
+	local, err := time.Parse(dateTimeFormat, value)
+	if err != nil {
+		return time.Time{}, false, "", err
+	}
+
+	name := strings.TrimPrefix(p.params["TZID"], "/")
+	if name == "" {
+		return inLocation(local, tr.defaultLoc), false, "", nil
+	}
+	if loc, ok := loadLocation(name); ok {
+		return inLocation(local, loc), false, loc.String(), nil
+	}
+	if zone, ok := tr.zones[p.params["TZID"]]; ok {
+		return local.Add(-time.Duration(zoneOffset(zone, local)) * time.Second), false, "", nil
+	}
+	return inLocation(local, tr.defaultLoc), false, "", nil
+}
+
+// inLocation reads the wall clock of a UTC-parsed time in loc
+func inLocation(local time.Time, loc *time.Location) time.Time {
+	return time.Date(local.Year(), local.Month(), local.Day(),
+		local.Hour(), local.Minute(), local.Second(), 0, loc).UTC()
+}
+
+// loadLocation loads an IANA zone, or the IANA equivalent of a common
+// Windows zone name (as written by Outlook and Exchange)
+func loadLocation(name string) (*time.Location, bool) {
+	if iana, ok := windowsZones[name]; ok {
+		name = iana
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return nil, false
+	}
+	return loc, true
+}
+
+// zoneOffset returns the UTC offset in seconds at local wall-clock time t
+// under a VTIMEZONE: that of the observance with the latest onset before t
+func zoneOffset(zone *component, t time.Time) int {
+	var (
+		best      time.Time
+		offset    int
+		earliest  time.Time
+		fallback  int
+		hasOnsets bool
+	)
+	for _, obs := range zone.components {
+		if obs.name != "STANDARD" && obs.name != "DAYLIGHT" {
+			continue
+		}
+		startProp := obs.get("DTSTART")
+		if startProp == nil {
+			continue
+		}
+		start, err := time.Parse(dateTimeFormat, strings.TrimSpace(startProp.value))
+		if err != nil {
+			continue
+		}
+		to := parseOffset(obs.text("TZOFFSETTO"))
+		if !hasOnsets || start.Before(earliest) {
+			earliest, fallback = start, parseOffset(obs.text("TZOFFSETFROM"))
+		}
+		hasOnsets = true
+
+		onset := start
+		if ruleProp := obs.get("RRULE"); ruleProp != nil && !start.After(t) {
+			if option, err := rrule.StrToROption(ruleProp.value); err == nil {
+				option.Dtstart = start
+				if rule, err := rrule.NewRRule(*option); err == nil {
+					if last := rule.Before(t, true); !last.IsZero() {
+						onset = last
+					}
+				}
+			}
+		}
+		if onset.After(t) {
+			continue
+		}
+		if best.IsZero() || onset.After(best) {
+			best, offset = onset, to
+		}
+	}
+	if best.IsZero() {
+		return fallback
+	}
+	return offset
+}
+
+// parseOffset parses a UTC offset such as +0100 or -0530
+func parseOffset(s string) int {
+	s = strings.TrimSpace(s)
+	if len(s) < 5 {
+		return 0
+	}
+	hours, err1 := strconv.Atoi(s[1:3])
+	minutes, err2 := strconv.Atoi(s[3:5])
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	seconds := hours*3600 + minutes*60
+	if s[0] == '-' {
+		return -seconds
+	}
+	return seconds
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an RFC 5545 duration such as PT1H30M or P1D
+func parseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// windowsZones maps common Windows time zone names to IANA zones
+var windowsZones = map[string]string{
+	"UTC":                             "UTC",
+	"GMT Standard Time":               "Europe/London",
+	"Greenwich Standard Time":         "Atlantic/Reykjavik",
+	"W. Europe Standard Time":         "Europe/Berlin",
+	"Romance Standard Time":           "Europe/Paris",
+	"Central Europe Standard Time":    "Europe/Budapest",
+	"E. Europe Standard Time":         "Europe/Chisinau",
+	"W. Central Africa Standard Time": "Africa/Lagos",
+	"South Africa Standard Time":      "Africa/Johannesburg",
+	"E. Africa Standard Time":         "Africa/Nairobi",
+	"Egypt Standard Time":             "Africa/Cairo",
+	"Morocco Standard Time":           "Africa/Casablanca",
+	"India Standard Time":             "Asia/Kolkata",
+	"Arabian Standard Time":           "Asia/Dubai",
+	"China Standard Time":             "Asia/Shanghai",
+	"Tokyo Standard Time":             "Asia/Tokyo",
+	"AUS Eastern Standard Time":       "Australia/Sydney",
+	"Eastern Standard Time":           "America/New_York",
+	"Central Standard Time":           "America/Chicago",
+	"Mountain Standard Time":          "America/Denver",
+	"US Mountain Standard Time":       "America/Phoenix",
+	"Pacific Standard Time":           "America/Los_Angeles",
+	"Central Standard Time (Mexico)":  "America/Mexico_City",
+	"Central America Standard Time":   "America/Guatemala",
+	"SA Pacific Standard Time":        "America/Bogota",
+	"SA Western Standard Time":        "America/La_Paz",
+	"Venezuela Standard Time":         "America/Caracas",
+	"Argentina Standard Time":         "America/Buenos_Aires",
+	"E. South America Standard Time":  "America/Sao_Paulo",
+	"Pacific SA Standard Time":        "America/Santiago",
+}
@@ -0,0 +1,319 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/internal/circuitbreaker"
+)
+
+// ErrNoProviders is returned when a Router has no usable provider.
+var ErrNoProviders = errors.New("no LLM providers available")
+
+var errStreamInterrupted = errors.New("stream closed before completion")
+
+// Provider is one backend behind a Router.
+type Provider struct {
+	Name   string
+	Client Client
+	// Weight is the provider's share of primary traffic for A/B splits.
+	// Zero-weight providers only serve as failover.
+	Weight int
+	// Models overrides the model per task; unset tasks use the client's default.
+	Models map[Task]string
+}
+
+// RouterConfig tunes per-provider circuit breakers and usage accounting.
+type RouterConfig struct {
+	MaxFailures  int           // Default: 5
+	ResetTimeout time.Duration // Default: 1 minute
+	Usage        UsageRecorder // Optional: receives token usage for every completion
+}
+
+type routedProvider struct {
+	Provider
+	breaker *circuitbreaker.CircuitBreaker
+}
+
+// Router implements Client over several providers with ordered failover,
+// weighted primary selection and a circuit breaker per provider.
+type Router struct {
+	providers []*routedProvider
+	usage     UsageRecorder
+	intn      func(n int) int
+}
+
+// NewRouter creates a router. Providers are tried in the given order after the
+// weighted primary; providers with a nil Client are skipped.
+func NewRouter(config RouterConfig, providers ...Provider) *Router {
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 5
+	}
+	if config.ResetTimeout <= 0 {
+		config.ResetTimeout = time.Minute
+	}
+
+	r := &Router{usage: config.Usage, intn: rand.Intn}
+	for _, p := range providers {
+		if p.Client == nil {
+			continue
+		}
+		r.providers = append(r.providers, &routedProvider{
+			Provider: p,
+			breaker:  circuitbreaker.NewCircuitBreaker(config.MaxFailures, config.ResetTimeout),
+		})
+	}
+	return r
+}
+
+// Providers returns the configured provider names in failover order.
+func (r *Router) Providers() []string {
+	names := make([]string, len(r.providers))
+	for i, p := range r.providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// ChatCompletion sends the request to the first healthy provider, failing over on error.
+// The serving provider is recorded in ChatResponse.Provider.
+func (r *Router) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	task := TaskFromContext(ctx)
+	var errs []error
+
+	for _, p := range r.order() {
+		var resp *ChatResponse
+		var callErr error
+		providerReq := p.request(req, task)
+		err := p.breaker.Call(func() error {
+			resp, callErr = p.Client.ChatCompletion(ctx, providerReq)
+			return providerFault(ctx, callErr)
+		})
+		if err == nil && callErr == nil {
+			resp.Provider = p.Name
+			r.recordResponse(ctx, p.Name, providerReq, resp)
+			return resp, nil
+		}
+		if callErr == nil {
+			callErr = err // Breaker rejected the call
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, callErr))
+		if ctx.Err() != nil {
+			// No time left to fail over; don't charge the next provider for it.
+			return nil, r.failure(errs)
+		}
+	}
+
+	return nil, r.failure(errs)
+}
+
+// StreamChatCompletion opens a stream on the first healthy provider. Failover only
+// happens before the stream starts; a stream that closes without a finish reason
+// is counted as a failure against its provider.
+func (r *Router) StreamChatCompletion(ctx context.Context, req ChatRequest) (<-chan ChatChunk, error) {
+	task := TaskFromContext(ctx)
+	var errs []error
+
+	for _, p := range r.order() {
+		var stream <-chan ChatChunk
+		var callErr error
+		providerReq := p.request(req, task)
+		err := p.breaker.Call(func() error {
+			stream, callErr = p.Client.StreamChatCompletion(ctx, providerReq)
+			return providerFault(ctx, callErr)
+		})
+		if err == nil && callErr == nil {
+			return r.watch(ctx, p, providerReq, stream), nil
+		}
+		if callErr == nil {
+			callErr = err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, callErr))
+		if ctx.Err() != nil {
+			// No time left to fail over; don't charge the next provider for it.
+			return nil, r.failure(errs)
+		}
+	}
+
+	return nil, r.failure(errs)
+}
+
+// order returns providers for one request: a weighted pick first (when weights
+// are configured), then the rest in configured order.
+func (r *Router) order() []*routedProvider {
+	total := 0
+	for _, p := range r.providers {
+		if p.Weight > 0 {
+			total += p.Weight
+		}
+	}
+	if total == 0 || len(r.providers) < 2 {
+		return r.providers
+	}
+
+	n := r.intn(total)
+	primary := 0
+	for i, p := range r.providers {
+		if p.Weight <= 0 {
+			continue
+		}
+		if n < p.Weight {
+			primary = i
+			break
+		}
+		n -= p.Weight
+	}
+
+	ordered := make([]*routedProvider, 0, len(r.providers))
+	ordered = append(ordered, r.providers[primary])
+	for i, p := range r.providers {
+		if i != primary {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered
+}
+
+func (r *Router) failure(errs []error) error {
+	if len(errs) == 0 {
+		return ErrNoProviders
+	}
+	return fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+func (p *routedProvider) request(req ChatRequest, task Task) ChatRequest {
+	if model := p.Models[task]; model != "" {
+		req.Model = model
+	}
+	return req
+}
+
+// watch forwards a stream, reports an interrupted stream to the breaker and
+// records the tokens the stream consumed.
+func (r *Router) watch(ctx context.Context, p *routedProvider, req ChatRequest, stream <-chan ChatChunk) <-chan ChatChunk {
+	out := make(chan ChatChunk)
+	go func() {
+		defer close(out)
+		finished := false
+		model := req.Model
+		var content strings.Builder
+		var usage *Usage
+
+		defer func() {
+			if usage != nil || content.Len() > 0 {
+				r.record(ctx, p.Name, model, req, usage, content.String())
+			}
+		}()
+
+		for chunk := range stream {
+			for _, choice := range chunk.Choices {
+				content.WriteString(choice.Delta.Content)
+				if choice.FinishReason != nil && *choice.FinishReason != "" {
+					finished = true
+				}
+			}
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			if model == "" {
+				model = chunk.Model
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// Drain so the provider's goroutine can exit.
+				for range stream {
+				}
+				return
+			}
+		}
+		if !finished && ctx.Err() == nil {
+			_ = p.breaker.Call(func() error { return errStreamInterrupted })
+		}
+	}()
+	return out
+}
+
+func (r *Router) recordResponse(ctx context.Context, provider string, req ChatRequest, resp *ChatResponse) {
+	model := req.Model
+	if model == "" {
+		model = resp.Model
+	}
+	var usage *Usage
+	if resp.Usage.PromptTokens > 0 || resp.Usage.CompletionTokens > 0 {
+		usage = &resp.Usage
+	}
+	content := ""
+	if len(resp.Choices) > 0 {
+		content = resp.Choices[0].Message.Content
+	}
+	r.record(ctx, provider, model, req, usage, content)
+}
+
+// record reports usage, estimating tokens when the provider didn't send counts.
+func (r *Router) record(ctx context.Context, provider, model string, req ChatRequest, usage *Usage, completion string) {
+	if r.usage == nil {
+		return
+	}
+	record := UsageRecord{
+		UserID:   UserFromContext(ctx),
+		Provider: provider,
+		Model:    model,
+		Task:     TaskFromContext(ctx),
+	}
+	if usage != nil {
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+	} else {
+		record.PromptTokens = estimatePromptTokens(req.Messages)
+		record.CompletionTokens = EstimateTokens(completion)
+		record.Estimated = true
+	}
+	r.usage.RecordUsage(ctx, record)
+}
+
+// providerFault returns err unless the caller cancelled the request, which
+// should not count against the provider.
+func providerFault(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// RouteEntry is one provider in a routing spec.
+type RouteEntry struct {
+	Name   string
+	Weight int
+}
+
+// ParseRoute parses an ordered provider list with optional weights,
+// e.g. "gemini,deepseek" (failover) or "gemini:70,deepseek:30" (A/B split).
+func ParseRoute(spec string) ([]RouteEntry, error) {
+	var entries []RouteEntry
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(part, ":")
+		entry := RouteEntry{Name: strings.ToLower(strings.TrimSpace(name))}
+		if hasWeight {
+			weight, err := strconv.Atoi(strings.TrimSpace(weightText))
+			if err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight for provider %q", entry.Name)
+			}
+			entry.Weight = weight
+		}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("invalid provider entry %q", part)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,319 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	content string
+	err     error
+	calls   int
+	models  []string
+	chunks  []ChatChunk
+}
+
+func (f *fakeClient) ChatCompletion(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	f.calls++
+	f.models = append(f.models, req.Model)
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &ChatResponse{}
+	resp.Choices = make([]struct {
+		Index   int `json:"index"`
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	}, 1)
+	resp.Choices[0].Message.Content = f.content
+	return resp, nil
+}
+
+func (f *fakeClient) StreamChatCompletion(_ context.Context, req ChatRequest) (<-chan ChatChunk, error) {
+	f.calls++
+	f.models = append(f.models, req.Model)
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan ChatChunk, len(f.chunks))
+	for _, c := range f.chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch, nil
+}
+
+func chunk(content string, finish bool) ChatChunk {
+	c := ChatChunk{}
+	c.Choices = make([]struct {
+		Index        int     `json:"index"`
+		Delta        Delta   `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	}, 1)
+	c.Choices[0].Delta.Content = content
+	if finish {
+		stop := "stop"
+		c.Choices[0].FinishReason = &stop
+	}
+	return c
+}
+
+func TestRouter_FailsOverInOrder(t *testing.T) {
+	primary := &fakeClient{err: errors.New("503")}
+	secondary := &fakeClient{content: "hello"}
+	r := NewRouter(RouterConfig{},
+		Provider{Name: "gemini", Client: primary},
+		Provider{Name: "deepseek", Client: secondary},
+	)
+
+	resp, err := r.ChatCompletion(context.Background(), ChatRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != "deepseek" || resp.Choices[0].Message.Content != "hello" {
+		t.Errorf("unexpected response: provider=%q", resp.Provider)
+	}
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Errorf("calls: primary=%d secondary=%d", primary.calls, secondary.calls)
+	}
+}
+
+func TestRouter_AllProvidersFail(t *testing.T) {
+	r := NewRouter(RouterConfig{},
+		Provider{Name: "a", Client: &fakeClient{err: errors.New("down")}},
+		Provider{Name: "b", Client: &fakeClient{err: errors.New("down")}},
+	)
+	if _, err := r.ChatCompletion(context.Background(), ChatRequest{}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	empty := NewRouter(RouterConfig{}, Provider{Name: "nil-client"})
+	if _, err := empty.ChatCompletion(context.Background(), ChatRequest{}); !errors.Is(err, ErrNoProviders) {
+		t.Errorf("expected ErrNoProviders, got %v", err)
+	}
+}
+
+func TestRouter_BreakerSkipsFailingProvider(t *testing.T) {
+	primary := &fakeClient{err: errors.New("down")}
+	secondary := &fakeClient{content: "ok"}
+	r := NewRouter(RouterConfig{MaxFailures: 2, ResetTimeout: time.Hour},
+		Provider{Name: "a", Client: primary},
+		Provider{Name: "b", Client: secondary},
+	)
+
+	for i := 0; i < 4; i++ {
+		if _, err := r.ChatCompletion(context.Background(), ChatRequest{}); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("expected breaker to stop calls after 2 failures, got %d", primary.calls)
+	}
+	if secondary.calls != 4 {
+		t.Errorf("expected secondary to serve every request, got %d", secondary.calls)
+	}
+}
+
+func TestRouter_CancelledRequestDoesNotTripBreaker(t *testing.T) {
+	client := &fakeClient{err: context.Canceled}
+	r := NewRouter(RouterConfig{MaxFailures: 1, ResetTimeout: time.Hour},
+		Provider{Name: "a", Client: client},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = r.ChatCompletion(ctx, ChatRequest{})
+
+	client.err = nil
+	if _, err := r.ChatCompletion(context.Background(), ChatRequest{}); err != nil {
+		t.Fatalf("breaker opened on caller cancellation: %v", err)
+	}
+}
+
+func TestRouter_WeightedPrimary(t *testing.T) {
+	a := &fakeClient{content: "a"}
+	b := &fakeClient{content: "b"}
+	r := NewRouter(RouterConfig{},
+		Provider{Name: "a", Client: a, Weight: 70},
+		Provider{Name: "b", Client: b, Weight: 30},
+	)
+
+	picks := map[int]string{0: "a", 69: "a", 70: "b", 99: "b"}
+	for n, want := range picks {
+		r.intn = func(int) int { return n }
+		resp, err := r.ChatCompletion(context.Background(), ChatRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Provider != want {
+			t.Errorf("intn=%d: provider = %s, want %s", n, resp.Provider, want)
+		}
+	}
+}
+
+func TestRouter_PerTaskModel(t *testing.T) {
+	client := &fakeClient{content: "ok"}
+	r := NewRouter(RouterConfig{}, Provider{
+		Name:   "gemini",
+		Client: client,
+		Models: map[Task]string{TaskTitle: "flash-lite"},
+	})
+
+	_, _ = r.ChatCompletion(WithTask(context.Background(), TaskTitle), ChatRequest{})
+	_, _ = r.ChatCompletion(context.Background(), ChatRequest{})
+
+	if client.models[0] != "flash-lite" || client.models[1] != "" {
+		t.Errorf("models = %v, want [flash-lite \"\"]", client.models)
+	}
+}
+
+func TestRouter_StreamFailoverAndInterruption(t *testing.T) {
+	broken := &fakeClient{err: errors.New("connect failed")}
+	partial := &fakeClient{chunks: []ChatChunk{chunk("Hel", false)}}
+	r := NewRouter(RouterConfig{MaxFailures: 1, ResetTimeout: time.Hour},
+		Provider{Name: "broken", Client: broken},
+		Provider{Name: "partial", Client: partial},
+	)
+
+	stream, err := r.StreamChatCompletion(context.Background(), ChatRequest{})
+	if err != nil {
+		t.Fatalf("expected failover to second provider: %v", err)
+	}
+	for range stream {
+	}
+
+	// Both providers are now open: the first failed to connect, the second
+	// closed its stream without a finish reason.
+	if _, err := r.StreamChatCompletion(context.Background(), ChatRequest{}); err == nil {
+		t.Fatal("expected all breakers to be open")
+	}
+	if partial.calls != 1 {
+		t.Errorf("partial provider calls = %d, want 1", partial.calls)
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	entries, err := ParseRoute(" Gemini:70, deepseek ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0] != (RouteEntry{Name: "gemini", Weight: 70}) || entries[1] != (RouteEntry{Name: "deepseek"}) {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	if _, err := ParseRoute("gemini:lots"); err == nil {
+		t.Error("expected error for invalid weight")
+	}
+}
+
+func TestParseTaskModels(t *testing.T) {
+	models, err := ParseTaskModels("chat=gemini-2.5-flash, Title=gemini-2.0-flash-lite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if models[TaskChat] != "gemini-2.5-flash" || models[TaskTitle] != "gemini-2.0-flash-lite" {
+		t.Errorf("unexpected models: %v", models)
+	}
+
+	if _, err := ParseTaskModels("chat"); err == nil {
+		t.Error("expected error for missing model")
+	}
+}
+
+type recorderFunc func(UsageRecord)
+
+func (f recorderFunc) RecordUsage(_ context.Context, r UsageRecord) { f(r) }
+
+func TestRouter_RecordsUsage(t *testing.T) {
+	var records []UsageRecord
+	recorder := recorderFunc(func(r UsageRecord) { records = append(records, r) })
+
+	reported := &fakeClient{content: "hello"}
+	r := NewRouter(RouterConfig{Usage: recorder}, Provider{
+		Name:   "deepseek",
+		Client: &usageClient{fakeClient: reported, usage: Usage{PromptTokens: 12, CompletionTokens: 3}},
+		Models: map[Task]string{TaskTitle: "deepseek-chat"},
+	})
+
+	ctx := WithUser(WithTask(context.Background(), TaskTitle), "user-1")
+	if _, err := r.ChatCompletion(ctx, ChatRequest{Messages: []ChatMessage{{Role: "user", Content: "hi"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	got := records[0]
+	want := UsageRecord{UserID: "user-1", Provider: "deepseek", Model: "deepseek-chat", Task: TaskTitle, PromptTokens: 12, CompletionTokens: 3}
+	if got != want {
+		t.Errorf("record = %+v, want %+v", got, want)
+	}
+}
+
+func TestRouter_EstimatesStreamUsageWhenUnreported(t *testing.T) {
+	var records []UsageRecord
+	recorder := recorderFunc(func(r UsageRecord) { records = append(records, r) })
+
+	client := &fakeClient{chunks: []ChatChunk{chunk("Hello ", false), chunk("there!", true)}}
+	r := NewRouter(RouterConfig{Usage: recorder}, Provider{Name: "ollama", Client: client})
+
+	stream, err := r.StreamChatCompletion(WithUser(context.Background(), "user-2"), ChatRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "Say hello please"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range stream {
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	got := records[0]
+	if !got.Estimated || got.Task != TaskChat || got.UserID != "user-2" {
+		t.Errorf("unexpected record: %+v", got)
+	}
+	if got.CompletionTokens != EstimateTokens("Hello there!") || got.PromptTokens == 0 {
+		t.Errorf("unexpected token estimate: %+v", got)
+	}
+}
+
+// usageClient reports fixed token usage on every completion.
+type usageClient struct {
+	*fakeClient
+	usage Usage
+}
+
+func (u *usageClient) ChatCompletion(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := u.fakeClient.ChatCompletion(ctx, req)
+	if resp != nil {
+		resp.Usage = u.usage
+	}
+	return resp, err
+}
+
+func TestPriceTable(t *testing.T) {
+	prices, err := ParsePriceTable("deepseek/deepseek-chat=0.27:1.10, gemini/*=0.10:0.40")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := prices.Cost("deepseek", "deepseek-chat", 1_000_000, 1_000_000); got != 1.37 {
+		t.Errorf("exact price cost = %v, want 1.37", got)
+	}
+	if got := prices.Cost("gemini", "Gemini-2.5-Flash", 1_000_000, 0); got != 0.10 {
+		t.Errorf("wildcard price cost = %v, want 0.10", got)
+	}
+	if got := prices.Cost("openai", "gpt-4o", 1000, 1000); got != 0 {
+		t.Errorf("unknown model cost = %v, want 0", got)
+	}
+
+	if _, err := ParsePriceTable("deepseek=1:2"); err == nil {
+		t.Error("expected error for key without model")
+	}
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Task identifies what a completion is for, so a Router can pick a model per task.
+type Task string
+
+const (
+	TaskChat       Task = "chat"
+	TaskTitle      Task = "title"
+	TaskSummary    Task = "summary"
+	TaskWelcome    Task = "welcome"
+	TaskModeration Task = "moderation"
+	TaskFacts      Task = "facts"
+	TaskSchedule   Task = "schedule"
+)
+
+type taskKey struct{}
+
+// WithTask tags ctx with the task a completion request is made for.
+func WithTask(ctx context.Context, task Task) context.Context {
+	return context.WithValue(ctx, taskKey{}, task)
+}
+
+// TaskFromContext returns the task set by WithTask, defaulting to TaskChat.
+func TaskFromContext(ctx context.Context) Task {
+	if task, ok := ctx.Value(taskKey{}).(Task); ok && task != "" {
+		return task
+	}
+	return TaskChat
+}
+
+// ParseTaskModels parses a "task=model,task=model" list (e.g. "chat=gemini-2.5-flash,title=gemini-2.0-flash-lite").
+func ParseTaskModels(spec string) (map[Task]string, error) {
+	models := make(map[Task]string)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		task, model, ok := strings.Cut(part, "=")
+		task, model = strings.TrimSpace(task), strings.TrimSpace(model)
+		if !ok || task == "" || model == "" {
+			return nil, fmt.Errorf("invalid task model %q (want task=model)", part)
+		}
+		models[Task(strings.ToLower(task))] = model
+	}
+	return models, nil
+}
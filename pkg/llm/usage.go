@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type userKey struct{}
+
+// WithUser tags ctx with the user a completion is made for, for usage accounting.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserFromContext returns the user set by WithUser, or "" for system calls.
+func UserFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userKey{}).(string)
+	return userID
+}
+
+// UsageRecord describes the tokens consumed by one completion.
+type UsageRecord struct {
+	UserID           string
+	Provider         string
+	Model            string
+	Task             Task
+	PromptTokens     int
+	CompletionTokens int
+	Estimated        bool // Tokens were estimated because the provider did not report usage
+}
+
+// UsageRecorder receives a record for every successful completion.
+// Implementations must not block the caller for long.
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, record UsageRecord)
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// PriceTable maps "provider/model" (or "provider/*" as a fallback) to prices.
+type PriceTable map[string]Price
+
+// Cost estimates the USD cost of a completion. Unknown models cost 0.
+func (t PriceTable) Cost(provider, model string, promptTokens, completionTokens int) float64 {
+	provider, model = strings.ToLower(provider), strings.ToLower(model)
+	price, ok := t[provider+"/"+model]
+	if !ok {
+		price, ok = t[provider+"/*"]
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.InputPerMillion + float64(completionTokens)*price.OutputPerMillion) / 1_000_000
+}
+
+// ParsePriceTable parses "provider/model=input:output" entries in USD per million tokens,
+// e.g. "deepseek/deepseek-chat=0.27:1.10,gemini/*=0.10:0.40".
+func ParsePriceTable(spec string) (PriceTable, error) {
+	table := make(PriceTable)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, prices, ok := strings.Cut(part, "=")
+		input, output, okPrices := strings.Cut(prices, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || !okPrices || !strings.Contains(key, "/") {
+			return nil, fmt.Errorf("invalid price %q (want provider/model=input:output)", part)
+		}
+		in, errIn := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		out, errOut := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		if errIn != nil || errOut != nil || in < 0 || out < 0 {
+			return nil, fmt.Errorf("invalid price %q", part)
+		}
+		table[key] = Price{InputPerMillion: in, OutputPerMillion: out}
+	}
+	return table, nil
+}
+
+// EstimateTokens approximates a token count from text (~4 characters per token).
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len([]rune(text)) + 3) / 4
+}
+
+func estimatePromptTokens(messages []ChatMessage) int {
+	total := 0
+	for _, m := range messages {
+		total += EstimateTokens(m.Content) + 4 // Per-message role/formatting overhead
+	}
+	return total
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNsConfig holds Apple Push Notification service token-auth settings
+type APNsConfig struct {
+	KeyID      string // ID of the .p8 signing key
+	TeamID     string
+	BundleID   string // apns-topic
+	PrivateKey []byte // Contents of the .p8 file
+	Sandbox    bool   // Use the development environment
+}
+
+// APNsSender sends push notifications to iOS devices over APNs HTTP/2
+type APNsSender struct {
+	keyID      string
+	teamID     string
+	bundleID   string
+	key        *ecdsa.PrivateKey
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenIssued time.Time
+}
+
+// Ensure APNsSender implements PushSender
+var _ PushSender = (*APNsSender)(nil)
+
+// NewAPNsSender creates an APNs sender
+func NewAPNsSender(config APNsConfig) (*APNsSender, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM(config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	baseURL := "https://api.push.apple.com"
+	if config.Sandbox {
+		baseURL = "https://api.sandbox.push.apple.com"
+	}
+	return &APNsSender{
+		keyID:      config.KeyID,
+		teamID:     config.TeamID,
+		bundleID:   config.BundleID,
+		key:        key,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// authToken returns the provider token, refreshed every 50 minutes
+// (APNs rejects tokens older than an hour).
+func (s *APNsSender) authToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.tokenIssued) < 50*time.Minute {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.keyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs token: %w", err)
+	}
+	s.token, s.tokenIssued = signed, now
+	return signed, nil
+}
+
+// Push implements PushSender.Push
+func (s *APNsSender) Push(ctx context.Context, token string, msg Message) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	auth, err := s.authToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+auth)
+	req.Header.Set("apns-topic", s.bundleID)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(respBody, &apnsErr)
+	switch apnsErr.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("APNs returned status %d: %s", resp.StatusCode, string(respBody))
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds SMTP server settings
+type SMTPConfig struct {
+	Host     string
+	Port     int // Default: 587
+	Username string
+	Password string
+	From     string // e.g. "MomLaunchpad <reminders@example.com>"
+}
+
+// EmailNotifier sends plain-text email over SMTP
+type EmailNotifier struct {
+	config   SMTPConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Ensure EmailNotifier implements Notifier
+var _ Notifier = (*EmailNotifier)(nil)
+
+// NewEmailNotifier creates an SMTP email notifier
+func NewEmailNotifier(config SMTPConfig) *EmailNotifier {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	return &EmailNotifier{config: config, sendMail: smtp.SendMail}
+}
+
+// Channel implements Notifier.Channel
+func (n *EmailNotifier) Channel() string { return ChannelEmail }
+
+// Send implements Notifier.Send
+func (n *EmailNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.Email == "" {
+		return ErrNoRecipient
+	}
+
+	from := n.config.From
+	envelopeFrom := from
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		envelopeFrom = strings.TrimSuffix(from[start+1:], ">")
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	body := buildEmail(from, msg.Email, msg.Title, msg.Body, time.Now())
+
+	// net/smtp has no context support; run it so cancellation is still honored
+	done := make(chan error, 1)
+	go func() { done <- n.sendMail(addr, auth, envelopeFrom, []string{msg.Email}, body) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildEmail(from, to, subject, body string, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
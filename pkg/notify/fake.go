@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LogNotifier only logs messages. It stands in for a channel that has no
+// provider configured in development. Sends always succeed, so it must not
+// be used in production, where deliveries would be marked sent but never arrive.
+type LogNotifier struct {
+	channel string
+}
+
+// NewLogNotifier creates a notifier that logs messages for a channel
+func NewLogNotifier(channel string) *LogNotifier {
+	return &LogNotifier{channel: channel}
+}
+
+// Channel implements Notifier.Channel
+func (n *LogNotifier) Channel() string { return n.channel }
+
+// Send implements Notifier.Send
+func (n *LogNotifier) Send(_ context.Context, msg Message) error {
+	log.Printf("[notify:%s] user=%s title=%q", n.channel, msg.UserID, msg.Title)
+	return nil
+}
+
+// FakeNotifier records messages instead of sending them, for tests
+type FakeNotifier struct {
+	channel string
+
+	mu   sync.Mutex
+	sent []Message
+	errs []error // Returned by successive Send calls; nil entries succeed
+}
+
+// NewFakeNotifier creates a recording notifier for a channel. Send returns
+// errs in order, then succeeds.
+func NewFakeNotifier(channel string, errs ...error) *FakeNotifier {
+	return &FakeNotifier{channel: channel, errs: errs}
+}
+
+// Channel implements Notifier.Channel
+func (n *FakeNotifier) Channel() string { return n.channel }
+
+// Send implements Notifier.Send
+func (n *FakeNotifier) Send(_ context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.errs) > 0 {
+		err := n.errs[0]
+		n.errs = n.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+// Sent returns the messages sent so far
+func (n *FakeNotifier) Sent() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sent := make([]Message, len(n.sent))
+	copy(sent, n.sent)
+	return sent
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMSender sends push notifications through the Firebase Cloud Messaging HTTP v1 API
+type FCMSender struct {
+	projectID  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Ensure FCMSender implements PushSender
+var _ PushSender = (*FCMSender)(nil)
+
+// NewFCMSender creates an FCM sender from a service account credentials JSON file's contents
+func NewFCMSender(ctx context.Context, credentialsJSON []byte) (*FCMSender, error) {
+	creds, err := google.CredentialsFromJSON(ctx, credentialsJSON, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	if creds.ProjectID == "" {
+		return nil, fmt.Errorf("FCM credentials have no project_id")
+	}
+
+	httpClient := oauth2.NewClient(ctx, creds.TokenSource)
+	httpClient.Timeout = 15 * time.Second
+	return &FCMSender{
+		projectID:  creds.ProjectID,
+		baseURL:    "https://fcm.googleapis.com",
+		httpClient: httpClient,
+	}, nil
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+}
+
+// Push implements PushSender.Push
+func (s *FCMSender) Push(ctx context.Context, token string, msg Message) error {
+	body, err := json.Marshal(fcmRequest{Message: fcmMessage{
+		Token:        token,
+		Notification: fcmNotification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", s.baseURL, s.projectID)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	// UNREGISTERED (404) and a malformed token (400) both mean the token is dead
+	if resp.StatusCode == http.StatusNotFound ||
+		(resp.StatusCode == http.StatusBadRequest && strings.Contains(string(respBody), "registration token")) {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("FCM returned status %d: %s", resp.StatusCode, string(respBody))
+}
@@ -0,0 +1,53 @@
+// Package notify delivers notifications to users over push, SMS and email.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Delivery channels
+const (
+	ChannelPush  = "push"
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelVoice = "voice" // Reminder calls, placed by the outbound caller
+)
+
+// Push providers
+const (
+	ProviderFCM  = "fcm"
+	ProviderAPNs = "apns"
+)
+
+var (
+	// ErrNoRecipient means the user has no address on the notifier's
+	// channel; retrying will not help.
+	ErrNoRecipient = errors.New("no recipient for channel")
+	// ErrInvalidToken means a push token is no longer registered with its provider.
+	ErrInvalidToken = errors.New("push token is no longer valid")
+)
+
+// Device is a push notification target
+type Device struct {
+	Provider string // ProviderFCM or ProviderAPNs
+	Token    string
+}
+
+// Message is a notification for one user. Each notifier uses the address
+// for its own channel.
+type Message struct {
+	UserID  string
+	Title   string
+	Body    string
+	Data    map[string]string // Extra push payload (e.g. reminder_id)
+	Email   string
+	Phone   string // E.164
+	Devices []Device
+}
+
+// Notifier delivers messages over one channel
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
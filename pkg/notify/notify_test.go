@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+type fakePushSender struct {
+	errs   map[string]error
+	pushed []string
+}
+
+func (f *fakePushSender) Push(_ context.Context, token string, _ Message) error {
+	if err := f.errs[token]; err != nil {
+		return err
+	}
+	f.pushed = append(f.pushed, token)
+	return nil
+}
+
+func TestPushNotifier_Send(t *testing.T) {
+	fcm := &fakePushSender{errs: map[string]error{"dead": ErrInvalidToken}}
+	apns := &fakePushSender{}
+
+	var removed []string
+	n := NewPushNotifier(map[string]PushSender{ProviderFCM: fcm, ProviderAPNs: apns}, func(d Device) {
+		removed = append(removed, d.Token)
+	})
+
+	err := n.Send(context.Background(), Message{Devices: []Device{
+		{Provider: ProviderFCM, Token: "dead"},
+		{Provider: ProviderAPNs, Token: "ios"},
+	}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(apns.pushed) != 1 {
+		t.Errorf("apns pushed = %v, want ios", apns.pushed)
+	}
+	if len(removed) != 1 || removed[0] != "dead" {
+		t.Errorf("removed = %v, want dead", removed)
+	}
+}
+
+func TestPushNotifier_SendFailures(t *testing.T) {
+	fcm := &fakePushSender{errs: map[string]error{
+		"dead":  ErrInvalidToken,
+		"flaky": errors.New("503"),
+	}}
+	n := NewPushNotifier(map[string]PushSender{ProviderFCM: fcm}, nil)
+
+	err := n.Send(context.Background(), Message{Devices: []Device{{Provider: ProviderFCM, Token: "dead"}}})
+	if !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("only invalid tokens: error = %v, want ErrNoRecipient", err)
+	}
+
+	err = n.Send(context.Background(), Message{Devices: []Device{{Provider: ProviderFCM, Token: "flaky"}}})
+	if err == nil || errors.Is(err, ErrNoRecipient) {
+		t.Errorf("provider error: error = %v, want a retryable error", err)
+	}
+
+	err = n.Send(context.Background(), Message{Devices: []Device{{Provider: ProviderAPNs, Token: "ios"}}})
+	if !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("unconfigured provider: error = %v, want ErrNoRecipient", err)
+	}
+}
+
+type fakeSMSSender struct {
+	to, body string
+}
+
+func (f *fakeSMSSender) SendSMS(_ context.Context, to, body string) (string, error) {
+	f.to, f.body = to, body
+	return "SM123", nil
+}
+
+func TestSMSNotifier_Send(t *testing.T) {
+	sender := &fakeSMSSender{}
+	n := NewSMSNotifier(sender)
+
+	if err := n.Send(context.Background(), Message{Title: "Scan"}); !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("no phone: error = %v, want ErrNoRecipient", err)
+	}
+
+	if err := n.Send(context.Background(), Message{Phone: "+2348012345678", Title: "Scan", Body: "At 10am"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sender.to != "+2348012345678" || sender.body != "Scan: At 10am" {
+		t.Errorf("sent %q to %q", sender.body, sender.to)
+	}
+
+	if err := n.Send(context.Background(), Message{Phone: "+1", Title: strings.Repeat("a", 400)}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := len([]rune(sender.body)); got != 300 {
+		t.Errorf("long message length = %d, want 300", got)
+	}
+}
+
+func TestEmailNotifier_Send(t *testing.T) {
+	n := NewEmailNotifier(SMTPConfig{Host: "smtp.example.com", From: "MomLaunchpad <reminders@example.com>"})
+
+	var gotAddr, gotFrom string
+	var gotMsg []byte
+	n.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotMsg = addr, from, msg
+		return nil
+	}
+
+	if err := n.Send(context.Background(), Message{Title: "Scan"}); !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("no email: error = %v, want ErrNoRecipient", err)
+	}
+
+	if err := n.Send(context.Background(), Message{Email: "mom@example.com", Title: "Ecografía", Body: "Mañana"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" {
+		t.Errorf("addr = %q", gotAddr)
+	}
+	if gotFrom != "reminders@example.com" {
+		t.Errorf("envelope from = %q", gotFrom)
+	}
+	msg := string(gotMsg)
+	if !strings.Contains(msg, "To: mom@example.com\r\n") || !strings.Contains(msg, "Subject: =?utf-8?q?") {
+		t.Errorf("unexpected headers:\n%s", msg)
+	}
+	if !strings.HasSuffix(msg, "\r\n\r\nMañana\r\n") {
+		t.Errorf("unexpected body:\n%s", msg)
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PushSender delivers a push notification to one device token
+type PushSender interface {
+	Push(ctx context.Context, token string, msg Message) error
+}
+
+// PushNotifier sends a message to every registered device of a user,
+// routing each device to the sender for its provider.
+type PushNotifier struct {
+	senders   map[string]PushSender
+	onInvalid func(Device)
+}
+
+// Ensure PushNotifier implements Notifier
+var _ Notifier = (*PushNotifier)(nil)
+
+// NewPushNotifier creates a push notifier. senders maps a provider
+// (ProviderFCM, ProviderAPNs) to its sender; onInvalid, if not nil, is
+// called for tokens the provider no longer recognises.
+func NewPushNotifier(senders map[string]PushSender, onInvalid func(Device)) *PushNotifier {
+	return &PushNotifier{senders: senders, onInvalid: onInvalid}
+}
+
+// Channel implements Notifier.Channel
+func (n *PushNotifier) Channel() string { return ChannelPush }
+
+// Send implements Notifier.Send. It succeeds if at least one device got the message.
+func (n *PushNotifier) Send(ctx context.Context, msg Message) error {
+	var (
+		delivered int
+		errs      []error
+	)
+	for _, device := range msg.Devices {
+		sender, ok := n.senders[device.Provider]
+		if !ok {
+			continue
+		}
+		err := sender.Push(ctx, device.Token, msg)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, ErrInvalidToken):
+			if n.onInvalid != nil {
+				n.onInvalid(device)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w", device.Provider, err))
+		}
+	}
+
+	if delivered > 0 {
+		return nil
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return ErrNoRecipient
+}
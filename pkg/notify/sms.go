@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"strings"
+)
+
+// SMSSender sends a text message (e.g. twilio.MessagingClient)
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) (string, error)
+}
+
+// SMSNotifier sends notifications as text messages
+type SMSNotifier struct {
+	sender SMSSender
+}
+
+// Ensure SMSNotifier implements Notifier
+var _ Notifier = (*SMSNotifier)(nil)
+
+// NewSMSNotifier creates an SMS notifier
+func NewSMSNotifier(sender SMSSender) *SMSNotifier {
+	return &SMSNotifier{sender: sender}
+}
+
+// Channel implements Notifier.Channel
+func (n *SMSNotifier) Channel() string { return ChannelSMS }
+
+// Send implements Notifier.Send
+func (n *SMSNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.Phone == "" {
+		return ErrNoRecipient
+	}
+
+	text := msg.Title
+	if body := strings.TrimSpace(msg.Body); body != "" {
+		text += ": " + body
+	}
+	// Keep reminders to two SMS segments
+	if runes := []rune(text); len(runes) > 300 {
+		text = string(runes[:299]) + "…"
+	}
+
+	_, err := n.sender.SendSMS(ctx, msg.Phone, text)
+	return err
+}
@@ -0,0 +1,254 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+// HTTPClient implements llm.Client using Ollama's native /api/chat endpoint,
+// for self-hosted models that must keep data on our own infrastructure.
+type HTTPClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	keepAlive  string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// Ensure HTTPClient implements llm.Client
+var _ llm.Client = (*HTTPClient)(nil)
+
+// Config holds configuration for the Ollama client
+type Config struct {
+	BaseURL   string        // Default: http://localhost:11434
+	Model     string        // Default: llama3.1
+	APIKey    string        // Optional bearer token when Ollama sits behind an auth proxy
+	KeepAlive string        // Optional: how long the model stays loaded (e.g. "10m")
+	Timeout   time.Duration // Default: 120s (local models load slowly on first request)
+}
+
+// NewHTTPClient creates a new Ollama HTTP client
+func NewHTTPClient(config Config) *HTTPClient {
+	if config.BaseURL == "" {
+		config.BaseURL = "http://localhost:11434"
+	}
+	if config.Model == "" {
+		config.Model = "llama3.1"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 120 * time.Second
+	}
+
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:        20,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &HTTPClient{
+		apiKey:    config.APIKey,
+		baseURL:   strings.TrimRight(config.BaseURL, "/"),
+		model:     config.Model,
+		keepAlive: config.KeepAlive,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+		},
+		timeout: config.Timeout,
+	}
+}
+
+// chatRequest is the body of POST /api/chat
+type chatRequest struct {
+	Model     string            `json:"model"`
+	Messages  []llm.ChatMessage `json:"messages"`
+	Stream    bool              `json:"stream"`
+	KeepAlive string            `json:"keep_alive,omitempty"`
+	Options   *chatOptions      `json:"options,omitempty"`
+}
+
+type chatOptions struct {
+	Temperature float64 `json:"temperature,omitempty"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// chatResponse is a full response or one NDJSON line of a stream
+type chatResponse struct {
+	Model           string          `json:"model"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Message         llm.ChatMessage `json:"message"`
+	Done            bool            `json:"done"`
+	DoneReason      string          `json:"done_reason"`
+	PromptEvalCount int             `json:"prompt_eval_count"`
+	EvalCount       int             `json:"eval_count"`
+	Error           string          `json:"error"`
+}
+
+func (r chatResponse) finishReason() string {
+	if r.DoneReason != "" {
+		return r.DoneReason
+	}
+	return "stop"
+}
+
+// StreamChatCompletion implements llm.Client.StreamChatCompletion
+func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	resp, err := c.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan llm.ChatChunk, 32)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		// Ollama streams newline-delimited JSON objects
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			var part chatResponse
+			if err := json.Unmarshal(line, &part); err != nil || part.Error != "" {
+				// A mid-stream error ends the stream without a finish reason
+				return
+			}
+
+			chunk := llm.ChatChunk{
+				Model:   part.Model,
+				Created: part.CreatedAt.Unix(),
+			}
+			chunk.Choices = make([]struct {
+				Index        int       `json:"index"`
+				Delta        llm.Delta `json:"delta"`
+				FinishReason *string   `json:"finish_reason"`
+			}, 1)
+			chunk.Choices[0].Delta = llm.Delta{Role: part.Message.Role, Content: part.Message.Content}
+			if part.Done {
+				reason := part.finishReason()
+				chunk.Choices[0].FinishReason = &reason
+				chunk.Usage = &llm.Usage{
+					PromptTokens:     part.PromptEvalCount,
+					CompletionTokens: part.EvalCount,
+					TotalTokens:      part.PromptEvalCount + part.EvalCount,
+				}
+			}
+
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			if part.Done {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ChatCompletion implements llm.Client.ChatCompletion
+func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	resp, err := c.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var oResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&oResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if oResp.Error != "" {
+		return nil, fmt.Errorf("API returned error: %s", oResp.Error)
+	}
+
+	chatResp := &llm.ChatResponse{
+		Object:  "chat.completion",
+		Created: oResp.CreatedAt.Unix(),
+		Model:   oResp.Model,
+	}
+	chatResp.Choices = make([]struct {
+		Index   int `json:"index"`
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	}, 1)
+	chatResp.Choices[0].Message.Role = oResp.Message.Role
+	chatResp.Choices[0].Message.Content = oResp.Message.Content
+	chatResp.Choices[0].FinishReason = oResp.finishReason()
+	chatResp.Usage.PromptTokens = oResp.PromptEvalCount
+	chatResp.Usage.CompletionTokens = oResp.EvalCount
+	chatResp.Usage.TotalTokens = oResp.PromptEvalCount + oResp.EvalCount
+
+	return chatResp, nil
+}
+
+// post sends a chat request and returns the response on HTTP 200.
+func (c *HTTPClient) post(ctx context.Context, req llm.ChatRequest, stream bool) (*http.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = c.model
+	}
+
+	oReq := chatRequest{
+		Model:     model,
+		Messages:  req.Messages,
+		Stream:    stream,
+		KeepAlive: c.keepAlive,
+	}
+	if req.Temperature != 0 || req.MaxTokens != 0 {
+		oReq.Options = &chatOptions{Temperature: req.Temperature, NumPredict: req.MaxTokens}
+	}
+
+	body, err := json.Marshal(oReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,174 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+// newStubServer mimics Ollama's /api/chat endpoint.
+func newStubServer(t *testing.T, handler func(w http.ResponseWriter, req chatRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client := NewHTTPClient(Config{})
+	if client.baseURL != "http://localhost:11434" {
+		t.Errorf("baseURL = %v", client.baseURL)
+	}
+	if client.model != "llama3.1" {
+		t.Errorf("model = %v", client.model)
+	}
+	if client.timeout != 120*time.Second {
+		t.Errorf("timeout = %v", client.timeout)
+	}
+}
+
+func TestHTTPClient_ChatCompletion(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, req chatRequest) {
+		if req.Stream {
+			t.Error("expected non-streaming request")
+		}
+		if req.Model != "qwen2.5" {
+			t.Errorf("model = %s, want qwen2.5", req.Model)
+		}
+		if req.Options == nil || req.Options.NumPredict != 64 || req.Options.Temperature != 0.2 {
+			t.Errorf("unexpected options: %+v", req.Options)
+		}
+		w.Write([]byte(`{"model":"qwen2.5","created_at":"2026-01-02T03:04:05Z","message":{"role":"assistant","content":"Hello there"},"done":true,"done_reason":"stop","prompt_eval_count":12,"eval_count":3}`))
+	})
+
+	client := NewHTTPClient(Config{BaseURL: server.URL, Model: "qwen2.5", Timeout: 5 * time.Second})
+	resp, err := client.ChatCompletion(context.Background(), llm.ChatRequest{
+		Messages:    []llm.ChatMessage{{Role: "user", Content: "Hi"}},
+		Temperature: 0.2,
+		MaxTokens:   64,
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletion() error = %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hello there" || resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("unexpected choice: %+v", resp.Choices[0])
+	}
+	if resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 3 || resp.Usage.TotalTokens != 15 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestHTTPClient_ChatCompletionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"model not found", http.StatusNotFound, `{"error":"model \"x\" not found"}`, "API returned status 404"},
+		{"malformed response", http.StatusOK, `{nope`, "failed to decode response"},
+		{"error payload", http.StatusOK, `{"error":"out of memory"}`, "out of memory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, func(w http.ResponseWriter, _ chatRequest) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := NewHTTPClient(Config{BaseURL: server.URL}).ChatCompletion(context.Background(), llm.ChatRequest{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_StreamChatCompletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantContent string
+		wantFinish  bool
+	}{
+		{
+			name: "complete stream",
+			body: `{"model":"llama3.1","message":{"role":"assistant","content":"Hel"},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":"lo"},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}
+`,
+			wantContent: "Hello",
+			wantFinish:  true,
+		},
+		{
+			name: "error mid-stream ends without finish reason",
+			body: `{"model":"llama3.1","message":{"role":"assistant","content":"Hel"},"done":false}
+{"error":"model crashed"}
+`,
+			wantContent: "Hel",
+			wantFinish:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStubServer(t, func(w http.ResponseWriter, req chatRequest) {
+				if !req.Stream {
+					t.Error("expected streaming request")
+				}
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.Write([]byte(tt.body))
+			})
+
+			ch, err := NewHTTPClient(Config{BaseURL: server.URL}).StreamChatCompletion(context.Background(), llm.ChatRequest{
+				Messages: []llm.ChatMessage{{Role: "user", Content: "Hi"}},
+			})
+			if err != nil {
+				t.Fatalf("StreamChatCompletion() error = %v", err)
+			}
+
+			var content strings.Builder
+			finished := false
+			for chunk := range ch {
+				content.WriteString(chunk.Choices[0].Delta.Content)
+				if chunk.Choices[0].FinishReason != nil {
+					finished = true
+				}
+			}
+			if content.String() != tt.wantContent {
+				t.Errorf("content = %q, want %q", content.String(), tt.wantContent)
+			}
+			if finished != tt.wantFinish {
+				t.Errorf("finished = %v, want %v", finished, tt.wantFinish)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_StreamChatCompletion_StatusError(t *testing.T) {
+	server := newStubServer(t, func(w http.ResponseWriter, _ chatRequest) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("loading model"))
+	})
+
+	_, err := NewHTTPClient(Config{BaseURL: server.URL}).StreamChatCompletion(context.Background(), llm.ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "API returned status 503: loading model") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+// HTTPClient implements llm.Client for any OpenAI-compatible chat completions API
+// (OpenAI, Azure OpenAI, OpenRouter, vLLM, llama.cpp server, LM Studio, ...).
+type HTTPClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	authHeader string
+	authScheme string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// Ensure HTTPClient implements llm.Client
+var _ llm.Client = (*HTTPClient)(nil)
+
+// Config holds configuration for an OpenAI-compatible client
+type Config struct {
+	APIKey     string        // Optional for local servers
+	BaseURL    string        // Default: https://api.openai.com/v1
+	Model      string        // Default: gpt-4o-mini
+	AuthHeader string        // Default: Authorization (Azure uses api-key)
+	AuthScheme string        // Default: Bearer when AuthHeader is Authorization, otherwise none
+	Timeout    time.Duration // Default: 30s
+}
+
+// NewHTTPClient creates a new OpenAI-compatible HTTP client
+func NewHTTPClient(config Config) *HTTPClient {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.openai.com/v1"
+	}
+	if config.Model == "" {
+		config.Model = "gpt-4o-mini"
+	}
+	if config.AuthHeader == "" {
+		config.AuthHeader = "Authorization"
+	}
+	if config.AuthScheme == "" && strings.EqualFold(config.AuthHeader, "Authorization") {
+		config.AuthScheme = "Bearer"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	return &HTTPClient{
+		apiKey:     config.APIKey,
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		model:      config.Model,
+		authHeader: config.AuthHeader,
+		authScheme: config.AuthScheme,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: transport,
+		},
+		timeout: config.Timeout,
+	}
+}
+
+// StreamChatCompletion implements llm.Client.StreamChatCompletion
+func (c *HTTPClient) StreamChatCompletion(ctx context.Context, req llm.ChatRequest) (<-chan llm.ChatChunk, error) {
+	if req.Model == "" {
+		req.Model = c.model
+	}
+	req.Stream = true
+	req.StreamOptions = &llm.StreamOptions{IncludeUsage: true}
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan llm.ChatChunk, 32)
+
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			// SSE format: "data: {...}"; some servers omit the space after the colon
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				break
+			}
+
+			var chunk llm.ChatChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ChatCompletion implements llm.Client.ChatCompletion
+func (c *HTTPClient) ChatCompletion(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if req.Model == "" {
+		req.Model = c.model
+	}
+	req.Stream = false
+	req.StreamOptions = nil
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chatResp llm.ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &chatResp, nil
+}
+
+// post sends a chat completions request and returns the response on HTTP 200.
+func (c *HTTPClient) post(ctx context.Context, req llm.ChatRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		value := c.apiKey
+		if c.authScheme != "" {
+			value = c.authScheme + " " + c.apiKey
+		}
+		httpReq.Header.Set(c.authHeader, value)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,226 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/themobileprof/momlaunchpad-be/pkg/llm"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         Config
+		wantBaseURL    string
+		wantModel      string
+		wantAuthHeader string
+		wantAuthScheme string
+	}{
+		{
+			name:           "default configuration",
+			config:         Config{APIKey: "test-key"},
+			wantBaseURL:    "https://api.openai.com/v1",
+			wantModel:      "gpt-4o-mini",
+			wantAuthHeader: "Authorization",
+			wantAuthScheme: "Bearer",
+		},
+		{
+			name: "azure-style api-key header",
+			config: Config{
+				APIKey:     "test-key",
+				BaseURL:    "https://example.openai.azure.com/openai/deployments/chat/",
+				Model:      "gpt-4o",
+				AuthHeader: "api-key",
+			},
+			wantBaseURL:    "https://example.openai.azure.com/openai/deployments/chat",
+			wantModel:      "gpt-4o",
+			wantAuthHeader: "api-key",
+			wantAuthScheme: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPClient(tt.config)
+
+			if client.baseURL != tt.wantBaseURL {
+				t.Errorf("baseURL = %v, want %v", client.baseURL, tt.wantBaseURL)
+			}
+			if client.model != tt.wantModel {
+				t.Errorf("model = %v, want %v", client.model, tt.wantModel)
+			}
+			if client.authHeader != tt.wantAuthHeader {
+				t.Errorf("authHeader = %v, want %v", client.authHeader, tt.wantAuthHeader)
+			}
+			if client.authScheme != tt.wantAuthScheme {
+				t.Errorf("authScheme = %v, want %v", client.authScheme, tt.wantAuthScheme)
+			}
+			if client.timeout != 30*time.Second {
+				t.Errorf("timeout = %v, want 30s", client.timeout)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_AuthHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		wantHeader string
+		wantValue  string
+	}{
+		{"bearer", Config{APIKey: "k"}, "Authorization", "Bearer k"},
+		{"custom header", Config{APIKey: "k", AuthHeader: "api-key"}, "api-key", "k"},
+		{"no key for local servers", Config{}, "Authorization", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get(tt.wantHeader); got != tt.wantValue {
+					t.Errorf("%s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+				}
+				w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+			}))
+			defer server.Close()
+
+			tt.config.BaseURL = server.URL
+			if _, err := NewHTTPClient(tt.config).ChatCompletion(context.Background(), llm.ChatRequest{}); err != nil {
+				t.Fatalf("ChatCompletion() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_StreamChatCompletion(t *testing.T) {
+	tests := []struct {
+		name           string
+		serverResponse string
+		statusCode     int
+		wantError      bool
+		wantContent    string
+		wantFinish     bool
+	}{
+		{
+			name:       "successful streaming",
+			statusCode: http.StatusOK,
+			serverResponse: `data: {"id":"c1","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
+
+data:{"id":"c2","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`,
+			wantContent: "Hello world",
+			wantFinish:  true,
+		},
+		{
+			name:       "malformed chunk is skipped",
+			statusCode: http.StatusOK,
+			serverResponse: `data: not json
+
+data: {"id":"c1","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]}
+
+data: [DONE]
+`,
+			wantContent: "Hi",
+		},
+		{
+			name:           "API error response",
+			statusCode:     http.StatusTooManyRequests,
+			serverResponse: `{"error":{"message":"rate limited"}}`,
+			wantError:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/chat/completions" {
+					t.Errorf("path = %s, want /chat/completions", r.URL.Path)
+				}
+				var body llm.ChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body.Stream || body.Model != "local-model" {
+					t.Errorf("unexpected request body: %+v (err %v)", body, err)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.serverResponse))
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(Config{BaseURL: server.URL, Model: "local-model", Timeout: 5 * time.Second})
+			ch, err := client.StreamChatCompletion(context.Background(), llm.ChatRequest{
+				Messages: []llm.ChatMessage{{Role: "user", Content: "Hello"}},
+			})
+
+			if tt.wantError {
+				if err == nil || !strings.Contains(err.Error(), "status 429") {
+					t.Errorf("expected status error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StreamChatCompletion() error = %v", err)
+			}
+
+			var content strings.Builder
+			finished := false
+			for chunk := range ch {
+				for _, choice := range chunk.Choices {
+					content.WriteString(choice.Delta.Content)
+					if choice.FinishReason != nil {
+						finished = true
+					}
+				}
+			}
+			if content.String() != tt.wantContent {
+				t.Errorf("content = %q, want %q", content.String(), tt.wantContent)
+			}
+			if finished != tt.wantFinish {
+				t.Errorf("finished = %v, want %v", finished, tt.wantFinish)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_ChatCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body llm.ChatRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Stream || body.Model != "override" {
+			t.Errorf("unexpected request body: %+v", body)
+		}
+		w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"model": "override",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi there"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 5, "completion_tokens": 2, "total_tokens": 7}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(Config{APIKey: "k", BaseURL: server.URL})
+	resp, err := client.ChatCompletion(context.Background(), llm.ChatRequest{Model: "override"})
+	if err != nil {
+		t.Fatalf("ChatCompletion() error = %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hi there" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	errServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer errServer.Close()
+
+	_, err = NewHTTPClient(Config{BaseURL: errServer.URL}).ChatCompletion(context.Background(), llm.ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "API returned status 500: boom") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
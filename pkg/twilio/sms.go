@@ -0,0 +1,128 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WhatsAppPrefix marks WhatsApp addresses in the Messages API, e.g. whatsapp:+15551234567
+const WhatsAppPrefix = "whatsapp:"
+
+// MessagingClient sends SMS and WhatsApp messages through the Twilio Messages API
+type MessagingClient struct {
+	accountSID   string
+	authToken    string
+	from         string
+	whatsAppFrom string
+	baseURL      string
+	httpClient   *http.Client
+}
+
+// MessagingConfig holds Twilio Messaging configuration
+type MessagingConfig struct {
+	AccountSID   string
+	AuthToken    string
+	From         string // Sender number (E.164) or messaging service SID (MG...)
+	WhatsAppFrom string // WhatsApp Business sender number (E.164); empty disables WhatsApp
+	BaseURL      string // Default: https://api.twilio.com
+}
+
+// IncomingMessageParams represents the parameters of an inbound SMS or WhatsApp webhook
+type IncomingMessageParams struct {
+	MessageSid string
+	From       string // E.164, or whatsapp:+E.164
+	To         string
+	Body       string
+	NumMedia   int
+}
+
+// ParseIncomingMessage parses an inbound message webhook
+func ParseIncomingMessage(values url.Values) IncomingMessageParams {
+	numMedia, _ := strconv.Atoi(values.Get("NumMedia"))
+	return IncomingMessageParams{
+		MessageSid: values.Get("MessageSid"),
+		From:       values.Get("From"),
+		To:         values.Get("To"),
+		Body:       values.Get("Body"),
+		NumMedia:   numMedia,
+	}
+}
+
+// NewMessagingClient creates a new Twilio Messaging client
+func NewMessagingClient(config MessagingConfig) *MessagingClient {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.twilio.com"
+	}
+	return &MessagingClient{
+		accountSID:   config.AccountSID,
+		authToken:    config.AuthToken,
+		from:         config.From,
+		whatsAppFrom: config.WhatsAppFrom,
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		httpClient:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SendSMS sends a text message and returns its Twilio message SID
+func (c *MessagingClient) SendSMS(ctx context.Context, to, body string) (string, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", body)
+	if strings.HasPrefix(c.from, "MG") {
+		form.Set("MessagingServiceSid", c.from)
+	} else {
+		form.Set("From", c.from)
+	}
+	return c.send(ctx, form)
+}
+
+// SendWhatsApp sends a WhatsApp message to an E.164 number and returns its
+// Twilio message SID. Free-form messages only reach users who wrote to us in
+// the last 24 hours.
+func (c *MessagingClient) SendWhatsApp(ctx context.Context, to, body string) (string, error) {
+	if c.whatsAppFrom == "" {
+		return "", fmt.Errorf("no WhatsApp sender configured")
+	}
+	form := url.Values{}
+	form.Set("To", WhatsAppPrefix+strings.TrimPrefix(to, WhatsAppPrefix))
+	form.Set("From", WhatsAppPrefix+strings.TrimPrefix(c.whatsAppFrom, WhatsAppPrefix))
+	form.Set("Body", body)
+	return c.send(ctx, form)
+}
+
+func (c *MessagingClient) send(ctx context.Context, form url.Values) (string, error) {
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", c.baseURL, c.accountSID)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("twilio returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		SID string `json:"sid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.SID, nil
+}
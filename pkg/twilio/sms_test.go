@@ -0,0 +1,90 @@
+package twilio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSendSMS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, _ := r.BasicAuth(); user != "AC123" || pass != "secret" {
+			t.Errorf("basic auth = %s:%s", user, pass)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("To") != "+15551234567" || r.PostForm.Get("Body") != "hi" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		if r.PostForm.Get("MessagingServiceSid") != "MG456" || r.PostForm.Get("From") != "" {
+			t.Errorf("sender = %v", r.PostForm)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM789"}`))
+	}))
+	defer server.Close()
+
+	client := NewMessagingClient(MessagingConfig{AccountSID: "AC123", AuthToken: "secret", From: "MG456", BaseURL: server.URL})
+	sid, err := client.SendSMS(context.Background(), "+15551234567", "hi")
+	if err != nil {
+		t.Fatalf("SendSMS() error = %v", err)
+	}
+	if sid != "SM789" {
+		t.Errorf("sid = %q, want SM789", sid)
+	}
+}
+
+func TestSendSMS_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+	}))
+	defer server.Close()
+
+	client := NewMessagingClient(MessagingConfig{AccountSID: "AC123", From: "+15550000000", BaseURL: server.URL})
+	if _, err := client.SendSMS(context.Background(), "bad", "hi"); err == nil {
+		t.Error("expected error for 400 response")
+	}
+}
+
+func TestSendWhatsApp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("To") != "whatsapp:+2348012345678" || r.PostForm.Get("From") != "whatsapp:+15550000000" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM790"}`))
+	}))
+	defer server.Close()
+
+	client := NewMessagingClient(MessagingConfig{AccountSID: "AC123", From: "MG456", WhatsAppFrom: "+15550000000", BaseURL: server.URL})
+	if sid, err := client.SendWhatsApp(context.Background(), "+2348012345678", "hi"); err != nil || sid != "SM790" {
+		t.Errorf("SendWhatsApp() = %q, %v", sid, err)
+	}
+
+	client = NewMessagingClient(MessagingConfig{AccountSID: "AC123", From: "MG456", BaseURL: server.URL})
+	if _, err := client.SendWhatsApp(context.Background(), "+2348012345678", "hi"); err == nil {
+		t.Error("expected error without a WhatsApp sender")
+	}
+}
+
+func TestParseIncomingMessage(t *testing.T) {
+	params := ParseIncomingMessage(url.Values{
+		"MessageSid": {"SM1"},
+		"From":       {"whatsapp:+2348012345678"},
+		"Body":       {"YES"},
+		"NumMedia":   {"1"},
+	})
+	if params.MessageSid != "SM1" || params.From != "whatsapp:+2348012345678" || params.Body != "YES" || params.NumMedia != 1 {
+		t.Errorf("params = %+v", params)
+	}
+}
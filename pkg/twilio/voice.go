@@ -1,13 +1,19 @@
 package twilio
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // VoiceClient handles Twilio Voice operations
@@ -15,28 +21,76 @@ type VoiceClient struct {
 	accountSID  string
 	authToken   string
 	phoneNumber string
+	baseURL     string
+	httpClient  *http.Client
 }
 
 // VoiceConfig holds Twilio Voice configuration
 type VoiceConfig struct {
 	AccountSID  string
 	AuthToken   string
-	PhoneNumber string
+	PhoneNumber string // Caller ID for outbound calls (E.164)
+	BaseURL     string // Default: https://api.twilio.com
 }
 
 // NewVoiceClient creates a new Twilio Voice client
 func NewVoiceClient(config VoiceConfig) *VoiceClient {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.twilio.com"
+	}
 	return &VoiceClient{
 		accountSID:  config.AccountSID,
 		authToken:   config.AuthToken,
 		phoneNumber: config.PhoneNumber,
+		baseURL:     strings.TrimRight(config.BaseURL, "/"),
+		httpClient:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// CreateCall places an outbound call from our number and returns its CallSid.
+// Twilio fetches TwiML from answerURL when the call is answered and posts the
+// final status (completed, busy, no-answer, failed) to statusURL.
+func (c *VoiceClient) CreateCall(ctx context.Context, to, answerURL, statusURL string) (string, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", c.phoneNumber)
+	form.Set("Url", answerURL)
+	form.Set("StatusCallback", statusURL)
+	form.Set("Timeout", "30") // Seconds to ring before no-answer
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Calls.json", c.baseURL, c.accountSID)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("twilio returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		SID string `json:"sid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
+	return result.SID, nil
 }
 
-// ValidateRequest validates a Twilio webhook request signature
-func (c *VoiceClient) ValidateRequest(url string, params map[string]string, signature string) bool {
+// ValidateRequest validates a Twilio webhook request signature. A parameter
+// sent more than once contributes each of its values, in sorted order.
+func (c *VoiceClient) ValidateRequest(webhookURL string, params url.Values, signature string) bool {
 	// Build data string as per Twilio's validation algorithm
-	data := url
+	data := webhookURL
 
 	// Sort keys alphabetically
 	keys := make([]string, 0, len(params))
@@ -45,9 +99,13 @@ func (c *VoiceClient) ValidateRequest(url string, params map[string]string, sign
 	}
 	sort.Strings(keys)
 
-	// Append key-value pairs
+	// Append key-value pairs, once per value
 	for _, k := range keys {
-		data += k + params[k]
+		values := append([]string(nil), params[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			data += k + v
+		}
 	}
 
 	// Compute HMAC-SHA1
@@ -159,6 +217,7 @@ type IncomingCallParams struct {
 	CallStatus    CallStatus
 	Direction     string
 	ForwardedFrom string
+	CallDuration  int // Seconds, on the final status callback
 }
 
 // GatherParams represents parameters from a Gather callback
@@ -166,12 +225,14 @@ type GatherParams struct {
 	CallSid              string
 	AccountSid           string
 	SpeechResult         string
+	Digits               string // Keys pressed, when the Gather accepts dtmf
 	Confidence           string
 	UnstableSpeechResult string
 }
 
 // ParseIncomingCall parses URL values into IncomingCallParams
 func ParseIncomingCall(values url.Values) IncomingCallParams {
+	duration, _ := strconv.Atoi(values.Get("CallDuration"))
 	return IncomingCallParams{
 		CallSid:       values.Get("CallSid"),
 		AccountSid:    values.Get("AccountSid"),
@@ -180,6 +241,7 @@ func ParseIncomingCall(values url.Values) IncomingCallParams {
 		CallStatus:    CallStatus(values.Get("CallStatus")),
 		Direction:     values.Get("Direction"),
 		ForwardedFrom: values.Get("ForwardedFrom"),
+		CallDuration:  duration,
 	}
 }
 
@@ -189,6 +251,7 @@ func ParseGather(values url.Values) GatherParams {
 		CallSid:              values.Get("CallSid"),
 		AccountSid:           values.Get("AccountSid"),
 		SpeechResult:         values.Get("SpeechResult"),
+		Digits:               values.Get("Digits"),
 		Confidence:           values.Get("Confidence"),
 		UnstableSpeechResult: values.Get("UnstableSpeechResult"),
 	}
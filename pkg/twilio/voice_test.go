@@ -1,6 +1,9 @@
 package twilio
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
@@ -80,6 +83,58 @@ func TestParseIncomingCall(t *testing.T) {
 	if params.CallStatus != CallStatusInProgress {
 		t.Errorf("got CallStatus %s, want in-progress", params.CallStatus)
 	}
+
+	values.Set("CallDuration", "42")
+	if params := ParseIncomingCall(values); params.CallDuration != 42 {
+		t.Errorf("got CallDuration %d, want 42", params.CallDuration)
+	}
+}
+
+func TestCreateCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Calls.json" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, _ := r.BasicAuth(); user != "AC123" || pass != "secret" {
+			t.Errorf("basic auth = %s:%s", user, pass)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("To") != "+2348012345678" || r.PostForm.Get("From") != "+15550000000" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		if r.PostForm.Get("Url") != "https://api.example.com/api/voice/outbound?callId=1" ||
+			r.PostForm.Get("StatusCallback") != "https://api.example.com/api/voice/status" {
+			t.Errorf("callbacks = %v", r.PostForm)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"CA789","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	client := NewVoiceClient(VoiceConfig{AccountSID: "AC123", AuthToken: "secret", PhoneNumber: "+15550000000", BaseURL: server.URL})
+	sid, err := client.CreateCall(context.Background(), "+2348012345678",
+		"https://api.example.com/api/voice/outbound?callId=1", "https://api.example.com/api/voice/status")
+	if err != nil {
+		t.Fatalf("CreateCall() error = %v", err)
+	}
+	if sid != "CA789" {
+		t.Errorf("sid = %q, want CA789", sid)
+	}
+}
+
+func TestCreateCall_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":21217,"message":"Phone number is not a valid mobile number"}`))
+	}))
+	defer server.Close()
+
+	client := NewVoiceClient(VoiceConfig{AccountSID: "AC123", BaseURL: server.URL})
+	if _, err := client.CreateCall(context.Background(), "bad", "https://a/answer", "https://a/status"); err == nil {
+		t.Error("expected error for 400 response")
+	}
 }
 
 func TestParseGather(t *testing.T) {